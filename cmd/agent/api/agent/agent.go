@@ -27,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	settingshttp "github.com/DataDog/datadog-agent/pkg/config/settings/http"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
@@ -36,6 +37,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
 
 type contextKey struct {
@@ -66,7 +68,12 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.SetValue).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
+	r.HandleFunc("/workload-list", getWorkloadList).Methods("GET")
+	r.HandleFunc("/workload-list/stream", streamWorkloadList).Methods("POST")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
+	r.HandleFunc("/forwarder/retry-queue", listRetryQueue).Methods("GET")
+	r.HandleFunc("/forwarder/retry-queue/purge", purgeRetryQueue).Methods("POST")
+	r.HandleFunc("/forwarder/retry-queue/retry", retryRetryQueue).Methods("POST")
 
 	return r
 }
@@ -383,6 +390,63 @@ func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonTags)
 }
 
+func getWorkloadList(w http.ResponseWriter, r *http.Request) {
+	verbose := r.URL.Query().Get("verbose") == "true"
+	response := workloadmeta.GetGlobalStore().Dump(verbose)
+
+	jsonDump, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal workload list response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonDump)
+}
+
+func streamWorkloadList(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request for stream workload list.")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Errorf("Expected a Flusher type, got: %v", w)
+		return
+	}
+
+	verbose := r.URL.Query().Get("verbose") == "true"
+
+	// Reset the `server_timeout` deadline for this connection as streaming holds the connection open.
+	conn := GetConnection(r)
+	_ = conn.SetDeadline(time.Time{})
+
+	store := workloadmeta.GetGlobalStore()
+	eventCh := store.Subscribe("workload-list-stream", nil)
+	defer store.Unsubscribe(eventCh)
+
+	flushTimer := time.NewTicker(time.Second)
+	defer flushTimer.Stop()
+	for {
+		// Handlers for detecting a closed connection (from either the server or client)
+		select {
+		case <-w.(http.CloseNotifier).CloseNotify():
+			return
+		case <-r.Context().Done():
+			return
+		case bundle := <-eventCh:
+			// the store waits for the bundle to be processed before
+			// sending the next one, so release it right away
+			close(bundle.Ch)
+			for _, ev := range bundle.Events {
+				fmt.Fprint(w, workloadmeta.FormatEvent(ev, verbose))
+			}
+		case <-flushTimer.C:
+			// The buffer will flush on its own most of the time, but when we run out of events flush so the client is up to date.
+			flusher.Flush()
+		}
+	}
+}
+
 func secretInfo(w http.ResponseWriter, r *http.Request) {
 	info, err := secrets.GetDebugInfo()
 	if err != nil {
@@ -401,6 +465,87 @@ func secretInfo(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonInfo)
 }
 
+// getRetryQueueManager returns the retry queue manager of the global forwarder and the
+// filter of the request. It writes the error response and returns false on failure.
+func getRetryQueueManager(w http.ResponseWriter, r *http.Request) (forwarder.RetryQueueManager, forwarder.RetryQueueFilter, bool) {
+	manager, ok := common.Forwarder.(forwarder.RetryQueueManager)
+	if !ok {
+		log.Errorf("Trying to use /forwarder/retry-queue before the forwarder has been initialized.")
+		body, _ := json.Marshal(map[string]string{"error": "forwarder not initialized"})
+		http.Error(w, string(body), 503)
+		return nil, forwarder.RetryQueueFilter{}, false
+	}
+
+	filter, err := forwarder.RetryQueueFilterFromQuery(r.URL.Query())
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 400)
+		return nil, filter, false
+	}
+	return manager, filter, true
+}
+
+func listRetryQueue(w http.ResponseWriter, r *http.Request) {
+	manager, filter, ok := getRetryQueueManager(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := manager.ListRetryQueue(filter)
+	if err != nil {
+		log.Errorf("Unable to list the forwarder retry queue: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	if transactions == nil {
+		transactions = []forwarder.RetryQueueTransaction{}
+	}
+
+	jsonTransactions, err := json.Marshal(transactions)
+	if err != nil {
+		log.Errorf("Unable to marshal retry queue response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonTransactions)
+}
+
+func purgeRetryQueue(w http.ResponseWriter, r *http.Request) {
+	manager, filter, ok := getRetryQueueManager(w, r)
+	if !ok {
+		return
+	}
+
+	log.Infof("Got a request to purge the forwarder retry queue with the filter %q.", filter.ToQuery().Encode())
+	count, err := manager.PurgeRetryQueue(filter)
+	writeRetryQueueActionResponse(w, count, err)
+}
+
+func retryRetryQueue(w http.ResponseWriter, r *http.Request) {
+	manager, filter, ok := getRetryQueueManager(w, r)
+	if !ok {
+		return
+	}
+
+	log.Infof("Got a request to retry the forwarder retry queue with the filter %q.", filter.ToQuery().Encode())
+	count, err := manager.RetryRetryQueue(filter)
+	writeRetryQueueActionResponse(w, count, err)
+}
+
+func writeRetryQueueActionResponse(w http.ResponseWriter, count int, err error) {
+	if err != nil {
+		log.Errorf("Error while updating the forwarder retry queue, %d transactions were processed: %s", count, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	jsonResponse, _ := json.Marshal(response.RetryQueueActionResponse{Count: count})
+	w.Write(jsonResponse)
+}
+
 // max returns the maximum value between a and b.
 func max(a, b int) int {
 	if a > b {
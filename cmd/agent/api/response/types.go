@@ -26,3 +26,8 @@ type TaggerListResponse struct {
 type TaggerListEntity struct {
 	Tags map[string][]string `json:"tags"`
 }
+
+// RetryQueueActionResponse holds the response of the forwarder retry queue purge and retry
+type RetryQueueActionResponse struct {
+	Count int `json:"count"`
+}
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/dryrun"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/flare"
+)
+
+var (
+	dryRunTemplatePaths   []string
+	dryRunAnnotationPaths []string
+	dryRunCheckName       string
+	dryRunServicePath     string
+)
+
+func init() {
+	AgentCmd.AddCommand(adDryRunCommand)
+
+	adDryRunCommand.Flags().StringSliceVarP(&dryRunTemplatePaths, "template", "t", nil, "template file, in the format of the configuration files")
+	adDryRunCommand.Flags().StringSliceVarP(&dryRunAnnotationPaths, "annotations", "a", nil, "YAML or JSON file of pod annotations defining templates")
+	adDryRunCommand.Flags().StringVar(&dryRunCheckName, "check", "", "check name of the template files, defaults to their file or conf.d directory name")
+	adDryRunCommand.Flags().StringVarP(&dryRunServicePath, "service", "s", "", "YAML description of the simulated service")
+	adDryRunCommand.MarkFlagRequired("service") //nolint:errcheck
+}
+
+var adDryRunCommand = &cobra.Command{
+	Use:   "ad-dry-run",
+	Short: "Resolve autodiscovery templates against a simulated service",
+	Long: `Resolve autodiscovery templates against a simulated service described in YAML,
+and print the resolved configs or the resolution errors. Nothing is scheduled.
+
+The service description supports the following keys: entity, name (container
+name, matched by the annotation templates), image, ad_identifiers, labels,
+annotations, hosts (network name to IP address), ports (list of port and name),
+pid, hostname, tags and extra_config.`,
+	RunE: doADDryRun,
+}
+
+func doADDryRun(cmd *cobra.Command, args []string) error {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	if len(dryRunTemplatePaths) == 0 && len(dryRunAnnotationPaths) == 0 {
+		return fmt.Errorf("at least one template or annotations file is required")
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return err
+	}
+
+	svc, err := dryrun.LoadService(dryRunServicePath)
+	if err != nil {
+		return err
+	}
+
+	var templates []integration.Config
+	for _, path := range dryRunAnnotationPaths {
+		tpls, err := dryrun.LoadAnnotationTemplates(path, svc)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, tpls...)
+	}
+	for _, path := range dryRunTemplatePaths {
+		tpl, err := dryrun.LoadFileTemplate(path, dryRunCheckName)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, tpl)
+	}
+
+	results := dryrun.Resolve(templates, svc)
+	printDryRunResults(color.Output, results)
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d templates could not be resolved", failed, len(results))
+	}
+	return nil
+}
+
+func printDryRunResults(w io.Writer, results []dryrun.Result) {
+	for _, result := range results {
+		if result.Error == nil {
+			flare.PrintConfig(w, result.Config)
+			continue
+		}
+		fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(result.Template.Name)))
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Configuration source"), color.CyanString(result.Template.Source)))
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Resolution error"), color.RedString(result.Error.Error())))
+		fmt.Fprintln(w, "===")
+	}
+}
@@ -0,0 +1,254 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+var (
+	forwarderCmd = &cobra.Command{
+		Use:   "forwarder",
+		Short: "Manage the retry queue of the forwarder of a running agent",
+		Long: `List, purge or retry the transactions waiting in the retry queues of the forwarder,
+in memory and on disk. The flags select the transactions, every transaction is selected
+when no flag is set.`,
+	}
+
+	forwarderListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the transactions of the retry queue",
+		Long:  ``,
+		RunE:  forwarderList,
+	}
+
+	forwarderPurgeCmd = &cobra.Command{
+		Use:   "purge",
+		Short: "Remove transactions from the retry queue",
+		Long: `Remove the selected transactions from the retry queue. The transactions are lost.
+--all is required to remove every transaction.`,
+		RunE: forwarderPurge,
+	}
+
+	forwarderRetryCmd = &cobra.Command{
+		Use:   "retry",
+		Short: "Send the transactions of the retry queue immediately",
+		Long: `Send the selected transactions without waiting for the end of the backoff of their
+endpoints. The transactions which fail again go back to the retry queue.`,
+		RunE: forwarderRetry,
+	}
+
+	forwarderArgs = struct {
+		domain    string
+		endpoint  string
+		priority  string
+		location  string
+		olderThan time.Duration
+		newerThan time.Duration
+		json      bool
+		all       bool
+	}{}
+)
+
+func init() {
+	AgentCmd.AddCommand(forwarderCmd)
+	forwarderCmd.AddCommand(forwarderListCmd, forwarderPurgeCmd, forwarderRetryCmd)
+
+	flags := forwarderCmd.PersistentFlags()
+	flags.StringVarP(&forwarderArgs.domain, "domain", "d", "", "select the transactions of a domain")
+	flags.StringVarP(&forwarderArgs.endpoint, "endpoint", "e", "", "select the transactions of an endpoint, for example series_v1")
+	flags.StringVarP(&forwarderArgs.priority, "priority", "", "", "select the transactions of a priority: normal or high")
+	flags.StringVarP(&forwarderArgs.location, "location", "l", "", "select the transactions stored in memory or on disk: memory or disk")
+	flags.DurationVarP(&forwarderArgs.olderThan, "older-than", "", 0, "select the transactions created before this duration, for example 1h")
+	flags.DurationVarP(&forwarderArgs.newerThan, "newer-than", "", 0, "select the transactions created in this duration, for example 10m")
+	forwarderListCmd.Flags().BoolVarP(&forwarderArgs.json, "json", "j", false, "print every transaction as JSON")
+	forwarderPurgeCmd.Flags().BoolVarP(&forwarderArgs.all, "all", "", false, "allow to purge every transaction")
+}
+
+func getRetryQueueFilter() forwarder.RetryQueueFilter {
+	return forwarder.RetryQueueFilter{
+		Domain:    forwarderArgs.domain,
+		Endpoint:  forwarderArgs.endpoint,
+		Priority:  forwarderArgs.priority,
+		Location:  forwarderArgs.location,
+		OlderThan: forwarderArgs.olderThan,
+		NewerThan: forwarderArgs.newerThan,
+	}
+}
+
+// queryRetryQueue sends a request to the retry queue API of the running agent.
+func queryRetryQueue(method string, action string, filter forwarder.RetryQueueFilter) ([]byte, error) {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return nil, err
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err = util.SetAuthToken()
+	if err != nil {
+		return nil, err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	urlstr := fmt.Sprintf("https://%v:%v/agent/forwarder/retry-queue%s?%s",
+		ipcAddress, config.Datadog.GetInt("cmd_port"), action, filter.ToQuery().Encode())
+	var r []byte
+	if method == http.MethodPost {
+		r, err = util.DoPost(c, urlstr, "application/json", bytes.NewBuffer(nil))
+	} else {
+		r, err = util.DoGet(c, urlstr)
+	}
+	if err != nil {
+		errMap := make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		if e, found := errMap["error"]; found {
+			return nil, fmt.Errorf("the agent ran into an error: %s", e)
+		}
+		return nil, fmt.Errorf("could not reach agent: %v\nMake sure the agent is running before requesting the retry queue", err)
+	}
+	return r, nil
+}
+
+type retryQueueGroup struct {
+	endpoint    string
+	priority    string
+	location    string
+	count       int
+	sizeInBytes int
+	oldest      time.Time
+}
+
+func forwarderList(_ *cobra.Command, _ []string) error {
+	r, err := queryRetryQueue(http.MethodGet, "", getRetryQueueFilter())
+	if err != nil {
+		return err
+	}
+
+	if forwarderArgs.json {
+		var out bytes.Buffer
+		if err := json.Indent(&out, r, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(color.Output, out.String())
+		return nil
+	}
+
+	var transactions []forwarder.RetryQueueTransaction
+	if err := json.Unmarshal(r, &transactions); err != nil {
+		return err
+	}
+	if len(transactions) == 0 {
+		fmt.Fprintln(color.Output, "No transaction in the retry queue")
+		return nil
+	}
+
+	groupsPerDomain := make(map[string]map[string]*retryQueueGroup)
+	for _, t := range transactions {
+		groups, found := groupsPerDomain[t.Domain]
+		if !found {
+			groups = make(map[string]*retryQueueGroup)
+			groupsPerDomain[t.Domain] = groups
+		}
+		key := t.Endpoint + "|" + t.Priority + "|" + t.Location
+		group, found := groups[key]
+		if !found {
+			group = &retryQueueGroup{endpoint: t.Endpoint, priority: t.Priority, location: t.Location, oldest: t.CreatedAt}
+			groups[key] = group
+		}
+		group.count++
+		group.sizeInBytes += t.PayloadSize
+		if t.CreatedAt.Before(group.oldest) {
+			group.oldest = t.CreatedAt
+		}
+	}
+
+	domains := make([]string, 0, len(groupsPerDomain))
+	for domain := range groupsPerDomain {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	now := time.Now()
+	for _, domain := range domains {
+		fmt.Fprintf(color.Output, "\n=== Domain %s ===\n", color.GreenString(domain))
+
+		groups := make([]*retryQueueGroup, 0, len(groupsPerDomain[domain]))
+		for _, group := range groupsPerDomain[domain] {
+			groups = append(groups, group)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].endpoint != groups[j].endpoint {
+				return groups[i].endpoint < groups[j].endpoint
+			}
+			if groups[i].priority != groups[j].priority {
+				return groups[i].priority < groups[j].priority
+			}
+			return groups[i].location < groups[j].location
+		})
+		for _, group := range groups {
+			fmt.Fprintf(color.Output, "  %s (priority: %s, location: %s): %d transactions, %d bytes, oldest created %s ago\n",
+				color.BlueString(group.endpoint), group.priority, group.location, group.count, group.sizeInBytes,
+				now.Sub(group.oldest).Round(time.Second))
+		}
+	}
+	fmt.Fprintf(color.Output, "\nTotal: %d transactions\n", len(transactions))
+	return nil
+}
+
+func forwarderPurge(_ *cobra.Command, _ []string) error {
+	filter := getRetryQueueFilter()
+	if filter.IsEmpty() && !forwarderArgs.all {
+		return fmt.Errorf("no transaction selected, use --all to purge every transaction")
+	}
+	return retryQueueAction("purge", "Purged", filter)
+}
+
+func forwarderRetry(_ *cobra.Command, _ []string) error {
+	return retryQueueAction("retry", "Retried", getRetryQueueFilter())
+}
+
+func retryQueueAction(action string, done string, filter forwarder.RetryQueueFilter) error {
+	r, err := queryRetryQueue(http.MethodPost, "/"+action, filter)
+	if err != nil {
+		return err
+	}
+
+	var actionResponse response.RetryQueueActionResponse
+	if err := json.Unmarshal(r, &actionResponse); err != nil {
+		return err
+	}
+	fmt.Fprintf(color.Output, "%s %d transactions\n", done, actionResponse.Count)
+	return nil
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -367,6 +368,7 @@ func StartAgent() error {
 
 	// Enable core agent specific features like persistence-to-disk
 	options := forwarder.NewOptions(keysPerDomain)
+	options.DomainCompressors = serializer.DomainCompressorsFromConfig(config.Datadog, keysPerDomain)
 	options.EnabledFeatures = forwarder.SetFeature(options.EnabledFeatures, forwarder.CoreFeatures)
 
 	common.Forwarder = forwarder.NewDefaultForwarder(options)
@@ -512,6 +514,10 @@ func StopAgent() {
 		eventPlatformForwarder.Stop()
 	}
 	logs.Stop()
+	// stopping the tagger saves its snapshot when persistence is enabled
+	if err := tagger.Stop(); err != nil {
+		log.Warnf("Error stopping the tagger: %s", err)
+	}
 	gui.StopGUIServer()
 	profiler.Stop()
 
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+var (
+	spoolCmd = &cobra.Command{
+		Use:   "spool",
+		Short: "Manage the transactions stored in offline mode",
+		Long: `Inspect, export or upload the transactions stored in a spool folder by an Agent running
+with forwarder_offline_mode. The spool folder must contain the keyfile used to encrypt it,
+unless forwarder_storage_encryption_key, forwarder_storage_encryption_keyfile or --keyfile
+is set.`,
+	}
+
+	spoolInspectCmd = &cobra.Command{
+		Use:   "inspect",
+		Short: "Print a summary of the transactions of a spool folder",
+		Long:  ``,
+		RunE:  spoolInspect,
+	}
+
+	spoolExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the transactions of a spool folder as JSON, with the API keys masked",
+		Long:  ``,
+		RunE:  spoolExport,
+	}
+
+	spoolUploadCmd = &cobra.Command{
+		Use:   "upload",
+		Short: "Send the transactions of a spool folder to the configured endpoints",
+		Long: `Send the transactions of a spool folder to the endpoints of the configuration. The
+endpoints and API keys must be the ones configured on the host which wrote the spool.
+The uploaded spool files are removed.`,
+		RunE: spoolUpload,
+	}
+
+	spoolArgs = struct {
+		path    string
+		keyfile string
+		output  string
+	}{}
+)
+
+func init() {
+	AgentCmd.AddCommand(spoolCmd)
+	spoolCmd.AddCommand(spoolInspectCmd, spoolExportCmd, spoolUploadCmd)
+
+	spoolCmd.PersistentFlags().StringVarP(&spoolArgs.path, "path", "p", "", "spool folder (default: forwarder_spool_path)")
+	spoolCmd.PersistentFlags().StringVarP(&spoolArgs.keyfile, "keyfile", "k", "", "keyfile used to encrypt the spool files")
+	spoolExportCmd.Flags().StringVarP(&spoolArgs.output, "output", "o", "", "output file (default: standard output)")
+}
+
+// setupSpoolCommand loads the configuration and returns the spool folder and the configured
+// endpoints. The secrets are decrypted only when withSecrets is true.
+func setupSpoolCommand(withSecrets bool) (string, map[string][]string, error) {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	var err error
+	if withSecrets {
+		err = common.SetupConfig(confFilePath)
+	} else {
+		err = common.SetupConfigWithoutSecrets(confFilePath, "")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return "", nil, err
+	}
+
+	if spoolArgs.keyfile != "" {
+		config.Datadog.Set("forwarder_storage_encryption_keyfile", spoolArgs.keyfile)
+	}
+	spoolPath := spoolArgs.path
+	if spoolPath == "" {
+		spoolPath = forwarder.GetSpoolPath()
+	}
+
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return "", nil, fmt.Errorf("misconfiguration of agent endpoints: %v", err)
+	}
+	return spoolPath, keysPerDomain, nil
+}
+
+func spoolInspect(_ *cobra.Command, _ []string) error {
+	spoolPath, keysPerDomain, err := setupSpoolCommand(false)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := forwarder.InspectSpool(spoolPath, keysPerDomain)
+	if err != nil {
+		return err
+	}
+	if len(summaries) == 0 {
+		fmt.Fprintf(color.Output, "The spool %s is empty\n", spoolPath)
+		return nil
+	}
+
+	for _, summary := range summaries {
+		fmt.Fprintf(color.Output, "\n=== Domain %s ===\n", color.GreenString(summary.Domain))
+		fmt.Fprintf(color.Output, "Folder: %s\n", summary.Folder)
+		fmt.Fprintf(color.Output, "Files: %d (%d bytes)\n", summary.Files, summary.SizeInBytes)
+		if summary.UnreadableFiles > 0 {
+			fmt.Fprintf(color.Output, "Unreadable files: %s\n", color.RedString("%d", summary.UnreadableFiles))
+		}
+		fmt.Fprintf(color.Output, "Transactions: %d\n", summary.Transactions)
+		if summary.Transactions > 0 {
+			fmt.Fprintf(color.Output, "Created between %s and %s\n", summary.OldestTransaction, summary.NewestTransaction)
+		}
+
+		endpoints := make([]string, 0, len(summary.TransactionsPerEndpoint))
+		for endpoint := range summary.TransactionsPerEndpoint {
+			endpoints = append(endpoints, endpoint)
+		}
+		sort.Strings(endpoints)
+		for _, endpoint := range endpoints {
+			fmt.Fprintf(color.Output, "  %s: %d\n", color.BlueString(endpoint), summary.TransactionsPerEndpoint[endpoint])
+		}
+	}
+	return nil
+}
+
+func spoolExport(_ *cobra.Command, _ []string) error {
+	spoolPath, keysPerDomain, err := setupSpoolCommand(false)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if spoolArgs.output != "" {
+		f, err := os.OpenFile(spoolArgs.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return forwarder.ExportSpool(spoolPath, keysPerDomain, w)
+}
+
+func spoolUpload(_ *cobra.Command, _ []string) error {
+	spoolPath, keysPerDomain, err := setupSpoolCommand(true)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	results, err := forwarder.UploadSpool(ctx, spoolPath, keysPerDomain)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(color.Output, "No spool to upload in %s for the configured endpoints\n", spoolPath)
+		return nil
+	}
+
+	failed := false
+	for _, result := range results {
+		fmt.Fprintf(color.Output, "%s: %d files uploaded, %d transactions sent, %d transactions rejected\n",
+			color.GreenString(result.Domain), result.Files, result.Transactions, result.Dropped)
+		if result.Err != nil {
+			failed = true
+			fmt.Fprintf(color.Output, "  %s %v\n", color.RedString("Upload stopped:"), result.Err)
+		}
+	}
+	if failed {
+		return fmt.Errorf("some spool files were not uploaded, run the command again to retry")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workloadListVerbose bool
+	workloadListStream  bool
+)
+
+func init() {
+	AgentCmd.AddCommand(workloadListCommand)
+	workloadListCommand.Flags().BoolVarP(&workloadListVerbose, "verbose", "v", false, "print out a full dump of the workload store")
+	workloadListCommand.Flags().BoolVarP(&workloadListStream, "stream", "s", false, "print the events of the workload store as they happen")
+}
+
+var workloadListCommand = &cobra.Command{
+	Use:   "workload-list",
+	Short: "Print the workload content of a running agent",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		ipcAddress, err := config.GetIPCAddress()
+		if err != nil {
+			return err
+		}
+
+		url := fmt.Sprintf("https://%v:%v/agent/workload-list", ipcAddress, config.Datadog.GetInt("cmd_port"))
+		if workloadListStream {
+			return streamRequest(fmt.Sprintf("%s/stream?verbose=%t", url, workloadListVerbose), nil, func(chunk []byte) {
+				fmt.Fprint(color.Output, string(chunk))
+			})
+		}
+
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+
+		// Set session token
+		err = util.SetAuthToken()
+		if err != nil {
+			return err
+		}
+
+		r, err := util.DoGet(c, fmt.Sprintf("%s?verbose=%t", url, workloadListVerbose))
+		if err != nil {
+			if r != nil && string(r) != "" {
+				fmt.Fprintln(color.Output, fmt.Sprintf("The agent ran into an error while getting the workload store information: %s", string(r)))
+			} else {
+				fmt.Fprintln(color.Output, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+			}
+			return err
+		}
+
+		workload := workloadmeta.WorkloadDumpResponse{}
+		err = json.Unmarshal(r, &workload)
+		if err != nil {
+			return err
+		}
+
+		printWorkloadList(workload)
+
+		return nil
+	},
+}
+
+func printWorkloadList(workload workloadmeta.WorkloadDumpResponse) {
+	// sort kinds and entities for deterministic output
+	kinds := make([]string, 0, len(workload.Entities))
+	for kind := range workload.Entities {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Fprintln(color.Output, fmt.Sprintf("\n=== Entity %s ===", color.GreenString(kind)))
+
+		infos := workload.Entities[kind].Infos
+		keys := make([]string, 0, len(infos))
+		for key := range infos {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Fprintln(color.Output, fmt.Sprintf("== Entity %s ==", color.BlueString(key)))
+			fmt.Fprint(color.Output, infos[key])
+			fmt.Fprintln(color.Output, "===")
+		}
+	}
+}
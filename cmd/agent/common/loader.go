@@ -8,9 +8,11 @@ package common
 import (
 	"context"
 	"path/filepath"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/collector"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	lsched "github.com/DataDog/datadog-agent/pkg/logs/scheduler"
 	lstatus "github.com/DataDog/datadog-agent/pkg/logs/status"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
@@ -29,7 +31,15 @@ func LoadComponents(confdPath string) {
 
 	// start the tagger. must be done before autodiscovery, as it needs to
 	// be the first subscribed to metadata store to avoid race conditions.
-	tagger.SetDefaultTagger(local.NewTagger(collectors.DefaultCatalog))
+	t := local.NewTagger(collectors.DefaultCatalog)
+	if config.Datadog.GetBool("tagger.persistence.enabled") {
+		t.EnablePersistence(
+			taggerSnapshotPath(),
+			time.Duration(config.Datadog.GetInt("tagger.persistence.snapshot_interval"))*time.Second,
+			time.Duration(config.Datadog.GetInt("tagger.persistence.stale_ttl"))*time.Second,
+		)
+	}
+	tagger.SetDefaultTagger(t)
 	tagger.Init()
 
 	// create the Collector instance and start all the components
@@ -58,3 +68,13 @@ func LoadComponents(confdPath string) {
 	// because of subscription to metadata store.
 	AC = setupAutoDiscovery(confSearchPaths, metaScheduler)
 }
+
+// taggerSnapshotPath returns the path of the tagger snapshot, in the run path
+// unless configured otherwise
+func taggerSnapshotPath() string {
+	if path := config.Datadog.GetString("tagger.persistence.path"); path != "" {
+		return path
+	}
+
+	return filepath.Join(config.Datadog.GetString("run_path"), "tagger_snapshot.json")
+}
@@ -164,7 +164,9 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	f := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	forwarderOpts := forwarder.NewOptions(keysPerDomain)
+	forwarderOpts.DomainCompressors = serializer.DomainCompressorsFromConfig(config.Datadog, keysPerDomain)
+	f := forwarder.NewDefaultForwarder(forwarderOpts)
 	f.Start() //nolint:errcheck
 	s := serializer.NewSerializer(f, nil)
 
@@ -212,6 +212,7 @@ func start(cmd *cobra.Command, args []string) error {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
 	forwarderOpts := forwarder.NewOptions(keysPerDomain)
+	forwarderOpts.DomainCompressors = serializer.DomainCompressorsFromConfig(config.Datadog, keysPerDomain)
 	// If a cluster-agent looses the connectivity to DataDog, we still want it to remain ready so that its endpoint remains in the service because:
 	// * It is still able to serve metrics to the WPA controller and
 	// * The metrics reported are reported as stale so that there is no "lie" about the accuracy of the reported metrics.
@@ -183,7 +183,9 @@ func runAgent(ctx context.Context) (err error) {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	f := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	forwarderOpts := forwarder.NewOptions(keysPerDomain)
+	forwarderOpts.DomainCompressors = serializer.DomainCompressorsFromConfig(config.Datadog, keysPerDomain)
+	f := forwarder.NewDefaultForwarder(forwarderOpts)
 	f.Start() //nolint:errcheck
 	s := serializer.NewSerializer(f, nil)
 
@@ -389,6 +389,8 @@ func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
 	if checkSampler, ok := agg.checkSamplers[ss.id]; ok {
 		if ss.commit {
 			checkSampler.commit(timeNowNano())
+		} else if ss.discard {
+			checkSampler.discard(timeNowNano())
 		} else {
 			ss.metricSample.Tags = util.SortUniqInPlace(ss.metricSample.Tags)
 			checkSampler.addSample(ss.metricSample)
@@ -174,6 +174,12 @@ func (cs *CheckSampler) commit(timestamp float64) {
 	cs.metrics.Expire(expiredContextKeys, timestamp)
 }
 
+// discard drops the samples and buckets added since the last commit
+func (cs *CheckSampler) discard(timestamp float64) {
+	cs.metrics.Flush(timestamp)
+	cs.sketchMap.flushBefore(int64(timestamp), func(ckey.ContextKey, metrics.SketchPoint) {})
+}
+
 func (cs *CheckSampler) flush() (metrics.Series, metrics.SketchSeriesList) {
 	// series
 	series := cs.series
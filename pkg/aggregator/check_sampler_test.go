@@ -355,3 +355,48 @@ func TestCheckHistogramBucketInfinityBucket(t *testing.T) {
 		ContextKey: generateContextKey(bucket1),
 	}, flushed[0], .03)
 }
+
+func TestCheckSamplerDiscard(t *testing.T) {
+	checkSampler := newCheckSampler(1, true, 1*time.Second)
+
+	mSample1 := metrics.MetricSample{
+		Name:       "my.metric.name",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo", "bar"},
+		SampleRate: 1,
+		Timestamp:  12345.0,
+	}
+	mSample2 := metrics.MetricSample{
+		Name:       "my.metric.name",
+		Value:      2,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo", "bar"},
+		SampleRate: 1,
+		Timestamp:  12350.0,
+	}
+	bucket := &metrics.HistogramBucket{
+		Name:       "my.histogram",
+		Value:      4,
+		LowerBound: 10.0,
+		UpperBound: 20.0,
+		Tags:       []string{"foo", "bar"},
+		Timestamp:  12345.0,
+	}
+
+	checkSampler.addSample(&mSample1)
+	checkSampler.addBucket(bucket)
+	checkSampler.discard(12346.0)
+
+	checkSampler.commit(12347.0)
+	series, sketches := checkSampler.flush()
+	assert.Len(t, series, 0)
+	assert.Len(t, sketches, 0)
+
+	// The samples added after the discard are committed
+	checkSampler.addSample(&mSample2)
+	checkSampler.commit(12351.0)
+	series, _ = checkSampler.flush()
+	require.Len(t, series, 1)
+	assert.Equal(t, []metrics.Point{{Ts: 12351.0, Value: mSample2.Value}}, series[0].Points)
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
@@ -53,6 +54,7 @@ type RawSender interface {
 
 // checkSender implements Sender
 type checkSender struct {
+	discarding              uint32 // set when the samples are discarded, see DiscardCheckRun
 	id                      check.ID
 	defaultHostname         string
 	defaultHostnameDisabled bool
@@ -73,6 +75,7 @@ type senderMetricSample struct {
 	id           check.ID
 	metricSample *metrics.MetricSample
 	commit       bool
+	discard      bool
 }
 
 type senderHistogramBucket struct {
@@ -163,6 +166,24 @@ func GetDefaultSender() (Sender, error) {
 	return senderInstance, nil
 }
 
+// DiscardCheckRun drops the metric samples and histogram buckets submitted by the sender
+// with passed ID since its last commit, and makes it drop everything submitted until
+// ResumeCheckRun is called with the same ID.
+// It is used to discard the late results of a check run which exceeded its timeout.
+func DiscardCheckRun(id check.ID) {
+	if s, ok := senderPool.getCheckSender(id); ok {
+		s.setDiscarding(true)
+	}
+}
+
+// ResumeCheckRun makes the sender with passed ID submit again what it receives,
+// see DiscardCheckRun.
+func ResumeCheckRun(id check.ID) {
+	if s, ok := senderPool.getCheckSender(id); ok {
+		s.setDiscarding(false)
+	}
+}
+
 // changeAllSendersDefaultHostname is to be called by the aggregator
 // when its hostname changes. All existing senders will have their
 // default hostname updated.
@@ -200,11 +221,33 @@ func (s *checkSender) FinalizeCheckServiceTag() {
 // Commit commits the metric samples & histogram buckets that were added during a check run
 // Should be called at the end of every check run
 func (s *checkSender) Commit() {
+	if s.isDiscarding() {
+		return
+	}
 	// we use a metric sample to commit both for metrics & sketches
-	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{}, true}
+	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{}, true, false}
 	s.cyclemetricStats()
 }
 
+func (s *checkSender) isDiscarding() bool {
+	return atomic.LoadUint32(&s.discarding) == 1
+}
+
+func (s *checkSender) setDiscarding(discard bool) {
+	if !discard {
+		atomic.StoreUint32(&s.discarding, 0)
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&s.discarding, 0, 1) {
+		return
+	}
+	// drop what was submitted since the last commit
+	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{}, false, true}
+	s.statsLock.Lock()
+	s.metricStats = check.NewSenderStats()
+	s.statsLock.Unlock()
+}
+
 func (s *checkSender) GetSenderStats() (metricStats check.SenderStats) {
 	s.statsLock.RLock()
 	defer s.statsLock.RUnlock()
@@ -221,10 +264,17 @@ func (s *checkSender) cyclemetricStats() {
 // SendRawMetricSample sends the raw sample
 // Useful for testing - submitting precomputed samples.
 func (s *checkSender) SendRawMetricSample(sample *metrics.MetricSample) {
-	s.smsOut <- senderMetricSample{s.id, sample, false}
+	if s.isDiscarding() {
+		return
+	}
+	s.smsOut <- senderMetricSample{s.id, sample, false, false}
 }
 
 func (s *checkSender) sendMetricSample(metric string, value float64, hostname string, tags []string, mType metrics.MetricType, flushFirstValue bool) {
+	if s.isDiscarding() {
+		return
+	}
+
 	tags = append(tags, s.checkTags...)
 
 	log.Trace(mType.String(), " sample: ", metric, ": ", value, " for hostname: ", hostname, " tags: ", tags)
@@ -244,7 +294,7 @@ func (s *checkSender) sendMetricSample(metric string, value float64, hostname st
 		metricSample.Host = s.defaultHostname
 	}
 
-	s.smsOut <- senderMetricSample{s.id, metricSample, false}
+	s.smsOut <- senderMetricSample{s.id, metricSample, false, false}
 
 	s.statsLock.Lock()
 	s.metricStats.MetricSamples++
@@ -292,6 +342,10 @@ func (s *checkSender) Histogram(metric string, value float64, hostname string, t
 
 // HistogramBucket should be called to directly send raw buckets to be submitted as distribution metrics
 func (s *checkSender) HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+	if s.isDiscarding() {
+		return
+	}
+
 	tags = append(tags, s.checkTags...)
 
 	log.Tracef(
@@ -337,11 +391,18 @@ func (s *checkSender) Historate(metric string, value float64, hostname string, t
 // SendRawServiceCheck sends the raw service check
 // Useful for testing - submitting precomputed service check.
 func (s *checkSender) SendRawServiceCheck(sc *metrics.ServiceCheck) {
+	if s.isDiscarding() {
+		return
+	}
 	s.serviceCheckOut <- *sc
 }
 
 // ServiceCheck submits a service check
 func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string) {
+	if s.isDiscarding() {
+		return
+	}
+
 	log.Trace("Service check submitted: ", checkName, ": ", status.String(), " for hostname: ", hostname, " tags: ", tags)
 	serviceCheck := metrics.ServiceCheck{
 		CheckName: checkName,
@@ -365,6 +426,10 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 
 // Event submits an event
 func (s *checkSender) Event(e metrics.Event) {
+	if s.isDiscarding() {
+		return
+	}
+
 	e.Tags = append(e.Tags, s.checkTags...)
 
 	log.Trace("Event submitted: ", e.Title, " for hostname: ", e.Host, " tags: ", e.Tags)
@@ -382,6 +447,9 @@ func (s *checkSender) Event(e metrics.Event) {
 
 // Event submits an event
 func (s *checkSender) EventPlatformEvent(rawEvent string, eventType string) {
+	if s.isDiscarding() {
+		return
+	}
 	s.eventPlatformOut <- senderEventPlatformEvent{
 		id:        s.id,
 		rawEvent:  rawEvent,
@@ -394,6 +462,9 @@ func (s *checkSender) EventPlatformEvent(rawEvent string, eventType string) {
 
 // OrchestratorMetadata submit orchestrator metadata messages
 func (s *checkSender) OrchestratorMetadata(msgs []serializer.ProcessMessageBody, clusterID string, nodeType int) {
+	if s.isDiscarding() {
+		return
+	}
 	om := senderOrchestratorMetadata{
 		msgs:        msgs,
 		clusterID:   clusterID,
@@ -425,6 +496,16 @@ func (sp *checkSenderPool) getSender(id check.ID) (Sender, error) {
 	return nil, fmt.Errorf("Sender not found")
 }
 
+// getCheckSender returns the checkSender with passed ID, if any. The mocked senders
+// set with SetSender are not returned.
+func (sp *checkSenderPool) getCheckSender(id check.ID) (*checkSender, bool) {
+	sp.m.Lock()
+	defer sp.m.Unlock()
+
+	s, ok := sp.senders[id].(*checkSender)
+	return s, ok
+}
+
 func (sp *checkSenderPool) mkSender(id check.ID) (Sender, error) {
 	sp.m.Lock()
 	defer sp.m.Unlock()
@@ -566,3 +566,58 @@ func TestChangeAllSendersDefaultHostname(t *testing.T) {
 	gaugeSenderSample = <-s.senderMetricSampleChan
 	assert.Equal(t, "hostname1", gaugeSenderSample.metricSample.Host)
 }
+
+func TestCheckSenderDiscarding(t *testing.T) {
+	s := initSender(checkID1, "default-hostname")
+
+	s.sender.Gauge("my.metric", 1.0, "", nil)
+	<-s.senderMetricSampleChan
+
+	s.sender.setDiscarding(true)
+	discardSenderSample := <-s.senderMetricSampleChan
+	assert.EqualValues(t, checkID1, discardSenderSample.id)
+	assert.True(t, discardSenderSample.discard)
+	assert.False(t, discardSenderSample.commit)
+
+	// Already discarding, nothing is sent
+	s.sender.setDiscarding(true)
+	s.sender.Gauge("my.metric", 1.0, "", nil)
+	s.sender.HistogramBucket("my.histogram_bucket", 42, 1.0, 2.0, true, "", nil, true)
+	s.sender.ServiceCheck("my_service.can_connect", metrics.ServiceCheckOK, "", nil, "message")
+	s.sender.Event(metrics.Event{Title: "Something happened"})
+	s.sender.EventPlatformEvent("raw-event", "dbm-sample")
+	s.sender.Commit()
+	assert.Len(t, s.senderMetricSampleChan, 0)
+	assert.Len(t, s.bucketChan, 0)
+	assert.Len(t, s.serviceCheckChan, 0)
+	assert.Len(t, s.eventChan, 0)
+	assert.Len(t, s.eventPlatformEventChan, 0)
+
+	s.sender.setDiscarding(false)
+	s.sender.Gauge("my.metric", 1.0, "", nil)
+	s.sender.Commit()
+	gaugeSenderSample := <-s.senderMetricSampleChan
+	assert.Equal(t, "my.metric", gaugeSenderSample.metricSample.Name)
+	commitSenderSample := <-s.senderMetricSampleChan
+	assert.True(t, commitSenderSample.commit)
+
+	// The samples sent before discarding are not counted
+	assert.Equal(t, int64(1), s.sender.GetSenderStats().MetricSamples)
+}
+
+func TestDiscardCheckRun(t *testing.T) {
+	resetAggregator()
+	InitAggregator(nil, nil, "")
+
+	s := initSender(checkID1, "default-hostname")
+	SetSender(s.sender, checkID1)
+
+	DiscardCheckRun(checkID1)
+	assert.True(t, s.sender.isDiscarding())
+	ResumeCheckRun(checkID1)
+	assert.False(t, s.sender.isDiscarding())
+
+	// Unknown senders are ignored
+	DiscardCheckRun(checkID2)
+	ResumeCheckRun(checkID2)
+}
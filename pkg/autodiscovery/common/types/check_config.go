@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// CheckConfigTargetPod selects pods, whose checks are scheduled by the node agents
+	CheckConfigTargetPod = "pod"
+	// CheckConfigTargetService selects services, whose checks are scheduled by the cluster agent as cluster checks
+	CheckConfigTargetService = "service"
+
+	// CheckConfigConditionValid is the type of the status condition reporting whether the spec is valid
+	CheckConfigConditionValid = "Valid"
+)
+
+// CheckConfigGVR is the group, version and resource of the DatadogCheckConfig custom resource
+var CheckConfigGVR = schema.GroupVersionResource{
+	Group:    "datadoghq.com",
+	Version:  "v1alpha1",
+	Resource: "datadogcheckconfigs",
+}
+
+// DatadogCheckConfig is a custom resource defining the check configs of the
+// pods or the services selected by its label selector in its namespace
+type DatadogCheckConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatadogCheckConfigSpec   `json:"spec,omitempty"`
+	Status DatadogCheckConfigStatus `json:"status,omitempty"`
+}
+
+// DatadogCheckConfigSpec is the specification of a DatadogCheckConfig
+type DatadogCheckConfigSpec struct {
+	// Target is the kind of resource selected, pod (default) or service
+	Target string `json:"target,omitempty"`
+	// Selector selects the pods or services of the namespace
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// ContainerName restricts the config of pods to one of their containers
+	ContainerName string `json:"containerName,omitempty"`
+
+	CheckName               string            `json:"checkName,omitempty"`
+	InitConfig              json.RawMessage   `json:"initConfig,omitempty"`
+	Instances               []json.RawMessage `json:"instances,omitempty"`
+	Logs                    json.RawMessage   `json:"logs,omitempty"`
+	IgnoreAutodiscoveryTags bool              `json:"ignoreAutodiscoveryTags,omitempty"`
+}
+
+// DatadogCheckConfigStatus is the status of a DatadogCheckConfig
+type DatadogCheckConfigStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// GetTarget returns the kind of resource selected by the config
+func (c *DatadogCheckConfig) GetTarget() string {
+	if c.Spec.Target == "" {
+		return CheckConfigTargetPod
+	}
+	return c.Spec.Target
+}
+
+// Validate checks the spec of the config, and returns its label selector
+func (c *DatadogCheckConfig) Validate() (labels.Selector, error) {
+	spec := c.Spec
+
+	target := c.GetTarget()
+	if target != CheckConfigTargetPod && target != CheckConfigTargetService {
+		return nil, fmt.Errorf("invalid target %q, must be %s or %s", spec.Target, CheckConfigTargetPod, CheckConfigTargetService)
+	}
+	if target == CheckConfigTargetService && spec.ContainerName != "" {
+		return nil, errors.New("containerName can only be set for the pod target")
+	}
+
+	if spec.Selector == nil {
+		return nil, errors.New("missing selector")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %s", err)
+	}
+	if selector.Empty() {
+		return nil, errors.New("the selector must not select every resource of the namespace")
+	}
+
+	if spec.CheckName == "" && len(spec.Logs) == 0 {
+		return nil, errors.New("either checkName or logs must be set")
+	}
+	if spec.CheckName != "" && len(spec.Instances) == 0 {
+		return nil, fmt.Errorf("the %s check has no instances", spec.CheckName)
+	}
+	if spec.CheckName == "" && len(spec.Instances) > 0 {
+		return nil, errors.New("instances require a checkName")
+	}
+
+	if len(spec.InitConfig) > 0 && string(spec.InitConfig) != "null" {
+		if err := isJSONObject(spec.InitConfig); err != nil {
+			return nil, fmt.Errorf("invalid initConfig: %s", err)
+		}
+	}
+	for i, instance := range spec.Instances {
+		if err := isJSONObject(instance); err != nil {
+			return nil, fmt.Errorf("invalid instance %d: %s", i, err)
+		}
+	}
+	if len(spec.Logs) > 0 {
+		var logs []map[string]interface{}
+		if err := json.Unmarshal(spec.Logs, &logs); err != nil {
+			return nil, fmt.Errorf("invalid logs: must be a list of objects")
+		}
+	}
+
+	return selector, nil
+}
+
+func isJSONObject(data json.RawMessage) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return errors.New("must be an object")
+	}
+	return nil
+}
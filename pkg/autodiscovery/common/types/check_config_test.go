@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDatadogCheckConfigValidate(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "redis"}}
+	instances := []json.RawMessage{json.RawMessage(`{"host": "%%host%%"}`)}
+
+	for _, tc := range []struct {
+		name string
+		spec DatadogCheckConfigSpec
+		err  string
+	}{
+		{
+			name: "valid check",
+			spec: DatadogCheckConfigSpec{Selector: selector, CheckName: "redisdb", Instances: instances},
+		},
+		{
+			name: "valid logs",
+			spec: DatadogCheckConfigSpec{Selector: selector, Logs: json.RawMessage(`[{"source": "redis"}]`)},
+		},
+		{
+			name: "valid service check",
+			spec: DatadogCheckConfigSpec{Target: "service", Selector: selector, CheckName: "http_check", InitConfig: json.RawMessage(`{}`), Instances: instances},
+		},
+		{
+			name: "invalid target",
+			spec: DatadogCheckConfigSpec{Target: "node", Selector: selector, CheckName: "redisdb", Instances: instances},
+			err:  `invalid target "node", must be pod or service`,
+		},
+		{
+			name: "container of a service",
+			spec: DatadogCheckConfigSpec{Target: "service", ContainerName: "redis", Selector: selector, CheckName: "redisdb", Instances: instances},
+			err:  "containerName can only be set for the pod target",
+		},
+		{
+			name: "missing selector",
+			spec: DatadogCheckConfigSpec{CheckName: "redisdb", Instances: instances},
+			err:  "missing selector",
+		},
+		{
+			name: "empty selector",
+			spec: DatadogCheckConfigSpec{Selector: &metav1.LabelSelector{}, CheckName: "redisdb", Instances: instances},
+			err:  "the selector must not select every resource of the namespace",
+		},
+		{
+			name: "invalid selector",
+			spec: DatadogCheckConfigSpec{
+				Selector:  &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}}},
+				CheckName: "redisdb",
+				Instances: instances,
+			},
+			err: `invalid selector: "Near" is not a valid pod selector operator`,
+		},
+		{
+			name: "nothing to schedule",
+			spec: DatadogCheckConfigSpec{Selector: selector},
+			err:  "either checkName or logs must be set",
+		},
+		{
+			name: "no instances",
+			spec: DatadogCheckConfigSpec{Selector: selector, CheckName: "redisdb"},
+			err:  "the redisdb check has no instances",
+		},
+		{
+			name: "instances without check",
+			spec: DatadogCheckConfigSpec{Selector: selector, Instances: instances, Logs: json.RawMessage(`[{"source": "redis"}]`)},
+			err:  "instances require a checkName",
+		},
+		{
+			name: "invalid instance",
+			spec: DatadogCheckConfigSpec{Selector: selector, CheckName: "redisdb", Instances: []json.RawMessage{json.RawMessage(`["host"]`)}},
+			err:  "invalid instance 0: must be an object",
+		},
+		{
+			name: "invalid init config",
+			spec: DatadogCheckConfigSpec{Selector: selector, CheckName: "redisdb", InitConfig: json.RawMessage(`"none"`), Instances: instances},
+			err:  "invalid initConfig: must be an object",
+		},
+		{
+			name: "invalid logs",
+			spec: DatadogCheckConfigSpec{Selector: selector, Logs: json.RawMessage(`{"source": "redis"}`)},
+			err:  "invalid logs: must be a list of objects",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &DatadogCheckConfig{Spec: tc.spec}
+			selector, err := c.Validate()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "app=redis", selector.String())
+		})
+	}
+}
@@ -10,28 +10,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/DataDog/datadog-agent/pkg/util/tmplvar"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/tmplvar"
 )
 
 type variableGetter func(ctx context.Context, key []byte, svc listeners.Service) ([]byte, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
 }
 
+// envVariable is the name of the template variables resolved from environment variables
+const envVariable = "env"
+
 // SubstituteTemplateEnvVars replaces %%ENV_VARIABLE%% from environment
 // variables in the config init, instances, and logs config.
 // When there is an error, it continues replacing. When there are multiple
@@ -158,13 +164,21 @@ func resolveDataWithTemplateVars(ctx context.Context, data integration.Data, svc
 
 	templateVars := tmplvar.Parse(data)
 	for _, tVar := range templateVars {
-		if f, found := templateVariables[string(tVar.Name)]; found {
-			resolvedVar, err := f(ctx, tVar.Key, svc)
-			if err != nil {
-				return res, err
-			}
-			res = bytes.Replace(res, tVar.Raw, resolvedVar, -1)
+		name := string(tVar.Name)
+		if name == envVariable {
+			// resolved by SubstituteTemplateEnvVars
+			continue
+		}
+		f, found := templateVariables[name]
+		if !found {
+			return res, fmt.Errorf("unknown template variable %s, skipping service %s", tVar.Raw, svc.GetEntity())
 		}
+		resolvedVar, err := f(ctx, tVar.Key, svc)
+		resolvedVar, err = applyFilters(tVar, resolvedVar, err)
+		if err != nil {
+			return res, err
+		}
+		res = bytes.Replace(res, tVar.Raw, resolvedVar, -1)
 	}
 
 	return res, nil
@@ -176,8 +190,9 @@ func resolveDataWithEnvs(data integration.Data) ([]byte, error) {
 
 	templateVars := tmplvar.Parse(data)
 	for _, tVar := range templateVars {
-		if "env" == string(tVar.Name) {
+		if envVariable == string(tVar.Name) {
 			resolvedVar, err := getEnvvar(tVar.Key)
+			resolvedVar, err = applyFilters(tVar, resolvedVar, err)
 			if err != nil {
 				log.Warnf("variable not replaced: %s", err)
 				if retErr == nil {
@@ -307,6 +322,30 @@ func getAdditionalTplVariables(_ context.Context, tplVar []byte, svc listeners.S
 	return value, nil
 }
 
+// getLabel returns a label of the service, like a container label
+func getLabel(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	return getMetadata(listeners.LabelExtraConfigPrefix, tplVar, svc)
+}
+
+// getAnnotation returns an annotation of the service, like a pod annotation
+func getAnnotation(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	return getMetadata(listeners.AnnotationExtraConfigPrefix, tplVar, svc)
+}
+
+// getMetadata returns the label or annotation of the service exposed as the
+// extra config key made of prefix and the name of the label or annotation
+func getMetadata(prefix string, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	kind := strings.TrimSuffix(prefix, "_")
+	if len(tplVar) == 0 {
+		return nil, fmt.Errorf("%s name is missing, skipping service %s", kind, svc.GetEntity())
+	}
+	value, err := svc.GetExtraConfig(append([]byte(prefix), tplVar...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s for service %s, skipping config - %s", kind, tplVar, svc.GetEntity(), err)
+	}
+	return value, nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(envVar []byte) ([]byte, error) {
 	if len(envVar) == 0 {
@@ -531,7 +531,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("host: %%FOO%%")},
 			},
-			errorString: "unable to add tags for service 'a5901276aed1', err: yaml: found character that cannot start any token",
+			errorString: "unknown template variable %%FOO%%, skipping service a5901276aed1",
 		},
 		//// check overrides
 		{
@@ -706,6 +706,88 @@ func TestResolve(t *testing.T) {
 				Entity:        "a5901276aed1",
 			},
 		},
+		//// labels, annotations and filters
+		{
+			testName: "%%label_*%% and %%annotation_*%% with filters",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig: map[string]string{
+					"label_app":                      "Redis-Cache",
+					"annotation_example.com/servers": "redis-0:6379,redis-1:6380",
+				},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data(`app: %%label_app | lower | replace "-" "_"%%` + "\n" + `server: %%annotation_example.com/servers | split "," -1%%`)},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: redis_cache\nserver: redis-1:6380\ntags:\n- foo:bar\n")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "default values for a missing port and an empty label",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data(`port: %%port | default "6379"%%` + "\n" + `db: %%label_db | default "0"%%` + "\n" + `env: %%env_test_envvar_not_set | default "prod" | upper%%`)},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("db: 0\nenv: PROD\nport: 6379\ntags:\n- foo:bar\n")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "%%label%% without name, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label%%")},
+			},
+			errorString: "label name is missing, skipping service a5901276aed1",
+		},
+		{
+			testName: "unknown filter, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Ports:         newFakeContainerPorts(),
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("port: %%port | title%%")},
+			},
+			errorString: `invalid template variable %%port | title%%: unknown filter "title"`,
+		},
+		{
+			testName: "filter error without default, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Ports:         newFakeContainerPorts(),
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data(`port: %%port | split "," 2%%`)},
+			},
+			errorString: `failed to apply the split filter of template variable %%port | split "," 2%%: index 2 is out of range for "3" split on ","`,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/tmplvar"
+)
+
+const defaultFilter = "default"
+
+// templateFilter transforms the value of a template variable
+type templateFilter struct {
+	args  int
+	apply func(value string, args []string) (string, error)
+}
+
+var templateFilters = map[string]templateFilter{
+	"lower": {0, func(value string, _ []string) (string, error) {
+		return strings.ToLower(value), nil
+	}},
+	"upper": {0, func(value string, _ []string) (string, error) {
+		return strings.ToUpper(value), nil
+	}},
+	"trim": {0, func(value string, _ []string) (string, error) {
+		return strings.TrimSpace(value), nil
+	}},
+	"replace": {2, func(value string, args []string) (string, error) {
+		return strings.Replace(value, args[0], args[1], -1), nil
+	}},
+	"split": {2, splitFilter},
+}
+
+// splitFilter splits the value on a separator, and returns the part at an
+// index, counted from the end when it is negative
+func splitFilter(value string, args []string) (string, error) {
+	idx, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid index %q for the split filter", args[1])
+	}
+	parts := strings.Split(value, args[0])
+	if idx < 0 {
+		idx += len(parts)
+	}
+	if idx < 0 || idx >= len(parts) {
+		return "", fmt.Errorf("index %s is out of range for %q split on %q", args[1], value, args[0])
+	}
+	return parts[idx], nil
+}
+
+// applyFilters applies the filters of a template variable to the value
+// returned by its getter. The resolution errors are passed through the filters
+// until a default filter replaces them with its value, as it does for empty
+// values. Unknown filters and invalid arguments are always returned as errors.
+func applyFilters(tVar tmplvar.TemplateVar, value []byte, err error) ([]byte, error) {
+	resolved := string(value)
+	for _, filter := range tVar.Filters {
+		if filter.Name == defaultFilter {
+			if len(filter.Args) != 1 {
+				return nil, fmt.Errorf("invalid template variable %s: the default filter takes 1 argument, got %d", tVar.Raw, len(filter.Args))
+			}
+			if err != nil || resolved == "" {
+				resolved, err = filter.Args[0], nil
+			}
+			continue
+		}
+
+		f, found := templateFilters[filter.Name]
+		if !found {
+			return nil, fmt.Errorf("invalid template variable %s: unknown filter %q", tVar.Raw, filter.Name)
+		}
+		if len(filter.Args) != f.args {
+			return nil, fmt.Errorf("invalid template variable %s: the %s filter takes %d arguments, got %d", tVar.Raw, filter.Name, f.args, len(filter.Args))
+		}
+		if err != nil {
+			continue
+		}
+		if resolved, err = f.apply(resolved, filter.Args); err != nil {
+			err = fmt.Errorf("failed to apply the %s filter of template variable %s: %s", filter.Name, tVar.Raw, err)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resolved), nil
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/tmplvar"
+)
+
+func TestApplyFilters(t *testing.T) {
+	resolutionErr := errors.New("no port found")
+
+	for _, tc := range []struct {
+		tmpl     string
+		value    string
+		err      error
+		expected string
+		errorMsg string
+	}{
+		{tmpl: "%%host%%", value: "10.0.0.1", expected: "10.0.0.1"},
+		{tmpl: "%%host%%", err: resolutionErr, errorMsg: "no port found"},
+		{tmpl: `%%port | default "80"%%`, value: "8080", expected: "8080"},
+		{tmpl: `%%port | default "80"%%`, err: resolutionErr, expected: "80"},
+		{tmpl: `%%port | default "80"%%`, value: "", expected: "80"},
+		{tmpl: `%%port | lower | default "80"%%`, err: resolutionErr, expected: "80"},
+		{tmpl: `%%port | default "http" | upper%%`, err: resolutionErr, expected: "HTTP"},
+		{tmpl: "%%extra_name | lower%%", value: "Foo", expected: "foo"},
+		{tmpl: "%%extra_name | upper%%", value: "Foo", expected: "FOO"},
+		{tmpl: "%%extra_name | trim%%", value: " foo\n", expected: "foo"},
+		{tmpl: `%%extra_name | replace "." "_"%%`, value: "a.b.c", expected: "a_b_c"},
+		{tmpl: `%%extra_name | split "/" 0%%`, value: "a/b/c", expected: "a"},
+		{tmpl: `%%extra_name | split "/" -1%%`, value: "a/b/c", expected: "c"},
+		{tmpl: `%%extra_name | split "/" 3%%`, value: "a/b/c", errorMsg: `failed to apply the split filter of template variable %%extra_name | split "/" 3%%: index 3 is out of range for "a/b/c" split on "/"`},
+		{tmpl: `%%extra_name | split "/" 3 | default "d"%%`, value: "a/b/c", expected: "d"},
+		{tmpl: `%%extra_name | split "/" x%%`, value: "a/b/c", errorMsg: `failed to apply the split filter of template variable %%extra_name | split "/" x%%: invalid index "x" for the split filter`},
+		{tmpl: `%%extra_name | reverse%%`, value: "foo", errorMsg: `invalid template variable %%extra_name | reverse%%: unknown filter "reverse"`},
+		{tmpl: `%%extra_name | %%`, value: "foo", errorMsg: `invalid template variable %%extra_name | %%: unknown filter ""`},
+		{tmpl: `%%extra_name | lower "a"%%`, value: "foo", errorMsg: `invalid template variable %%extra_name | lower "a"%%: the lower filter takes 0 arguments, got 1`},
+		{tmpl: `%%extra_name | default%%`, err: resolutionErr, errorMsg: `invalid template variable %%extra_name | default%%: the default filter takes 1 argument, got 0`},
+	} {
+		t.Run(tc.tmpl, func(t *testing.T) {
+			vars := tmplvar.ParseString(tc.tmpl)
+			require.Len(t, vars, 1)
+
+			resolved, err := applyFilters(vars[0], []byte(tc.value), tc.err)
+			if tc.errorMsg != "" {
+				assert.EqualError(t, err, tc.errorMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, string(resolved))
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package dryrun resolves autodiscovery templates against a simulated
+// service, to debug them without deploying a workload nor scheduling checks.
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+)
+
+// Result is the resolution of a template against the simulated service
+type Result struct {
+	Template integration.Config
+	// Config is the resolved config, only set when Error is nil
+	Config integration.Config
+	Error  error
+}
+
+// LoadFileTemplate reads a template in the format of the configuration files.
+// The check name defaults to the name of the file, or of its directory for a
+// conf.d/<check>.d/ file.
+func LoadFileTemplate(path, checkName string) (integration.Config, error) {
+	if checkName == "" {
+		checkName = checkNameFromPath(path)
+	}
+	tpl, err := providers.GetIntegrationConfigFromFile(checkName, path)
+	if err != nil {
+		return tpl, fmt.Errorf("invalid template %s: %s", path, err)
+	}
+	tpl.Provider = names.File
+	return tpl, nil
+}
+
+func checkNameFromPath(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	if strings.HasSuffix(dir, ".d") {
+		return strings.TrimSuffix(dir, ".d")
+	}
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// LoadAnnotationTemplates reads a YAML or JSON map of pod annotations, and
+// returns the templates they define for the container of the service. The
+// annotations are added to the ones of the service, and the names of the
+// checks they define override the file templates of the same checks, as they
+// would in a pod.
+func LoadAnnotationTemplates(path string, svc *Service) ([]integration.Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the annotations: %s", err)
+	}
+	var annotations map[string]string
+	if err := yaml.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("unable to parse the annotations: %s", err)
+	}
+	if svc.Name == "" {
+		return nil, fmt.Errorf("the service description must have a name to match the annotation templates")
+	}
+
+	if svc.Annotations == nil {
+		svc.Annotations = make(map[string]string, len(annotations))
+	}
+	for key, value := range annotations {
+		if _, found := svc.Annotations[key]; !found {
+			svc.Annotations[key] = value
+		}
+	}
+
+	templates, errs := providers.ExtractPodAnnotationTemplates(svc.GetEntity(), annotations, svc.Name)
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		return nil, fmt.Errorf("invalid annotation templates: %s", strings.Join(msgs, ", "))
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no template found in the annotations for the container %s", svc.Name)
+	}
+
+	for i := range templates {
+		templates[i].Provider = names.Kubernetes
+		templates[i].Source = "kubelet:" + svc.GetEntity()
+		if templates[i].IsCheckConfig() {
+			svc.checkNames = append(svc.checkNames, templates[i].Name)
+		}
+	}
+	return templates, nil
+}
+
+// Resolve resolves the templates against the service, as autodiscovery would
+// when the service is discovered, without scheduling the configs
+func Resolve(templates []integration.Config, svc *Service) []Result {
+	ids, _ := svc.GetADIdentifiers(context.TODO())
+
+	results := make([]Result, 0, len(templates))
+	for _, tpl := range templates {
+		result := Result{Template: tpl}
+		switch {
+		case !tpl.IsTemplate():
+			result.Error = fmt.Errorf("not a template: the config has no autodiscovery identifier")
+		case !matches(tpl.ADIdentifiers, ids):
+			result.Error = fmt.Errorf("no autodiscovery identifier of the template %v matches the service identifiers %v", tpl.ADIdentifiers, ids)
+		default:
+			result.Config, _, result.Error = configresolver.Resolve(tpl, svc)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func matches(templateIDs, serviceIDs []string) bool {
+	for _, tplID := range templateIDs {
+		for _, svcID := range serviceIDs {
+			if tplID == svcID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dryrun
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+)
+
+const (
+	serviceDescription = `
+name: cache
+image: redis:6.2
+labels:
+  env: prod
+hosts:
+  bridge: 172.17.0.2
+ports:
+  - port: 6380
+    name: tls
+  - port: 6379
+pid: 42
+`
+	redisTemplate = `
+ad_identifiers:
+  - redis
+init_config:
+instances:
+  - host: "%%host%%"
+    port: "%%port_0%%"
+    env: "%%label_env%%"
+`
+	nginxTemplate = `
+ad_identifiers:
+  - nginx
+init_config:
+instances:
+  - url: http://%%host%%
+`
+	annotations = `
+ad.datadoghq.com/cache.check_names: '["redisdb"]'
+ad.datadoghq.com/cache.init_configs: '[{}]'
+ad.datadoghq.com/cache.instances: '[{"host": "%%host%%", "pid": "%%pid%%", "team": "%%annotation_team%%"}]'
+team: cache
+`
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseService(t *testing.T) {
+	svc, err := ParseService([]byte(serviceDescription))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.Equal(t, defaultEntity, svc.GetEntity())
+	ids, err := svc.GetADIdentifiers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{defaultEntity, "redis"}, ids)
+	ports, err := svc.GetPorts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []listeners.ContainerPort{{Port: 6379}, {Port: 6380, Name: "tls"}}, ports)
+	_, err = svc.GetHostname(ctx)
+	assert.Error(t, err)
+
+	value, err := svc.GetExtraConfig([]byte("label_env"))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", string(value))
+	_, err = svc.GetExtraConfig([]byte("annotation_team"))
+	assert.EqualError(t, err, `annotation "team" not found`)
+
+	_, err = ParseService([]byte("unknown_key: true"))
+	assert.Error(t, err)
+	_, err = ParseService([]byte("ports: [{port: 70000}]"))
+	assert.Error(t, err)
+}
+
+func TestLoadFileTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dryrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tpl, err := LoadFileTemplate(writeFile(t, dir, "redisdb.d/auto_conf.yaml", redisTemplate), "")
+	require.NoError(t, err)
+	assert.Equal(t, "redisdb", tpl.Name)
+	assert.Equal(t, "file", tpl.Provider)
+
+	tpl, err = LoadFileTemplate(writeFile(t, dir, "nginx.yaml", nginxTemplate), "")
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", tpl.Name)
+
+	tpl, err = LoadFileTemplate(filepath.Join(dir, "nginx.yaml"), "custom")
+	require.NoError(t, err)
+	assert.Equal(t, "custom", tpl.Name)
+
+	_, err = LoadFileTemplate(writeFile(t, dir, "invalid.yaml", "init_config:\n"), "")
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dryrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, err := ParseService([]byte(serviceDescription))
+	require.NoError(t, err)
+	redis, err := LoadFileTemplate(writeFile(t, dir, "redisdb.d/auto_conf.yaml", redisTemplate), "")
+	require.NoError(t, err)
+	nginx, err := LoadFileTemplate(writeFile(t, dir, "nginx.d/conf.yaml", nginxTemplate), "")
+	require.NoError(t, err)
+
+	results := Resolve([]integration.Config{redis, nginx}, svc)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Error)
+	require.Len(t, results[0].Config.Instances, 1)
+	assert.Equal(t, "env: 'prod'\nhost: '172.17.0.2'\nport: '6379'\n", string(results[0].Config.Instances[0]))
+	assert.Equal(t, defaultEntity, results[0].Config.Entity)
+	assert.EqualError(t, results[1].Error, "no autodiscovery identifier of the template [nginx] matches the service identifiers [dryrun://service redis]")
+
+	// the annotation templates override the file template of the same check
+	annotationTemplates, err := LoadAnnotationTemplates(writeFile(t, dir, "annotations.yaml", annotations), svc)
+	require.NoError(t, err)
+	require.Len(t, annotationTemplates, 1)
+	assert.Equal(t, "kubernetes", annotationTemplates[0].Provider)
+
+	results = Resolve(append(annotationTemplates, redis), svc)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Error)
+	assert.Equal(t, `{"host":"172.17.0.2","pid":"42","team":"cache"}`, string(results[0].Config.Instances[0]))
+	assert.Error(t, results[1].Error)
+	assert.Contains(t, results[1].Error.Error(), "another config is defined for the check redisdb")
+}
+
+func TestLoadAnnotationTemplatesErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dryrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, err := ParseService([]byte("name: web"))
+	require.NoError(t, err)
+	_, err = LoadAnnotationTemplates(writeFile(t, dir, "annotations.yaml", annotations), svc)
+	assert.EqualError(t, err, "no template found in the annotations for the container web")
+
+	_, err = LoadAnnotationTemplates(writeFile(t, dir, "invalid.yaml", "ad.datadoghq.com/web.check_names: '[\"redisdb\"]'\n"), svc)
+	assert.Error(t, err)
+}
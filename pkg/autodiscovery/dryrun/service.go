@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+const defaultEntity = "dryrun://service"
+
+// Port is a network port of a simulated service
+type Port struct {
+	Port int    `yaml:"port"`
+	Name string `yaml:"name"`
+}
+
+// Service is a simulated service, described in YAML, that implements the
+// listeners.Service interface to resolve templates without a running workload
+type Service struct {
+	// Entity defaults to dryrun://service
+	Entity string `yaml:"entity"`
+	// Name is the container name, matched by the pod annotation templates
+	Name  string `yaml:"name"`
+	Image string `yaml:"image"`
+	// ADIdentifiers override the identifiers computed from the entity, the image and the labels
+	ADIdentifiers []string          `yaml:"ad_identifiers"`
+	Labels        map[string]string `yaml:"labels"`
+	Annotations   map[string]string `yaml:"annotations"`
+	// Hosts maps the network names to the IP addresses of the service
+	Hosts       map[string]string `yaml:"hosts"`
+	Ports       []Port            `yaml:"ports"`
+	Pid         int               `yaml:"pid"`
+	Hostname    string            `yaml:"hostname"`
+	Tags        []string          `yaml:"tags"`
+	ExtraConfig map[string]string `yaml:"extra_config"`
+
+	// checkNames are the names of the checks defined in the annotation templates
+	checkNames []string
+}
+
+// LoadService reads the description of a simulated service from a YAML file
+func LoadService(path string) (*Service, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the service description: %s", err)
+	}
+	return ParseService(data)
+}
+
+// ParseService parses the YAML description of a simulated service
+func ParseService(data []byte) (*Service, error) {
+	svc := &Service{}
+	if err := yaml.UnmarshalStrict(data, svc); err != nil {
+		return nil, fmt.Errorf("unable to parse the service description: %s", err)
+	}
+	if svc.Entity == "" {
+		svc.Entity = defaultEntity
+	}
+	for _, p := range svc.Ports {
+		if p.Port <= 0 || p.Port > 65535 {
+			return nil, fmt.Errorf("invalid port %d in the service description", p.Port)
+		}
+	}
+	return svc, nil
+}
+
+// GetEntity returns the unique entity name of the service
+func (s *Service) GetEntity() string {
+	return s.Entity
+}
+
+// GetTaggerEntity returns the tagger entity of the service
+func (s *Service) GetTaggerEntity() string {
+	return s.Entity
+}
+
+// GetADIdentifiers returns the identifiers of the service, computed like the
+// ones of a container unless they are set in the description
+func (s *Service) GetADIdentifiers(context.Context) ([]string, error) {
+	if len(s.ADIdentifiers) > 0 {
+		return s.ADIdentifiers, nil
+	}
+	return listeners.ComputeContainerServiceIDs(s.Entity, s.Image, s.Labels), nil
+}
+
+// GetHosts returns the IP addresses of the service by network
+func (s *Service) GetHosts(context.Context) (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+// GetPorts returns the ports of the service, sorted like the listeners do
+func (s *Service) GetPorts(context.Context) ([]listeners.ContainerPort, error) {
+	ports := make([]listeners.ContainerPort, 0, len(s.Ports))
+	for _, p := range s.Ports {
+		ports = append(ports, listeners.ContainerPort{Port: p.Port, Name: p.Name})
+	}
+	sort.SliceStable(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	return ports, nil
+}
+
+// GetTags returns the tags of the service
+func (s *Service) GetTags() ([]string, string, error) {
+	return s.Tags, "", nil
+}
+
+// GetPid returns the process ID of the service
+func (s *Service) GetPid(context.Context) (int, error) {
+	if s.Pid == 0 {
+		return -1, fmt.Errorf("no pid in the service description")
+	}
+	return s.Pid, nil
+}
+
+// GetHostname returns the hostname of the service
+func (s *Service) GetHostname(context.Context) (string, error) {
+	if s.Hostname == "" {
+		return "", fmt.Errorf("no hostname in the service description")
+	}
+	return s.Hostname, nil
+}
+
+// GetCreationTime returns integration.After, as the service is simulated
+func (s *Service) GetCreationTime() integration.CreationTime {
+	return integration.After
+}
+
+// IsReady returns true, as the service is simulated
+func (s *Service) IsReady(context.Context) bool {
+	return true
+}
+
+// GetCheckNames returns the names of the checks defined in the annotation
+// templates, which override the file templates of the same checks
+func (s *Service) GetCheckNames(context.Context) []string {
+	return s.checkNames
+}
+
+// HasFilter returns false, the exclusion rules don't apply to the service
+func (s *Service) HasFilter(containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig resolves the labels, the annotations and the extra config
+// of the description
+func (s *Service) GetExtraConfig(key []byte) ([]byte, error) {
+	if value, found, err := listeners.GetMetadataExtraConfig(key, s.Labels, s.Annotations); found {
+		return value, err
+	}
+	if value, found := s.ExtraConfig[string(key)]; found {
+		return []byte(value), nil
+	}
+	return []byte{}, listeners.ErrNotSupported
+}
@@ -61,12 +61,24 @@ type Config struct {
 
 // CommonInstanceConfig holds the reserved fields for the yaml instance data
 type CommonInstanceConfig struct {
-	MinCollectionInterval int      `yaml:"min_collection_interval"`
-	EmptyDefaultHostname  bool     `yaml:"empty_default_hostname"`
-	Tags                  []string `yaml:"tags"`
-	Service               string   `yaml:"service"`
-	Name                  string   `yaml:"name"`
-	Namespace             string   `yaml:"namespace"`
+	MinCollectionInterval int                  `yaml:"min_collection_interval"`
+	CheckTimeout          int                  `yaml:"check_timeout"`
+	Cron                  string               `yaml:"cron"`
+	TimeWindows           []string             `yaml:"time_windows"`
+	Jitter                int                  `yaml:"jitter"`
+	OneShot               bool                 `yaml:"one_shot"`
+	ResourceLimits        ResourceLimitsConfig `yaml:"resource_limits"`
+	EmptyDefaultHostname  bool                 `yaml:"empty_default_hostname"`
+	Tags                  []string             `yaml:"tags"`
+	Service               string               `yaml:"service"`
+	Name                  string               `yaml:"name"`
+	Namespace             string               `yaml:"namespace"`
+}
+
+// ResourceLimitsConfig holds the resource limits of the runs of a check instance
+type ResourceLimitsConfig struct {
+	CPUTime float64 `yaml:"cpu_time"`
+	Action  string  `yaml:"action"`
 }
 
 // CommonGlobalConfig holds the reserved fields for the yaml init_config data
@@ -608,7 +608,19 @@ func (s *DockerService) HasFilter(filter containers.FilterType) bool {
 	return false
 }
 
-// GetExtraConfig isn't supported
+// GetExtraConfig resolves the labels of the container
 func (s *DockerService) GetExtraConfig(key []byte) ([]byte, error) {
-	return []byte{}, ErrNotSupported
+	if !strings.HasPrefix(string(key), LabelExtraConfigPrefix) {
+		return []byte{}, ErrNotSupported
+	}
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		return []byte{}, err
+	}
+	cj, err := du.Inspect(context.TODO(), s.cID, false)
+	if err != nil {
+		return []byte{}, err
+	}
+	value, _, err := GetMetadataExtraConfig(key, cj.Config.Labels, nil)
+	return value, err
 }
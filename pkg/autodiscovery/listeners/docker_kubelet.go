@@ -123,7 +123,15 @@ func (s *DockerKubeletService) HasFilter(filter containers.FilterType) bool {
 	return false
 }
 
-// GetExtraConfig isn't supported
+// GetExtraConfig resolves the labels and annotations of the pod
 func (s *DockerKubeletService) GetExtraConfig(key []byte) ([]byte, error) {
-	return []byte{}, ErrNotSupported
+	if !isMetadataExtraConfig(key) {
+		return []byte{}, ErrNotSupported
+	}
+	pod, err := s.getPod(context.TODO())
+	if err != nil {
+		return []byte{}, err
+	}
+	value, _, err := GetMetadataExtraConfig(key, pod.Metadata.Labels, pod.Metadata.Annotations)
+	return value, err
 }
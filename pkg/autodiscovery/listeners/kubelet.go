@@ -184,6 +184,8 @@ func (l *KubeletListener) createPodService(pod workloadmeta.KubernetesPod, conta
 		hosts:         map[string]string{"pod": pod.IP},
 		ports:         ports,
 		creationTime:  crTime,
+		labels:        pod.Labels,
+		annotations:   pod.Annotations,
 	}
 
 	l.mu.Lock()
@@ -241,7 +243,9 @@ func (l *KubeletListener) createContainerService(pod workloadmeta.KubernetesPod,
 			"namespace": pod.Namespace,
 			"pod_uid":   pod.ID,
 		},
-		hosts: map[string]string{"pod": pod.IP},
+		hosts:       map[string]string{"pod": pod.IP},
+		labels:      pod.Labels,
+		annotations: pod.Annotations,
 
 		// Exclude non-running containers (including init containers)
 		// from metrics collection but keep them for collecting logs.
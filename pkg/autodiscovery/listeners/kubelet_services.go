@@ -28,6 +28,8 @@ type KubeContainerService struct {
 	metricsExcluded bool
 	logsExcluded    bool
 	extraConfig     map[string]string
+	labels          map[string]string
+	annotations     map[string]string
 }
 
 // Make sure KubeContainerService implements the Service interface
@@ -41,6 +43,8 @@ type KubePodService struct {
 	hosts         map[string]string
 	ports         []ContainerPort
 	creationTime  integration.CreationTime
+	labels        map[string]string
+	annotations   map[string]string
 }
 
 // Make sure KubePodService implements the Service interface
@@ -52,6 +56,7 @@ var _ Service = &KubePodService{}
 // - ad identifiers
 // - check names
 // - readiness
+// - labels and annotations, which can be used by template variables
 func kubeletSvcEqual(first, second Service) bool {
 	ctx := context.TODO()
 
@@ -77,6 +82,13 @@ func kubeletSvcEqual(first, second Service) bool {
 		return false
 	}
 
+	container1, ok1 := first.(*KubeContainerService)
+	container2, ok2 := second.(*KubeContainerService)
+	if ok1 && ok2 && (!reflect.DeepEqual(container1.labels, container2.labels) ||
+		!reflect.DeepEqual(container1.annotations, container2.annotations)) {
+		return false
+	}
+
 	return first.IsReady(ctx) == second.IsReady(ctx)
 }
 
@@ -134,8 +146,13 @@ func (s *KubeContainerService) IsReady(context.Context) bool {
 	return s.ready
 }
 
-// GetExtraConfig resolves kubelet-specific template variables.
+// GetExtraConfig resolves kubelet-specific template variables, and the labels
+// and annotations of the pod.
 func (s *KubeContainerService) GetExtraConfig(key []byte) ([]byte, error) {
+	if value, found, err := GetMetadataExtraConfig(key, s.labels, s.annotations); found {
+		return value, err
+	}
+
 	result, found := s.extraConfig[string(key)]
 	if !found {
 		return []byte{}, fmt.Errorf("extra config %q is not supported", key)
@@ -227,7 +244,10 @@ func (s *KubePodService) HasFilter(filter containers.FilterType) bool {
 	return false
 }
 
-// GetExtraConfig isn't supported
+// GetExtraConfig resolves the labels and annotations of the pod
 func (s *KubePodService) GetExtraConfig(key []byte) ([]byte, error) {
+	if value, found, err := GetMetadataExtraConfig(key, s.labels, s.annotations); found {
+		return value, err
+	}
 	return []byte{}, ErrNotSupported
 }
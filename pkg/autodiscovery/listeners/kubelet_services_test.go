@@ -54,6 +54,12 @@ func TestKubeletSvcEqual(t *testing.T) {
 			second: &KubeContainerService{hosts: map[string]string{"pod": "10.0.1.1"}, adIdentifiers: []string{"foo"}, ports: []ContainerPort{{Port: 80, Name: "http"}}, checkNames: []string{"foo_check"}, ready: false},
 			want:   false,
 		},
+		{
+			name:   "annotation change",
+			first:  &KubeContainerService{hosts: map[string]string{"pod": "10.0.1.1"}, adIdentifiers: []string{"foo"}, annotations: map[string]string{"foo": "bar"}, ready: true},
+			second: &KubeContainerService{hosts: map[string]string{"pod": "10.0.1.1"}, adIdentifiers: []string{"foo"}, annotations: map[string]string{"foo": "baz"}, ready: true},
+			want:   false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -61,3 +67,28 @@ func TestKubeletSvcEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestKubeContainerServiceGetExtraConfig(t *testing.T) {
+	svc := &KubeContainerService{
+		extraConfig: map[string]string{"pod_name": "redis-0"},
+		labels:      map[string]string{"app": "redis"},
+		annotations: map[string]string{"example.com/port": "6379"},
+	}
+
+	for key, expected := range map[string]string{
+		"pod_name":                    "redis-0",
+		"label_app":                   "redis",
+		"annotation_example.com/port": "6379",
+	} {
+		value, err := svc.GetExtraConfig([]byte(key))
+		assert.NoError(t, err, key)
+		assert.Equal(t, expected, string(value), key)
+	}
+
+	_, err := svc.GetExtraConfig([]byte("label_tier"))
+	assert.EqualError(t, err, `label "tier" not found`)
+	_, err = svc.GetExtraConfig([]byte("annotation_example.com/host"))
+	assert.EqualError(t, err, `annotation "example.com/host" not found`)
+	_, err = svc.GetExtraConfig([]byte("node_name"))
+	assert.EqualError(t, err, `extra config "node_name" is not supported`)
+}
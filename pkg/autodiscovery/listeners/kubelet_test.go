@@ -320,6 +320,7 @@ func TestCreateContainerService(t *testing.T) {
 						"pod_name":  podName,
 						"pod_uid":   podID,
 					},
+					annotations: podWithAnnotations.Annotations,
 				},
 			},
 		},
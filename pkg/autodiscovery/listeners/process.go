@@ -0,0 +1,424 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2021-present Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	processEntityPrefix = "process://"
+)
+
+// processIdentifier maps the processes matching a name, and optionally a
+// command line substring, to the AD identifier of their integration
+type processIdentifier struct {
+	name          string
+	cmdlineSubstr string
+	adIdentifier  string
+}
+
+// processIdentifiers lists the AD identifiers of the integrations whose
+// process name differs from the identifier of their auto_conf.yaml template
+var processIdentifiers = []processIdentifier{
+	{name: "redis-server", adIdentifier: "redisdb"},
+	{name: "mysqld", adIdentifier: "mysql"},
+	{name: "mariadbd", adIdentifier: "mysql"},
+	{name: "mongod", adIdentifier: "mongo"},
+	{name: "memcached", adIdentifier: "mcache"},
+	{name: "httpd", adIdentifier: "apache"},
+	{name: "apache2", adIdentifier: "apache"},
+	{name: "php-fpm", adIdentifier: "php_fpm"},
+	{name: "beam.smp", cmdlineSubstr: "rabbit", adIdentifier: "rabbitmq"},
+	{name: "beam.smp", cmdlineSubstr: "couchdb", adIdentifier: "couch"},
+	{name: "java", cmdlineSubstr: "org.elasticsearch.bootstrap.Elasticsearch", adIdentifier: "elastic"},
+	{name: "java", cmdlineSubstr: "kafka.Kafka", adIdentifier: "kafka"},
+	{name: "java", cmdlineSubstr: "org.apache.zookeeper.server", adIdentifier: "zk"},
+	{name: "java", cmdlineSubstr: "org.apache.cassandra.service.CassandraDaemon", adIdentifier: "cassandra"},
+	{name: "java", cmdlineSubstr: "org.apache.catalina.startup.Bootstrap", adIdentifier: "tomcat"},
+}
+
+// ProcessListener implements the ServiceListener interface for the processes
+// of the host. It subscribes to the processes of the workloadmeta store, and
+// reports the processes listening on TCP ports as services.
+type ProcessListener struct {
+	store      *workloadmeta.Store
+	processes  map[int]workloadmeta.Process // maps PIDs to the host processes listening on TCP ports
+	services   map[int]*ProcessService      // maps PIDs to services
+	newService chan<- Service
+	delService chan<- Service
+	stop       chan struct{}
+	m          sync.RWMutex
+}
+
+// ProcessService implements and store results from the Service interface for the process listener
+type ProcessService struct {
+	pid           int
+	name          string
+	cmdline       []string
+	adIdentifiers []string
+	hosts         map[string]string
+	ports         []ContainerPort
+	creationTime  integration.CreationTime
+}
+
+// Make sure ProcessService implements the Service interface
+var _ Service = &ProcessService{}
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// NewProcessListener creates a ProcessListener
+func NewProcessListener() (ServiceListener, error) {
+	return &ProcessListener{
+		store:     workloadmeta.GetGlobalStore(),
+		processes: make(map[int]workloadmeta.Process),
+		services:  make(map[int]*ProcessService),
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Listen starts listening to the process events of the workloadmeta store,
+// and reports the processes listening on TCP ports as Services.
+func (l *ProcessListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	// setup the I/O channels
+	l.newService = newSvc
+	l.delService = delSvc
+
+	const name = "ad-workloadmeta-processlistener"
+
+	ch := l.store.Subscribe(name, workloadmeta.NewFilter([]workloadmeta.Kind{workloadmeta.KindProcess}, nil))
+	health := health.RegisterLiveness(name)
+	firstRun := true
+
+	log.Info("process listener initialized successfully")
+
+	go func() {
+		for {
+			select {
+			case evBundle := <-ch:
+				l.processEvents(evBundle, firstRun)
+				firstRun = false
+
+			case <-health.C:
+
+			case <-l.stop:
+				err := health.Deregister()
+				if err != nil {
+					log.Warnf("error de-registering health check: %s", err)
+				}
+
+				l.store.Unsubscribe(ch)
+
+				return
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of ProcessListener
+func (l *ProcessListener) Stop() {
+	l.stop <- struct{}{}
+}
+
+// processEvents updates the processes of the host with the events of the
+// store, then refreshes the services.
+func (l *ProcessListener) processEvents(evBundle workloadmeta.EventBundle, firstRun bool) {
+	// close the bundle channel asap since there are no downstream
+	// collectors that depend on AD having up to date data.
+	close(evBundle.Ch)
+
+	self := os.Getpid()
+	for _, ev := range evBundle.Events {
+		entityID := ev.Entity.GetID()
+		if entityID.Kind != workloadmeta.KindProcess {
+			log.Errorf("got event %d with entity of kind %q. filters broken?", ev.Type, entityID.Kind)
+			continue
+		}
+
+		pid, err := strconv.Atoi(entityID.ID)
+		if err != nil {
+			log.Debugf("invalid process entity ID %q: %s", entityID.ID, err)
+			continue
+		}
+
+		switch ev.Type {
+		case workloadmeta.EventTypeSet:
+			process := ev.Entity.(workloadmeta.Process)
+			// the processes of the containers are discovered by the
+			// container listeners
+			if pid == self || process.ContainerID != "" || len(tcpPorts(process.ListeningPorts)) == 0 {
+				delete(l.processes, pid)
+				continue
+			}
+			l.processes[pid] = process
+
+		case workloadmeta.EventTypeUnset:
+			delete(l.processes, pid)
+
+		default:
+			log.Errorf("cannot handle event of type %d", ev.Type)
+		}
+	}
+
+	var crTime integration.CreationTime
+	if firstRun {
+		crTime = integration.Before
+	} else {
+		crTime = integration.After
+	}
+	l.refreshServices(crTime)
+}
+
+// refreshServices compares the processes of the host to the local cache and
+// sends new/dead services over newService and delService accordingly. A
+// process whose listening ports changed is reported as a new service.
+func (l *ProcessListener) refreshServices(crTime integration.CreationTime) {
+	notSeen := make(map[int]struct{})
+	l.m.RLock()
+	for pid := range l.services {
+		notSeen[pid] = struct{}{}
+	}
+	l.m.RUnlock()
+
+	for pid, ports := range l.processPorts() {
+		svc, err := l.createService(l.processes[pid], ports, crTime)
+		if err != nil {
+			log.Debugf("couldn't create a service out of process %d - Auto Discovery will ignore it: %s", pid, err)
+			continue
+		}
+
+		l.m.RLock()
+		old, found := l.services[pid]
+		l.m.RUnlock()
+		if found && old.name == svc.name && portsEqual(old.ports, svc.ports) {
+			delete(notSeen, pid)
+			continue
+		}
+		if found {
+			l.delService <- old
+		}
+
+		l.m.Lock()
+		l.services[pid] = svc
+		l.m.Unlock()
+		l.newService <- svc
+		delete(notSeen, pid)
+	}
+
+	for pid := range notSeen {
+		l.m.Lock()
+		svc := l.services[pid]
+		delete(l.services, pid)
+		l.m.Unlock()
+		l.delService <- svc
+	}
+}
+
+// processPorts maps the PIDs of the processes to their listening TCP ports. A
+// socket shared by several processes, like the listening socket a pre-fork
+// server passes down to its workers, belongs to the one with the lowest PID
+// only, so that it is reported once.
+func (l *ProcessListener) processPorts() map[int][]workloadmeta.ProcessPort {
+	owners := make(map[workloadmeta.ProcessPort]int) // maps the listening addresses to the PID of their owner
+	for pid, process := range l.processes {
+		for _, port := range tcpPorts(process.ListeningPorts) {
+			if owner, found := owners[port]; !found || pid < owner {
+				owners[port] = pid
+			}
+		}
+	}
+
+	processes := make(map[int][]workloadmeta.ProcessPort)
+	for port, pid := range owners {
+		processes[pid] = append(processes[pid], port)
+	}
+	for _, ports := range processes {
+		sort.Slice(ports, func(i, j int) bool {
+			if ports[i].Port != ports[j].Port {
+				return ports[i].Port < ports[j].Port
+			}
+			return ports[i].IP < ports[j].IP
+		})
+	}
+	return processes
+}
+
+func (l *ProcessListener) createService(process workloadmeta.Process, ports []workloadmeta.ProcessPort, crTime integration.CreationTime) (*ProcessService, error) {
+	if process.Name == "" {
+		return nil, fmt.Errorf("empty process name")
+	}
+
+	svc := &ProcessService{
+		pid:           process.PID,
+		name:          process.Name,
+		cmdline:       process.Cmdline,
+		adIdentifiers: computeProcessServiceIDs(process.Name, process.Cmdline),
+		hosts:         map[string]string{"host": listeningHost(ports)},
+		ports:         listeningPorts(ports),
+		creationTime:  crTime,
+	}
+	return svc, nil
+}
+
+// tcpPorts returns the TCP ports of ports
+func tcpPorts(ports []workloadmeta.ProcessPort) []workloadmeta.ProcessPort {
+	var tcp []workloadmeta.ProcessPort
+	for _, port := range ports {
+		if port.Protocol == "tcp" {
+			tcp = append(tcp, port)
+		}
+	}
+	return tcp
+}
+
+// computeProcessServiceIDs returns the AD identifiers of a process: the
+// identifier of its integration first when it is known, then its name.
+func computeProcessServiceIDs(name string, cmdline []string) []string {
+	var ids []string
+	joined := strings.Join(cmdline, " ")
+	for _, pi := range processIdentifiers {
+		if pi.name != name || !strings.Contains(joined, pi.cmdlineSubstr) {
+			continue
+		}
+		if pi.adIdentifier != name {
+			ids = append(ids, pi.adIdentifier)
+		}
+		break
+	}
+	return append(ids, name)
+}
+
+// listeningHost returns the IP address to reach the process: the loopback
+// address if it listens on all interfaces or on the loopback interface,
+// otherwise the first address it listens on.
+func listeningHost(ports []workloadmeta.ProcessPort) string {
+	for _, port := range ports {
+		if ip := net.ParseIP(port.IP); ip.IsUnspecified() || ip.IsLoopback() {
+			return "127.0.0.1"
+		}
+	}
+	if len(ports) == 0 {
+		return "127.0.0.1"
+	}
+	return ports[0].IP
+}
+
+// listeningPorts returns the sorted and deduplicated ports of the listening
+// addresses of a process
+func listeningPorts(processPorts []workloadmeta.ProcessPort) []ContainerPort {
+	seen := make(map[int]struct{})
+	var ports []ContainerPort
+	for _, port := range processPorts {
+		if _, found := seen[port.Port]; found {
+			continue
+		}
+		seen[port.Port] = struct{}{}
+		ports = append(ports, ContainerPort{Port: port.Port, Name: fmt.Sprintf("p%d", port.Port)})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+func portsEqual(a, b []ContainerPort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetEntity returns the unique entity name linked to that service
+func (s *ProcessService) GetEntity() string {
+	return processEntityPrefix + strconv.Itoa(s.pid)
+}
+
+// GetTaggerEntity returns the tagger entity, processes have none
+func (s *ProcessService) GetTaggerEntity() string {
+	return ""
+}
+
+// GetADIdentifiers returns the AD identifiers of the process: the identifier of
+// its integration when it is known, then the process name
+func (s *ProcessService) GetADIdentifiers(context.Context) ([]string, error) {
+	return s.adIdentifiers, nil
+}
+
+// GetHosts returns the IP address to reach the process
+func (s *ProcessService) GetHosts(context.Context) (map[string]string, error) {
+	return s.hosts, nil
+}
+
+// GetPorts returns the TCP ports the process listens on
+func (s *ProcessService) GetPorts(context.Context) ([]ContainerPort, error) {
+	return s.ports, nil
+}
+
+// GetTags returns no tag, the checks of the process get the host tags
+func (s *ProcessService) GetTags() ([]string, string, error) {
+	return []string{}, "", nil
+}
+
+// GetPid returns the process PID
+func (s *ProcessService) GetPid(context.Context) (int, error) {
+	return s.pid, nil
+}
+
+// GetHostname returns nil and an error because the hostname is not supported in this listener
+func (s *ProcessService) GetHostname(context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetCreationTime returns the creation time of the process compared to the agent start
+func (s *ProcessService) GetCreationTime() integration.CreationTime {
+	return s.creationTime
+}
+
+// IsReady returns true, a process listening on a port is ready
+func (s *ProcessService) IsReady(context.Context) bool {
+	return true
+}
+
+// GetCheckNames returns nil, processes can't define check names
+func (s *ProcessService) GetCheckNames(context.Context) []string {
+	return nil
+}
+
+// HasFilter returns false, processes can't be filtered
+func (s *ProcessService) HasFilter(filter containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig returns the process name and command line
+func (s *ProcessService) GetExtraConfig(key []byte) ([]byte, error) {
+	switch string(key) {
+	case "process_name":
+		return []byte(s.name), nil
+	case "cmdline":
+		return []byte(strings.Join(s.cmdline, " ")), nil
+	}
+	return []byte{}, ErrNotSupported
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2021-present Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func newTestProcessListener() (*ProcessListener, chan Service, chan Service) {
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l := &ProcessListener{
+		processes:  make(map[int]workloadmeta.Process),
+		services:   make(map[int]*ProcessService),
+		newService: newSvc,
+		delService: delSvc,
+	}
+	return l, newSvc, delSvc
+}
+
+func newTestProcess(pid int, name string, cmdline []string, ports ...workloadmeta.ProcessPort) workloadmeta.Process {
+	return workloadmeta.Process{
+		EntityID:       workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: strconv.Itoa(pid)},
+		EntityMeta:     workloadmeta.EntityMeta{Name: name},
+		PID:            pid,
+		Cmdline:        cmdline,
+		ListeningPorts: ports,
+	}
+}
+
+func setEvent(process workloadmeta.Process) workloadmeta.Event {
+	return workloadmeta.Event{Type: workloadmeta.EventTypeSet, Source: "process", Entity: process}
+}
+
+func unsetEvent(pid int) workloadmeta.Event {
+	return workloadmeta.Event{
+		Type:   workloadmeta.EventTypeUnset,
+		Source: "process",
+		Entity: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: strconv.Itoa(pid)},
+	}
+}
+
+func processEventBundle(events ...workloadmeta.Event) workloadmeta.EventBundle {
+	return workloadmeta.EventBundle{Events: events, Ch: make(chan struct{})}
+}
+
+func TestComputeProcessServiceIDs(t *testing.T) {
+	assert.Equal(t, []string{"redisdb", "redis-server"}, computeProcessServiceIDs("redis-server", []string{"/usr/bin/redis-server", "127.0.0.1:6379"}))
+	assert.Equal(t, []string{"nginx"}, computeProcessServiceIDs("nginx", []string{"nginx: master process /usr/sbin/nginx"}))
+	assert.Equal(t, []string{"kafka", "java"}, computeProcessServiceIDs("java", []string{"java", "-Xmx1G", "kafka.Kafka", "server.properties"}))
+	assert.Equal(t, []string{"java"}, computeProcessServiceIDs("java", []string{"java", "-jar", "app.jar"}))
+}
+
+func TestListeningHost(t *testing.T) {
+	specific := workloadmeta.ProcessPort{Protocol: "tcp", IP: "10.0.0.5", Port: 8080}
+	assert.Equal(t, "10.0.0.5", listeningHost([]workloadmeta.ProcessPort{specific}))
+	assert.Equal(t, "127.0.0.1", listeningHost([]workloadmeta.ProcessPort{specific, {Protocol: "tcp", IP: "::", Port: 8081}}))
+	assert.Equal(t, "127.0.0.1", listeningHost([]workloadmeta.ProcessPort{{Protocol: "tcp", IP: "127.0.0.1", Port: 6379}}))
+}
+
+func TestProcessListener(t *testing.T) {
+	l, newSvc, delSvc := newTestProcessListener()
+
+	redis := newTestProcess(100, "redis-server", []string{"/usr/bin/redis-server", "*:6379"},
+		workloadmeta.ProcessPort{Protocol: "tcp", IP: "0.0.0.0", Port: 6379},
+		workloadmeta.ProcessPort{Protocol: "tcp", IP: "::", Port: 16384},
+	)
+	java := newTestProcess(200, "java", []string{"java", "-jar", "app.jar"},
+		workloadmeta.ProcessPort{Protocol: "tcp", IP: "10.0.0.5", Port: 8080},
+	)
+	// no listening TCP socket
+	bash := newTestProcess(300, "bash", []string{"bash"},
+		workloadmeta.ProcessPort{Protocol: "udp", IP: "0.0.0.0", Port: 11211},
+	)
+	// the processes of the containers are discovered by the container listeners
+	nginx := newTestProcess(400, "nginx", []string{"nginx"},
+		workloadmeta.ProcessPort{Protocol: "tcp", IP: "0.0.0.0", Port: 80},
+	)
+	nginx.ContainerID = "3b8efe0c50e8"
+
+	l.processEvents(processEventBundle(setEvent(redis), setEvent(java), setEvent(bash), setEvent(nginx)), true)
+	require.Len(t, newSvc, 2)
+	assert.Len(t, delSvc, 0)
+
+	services := map[string]Service{}
+	for i := 0; i < 2; i++ {
+		svc := <-newSvc
+		services[svc.GetEntity()] = svc
+	}
+
+	ctx := context.Background()
+	redisSvc := services["process://100"]
+	require.NotNil(t, redisSvc)
+	ids, err := redisSvc.GetADIdentifiers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"redisdb", "redis-server"}, ids)
+	hosts, err := redisSvc.GetHosts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+	ports, err := redisSvc.GetPorts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{6379, "p6379"}, {16384, "p16384"}}, ports)
+	pid, err := redisSvc.GetPid(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, pid)
+	assert.Equal(t, integration.Before, redisSvc.GetCreationTime())
+	cmdline, err := redisSvc.GetExtraConfig([]byte("cmdline"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/bin/redis-server *:6379", string(cmdline))
+
+	javaSvc := services["process://200"]
+	require.NotNil(t, javaSvc)
+	hosts, err = javaSvc.GetHosts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "10.0.0.5"}, hosts)
+
+	// an unchanged process doesn't change the services
+	l.processEvents(processEventBundle(setEvent(redis)), false)
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+
+	// the java process exited
+	l.processEvents(processEventBundle(unsetEvent(200)), false)
+	assert.Len(t, newSvc, 0)
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, "process://200", (<-delSvc).GetEntity())
+
+	// the bash process started listening on 10.0.0.5:8080
+	bash.ListeningPorts = append(bash.ListeningPorts, workloadmeta.ProcessPort{Protocol: "tcp", IP: "10.0.0.5", Port: 8080})
+	l.processEvents(processEventBundle(setEvent(bash)), false)
+	require.Len(t, newSvc, 1)
+	bashSvc := <-newSvc
+	assert.Equal(t, "process://300", bashSvc.GetEntity())
+	assert.Equal(t, integration.After, bashSvc.GetCreationTime())
+}
+
+func TestProcessListenerPortsChanged(t *testing.T) {
+	l, newSvc, delSvc := newTestProcessListener()
+
+	redis := newTestProcess(100, "redis-server", []string{"redis-server"},
+		workloadmeta.ProcessPort{Protocol: "tcp", IP: "0.0.0.0", Port: 6379},
+	)
+	l.processEvents(processEventBundle(setEvent(redis)), true)
+	require.Len(t, newSvc, 1)
+	old := <-newSvc
+
+	// a process listening on a new port is reported again, to resolve its templates with the new ports
+	redis.ListeningPorts = append(redis.ListeningPorts, workloadmeta.ProcessPort{Protocol: "tcp", IP: "::", Port: 16384})
+	l.processEvents(processEventBundle(setEvent(redis)), false)
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, old, <-delSvc)
+	require.Len(t, newSvc, 1)
+	ports, err := (<-newSvc).GetPorts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{6379, "p6379"}, {16384, "p16384"}}, ports)
+}
+
+func TestProcessListenerSharedSockets(t *testing.T) {
+	l, newSvc, delSvc := newTestProcessListener()
+
+	// a pre-fork server passes its listening socket down to its workers
+	shared := workloadmeta.ProcessPort{Protocol: "tcp", IP: "0.0.0.0", Port: 6379}
+	cmdline := []string{"/usr/sbin/apache2", "-k", "start"}
+	l.processEvents(processEventBundle(
+		setEvent(newTestProcess(90, "apache2", cmdline, shared)),
+		setEvent(newTestProcess(100, "apache2", cmdline, shared)),
+		setEvent(newTestProcess(1000, "apache2", cmdline, shared, workloadmeta.ProcessPort{Protocol: "tcp", IP: "10.0.0.5", Port: 8080})),
+	), true)
+
+	// the shared socket belongs to the process with the lowest PID only
+	require.Len(t, newSvc, 2)
+	services := map[string]Service{}
+	for i := 0; i < 2; i++ {
+		svc := <-newSvc
+		services[svc.GetEntity()] = svc
+	}
+	require.Contains(t, services, "process://90")
+	ports, err := services["process://90"].GetPorts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{6379, "p6379"}}, ports)
+	require.Contains(t, services, "process://1000")
+	ports, err = services["process://1000"].GetPorts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{8080, "p8080"}}, ports)
+
+	// recycling the workers doesn't change the services
+	l.processEvents(processEventBundle(
+		unsetEvent(100),
+		setEvent(newTestProcess(110, "apache2", cmdline, shared)),
+	), false)
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+}
@@ -8,12 +8,21 @@ package listeners
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+const (
+	// LabelExtraConfigPrefix prefixes the extra config keys of the labels of a Service
+	LabelExtraConfigPrefix = "label_"
+	// AnnotationExtraConfigPrefix prefixes the extra config keys of the annotations of a Service
+	AnnotationExtraConfigPrefix = "annotation_"
+)
+
 // ContainerPort represents a network port in a Service.
 type ContainerPort struct {
 	Port int
@@ -68,3 +77,33 @@ func Register(name string, factory ServiceListenerFactory) {
 
 // ErrNotSupported is thrown if listener doesn't support the asked variable
 var ErrNotSupported = errors.New("AD: variable not supported by listener")
+
+// isMetadataExtraConfig returns whether key is the extra config key of a label or an annotation
+func isMetadataExtraConfig(key []byte) bool {
+	name := string(key)
+	return strings.HasPrefix(name, LabelExtraConfigPrefix) || strings.HasPrefix(name, AnnotationExtraConfigPrefix)
+}
+
+// GetMetadataExtraConfig resolves the extra config keys of the labels and
+// annotations of a Service, and returns whether key is one of them
+func GetMetadataExtraConfig(key []byte, labels, annotations map[string]string) ([]byte, bool, error) {
+	var kind string
+	var metadata map[string]string
+	name := string(key)
+	switch {
+	case strings.HasPrefix(name, LabelExtraConfigPrefix):
+		kind, metadata = "label", labels
+		name = strings.TrimPrefix(name, LabelExtraConfigPrefix)
+	case strings.HasPrefix(name, AnnotationExtraConfigPrefix):
+		kind, metadata = "annotation", annotations
+		name = strings.TrimPrefix(name, AnnotationExtraConfigPrefix)
+	default:
+		return nil, false, nil
+	}
+
+	value, found := metadata[name]
+	if !found {
+		return []byte{}, true, fmt.Errorf("%s %q not found", kind, name)
+	}
+	return []byte(value), true, nil
+}
@@ -280,6 +280,20 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
 	}
 
+	config, err = configFromFormat(name, cf)
+	if err != nil {
+		return config, err
+	}
+
+	config.Source = "file:" + fpath
+
+	return config, nil
+}
+
+// configFromFormat returns the integration.Config of a parsed configuration
+func configFromFormat(name string, cf configFormat) (integration.Config, error) {
+	config := integration.Config{Name: name}
+
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
 	// this is not a valid configuration file
 	if cf.MetricConfig == nil && cf.LogsConfig == nil && len(cf.Instances) < 1 {
@@ -329,9 +343,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Interpolate env vars. Returns an error a variable wasn't subsituted, ignore it.
 	_ = configresolver.SubstituteTemplateEnvVars(&config)
 
-	config.Source = "file:" + fpath
-
-	return config, err
+	return config, nil
 }
 
 func containsString(slice []string, str string) bool {
@@ -0,0 +1,242 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpRequestTimeout = 30 * time.Second
+	// httpMaxBodySize limits the size of the templates served
+	httpMaxBodySize = 10 * 1024 * 1024
+)
+
+// HTTPConfigProvider implements the ConfigProvider interface for the templates
+// served by an HTTP endpoint. The endpoint serves a YAML document mapping the
+// check names to their configuration, in the format of the configuration files.
+// The conditional requests made with the ETag and Last-Modified headers of the
+// previous response avoid parsing unchanged templates.
+type HTTPConfigProvider struct {
+	client       *http.Client
+	url          string
+	source       string
+	token        string
+	etag         string
+	lastModified string
+	configs      []integration.Config
+	fetched      bool // whether configs were fetched since the last Collect
+	configErrors map[string]ErrorMsgSet
+	sync.Mutex
+}
+
+// NewHTTPConfigProvider returns a new ConfigProvider polling the templates from config.TemplateURL
+func NewHTTPConfigProvider(config config.ConfigurationProviders) (ConfigProvider, error) {
+	templateURL, err := url.Parse(config.TemplateURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template URL: %s", err)
+	}
+	if templateURL.Scheme != "http" && templateURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid template URL %q: the scheme must be http or https", templateURL.Redacted())
+	}
+
+	transport := httputils.CreateHTTPTransport()
+	if config.CAFile != "" {
+		caCert, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("unable to load the CA certificates from %s", config.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %s", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   httpRequestTimeout,
+		},
+		url:          templateURL.String(),
+		source:       templateURL.Redacted(),
+		token:        config.Token,
+		configErrors: make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect returns the templates fetched from the endpoint
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.fetched {
+		if _, err := p.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	p.fetched = false
+
+	return p.configs, nil
+}
+
+// IsUpToDate makes a conditional request to the endpoint, and returns whether
+// the templates are unchanged since the last request
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	modified, err := p.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !modified, nil
+}
+
+// fetch requests the templates if they were modified since the last request,
+// and returns whether they were. The fetch errors are reported as the
+// configuration errors of the URL, and the previous templates are kept.
+func (p *HTTPConfigProvider) fetch(ctx context.Context) (bool, error) {
+	modified, err := p.doFetch(ctx)
+	if err != nil {
+		p.configErrors[p.source] = ErrorMsgSet{err.Error(): {}}
+		return false, err
+	}
+	return modified, nil
+}
+
+func (p *HTTPConfigProvider) doFetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	if p.lastModified != "" {
+		req.Header.Set("If-Modified-Since", p.lastModified)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch the templates: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		log.Tracef("Templates at %s not modified", p.source)
+		delete(p.configErrors, p.source)
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unable to fetch the templates: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpMaxBodySize+1))
+	if err != nil {
+		return false, fmt.Errorf("unable to read the templates: %s", err)
+	}
+	if len(body) > httpMaxBodySize {
+		return false, fmt.Errorf("unable to read the templates: the response exceeds %d bytes", httpMaxBodySize)
+	}
+
+	configs, configErrors, err := p.parseTemplates(body)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debugf("Fetched %d templates from %s", len(configs), p.source)
+	p.configs = configs
+	p.configErrors = configErrors
+	p.etag = resp.Header.Get("ETag")
+	p.lastModified = resp.Header.Get("Last-Modified")
+	p.fetched = true
+	return true, nil
+}
+
+// parseTemplates parses the YAML document mapping the check names to their
+// configuration. The invalid configurations are returned as configuration
+// errors, an invalid document as an error.
+func (p *HTTPConfigProvider) parseTemplates(body []byte) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	var templates map[string]configFormat
+	// Try UnmarshalStrict first, so we can warn about duplicated keys
+	if strictErr := yaml.UnmarshalStrict(body, &templates); strictErr != nil {
+		if err := yaml.Unmarshal(body, &templates); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse the templates: %s", err)
+		}
+		log.Warnf("reading templates from %s: %v", p.source, strictErr)
+	}
+
+	checkNames := make([]string, 0, len(templates))
+	for name := range templates {
+		checkNames = append(checkNames, name)
+	}
+	sort.Strings(checkNames)
+
+	configs := make([]integration.Config, 0, len(templates))
+	configErrors := make(map[string]ErrorMsgSet)
+	for _, name := range checkNames {
+		c, err := configFromFormat(name, templates[name])
+		if err != nil {
+			log.Warnf("invalid template for check %s at %s: %s", name, p.source, err)
+			configErrors[name] = ErrorMsgSet{err.Error(): {}}
+			continue
+		}
+		c.Source = "http:" + p.source
+		configs = append(configs, c)
+	}
+	return configs, configErrors, nil
+}
+
+// GetConfigErrors returns the fetch errors of the URL, and the errors of the
+// invalid templates by check name
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.Lock()
+	defer p.Unlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.configErrors))
+	for key, errs := range p.configErrors {
+		errors[key] = errs
+	}
+	return errors
+}
+
+func init() {
+	RegisterProvider("http", NewHTTPConfigProvider)
+}
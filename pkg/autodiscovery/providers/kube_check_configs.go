@@ -0,0 +1,373 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	checkConfigReasonValid   = "Valid"
+	checkConfigReasonInvalid = "InvalidSpec"
+)
+
+// checkConfigTargets finds the AD identifiers of the resources of a kind
+// selected by the DatadogCheckConfigs
+type checkConfigTargets interface {
+	// kind returns the kind of resource handled, types.CheckConfigTargetPod or types.CheckConfigTargetService
+	kind() string
+	identifiers(ctx context.Context, namespace string, selector labels.Selector, containerName string) ([]string, error)
+}
+
+// KubeCheckConfigProvider implements the ConfigProvider interface for the
+// DatadogCheckConfig custom resources. The node agents generate templates for
+// the containers of their pods selected by the resources, and the cluster
+// agent generates cluster check templates for the selected services. The
+// leader cluster agent reports the validation of the resources in their status,
+// or the leader node agent when there is no cluster agent.
+type KubeCheckConfigProvider struct {
+	lister       cache.GenericLister
+	synced       cache.InformerSynced
+	client       dynamic.Interface
+	targets      checkConfigTargets
+	updateStatus func() bool
+	upToDate     bool
+	configErrors map[string]ErrorMsgSet
+	sync.Mutex
+}
+
+// NewKubeCheckConfigProvider returns a new ConfigProvider watching the DatadogCheckConfig resources
+func NewKubeCheckConfigProvider(providerConfig config.ConfigurationProviders) (ConfigProvider, error) {
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	informerFactory, err := apiserver.GetDDInformerFactory()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the informer factory: %s", err)
+	}
+	client, err := apiserver.GetDDClient(time.Duration(config.Datadog.GetInt64("kubernetes_apiserver_client_timeout")) * time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the apiserver client: %s", err)
+	}
+
+	p := &KubeCheckConfigProvider{
+		client:       client,
+		updateStatus: func() bool { return false },
+		configErrors: make(map[string]ErrorMsgSet),
+	}
+
+	if flavor.GetFlavor() == flavor.ClusterAgent {
+		servicesInformer := ac.InformerFactory.Core().V1().Services()
+		servicesInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    p.invalidate,
+			UpdateFunc: p.invalidateIfServiceLabelsChanged,
+			DeleteFunc: p.invalidate,
+		})
+		p.targets = &serviceTargets{lister: servicesInformer.Lister()}
+		p.updateStatus = isStatusWriter
+	} else {
+		if p.targets, err = newPodTargets(); err != nil {
+			return nil, err
+		}
+		if !config.Datadog.GetBool("cluster_agent.enabled") {
+			if config.Datadog.GetBool("leader_election") {
+				p.updateStatus = isNodeStatusWriter
+			} else {
+				log.Infof("The status of the DatadogCheckConfigs is not updated without the cluster agent or leader_election")
+			}
+		}
+	}
+
+	checkConfigsInformer := informerFactory.ForResource(types.CheckConfigGVR)
+	checkConfigsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfSpecChanged,
+		DeleteFunc: p.invalidate,
+	})
+	p.lister = checkConfigsInformer.Lister()
+	p.synced = checkConfigsInformer.Informer().HasSynced
+	informerFactory.Start(wait.NeverStop)
+
+	return p, nil
+}
+
+// isStatusWriter returns whether the cluster agent is the leader, to have a
+// single writer of the status of the resources
+func isStatusWriter() bool {
+	if !config.Datadog.GetBool("leader_election") {
+		return true
+	}
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		return false
+	}
+	return leaderEngine.IsLeader()
+}
+
+// isNodeStatusWriter returns whether the node agent is the leader, to have a
+// single writer of the status of the resources without a cluster agent
+func isNodeStatusWriter() bool {
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		return false
+	}
+	if err := leaderEngine.EnsureLeaderElectionRuns(); err != nil {
+		log.Debugf("Cannot update the status of the DatadogCheckConfigs: %s", err)
+		return false
+	}
+	return leaderEngine.IsLeader()
+}
+
+// String returns a string representation of the KubeCheckConfigProvider
+func (p *KubeCheckConfigProvider) String() string {
+	return names.KubeCheckConfigs
+}
+
+// Collect builds the templates of the DatadogCheckConfig resources
+func (p *KubeCheckConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	if !p.synced() {
+		return nil, errors.New("the DatadogCheckConfig informer is not synced yet")
+	}
+	objects, err := p.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.upToDate = true
+	p.Unlock()
+
+	configs, configErrors := p.parseCheckConfigs(ctx, objects)
+
+	p.Lock()
+	p.configErrors = configErrors
+	p.Unlock()
+
+	return configs, nil
+}
+
+// IsUpToDate returns false when the resources or the services changed since
+// the last Collect. The pods of the node agent are always considered changed,
+// as they are for the kubelet provider.
+func (p *KubeCheckConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	if p.targets.kind() == types.CheckConfigTargetPod {
+		return false, nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	return p.upToDate, nil
+}
+
+// GetConfigErrors returns the validation errors of the resources, by namespace/name
+func (p *KubeCheckConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.Lock()
+	defer p.Unlock()
+	return p.configErrors
+}
+
+func (p *KubeCheckConfigProvider) parseCheckConfigs(ctx context.Context, objects []runtime.Object) ([]integration.Config, map[string]ErrorMsgSet) {
+	var configs []integration.Config
+	configErrors := make(map[string]ErrorMsgSet)
+	updateStatus := p.updateStatus()
+
+	for _, obj := range objects {
+		unstructuredObj, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			log.Errorf("Expected an Unstructured type, got: %T", obj)
+			continue
+		}
+		checkConfig := &types.DatadogCheckConfig{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObj.UnstructuredContent(), checkConfig); err != nil {
+			key := unstructuredObj.GetNamespace() + "/" + unstructuredObj.GetName()
+			log.Warnf("Cannot parse the DatadogCheckConfig %s: %s", key, err)
+			configErrors[key] = ErrorMsgSet{err.Error(): {}}
+			continue
+		}
+		key := checkConfig.Namespace + "/" + checkConfig.Name
+
+		selector, err := checkConfig.Validate()
+		if updateStatus {
+			if statusErr := p.writeStatus(ctx, unstructuredObj, checkConfig, err); statusErr != nil {
+				log.Warnf("Cannot update the status of the DatadogCheckConfig %s: %s", key, statusErr)
+				// retry on the next poll
+				p.invalidate(obj)
+			}
+		}
+		if err != nil {
+			log.Debugf("Invalid DatadogCheckConfig %s: %s", key, err)
+			configErrors[key] = ErrorMsgSet{err.Error(): {}}
+			continue
+		}
+		if checkConfig.GetTarget() != p.targets.kind() {
+			continue
+		}
+
+		ids, err := p.targets.identifiers(ctx, checkConfig.Namespace, selector, checkConfig.Spec.ContainerName)
+		if err != nil {
+			log.Warnf("Cannot find the resources selected by the DatadogCheckConfig %s: %s", key, err)
+			configErrors[key] = ErrorMsgSet{err.Error(): {}}
+			continue
+		}
+		if len(ids) == 0 {
+			log.Tracef("The DatadogCheckConfig %s doesn't select any %s", key, p.targets.kind())
+			continue
+		}
+		configs = append(configs, buildCheckConfigTemplate(checkConfig, ids))
+	}
+
+	return configs, configErrors
+}
+
+// buildCheckConfigTemplate returns the template of a valid DatadogCheckConfig
+// matching the resources with the given AD identifiers
+func buildCheckConfigTemplate(checkConfig *types.DatadogCheckConfig, ids []string) integration.Config {
+	spec := checkConfig.Spec
+	sort.Strings(ids)
+
+	tpl := integration.Config{
+		Name:                    spec.CheckName,
+		ADIdentifiers:           ids,
+		ClusterCheck:            checkConfig.GetTarget() == types.CheckConfigTargetService,
+		Source:                  "kube_check_configs:" + checkConfig.Namespace + "/" + checkConfig.Name,
+		IgnoreAutodiscoveryTags: spec.IgnoreAutodiscoveryTags,
+	}
+	if spec.CheckName != "" {
+		tpl.InitConfig = integration.Data("{}")
+		if len(spec.InitConfig) > 0 && string(spec.InitConfig) != "null" {
+			tpl.InitConfig = integration.Data(spec.InitConfig)
+		}
+		for _, instance := range spec.Instances {
+			tpl.Instances = append(tpl.Instances, integration.Data(instance))
+		}
+	}
+	if len(spec.Logs) > 0 {
+		tpl.LogsConfig = integration.Data(spec.Logs)
+	}
+	return tpl
+}
+
+// writeStatus sets the Valid condition of the resource, unless it's already up to date
+func (p *KubeCheckConfigProvider) writeStatus(ctx context.Context, obj *unstructured.Unstructured, checkConfig *types.DatadogCheckConfig, validationErr error) error {
+	condition := metav1.Condition{
+		Type:               types.CheckConfigConditionValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             checkConfigReasonValid,
+		ObservedGeneration: checkConfig.Generation,
+	}
+	if validationErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = checkConfigReasonInvalid
+		condition.Message = validationErr.Error()
+	}
+
+	current := meta.FindStatusCondition(checkConfig.Status.Conditions, types.CheckConfigConditionValid)
+	if current != nil && current.Status == condition.Status && current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+
+	status := checkConfig.Status
+	meta.SetStatusCondition(&status.Conditions, condition)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+
+	updated := obj.DeepCopy()
+	if err := unstructured.SetNestedField(updated.Object, content, "status"); err != nil {
+		return err
+	}
+	_, err = p.client.Resource(types.CheckConfigGVR).Namespace(checkConfig.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+func (p *KubeCheckConfigProvider) invalidate(obj interface{}) {
+	if obj != nil {
+		log.Trace("Invalidating configs on new/deleted DatadogCheckConfig or service")
+		p.Lock()
+		p.upToDate = false
+		p.Unlock()
+	}
+}
+
+func (p *KubeCheckConfigProvider) invalidateIfSpecChanged(old, obj interface{}) {
+	castedObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		log.Errorf("Expected an Unstructured type, got: %T", obj)
+		return
+	}
+	castedOld, ok := old.(*unstructured.Unstructured)
+	if !ok || castedObj.GetGeneration() != castedOld.GetGeneration() {
+		log.Trace("Invalidating configs on DatadogCheckConfig change")
+		p.invalidate(obj)
+	}
+}
+
+func (p *KubeCheckConfigProvider) invalidateIfServiceLabelsChanged(old, obj interface{}) {
+	castedObj, ok := obj.(*v1.Service)
+	if !ok {
+		log.Errorf("Expected a Service type, got: %T", obj)
+		return
+	}
+	castedOld, ok := old.(*v1.Service)
+	if !ok || !labels.Equals(castedObj.Labels, castedOld.Labels) {
+		log.Trace("Invalidating configs on service labels change")
+		p.invalidate(obj)
+	}
+}
+
+// serviceTargets finds the services selected by the DatadogCheckConfigs
+type serviceTargets struct {
+	lister listersv1.ServiceLister
+}
+
+func (t *serviceTargets) kind() string {
+	return types.CheckConfigTargetService
+}
+
+func (t *serviceTargets) identifiers(_ context.Context, namespace string, selector labels.Selector, _ string) ([]string, error) {
+	services, err := t.lister.Services(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(services))
+	for _, svc := range services {
+		ids = append(ids, apiserver.EntityForService(svc))
+	}
+	return ids, nil
+}
+
+func init() {
+	RegisterProvider("kube_check_configs", NewKubeCheckConfigProvider)
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver,kubelet
+
+package providers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+// podTargets finds the containers of the pods of the node selected by the
+// DatadogCheckConfigs
+type podTargets struct {
+	kubelet kubelet.KubeUtilInterface
+}
+
+func newPodTargets() (checkConfigTargets, error) {
+	return &podTargets{}, nil
+}
+
+func (t *podTargets) kind() string {
+	return types.CheckConfigTargetPod
+}
+
+func (t *podTargets) identifiers(ctx context.Context, namespace string, selector labels.Selector, containerName string) ([]string, error) {
+	var err error
+	if t.kubelet == nil {
+		t.kubelet, err = kubelet.GetKubeUtil()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pods, err := t.kubelet.GetLocalPodList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, pod := range pods {
+		if pod.Metadata.Namespace != namespace || !selector.Matches(labels.Set(pod.Metadata.Labels)) {
+			continue
+		}
+		for _, container := range pod.Status.GetAllContainers() {
+			if container.ID == "" || (containerName != "" && container.Name != containerName) {
+				continue
+			}
+			ids = append(ids, container.ID)
+		}
+	}
+	return ids, nil
+}
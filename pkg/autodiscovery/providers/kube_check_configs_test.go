@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+type fakeCheckConfigTargets struct {
+	targetKind string
+	// ids by namespace and selector
+	ids map[string][]string
+}
+
+func (t *fakeCheckConfigTargets) kind() string {
+	return t.targetKind
+}
+
+func (t *fakeCheckConfigTargets) identifiers(_ context.Context, namespace string, selector labels.Selector, containerName string) ([]string, error) {
+	key := namespace + "/" + selector.String()
+	if containerName != "" {
+		key += "/" + containerName
+	}
+	return t.ids[key], nil
+}
+
+func newCheckConfigObject(name string, generation int64, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "datadoghq.com/v1alpha1",
+		"kind":       "DatadogCheckConfig",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  "cache",
+			"generation": generation,
+		},
+		"spec": spec,
+	}}
+	return obj
+}
+
+func checkConfigObjects() []runtime.Object {
+	return []runtime.Object{
+		newCheckConfigObject("redis", 1, map[string]interface{}{
+			"selector":      map[string]interface{}{"matchLabels": map[string]interface{}{"app": "redis"}},
+			"containerName": "redis",
+			"checkName":     "redisdb",
+			"instances":     []interface{}{map[string]interface{}{"host": "%%host%%", "port": "6379"}},
+			"logs":          []interface{}{map[string]interface{}{"source": "redis"}},
+		}),
+		newCheckConfigObject("memcached", 1, map[string]interface{}{
+			"selector":   map[string]interface{}{"matchLabels": map[string]interface{}{"app": "memcached"}},
+			"checkName":  "mcache",
+			"initConfig": map[string]interface{}{"timeout": int64(5)},
+			"instances":  []interface{}{map[string]interface{}{"url": "%%host%%"}},
+		}),
+		newCheckConfigObject("unmatched", 1, map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": map[string]interface{}{"app": "none"}},
+			"checkName": "redisdb",
+			"instances": []interface{}{map[string]interface{}{"host": "%%host%%"}},
+		}),
+		newCheckConfigObject("frontend", 1, map[string]interface{}{
+			"target":    "service",
+			"selector":  map[string]interface{}{"matchLabels": map[string]interface{}{"app": "frontend"}},
+			"checkName": "http_check",
+			"instances": []interface{}{map[string]interface{}{"url": "http://%%host%%"}},
+		}),
+		newCheckConfigObject("invalid", 1, map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": map[string]interface{}{"app": "redis"}},
+			"checkName": "redisdb",
+		}),
+	}
+}
+
+func TestKubeCheckConfigProviderPods(t *testing.T) {
+	p := &KubeCheckConfigProvider{
+		targets: &fakeCheckConfigTargets{
+			targetKind: types.CheckConfigTargetPod,
+			ids: map[string][]string{
+				"cache/app=redis/redis":   {"docker://b", "docker://a"},
+				"cache/app=memcached":     {"containerd://c"},
+				"cache/app=frontend":      {"kube_service_uid://d"},
+				"cache/app=redis/sidecar": {"docker://e"},
+			},
+		},
+		updateStatus: func() bool { return false },
+	}
+
+	configs, configErrors := p.parseCheckConfigs(context.Background(), checkConfigObjects())
+	assert.Equal(t, []integration.Config{
+		{
+			Name:          "redisdb",
+			ADIdentifiers: []string{"docker://a", "docker://b"},
+			InitConfig:    integration.Data("{}"),
+			Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":"6379"}`)},
+			LogsConfig:    integration.Data(`[{"source":"redis"}]`),
+			Source:        "kube_check_configs:cache/redis",
+		},
+		{
+			Name:          "mcache",
+			ADIdentifiers: []string{"containerd://c"},
+			InitConfig:    integration.Data(`{"timeout":5}`),
+			Instances:     []integration.Data{integration.Data(`{"url":"%%host%%"}`)},
+			Source:        "kube_check_configs:cache/memcached",
+		},
+	}, configs)
+	assert.Equal(t, map[string]ErrorMsgSet{
+		"cache/invalid": {"the redisdb check has no instances": {}},
+	}, configErrors)
+}
+
+func TestKubeCheckConfigProviderServices(t *testing.T) {
+	objects := checkConfigObjects()
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.CheckConfigGVR: "DatadogCheckConfigList",
+	}, objects...)
+
+	p := &KubeCheckConfigProvider{
+		client: client,
+		targets: &fakeCheckConfigTargets{
+			targetKind: types.CheckConfigTargetService,
+			ids:        map[string][]string{"cache/app=frontend": {"kube_service_uid://d"}},
+		},
+		updateStatus: func() bool { return true },
+	}
+
+	ctx := context.Background()
+	configs, configErrors := p.parseCheckConfigs(ctx, objects)
+	assert.Equal(t, []integration.Config{
+		{
+			Name:          "http_check",
+			ADIdentifiers: []string{"kube_service_uid://d"},
+			InitConfig:    integration.Data("{}"),
+			Instances:     []integration.Data{integration.Data(`{"url":"http://%%host%%"}`)},
+			ClusterCheck:  true,
+			Source:        "kube_check_configs:cache/frontend",
+		},
+	}, configs)
+	assert.Len(t, configErrors, 1)
+
+	// the validation is reported in the status of every resource
+	assert.Len(t, client.Actions(), len(objects))
+	updatedObjects := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		name := obj.(*unstructured.Unstructured).GetName()
+		updated, err := client.Resource(types.CheckConfigGVR).Namespace("cache").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		updatedObjects = append(updatedObjects, updated)
+
+		checkConfig := &types.DatadogCheckConfig{}
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(updated.UnstructuredContent(), checkConfig))
+		condition := meta.FindStatusCondition(checkConfig.Status.Conditions, types.CheckConfigConditionValid)
+		require.NotNil(t, condition, name)
+		assert.Equal(t, int64(1), condition.ObservedGeneration)
+		if name == "invalid" {
+			assert.Equal(t, metav1.ConditionFalse, condition.Status)
+			assert.Equal(t, "InvalidSpec", condition.Reason)
+			assert.Equal(t, "the redisdb check has no instances", condition.Message)
+		} else {
+			assert.Equal(t, metav1.ConditionTrue, condition.Status, name)
+		}
+	}
+
+	// the status is up to date
+	client.ClearActions()
+	p.parseCheckConfigs(ctx, updatedObjects)
+	assert.Empty(t, client.Actions())
+}
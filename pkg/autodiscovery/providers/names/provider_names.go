@@ -15,6 +15,8 @@ const (
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	HTTP               = "http"
+	KubeCheckConfigs   = "kubernetes-check-configs"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
 	KubeEndpoints      = "kubernetes-endpoints"
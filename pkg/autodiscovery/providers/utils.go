@@ -10,8 +10,10 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/utils"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -150,6 +152,31 @@ func extractTemplatesFromMap(key string, input map[string]string, prefix string)
 	return configs, errors
 }
 
+// ExtractPodAnnotationTemplates returns the templates defined in the pod
+// annotations for the container with the given name, as the kubelet provider
+// does. The new annotation prefix takes precedence over the legacy one.
+func ExtractPodAnnotationTemplates(key string, annotations map[string]string, containerName string) ([]integration.Config, []error) {
+	var adExtractFormat string
+	for name := range annotations {
+		if strings.HasPrefix(name, utils.NewPodAnnotationPrefix) {
+			adExtractFormat = utils.NewPodAnnotationFormat
+			break
+		}
+		if strings.HasPrefix(name, utils.LegacyPodAnnotationPrefix) {
+			adExtractFormat = utils.LegacyPodAnnotationFormat
+		}
+	}
+	if adExtractFormat == "" {
+		return nil, nil
+	}
+
+	adIdentifier := containerName
+	if customADIdentifier, customIDFound := utils.GetCustomCheckID(annotations, containerName); customIDFound {
+		adIdentifier = customADIdentifier
+	}
+	return extractTemplatesFromMap(key, annotations, fmt.Sprintf(adExtractFormat, adIdentifier))
+}
+
 // extractCheckTemplatesFromMap returns all the check configurations from a given map.
 func extractCheckTemplatesFromMap(key string, input map[string]string, prefix string) ([]integration.Config, error) {
 	value, found := input[prefix+checkNamePath]
@@ -425,6 +425,57 @@ func TestExtractTemplatesFromMap(t *testing.T) {
 	}
 }
 
+func TestExtractPodAnnotationTemplates(t *testing.T) {
+	apache := []integration.Config{
+		{
+			Name:          "apache",
+			Instances:     []integration.Data{integration.Data("{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}")},
+			InitConfig:    integration.Data("{}"),
+			ADIdentifiers: []string{"docker://abc"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		annotations map[string]string
+		output      []integration.Config
+	}{
+		"new prefix": {
+			annotations: map[string]string{
+				"ad.datadoghq.com/web.check_names":  "[\"apache\"]",
+				"ad.datadoghq.com/web.init_configs": "[{}]",
+				"ad.datadoghq.com/web.instances":    "[{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}]",
+			},
+			output: apache,
+		},
+		"legacy prefix": {
+			annotations: map[string]string{
+				"service-discovery.datadoghq.com/web.check_names":  "[\"apache\"]",
+				"service-discovery.datadoghq.com/web.init_configs": "[{}]",
+				"service-discovery.datadoghq.com/web.instances":    "[{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}]",
+			},
+			output: apache,
+		},
+		"custom check id": {
+			annotations: map[string]string{
+				"ad.datadoghq.com/web.check.id":        "custom",
+				"ad.datadoghq.com/custom.check_names":  "[\"apache\"]",
+				"ad.datadoghq.com/custom.init_configs": "[{}]",
+				"ad.datadoghq.com/custom.instances":    "[{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}]",
+			},
+			output: apache,
+		},
+		"no template": {
+			annotations: map[string]string{"team": "web"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			configs, errs := ExtractPodAnnotationTemplates("docker://abc", tc.annotations, "web")
+			assert.Empty(t, errs)
+			assert.Equal(t, tc.output, configs)
+		})
+	}
+}
+
 func TestGetPollInterval(t *testing.T) {
 	cp := config.ConfigurationProviders{}
 	assert.Equal(t, GetPollInterval(cp), 10*time.Second)
@@ -6,6 +6,7 @@
 package check
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -27,6 +28,12 @@ type Check interface {
 	Configure(config, initConfig integration.Data, source string) error
 	// Interval returns the interval time for the check
 	Interval() time.Duration
+	// Timeout returns the maximum duration of a run of the check, 0 if the runs are not limited
+	Timeout() time.Duration
+	// Schedule returns the scheduling options of the check, on top of its interval
+	Schedule() Schedule
+	// ResourceLimits returns the resource limits of the runs of the check
+	ResourceLimits() ResourceLimits
 	// ID provides a unique identifier for every check instance
 	ID() ID
 	// GetWarnings returns the last warning registered by the check
@@ -40,3 +47,12 @@ type Check interface {
 	// IsTelemetryEnabled returns if telemetry is enabled for this check
 	IsTelemetryEnabled() bool
 }
+
+// TimeoutError is the error of a check run which didn't complete within the timeout of the check
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("check run timed out after %s, its results are discarded", e.Timeout)
+}
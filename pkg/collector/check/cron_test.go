@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, 3, 10, 14, 25, 30, 0, time.UTC)
+
+	for _, tc := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, 3, 10, 14, 26, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 3, 10, 14, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2021, 3, 11, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2021, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"0,30 9-17 * * 1-5", time.Date(2021, 3, 10, 14, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"10/20 * * * *", time.Date(2021, 3, 10, 14, 30, 0, 0, time.UTC)},
+		// the day of month and the day of week match independently when both are set
+		{"0 0 15 * 5", time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	} {
+		cron, err := ParseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.expected, cron.Next(now), tc.expr)
+	}
+
+	cron, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, cron.Next(now).IsZero())
+
+	// a time on a matching minute is returned as is
+	cron, err = ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	at := time.Date(2021, 3, 10, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, at, cron.Next(at))
+}
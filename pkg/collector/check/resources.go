@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// ResourceLimitActionSkip skips the next run of a check which exceeded its resource limits
+	ResourceLimitActionSkip = "skip"
+	// ResourceLimitActionBackoff skips a number of runs, doubling with every consecutive
+	// run which exceeded the resource limits of the check
+	ResourceLimitActionBackoff = "backoff"
+)
+
+// cpuTimeLimitSupported is whether the CPU time of the checks can be sampled to
+// enforce their limits, which is only the case on Linux
+var cpuTimeLimitSupported = runtime.GOOS == "linux"
+
+// ResourceUsage holds the resources used by a check run. As the checks share the
+// agent process, the allocations and goroutines are sampled process-wide, and
+// include those of the checks running concurrently: they are only informative, and
+// can't be limited.
+type ResourceUsage struct {
+	// CPUTime is the CPU time of the thread running the check, 0 if unavailable
+	CPUTime time.Duration
+	// Allocations is the number of heap objects allocated by the process during the run
+	Allocations uint64
+	// AllocatedBytes is the number of heap bytes allocated by the process during the run
+	AllocatedBytes uint64
+	// Goroutines is the change of the number of goroutines of the process during the run
+	Goroutines int
+}
+
+// ResourceLimits holds the resource limits of the runs of a check. The zero
+// value doesn't limit the runs.
+type ResourceLimits struct {
+	CPUTime time.Duration
+	// Action is ResourceLimitActionSkip or ResourceLimitActionBackoff
+	Action string
+}
+
+// ParseResourceLimits returns the resource limits of an instance
+func ParseResourceLimits(options integration.CommonInstanceConfig) (ResourceLimits, error) {
+	config := options.ResourceLimits
+	limits := ResourceLimits{
+		CPUTime: time.Duration(config.CPUTime * float64(time.Second)),
+		Action:  config.Action,
+	}
+
+	if config.CPUTime < 0 {
+		return limits, fmt.Errorf("invalid resource limits: the limits must be positive")
+	}
+	if config.CPUTime > 0 && !cpuTimeLimitSupported {
+		log.Warnf("resource_limits.cpu_time is ignored on %s: the CPU time of the checks is only sampled on Linux", runtime.GOOS)
+	}
+	switch limits.Action {
+	case "":
+		limits.Action = ResourceLimitActionBackoff
+	case ResourceLimitActionSkip, ResourceLimitActionBackoff:
+	default:
+		return limits, fmt.Errorf("invalid resource limits action %q: the action must be %q or %q",
+			limits.Action, ResourceLimitActionSkip, ResourceLimitActionBackoff)
+	}
+	return limits, nil
+}
+
+// IsZero returns whether the runs are not limited
+func (l ResourceLimits) IsZero() bool {
+	return l.CPUTime == 0
+}
+
+// Exceeded returns the descriptions of the limits exceeded by usage, nil if none is
+func (l ResourceLimits) Exceeded(usage ResourceUsage) []string {
+	var exceeded []string
+	if l.CPUTime > 0 && usage.CPUTime > l.CPUTime {
+		exceeded = append(exceeded, fmt.Sprintf("CPU time %s > %s", usage.CPUTime, l.CPUTime))
+	}
+	return exceeded
+}
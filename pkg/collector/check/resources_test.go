@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseResourceLimits(t *testing.T) {
+	limits, err := ParseResourceLimits(integration.CommonInstanceConfig{})
+	require.NoError(t, err)
+	assert.True(t, limits.IsZero())
+	assert.Equal(t, ResourceLimitActionBackoff, limits.Action)
+
+	limits, err = ParseResourceLimits(integration.CommonInstanceConfig{
+		ResourceLimits: integration.ResourceLimitsConfig{CPUTime: 1.5, Action: "skip"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ResourceLimits{
+		CPUTime: 1500 * time.Millisecond,
+		Action:  ResourceLimitActionSkip,
+	}, limits)
+
+	for _, config := range []integration.ResourceLimitsConfig{
+		{CPUTime: -1},
+		{Action: "stop"},
+	} {
+		_, err := ParseResourceLimits(integration.CommonInstanceConfig{ResourceLimits: config})
+		assert.Error(t, err, config)
+	}
+}
+
+func TestResourceLimitsExceeded(t *testing.T) {
+	limits := ResourceLimits{CPUTime: time.Second}
+
+	assert.Empty(t, limits.Exceeded(ResourceUsage{CPUTime: time.Second}))
+	assert.Equal(t, []string{"CPU time 2s > 1s"}, limits.Exceeded(ResourceUsage{CPUTime: 2 * time.Second}))
+
+	// The process-wide allocations and goroutines are not limited
+	assert.Empty(t, limits.Exceeded(ResourceUsage{CPUTime: time.Second, AllocatedBytes: 1 << 30, Goroutines: 1000}))
+
+	// The zero limits don't limit anything
+	assert.Empty(t, ResourceLimits{}.Exceeded(ResourceUsage{CPUTime: time.Hour, AllocatedBytes: 1 << 30, Goroutines: 1000}))
+}
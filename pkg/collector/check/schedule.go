@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// maxScheduleIterations bounds the search of a run matching both the cron
+// expression and the time windows of a schedule.
+const maxScheduleIterations = 1000
+
+// Schedule holds the scheduling options of a check instance, on top of its
+// interval. The zero value schedules the check at its interval.
+type Schedule struct {
+	// Cron replaces the interval of the check when set
+	Cron *CronExpression
+	// Windows restrict the runs to some times of the day
+	Windows []TimeWindow
+	// Jitter is the maximum random delay added to every run
+	Jitter time.Duration
+	// OneShot runs the check only once, at its first scheduled run
+	OneShot bool
+}
+
+// TimeWindow is a time of the day range, in the local time zone. The end
+// is excluded, and is before the start when the window spans midnight.
+type TimeWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseSchedule returns the scheduling options of an instance
+func ParseSchedule(options integration.CommonInstanceConfig) (Schedule, error) {
+	schedule := Schedule{OneShot: options.OneShot}
+
+	if options.Cron != "" {
+		cron, err := ParseCron(options.Cron)
+		if err != nil {
+			return schedule, err
+		}
+		schedule.Cron = cron
+	}
+
+	for _, window := range options.TimeWindows {
+		w, err := ParseTimeWindow(window)
+		if err != nil {
+			return schedule, err
+		}
+		schedule.Windows = append(schedule.Windows, w)
+	}
+
+	if options.Jitter < 0 {
+		return schedule, fmt.Errorf("invalid jitter %d: the jitter must be positive", options.Jitter)
+	}
+	schedule.Jitter = time.Duration(options.Jitter) * time.Second
+
+	return schedule, nil
+}
+
+// ParseTimeWindow parses a time window formatted as "HH:MM-HH:MM"
+func ParseTimeWindow(window string) (TimeWindow, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return TimeWindow{}, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", window)
+	}
+
+	var bounds [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return TimeWindow{}, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", window)
+		}
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if bounds[0] == bounds[1] {
+		return TimeWindow{}, fmt.Errorf("invalid time window %q: the window is empty", window)
+	}
+	return TimeWindow{Start: bounds[0], End: bounds[1]}, nil
+}
+
+// Contains returns whether t is in the window
+func (w TimeWindow) Contains(t time.Time) bool {
+	timeOfDay := t.Sub(midnight(t))
+	if w.Start < w.End {
+		return timeOfDay >= w.Start && timeOfDay < w.End
+	}
+	return timeOfDay >= w.Start || timeOfDay < w.End
+}
+
+// nextStart returns the first start of the window after t
+func (w TimeWindow) nextStart(t time.Time) time.Time {
+	day := midnight(t)
+	start := day.Add(w.Start)
+	if !start.After(t) {
+		start = day.AddDate(0, 0, 1).Add(w.Start)
+	}
+	return start
+}
+
+// String returns the window formatted as "HH:MM-HH:MM"
+func (w TimeWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d",
+		int(w.Start.Hours()), int(w.Start.Minutes())%60, int(w.End.Hours()), int(w.End.Minutes())%60)
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// IsZero returns whether the schedule only relies on the interval of the check
+func (s Schedule) IsZero() bool {
+	return s.Cron == nil && len(s.Windows) == 0 && s.Jitter == 0 && !s.OneShot
+}
+
+// FirstRun returns the time of the first run of the check, without the jitter
+func (s Schedule) FirstRun(now time.Time) time.Time {
+	return s.next(now)
+}
+
+// NextRun returns the time of the run following the run at last, without the
+// jitter. It returns the zero time when there is no next run.
+func (s Schedule) NextRun(last time.Time, interval time.Duration) time.Time {
+	if s.OneShot {
+		return time.Time{}
+	}
+	if s.Cron != nil {
+		return s.next(last.Truncate(time.Minute).Add(time.Minute))
+	}
+	return s.next(last.Add(interval))
+}
+
+// Delay returns a random delay, up to the jitter, to add to a run
+func (s Schedule) Delay() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.Jitter)))
+}
+
+// next returns the first time at or after t matching the cron expression and
+// the time windows, or the zero time if there is none.
+func (s Schedule) next(t time.Time) time.Time {
+	for i := 0; i < maxScheduleIterations; i++ {
+		if s.Cron != nil {
+			if t = s.Cron.Next(t); t.IsZero() {
+				return t
+			}
+		}
+		if len(s.Windows) == 0 {
+			return t
+		}
+
+		var nextStart time.Time
+		for _, w := range s.Windows {
+			if w.Contains(t) {
+				return t
+			}
+			if start := w.nextStart(t); nextStart.IsZero() || start.Before(nextStart) {
+				nextStart = start
+			}
+		}
+		t = nextStart
+	}
+	return time.Time{}
+}
+
+// String returns a printable version of the schedule options
+func (s Schedule) String() string {
+	var options []string
+	if s.Cron != nil {
+		options = append(options, fmt.Sprintf("cron %q", s.Cron))
+	}
+	for _, w := range s.Windows {
+		options = append(options, "window "+w.String())
+	}
+	if s.Jitter > 0 {
+		options = append(options, "jitter "+s.Jitter.String())
+	}
+	if s.OneShot {
+		options = append(options, "one-shot")
+	}
+	return strings.Join(options, ", ")
+}
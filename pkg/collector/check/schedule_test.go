@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule(integration.CommonInstanceConfig{})
+	require.NoError(t, err)
+	assert.True(t, schedule.IsZero())
+
+	schedule, err = ParseSchedule(integration.CommonInstanceConfig{
+		Cron:        "0 2 * * *",
+		TimeWindows: []string{"22:00-06:00"},
+		Jitter:      30,
+		OneShot:     true,
+	})
+	require.NoError(t, err)
+	assert.False(t, schedule.IsZero())
+	assert.Equal(t, "0 2 * * *", schedule.Cron.String())
+	assert.Equal(t, []TimeWindow{{Start: 22 * time.Hour, End: 6 * time.Hour}}, schedule.Windows)
+	assert.Equal(t, 30*time.Second, schedule.Jitter)
+	assert.True(t, schedule.OneShot)
+	assert.Equal(t, `cron "0 2 * * *", window 22:00-06:00, jitter 30s, one-shot`, schedule.String())
+
+	for _, options := range []integration.CommonInstanceConfig{
+		{Cron: "0 2 * *"},
+		{TimeWindows: []string{"22:00"}},
+		{TimeWindows: []string{"25:00-26:00"}},
+		{TimeWindows: []string{"10:00-10:00"}},
+		{Jitter: -1},
+	} {
+		_, err := ParseSchedule(options)
+		assert.Error(t, err, options)
+	}
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	day := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	w := TimeWindow{Start: 9 * time.Hour, End: 17 * time.Hour}
+	assert.False(t, w.Contains(day.Add(8*time.Hour+59*time.Minute)))
+	assert.True(t, w.Contains(day.Add(9*time.Hour)))
+	assert.True(t, w.Contains(day.Add(16*time.Hour+59*time.Minute)))
+	assert.False(t, w.Contains(day.Add(17*time.Hour)))
+
+	overnight := TimeWindow{Start: 22 * time.Hour, End: 6 * time.Hour}
+	assert.True(t, overnight.Contains(day.Add(23*time.Hour)))
+	assert.True(t, overnight.Contains(day.Add(5*time.Hour)))
+	assert.False(t, overnight.Contains(day.Add(12*time.Hour)))
+}
+
+func TestScheduleRuns(t *testing.T) {
+	day := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	now := day.Add(12*time.Hour + 30*time.Second)
+
+	// interval only
+	schedule := Schedule{Jitter: time.Second}
+	assert.Equal(t, now, schedule.FirstRun(now))
+	assert.Equal(t, now.Add(15*time.Second), schedule.NextRun(now, 15*time.Second))
+
+	// cron expression
+	cron, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	schedule = Schedule{Cron: cron}
+	first := schedule.FirstRun(now)
+	assert.Equal(t, day.Add(26*time.Hour), first)
+	assert.Equal(t, day.Add(50*time.Hour), schedule.NextRun(first, 15*time.Second))
+
+	// time window: the runs are delayed to the start of the window
+	schedule = Schedule{Windows: []TimeWindow{{Start: 22 * time.Hour, End: 23 * time.Hour}}}
+	first = schedule.FirstRun(now)
+	assert.Equal(t, day.Add(22*time.Hour), first)
+	assert.Equal(t, day.Add(22*time.Hour+time.Minute), schedule.NextRun(first, time.Minute))
+	assert.Equal(t, day.Add(46*time.Hour), schedule.NextRun(day.Add(22*time.Hour+59*time.Minute), time.Minute))
+
+	// cron expression restricted to a time window
+	cron, err = ParseCron("*/30 * * * *")
+	require.NoError(t, err)
+	schedule = Schedule{Cron: cron, Windows: []TimeWindow{{Start: 13*time.Hour + 10*time.Minute, End: 14 * time.Hour}}}
+	first = schedule.FirstRun(now)
+	assert.Equal(t, day.Add(13*time.Hour+30*time.Minute), first)
+	assert.Equal(t, day.Add(37*time.Hour+30*time.Minute), schedule.NextRun(first, 15*time.Second))
+
+	// a cron expression never matching its time window has no run
+	cron, err = ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	schedule = Schedule{Cron: cron, Windows: []TimeWindow{{Start: 10 * time.Hour, End: 11 * time.Hour}}}
+	assert.True(t, schedule.FirstRun(now).IsZero())
+
+	// one-shot
+	schedule = Schedule{OneShot: true}
+	assert.Equal(t, now, schedule.FirstRun(now))
+	assert.True(t, schedule.NextRun(now, 15*time.Second).IsZero())
+}
+
+func TestScheduleDelay(t *testing.T) {
+	assert.Zero(t, Schedule{}.Delay())
+
+	schedule := Schedule{Jitter: 10 * time.Second}
+	for i := 0; i < 100; i++ {
+		delay := schedule.Delay()
+		assert.True(t, delay >= 0 && delay < 10*time.Second, delay)
+	}
+}
@@ -41,6 +41,14 @@ var (
 		[]string{"check_name"}, "Service checks count")
 	tlmExecutionTime = telemetry.NewGauge("checks", "execution_time",
 		[]string{"check_name"}, "Check execution time")
+	tlmTimeouts = telemetry.NewCounter("checks", "timeouts",
+		[]string{"check_name"}, "Check runs which exceeded their timeout")
+	tlmCPUTime = telemetry.NewCounter("checks", "cpu_time",
+		[]string{"check_name"}, "Check CPU time in milliseconds")
+	tlmProcessAllocatedBytes = telemetry.NewCounter("checks", "process_allocated_bytes",
+		[]string{"check_name"}, "Heap bytes allocated by the agent process during check runs")
+	tlmSkippedRuns = telemetry.NewCounter("checks", "skipped_runs",
+		[]string{"check_name"}, "Check runs skipped because the check exceeded its resource limits")
 )
 
 // SenderStats contains statistics showing the count of various types of telemetry sent by a check sender
@@ -79,6 +87,7 @@ type Stats struct {
 	TotalRuns                uint64
 	TotalErrors              uint64
 	TotalWarnings            uint64
+	TotalTimeouts            uint64
 	MetricSamples            int64
 	Events                   int64
 	ServiceChecks            int64
@@ -90,10 +99,22 @@ type Stats struct {
 	ExecutionTimes           [32]int64 // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
 	AverageExecutionTime     int64     // average run duration
 	LastExecutionTime        int64     // most recent run duration, provided for convenience
+	Timeout                  int64     // maximum run duration, 0 if the runs are not limited
 	LastSuccessDate          int64     // most recent successful execution date, unix timestamp in seconds
 	LastError                string    // error that occurred in the last run, if any
 	LastWarnings             []string  // warnings that occurred in the last run, if any
 	UpdateTimestamp          int64     // latest update to this instance, unix timestamp in seconds
+	ResourceUsageRuns        uint64    // runs whose resource usage was sampled
+	LastCPUTime              int64     // CPU time of the most recent sampled run, in milliseconds
+	AverageCPUTime           int64     // average CPU time of the sampled runs, in milliseconds
+	LastProcessAllocations   uint64    // heap objects allocated by the agent process during the most recent sampled run
+	LastProcessAllocBytes    uint64    // heap bytes allocated by the agent process during the most recent sampled run
+	AverageProcessAllocBytes uint64    // average heap bytes allocated by the agent process during the sampled runs
+	LastProcessGoroutines    int64     // change of the number of goroutines of the agent process during the most recent sampled run
+	ExceededResourceLimits   []string  // resource limits exceeded by the most recent sampled run, if any
+	TotalSkippedRuns         uint64    // runs skipped because the check exceeded its resource limits
+	totalCPUTime             int64
+	totalProcessAllocBytes   uint64
 	m                        sync.Mutex
 	telemetry                bool // do we want telemetry on this Check
 }
@@ -105,6 +126,7 @@ func NewStats(c Check) *Stats {
 		CheckName:                c.String(),
 		CheckVersion:             c.Version(),
 		CheckConfigSource:        c.ConfigSource(),
+		Timeout:                  c.Timeout().Nanoseconds() / 1e6,
 		telemetry:                telemetry_utils.IsCheckEnabled(c.String()),
 		EventPlatformEvents:      make(map[string]int64),
 		TotalEventPlatformEvents: make(map[string]int64),
@@ -148,6 +170,12 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 			tlmRuns.Inc(cs.CheckName, runCheckFailureTag)
 		}
 		cs.LastError = err.Error()
+		if _, ok := err.(TimeoutError); ok {
+			cs.TotalTimeouts++
+			if cs.telemetry {
+				tlmTimeouts.Inc(cs.CheckName)
+			}
+		}
 	} else {
 		if cs.telemetry {
 			tlmRuns.Inc(cs.CheckName, runCheckSuccessTag)
@@ -167,6 +195,11 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 	}
 	cs.UpdateTimestamp = time.Now().Unix()
 
+	// The results of a run which timed out are discarded
+	if _, ok := err.(TimeoutError); ok {
+		return
+	}
+
 	if metricStats.MetricSamples > 0 {
 		cs.MetricSamples = metricStats.MetricSamples
 		cs.TotalMetricSamples += uint64(metricStats.MetricSamples)
@@ -198,6 +231,39 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 	}
 }
 
+// AddResourceUsage tracks the resource usage of a run, and the resource limits it exceeded
+func (cs *Stats) AddResourceUsage(usage ResourceUsage, exceeded []string) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	cpuTime := usage.CPUTime.Nanoseconds() / 1e6
+	cs.ResourceUsageRuns++
+	cs.LastCPUTime = cpuTime
+	cs.totalCPUTime += cpuTime
+	cs.AverageCPUTime = cs.totalCPUTime / int64(cs.ResourceUsageRuns)
+	cs.LastProcessAllocations = usage.Allocations
+	cs.LastProcessAllocBytes = usage.AllocatedBytes
+	cs.totalProcessAllocBytes += usage.AllocatedBytes
+	cs.AverageProcessAllocBytes = cs.totalProcessAllocBytes / cs.ResourceUsageRuns
+	cs.LastProcessGoroutines = int64(usage.Goroutines)
+	cs.ExceededResourceLimits = exceeded
+	if cs.telemetry {
+		tlmCPUTime.Add(float64(cpuTime), cs.CheckName)
+		tlmProcessAllocatedBytes.Add(float64(usage.AllocatedBytes), cs.CheckName)
+	}
+}
+
+// AddSkippedRun tracks a run skipped because the check exceeded its resource limits
+func (cs *Stats) AddSkippedRun() {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	cs.TotalSkippedRuns++
+	if cs.telemetry {
+		tlmSkippedRuns.Inc(cs.CheckName)
+	}
+}
+
 type aggStats struct {
 	EventPlatformEvents       map[string]interface{}
 	EventPlatformEventsErrors map[string]interface{}
@@ -6,9 +6,11 @@
 package check
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -104,6 +106,44 @@ func TestNewStatsStateTelemetryInitializedWhenGloballyEnabled(t *testing.T) {
 	)
 }
 
+func TestStatsAddTimeout(t *testing.T) {
+	stats := NewStats(newMockCheck())
+
+	stats.Add(time.Second, nil, nil, SenderStats{MetricSamples: 10})
+	assert.Equal(t, int64(10), stats.MetricSamples)
+
+	stats.Add(time.Minute, TimeoutError{Timeout: time.Minute}, nil, SenderStats{MetricSamples: 20})
+	assert.Equal(t, uint64(2), stats.TotalRuns)
+	assert.Equal(t, uint64(1), stats.TotalErrors)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+	assert.Equal(t, "check run timed out after 1m0s, its results are discarded", stats.LastError)
+	// The results of the run which timed out are discarded
+	assert.Equal(t, int64(10), stats.MetricSamples)
+	assert.Equal(t, uint64(10), stats.TotalMetricSamples)
+
+	stats.Add(time.Second, fmt.Errorf("error"), nil, SenderStats{})
+	assert.Equal(t, uint64(2), stats.TotalErrors)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+}
+
+func TestStatsAddResourceUsage(t *testing.T) {
+	stats := NewStats(newMockCheck())
+
+	stats.AddResourceUsage(ResourceUsage{CPUTime: 100 * time.Millisecond, Allocations: 10, AllocatedBytes: 1000, Goroutines: 2}, nil)
+	stats.AddResourceUsage(ResourceUsage{CPUTime: 300 * time.Millisecond, Allocations: 30, AllocatedBytes: 3000, Goroutines: -1}, []string{"goroutines"})
+	assert.Equal(t, uint64(2), stats.ResourceUsageRuns)
+	assert.Equal(t, int64(300), stats.LastCPUTime)
+	assert.Equal(t, int64(200), stats.AverageCPUTime)
+	assert.Equal(t, uint64(30), stats.LastProcessAllocations)
+	assert.Equal(t, uint64(3000), stats.LastProcessAllocBytes)
+	assert.Equal(t, uint64(2000), stats.AverageProcessAllocBytes)
+	assert.Equal(t, int64(-1), stats.LastProcessGoroutines)
+	assert.Equal(t, []string{"goroutines"}, stats.ExceededResourceLimits)
+
+	stats.AddSkippedRun()
+	assert.Equal(t, uint64(1), stats.TotalSkippedRuns)
+}
+
 func TestTranslateEventPlatformEventTypes(t *testing.T) {
 	original := map[string]interface{}{
 		"EventPlatformEvents": map[string]interface{}{
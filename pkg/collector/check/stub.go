@@ -35,6 +35,15 @@ func (c *StubCheck) Configure(integration.Data, integration.Data, string) error
 // Interval returns a duration of one second
 func (c *StubCheck) Interval() time.Duration { return 1 * time.Second }
 
+// Timeout returns 0, the runs are not limited
+func (c *StubCheck) Timeout() time.Duration { return 0 }
+
+// Schedule returns the zero Schedule
+func (c *StubCheck) Schedule() Schedule { return Schedule{} }
+
+// ResourceLimits returns the zero ResourceLimits
+func (c *StubCheck) ResourceLimits() ResourceLimits { return ResourceLimits{} }
+
 // Run is a noop
 func (c *StubCheck) Run() error { return nil }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
+	"github.com/DataDog/datadog-agent/pkg/collector/worker"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -142,6 +143,9 @@ func (c *Collector) StopCheck(id check.ID) error {
 		return fmt.Errorf("an error occurred while canceling the check schedule: %s", err)
 	}
 
+	// forget the runs left to skip if the check exceeded its resource limits
+	worker.ForgetResourceBudget(id)
+
 	err = c.runner.StopCheck(id)
 	if err != nil {
 		// still attempt to cancel the check before returning the error
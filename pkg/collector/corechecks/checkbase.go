@@ -42,6 +42,9 @@ type CheckBase struct {
 	checkID        check.ID
 	latestWarnings []error
 	checkInterval  time.Duration
+	checkTimeout   time.Duration
+	schedule       check.Schedule
+	resourceLimits check.ResourceLimits
 	source         string
 	telemetry      bool
 }
@@ -118,6 +121,23 @@ func (c *CheckBase) CommonConfigure(instance integration.Data, source string) er
 		c.checkInterval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.checkTimeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
+	// Set the scheduling options
+	if c.schedule, err = check.ParseSchedule(commonOptions); err != nil {
+		log.Errorf("invalid scheduling options for check %s: %s", string(c.ID()), err)
+		return err
+	}
+
+	// Set the resource limits
+	if c.resourceLimits, err = check.ParseResourceLimits(commonOptions); err != nil {
+		log.Errorf("invalid resource limits for check %s: %s", string(c.ID()), err)
+		return err
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.checkID)
@@ -192,6 +212,22 @@ func (c *CheckBase) Interval() time.Duration {
 	return c.checkInterval
 }
 
+// Timeout returns the maximum duration of a run of the check, 0 if
+// the runs are not limited.
+func (c *CheckBase) Timeout() time.Duration {
+	return c.checkTimeout
+}
+
+// Schedule returns the scheduling options of the check, on top of its interval.
+func (c *CheckBase) Schedule() check.Schedule {
+	return c.schedule
+}
+
+// ResourceLimits returns the resource limits of the runs of the check.
+func (c *CheckBase) ResourceLimits() check.ResourceLimits {
+	return c.resourceLimits
+}
+
 // String returns the name of the check, the same for every instance
 func (c *CheckBase) String() string {
 	return c.checkName
@@ -41,6 +41,7 @@ type mockItf struct {
 	mockNamespace         func() string
 	mockSpec              func(ctn containerd.Container) (*oci.Spec, error)
 	mockSpecWithContext   func(ctx context.Context, ctn containerd.Container) (*oci.Spec, error)
+	mockStatus            func(ctn containerd.Container) (containerd.Status, error)
 }
 
 func (m *mockItf) ImageSize(ctn containerd.Container) (int64, error) {
@@ -99,6 +100,10 @@ func (m *mockItf) SpecWithContext(ctx context.Context, ctn containerd.Container)
 	return m.mockSpecWithContext(ctx, ctn)
 }
 
+func (m *mockItf) Status(ctn containerd.Container) (containerd.Status, error) {
+	return m.mockStatus(ctn)
+}
+
 type mockEvt struct {
 	events.Publisher
 	events.Forwarder
@@ -182,6 +182,21 @@ func (c *APMCheck) Interval() time.Duration {
 	return 0
 }
 
+// Timeout returns 0, the run of a long running check is not limited
+func (c *APMCheck) Timeout() time.Duration {
+	return 0
+}
+
+// Schedule returns the zero Schedule, a long running check is started only once
+func (c *APMCheck) Schedule() check.Schedule {
+	return check.Schedule{}
+}
+
+// ResourceLimits returns the zero ResourceLimits, the resources of a long running check are not accounted
+func (c *APMCheck) ResourceLimits() check.ResourceLimits {
+	return check.ResourceLimits{}
+}
+
 // ID returns the name of the check since there should be only one instance running
 func (c *APMCheck) ID() check.ID {
 	return "APM_AGENT"
@@ -83,6 +83,18 @@ func (c *JMXCheck) Interval() time.Duration {
 	return 0
 }
 
+func (c *JMXCheck) Timeout() time.Duration {
+	return 0
+}
+
+func (c *JMXCheck) Schedule() check.Schedule {
+	return check.Schedule{}
+}
+
+func (c *JMXCheck) ResourceLimits() check.ResourceLimits {
+	return check.ResourceLimits{}
+}
+
 func (c *JMXCheck) ID() check.ID {
 	return c.id
 }
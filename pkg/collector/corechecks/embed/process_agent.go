@@ -178,6 +178,21 @@ func (c *ProcessAgentCheck) Interval() time.Duration {
 	return 0
 }
 
+// Timeout returns 0, the run of a long running check is not limited
+func (c *ProcessAgentCheck) Timeout() time.Duration {
+	return 0
+}
+
+// Schedule returns the zero Schedule, a long running check is started only once
+func (c *ProcessAgentCheck) Schedule() check.Schedule {
+	return check.Schedule{}
+}
+
+// ResourceLimits returns the zero ResourceLimits, the resources of a long running check are not accounted
+func (c *ProcessAgentCheck) ResourceLimits() check.ResourceLimits {
+	return check.ResourceLimits{}
+}
+
 // ID returns the name of the check since there should be only one instance running
 func (c *ProcessAgentCheck) ID() check.ID {
 	return "PROCESS_AGENT"
@@ -43,6 +43,9 @@ type PythonCheck struct {
 	class        *C.rtloader_pyobject_t
 	ModuleName   string
 	interval     time.Duration
+	timeout      time.Duration
+	schedule     check.Schedule
+	limits       check.ResourceLimits
 	lastWarnings []error
 	source       string
 	telemetry    bool // whether or not the telemetry is enabled for this check
@@ -226,6 +229,24 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.timeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
+	// Set the scheduling options
+	var err error
+	if c.schedule, err = check.ParseSchedule(commonOptions); err != nil {
+		log.Errorf("invalid scheduling options for check %s: %s", string(c.id), err)
+		return err
+	}
+
+	// Set the resource limits
+	if c.limits, err = check.ParseResourceLimits(commonOptions); err != nil {
+		log.Errorf("invalid resource limits for check %s: %s", string(c.id), err)
+		return err
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.id)
@@ -310,6 +331,21 @@ func (c *PythonCheck) Interval() time.Duration {
 	return c.interval
 }
 
+// Timeout returns the maximum duration of a run of the check, 0 if the runs are not limited
+func (c *PythonCheck) Timeout() time.Duration {
+	return c.timeout
+}
+
+// Schedule returns the scheduling options of the check, on top of its interval
+func (c *PythonCheck) Schedule() check.Schedule {
+	return c.schedule
+}
+
+// ResourceLimits returns the resource limits of the runs of the check
+func (c *PythonCheck) ResourceLimits() check.ResourceLimits {
+	return c.limits
+}
+
 // ID returns the ID of the check
 func (c *PythonCheck) ID() check.ID {
 	return c.id
@@ -24,6 +24,8 @@ const (
 	runningChecksExpvarKey = "RunningChecks"
 	runsExpvarKey          = "Runs"
 	runningExpvarKey       = "Running"
+	skippedRunsExpvarKey   = "SkippedRuns"
+	timeoutsExpvarKey      = "Timeouts"
 	warningsExpvarKey      = "Warnings"
 )
 
@@ -79,6 +81,8 @@ func Reset() {
 		errorsExpvarKey,
 		runsExpvarKey,
 		runningChecksExpvarKey,
+		skippedRunsExpvarKey,
+		timeoutsExpvarKey,
 		warningsExpvarKey,
 	} {
 		runnerStats.Delete(key)
@@ -142,6 +146,29 @@ func AddCheckStats(
 	s.Add(execTime, err, warnings, mStats)
 }
 
+// AddCheckResourceUsage adds the resource usage of a run to the check's expvars
+func AddCheckResourceUsage(c check.Check, usage check.ResourceUsage, exceeded []string) {
+	if s := getCheckStats(c); s != nil {
+		s.AddResourceUsage(usage, exceeded)
+	}
+}
+
+// AddCheckSkippedRun adds a run skipped because of the resource limits of the check to
+// the check's expvars
+func AddCheckSkippedRun(c check.Check) {
+	if s := getCheckStats(c); s != nil {
+		s.AddSkippedRun()
+	}
+}
+
+// getCheckStats returns the stats of a check, nil if the check has none yet
+func getCheckStats(c check.Check) *check.Stats {
+	checkStats.statsLock.RLock()
+	defer checkStats.statsLock.RUnlock()
+
+	return checkStats.stats[check.IDToCheckName(c.ID())][c.ID()]
+}
+
 // RemoveCheckStats removes a check from the check stats map
 func RemoveCheckStats(checkID check.ID) {
 	checkStats.statsLock.Lock()
@@ -262,3 +289,31 @@ func GetErrorsCount() int64 {
 	}
 	return count.(*expvar.Int).Value()
 }
+
+// AddTimeoutsCount is used to increment the 'Timeouts' expvar
+func AddTimeoutsCount(amount int) {
+	runnerStats.Add(timeoutsExpvarKey, int64(amount))
+}
+
+// GetTimeoutsCount is used to get the value of 'Timeouts' expvar
+func GetTimeoutsCount() int64 {
+	count := runnerStats.Get(timeoutsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
+
+// AddSkippedRunsCount is used to increment the 'SkippedRuns' expvar
+func AddSkippedRunsCount(amount int) {
+	runnerStats.Add(skippedRunsExpvarKey, int64(amount))
+}
+
+// GetSkippedRunsCount is used to get the value of 'SkippedRuns' expvar
+func GetSkippedRunsCount() int64 {
+	count := runnerStats.Get(skippedRunsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
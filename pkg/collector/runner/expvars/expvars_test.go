@@ -176,6 +176,8 @@ func TestExpvarsReset(t *testing.T) {
 	AddRunsCount(2)
 	AddRunningCheckCount(3)
 	AddWarningsCount(4)
+	AddTimeoutsCount(5)
+	AddSkippedRunsCount(6)
 
 	assert.Equal(t, numCheckNames, len(GetCheckStats()))
 	assert.Equal(t, numCheckNames, len(getCheckStatsExpvarMap(t)))
@@ -183,6 +185,8 @@ func TestExpvarsReset(t *testing.T) {
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(warningsExpvarKey))
+	assert.NotNil(t, getRunnerExpvarMap(t).Get(timeoutsExpvarKey))
+	assert.NotNil(t, getRunnerExpvarMap(t).Get(skippedRunsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(workersExpvarKey))
 
 	Reset()
@@ -194,6 +198,8 @@ func TestExpvarsReset(t *testing.T) {
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(warningsExpvarKey))
+	assert.Nil(t, getRunnerExpvarMap(t).Get(timeoutsExpvarKey))
+	assert.Nil(t, getRunnerExpvarMap(t).Get(skippedRunsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(workersExpvarKey))
 }
 
@@ -378,6 +384,8 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 		"Errors":        GetErrorsCount,
 		"Runs":          GetRunsCount,
 		"RunningChecks": GetRunningCheckCount,
+		"SkippedRuns":   GetSkippedRunsCount,
+		"Timeouts":      GetTimeoutsCount,
 		"Warnings":      GetWarningsCount,
 	}
 
@@ -385,6 +393,8 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 		"Errors":        AddErrorsCount,
 		"Runs":          AddRunsCount,
 		"RunningChecks": AddRunningCheckCount,
+		"SkippedRuns":   AddSkippedRunsCount,
+		"Timeouts":      AddTimeoutsCount,
 		"Warnings":      AddWarningsCount,
 	} {
 
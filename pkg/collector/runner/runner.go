@@ -44,6 +44,8 @@ type Runner struct {
 	workers             map[int]*worker.Worker        // Workers currrently under this Runner's management
 	workersLock         sync.Mutex                    // Lock to prevent concurrent worker changes
 	isStaticWorkerCount bool                          // Flag indicating if numWorkers is dynamically updated
+	blockedWorkers      map[int]struct{}              // Workers blocked by a check which exceeded its timeout, and replaced
+	timeoutBudget       int                           // Maximum number of blocked workers which can be replaced
 	pendingChecksChan   chan check.Check              // The channel where checks come from
 	checksTracker       *tracker.RunningChecksTracker // Tracker in charge of maintaining the running check list
 	scheduler           *scheduler.Scheduler          // Scheduler runner operates on
@@ -59,6 +61,8 @@ func NewRunner() *Runner {
 		isRunning:           1,
 		workers:             make(map[int]*worker.Worker),
 		isStaticWorkerCount: numWorkers != 0,
+		blockedWorkers:      make(map[int]struct{}),
+		timeoutBudget:       config.Datadog.GetInt("check_runners_timeout_budget"),
 		pendingChecksChan:   make(chan check.Check),
 		checksTracker:       tracker.NewRunningChecksTracker(),
 	}
@@ -111,6 +115,38 @@ func (r *Runner) AddWorker() {
 	}
 }
 
+// addReplacementWorker adds a worker to replace a worker blocked by a check which
+// exceeded its timeout. It returns false if the timeout budget is exhausted.
+func (r *Runner) addReplacementWorker(blockedWorkerID int) bool {
+	r.workersLock.Lock()
+	defer r.workersLock.Unlock()
+
+	if len(r.blockedWorkers) >= r.timeoutBudget {
+		log.Warnf(
+			"Runner %d can't replace worker %d, %d workers are already blocked by checks which exceeded their timeout",
+			r.id,
+			blockedWorkerID,
+			len(r.blockedWorkers),
+		)
+		return false
+	}
+
+	worker, err := r.newWorker()
+	if err != nil {
+		return false
+	}
+	r.workers[worker.ID] = worker
+	r.blockedWorkers[blockedWorkerID] = struct{}{}
+
+	log.Infof(
+		"Runner %d added worker %d to replace worker %d blocked by a check which exceeded its timeout",
+		r.id,
+		worker.ID,
+		blockedWorkerID,
+	)
+	return true
+}
+
 // addWorker adds a new worker running in a separate goroutine
 func (r *Runner) newWorker() (*worker.Worker, error) {
 	worker, err := worker.NewWorker(
@@ -119,6 +155,7 @@ func (r *Runner) newWorker() (*worker.Worker, error) {
 		r.pendingChecksChan,
 		r.checksTracker,
 		r.ShouldAddCheckStats,
+		r.addReplacementWorker,
 	)
 	if err != nil {
 		log.Errorf("Runner %d was unable to instantiate a worker: %s", err)
@@ -139,6 +176,7 @@ func (r *Runner) removeWorker(id int) {
 	defer r.workersLock.Unlock()
 
 	delete(r.workers, id)
+	delete(r.blockedWorkers, id)
 }
 
 // UpdateNumWorkers checks if the current number of workers is reasonable,
@@ -39,6 +39,7 @@ type testCheck struct {
 	t           *testing.T
 	runFunc     func(id check.ID)
 	startedChan chan struct{}
+	timeout     time.Duration
 }
 
 func (c *testCheck) ID() check.ID   { return check.ID(c.id) }
@@ -50,7 +51,8 @@ func (c *testCheck) Stop() {
 
 	atomic.StoreUint64(&c.stopped, 1)
 }
-func (c *testCheck) IsStopped() bool { return atomic.LoadUint64(&c.stopped) != 0 }
+func (c *testCheck) IsStopped() bool        { return atomic.LoadUint64(&c.stopped) != 0 }
+func (c *testCheck) Timeout() time.Duration { return c.timeout }
 func (c *testCheck) StartedChan() chan struct{} {
 	c.StartLock.Lock()
 	defer c.StartLock.Unlock()
@@ -174,6 +176,65 @@ func TestRunnerAddWorker(t *testing.T) {
 	assertAsyncWorkerCount(t, 4)
 }
 
+func TestRunnerAddReplacementWorker(t *testing.T) {
+	testSetUp(t)
+	config.Datadog.Set("check_runners", "1")
+	config.Datadog.Set("check_runners_timeout_budget", 2)
+	defer config.Datadog.Set("check_runners_timeout_budget", 4)
+
+	r := NewRunner()
+	require.NotNil(t, r)
+	defer r.Stop()
+
+	assert.True(t, r.addReplacementWorker(1001))
+	assert.True(t, r.addReplacementWorker(1002))
+	assert.False(t, r.addReplacementWorker(1003))
+	assertAsyncWorkerCount(t, 3)
+
+	// The budget is released when a blocked worker exits
+	r.removeWorker(1001)
+	assert.True(t, r.addReplacementWorker(1003))
+	assertAsyncWorkerCount(t, 4)
+}
+
+func TestRunnerCheckTimeout(t *testing.T) {
+	testSetUp(t)
+	config.Datadog.Set("check_runners", "1")
+
+	r := NewRunner()
+	require.NotNil(t, r)
+	defer r.Stop()
+
+	stuckCheck := newCheck(t, "stuck:123", false, nil)
+	stuckCheck.timeout = 50 * time.Millisecond
+	stuckCheck.RunLock.Lock()
+
+	r.GetChan() <- stuckCheck
+	<-stuckCheck.StartedChan()
+
+	// A worker is added to replace the blocked one, and runs the other checks
+	assertAsyncWorkerCount(t, 2)
+	otherCheck := newCheck(t, "other:123", false, nil)
+	r.GetChan() <- otherCheck
+	<-otherCheck.StartedChan()
+
+	// The stuck check isn't run again while its late run is in progress: once
+	// the next check starts, the replacement worker has skipped it
+	r.GetChan() <- stuckCheck
+	nextCheck := newCheck(t, "next:123", false, nil)
+	r.GetChan() <- nextCheck
+	<-nextCheck.StartedChan()
+
+	// The blocked worker exits once the run completes
+	stuckCheck.RunLock.Unlock()
+	assertAsyncWorkerCount(t, 1)
+	assert.Equal(t, 1, stuckCheck.RunCount())
+
+	stats, found := expvars.CheckStats(stuckCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 1, int(stats.TotalTimeouts))
+}
+
 func TestRunnerStaticUpdateNumWorkers(t *testing.T) {
 	testSetUp(t)
 	config.Datadog.Set("check_runners", "2")
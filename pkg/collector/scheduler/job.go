@@ -34,6 +34,18 @@ func (jb *jobBucket) addJob(c check.Check) {
 	jb.jobs = append(jb.jobs, c)
 }
 
+func (jb *jobBucket) contains(id check.ID) bool {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+
+	for _, c := range jb.jobs {
+		if c.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
 // removeJob removes the check from the bucket, and returns
 // whether the check was indeed in the bucket (and therefore actually removed)
 func (jb *jobBucket) removeJob(id check.ID) bool {
@@ -136,6 +148,26 @@ func (jq *jobQueue) removeJob(id check.ID) error {
 	return fmt.Errorf("check with id %s is not in this Job Queue", id)
 }
 
+// nextRun returns when the check is next sent to the execution pipeline: the
+// buckets are processed one per second, starting from the current one.
+func (jq *jobQueue) nextRun(id check.ID) (time.Time, bool) {
+	jq.mu.RLock()
+	defer jq.mu.RUnlock()
+
+	lastTick := jq.lastTick
+	if lastTick.IsZero() {
+		lastTick = time.Now()
+	}
+	nb := uint(len(jq.buckets))
+	for idx, bucket := range jq.buckets {
+		if bucket.contains(id) {
+			steps := (uint(idx) + nb - jq.currentBucketIdx) % nb
+			return lastTick.Add(time.Duration(steps+1) * time.Second), true
+		}
+	}
+	return time.Time{}, false
+}
+
 func (jq *jobQueue) stats() map[string]interface{} {
 	jq.mu.RLock()
 	defer jq.mu.RUnlock()
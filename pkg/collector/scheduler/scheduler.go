@@ -46,11 +46,13 @@ type Scheduler struct {
 	started          chan bool                   // Used to internally communicate the queues are up
 	jobQueues        map[time.Duration]*jobQueue // We have one scheduling queue for every interval
 	checkToQueue     map[check.ID]*jobQueue      // Keep track of what is the queue for any Check
+	timedJobs        map[check.ID]*timedJob      // The checks with scheduling options, scheduled outside of the queues
 	tlmTrackedChecks map[check.ID]string         // Keep track of the checks that are tracked with telemetry
 	mu               sync.Mutex                  // To protect critical sections in struct's fields
 
 	cancelOneTime chan bool      // Used to internally communicate a cancel signal to one-time schedule goroutines
 	wgOneTime     sync.WaitGroup // WaitGroup to track the exit of one-time schedule goroutines
+	wgTimed       sync.WaitGroup // WaitGroup to track the exit of the timed jobs goroutines
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
@@ -62,6 +64,7 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		started:          make(chan bool),
 		jobQueues:        make(map[time.Duration]*jobQueue),
 		checkToQueue:     make(map[check.ID]*jobQueue),
+		timedJobs:        make(map[check.ID]*timedJob),
 		tlmTrackedChecks: make(map[check.ID]string),
 		running:          0,
 		cancelOneTime:    make(chan bool),
@@ -71,6 +74,7 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 
 // Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value.
 // If the interval is 0, the check is supposed to run only once.
+// If the check has scheduling options, they are applied on top of the interval.
 func (s *Scheduler) Enter(check check.Check) error {
 	// enqueue immediately if this is a one-time schedule
 	if check.Interval() == 0 {
@@ -82,12 +86,33 @@ func (s *Scheduler) Enter(check check.Check) error {
 		return fmt.Errorf("Schedule interval must be greater than %v or 0", minAllowedInterval)
 	}
 
-	log.Infof("Scheduling check %v with an interval of %v", check, check.Interval())
-
 	// sync when accessing `jobQueues` and `check2queue`
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if schedule := check.Schedule(); !schedule.IsZero() {
+		log.Infof("Scheduling check %v with an interval of %v and the options: %s", check, check.Interval(), schedule)
+		job := newTimedJob(check)
+		job.run(s)
+		s.timedJobs[check.ID()] = job
+	} else {
+		log.Infof("Scheduling check %v with an interval of %v", check, check.Interval())
+		s.enterQueue(check)
+	}
+
+	schedulerChecksEntered.Add(1)
+	if check.IsTelemetryEnabled() {
+		checkName := check.String()
+		s.tlmTrackedChecks[check.ID()] = checkName
+		tlmChecksEntered.Inc(checkName)
+	}
+	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
+	schedulerExpvars.Set("NextRuns", expvar.Func(expNextRuns(s)))
+	return nil
+}
+
+// enterQueue adds the check to the queue of its interval
+func (s *Scheduler) enterQueue(check check.Check) {
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
 		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
 		s.startQueue(s.jobQueues[check.Interval()])
@@ -99,15 +124,6 @@ func (s *Scheduler) Enter(check check.Check) error {
 	s.jobQueues[check.Interval()].addJob(check)
 	// map each check to the Job Queue it was assigned to
 	s.checkToQueue[check.ID()] = s.jobQueues[check.Interval()]
-
-	schedulerChecksEntered.Add(1)
-	if check.IsTelemetryEnabled() {
-		checkName := check.String()
-		s.tlmTrackedChecks[check.ID()] = checkName
-		tlmChecksEntered.Inc(checkName)
-	}
-	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
-	return nil
 }
 
 // Cancel remove a Check from the scheduled queue. If the check is not
@@ -118,23 +134,27 @@ func (s *Scheduler) Cancel(id check.ID) error {
 
 	log.Infof("Unscheduling check %s", string(id))
 
-	if _, ok := s.checkToQueue[id]; !ok {
+	if job, ok := s.timedJobs[id]; ok {
+		job.stopJob()
+		delete(s.timedJobs, id)
+	} else if queue, ok := s.checkToQueue[id]; ok {
+		// remove it from the queue
+		err := queue.removeJob(id)
+		if err != nil {
+			return fmt.Errorf("unable to remove the Job from the queue: %s", err)
+		}
+		delete(s.checkToQueue, id)
+	} else {
 		return nil
 	}
 
-	// remove it from the queue
-	err := s.checkToQueue[id].removeJob(id)
-	if err != nil {
-		return fmt.Errorf("unable to remove the Job from the queue: %s", err)
-	}
-	delete(s.checkToQueue, id)
-
 	schedulerChecksEntered.Add(-1)
 	if checkName, ok := s.tlmTrackedChecks[id]; ok {
 		delete(s.tlmTrackedChecks, id)
 		tlmChecksEntered.Dec(checkName)
 	}
 	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
+	schedulerExpvars.Set("NextRuns", expvar.Func(expNextRuns(s)))
 	return nil
 }
 
@@ -190,6 +210,10 @@ func (s *Scheduler) Stop() error {
 	close(s.cancelOneTime)
 	s.wgOneTime.Wait()
 
+	// Stop the timed jobs, and wait for their goroutines to exit
+	s.stopTimedJobs()
+	s.wgTimed.Wait()
+
 	log.Debugf("Waiting for the scheduler to shutdown")
 
 	select {
@@ -203,10 +227,43 @@ func (s *Scheduler) IsCheckScheduled(id check.ID) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, found := s.timedJobs[id]; found {
+		return true
+	}
 	_, found := s.checkToQueue[id]
 	return found
 }
 
+// NextRun returns when the check is next sent to the execution pipeline, and
+// whether it's scheduled to run again.
+func (s *Scheduler) NextRun(id check.ID) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nextRun(id)
+}
+
+func (s *Scheduler) nextRun(id check.ID) (time.Time, bool) {
+	if job, found := s.timedJobs[id]; found {
+		return job.getNextRun()
+	}
+	if queue, found := s.checkToQueue[id]; found {
+		return queue.nextRun(id)
+	}
+	return time.Time{}, false
+}
+
+// stopTimedJobs stops the goroutines of the timed jobs. The jobs stay in the
+// schedule, as the checks of the stopped queues.
+func (s *Scheduler) stopTimedJobs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.timedJobs {
+		job.stopJob()
+	}
+}
+
 // stopQueues shuts down the timers for each active queue
 // Blocks until all the queues have fully stopped
 func (s *Scheduler) stopQueues() {
@@ -274,3 +331,25 @@ func expQueues(s *Scheduler) func() interface{} {
 		return queues
 	}
 }
+
+// expNextRuns return a function to get the next run of the scheduled checks,
+// as Unix timestamps
+func expNextRuns(s *Scheduler) func() interface{} {
+	return func() interface{} {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		nextRuns := make(map[string]int64)
+		for id := range s.checkToQueue {
+			if nextRun, found := s.nextRun(id); found {
+				nextRuns[string(id)] = nextRun.Unix()
+			}
+		}
+		for id := range s.timedJobs {
+			if nextRun, found := s.nextRun(id); found {
+				nextRuns[string(id)] = nextRun.Unix()
+			}
+		}
+		return nextRuns
+	}
+}
@@ -16,11 +16,14 @@ import (
 // FIXTURE
 type TestCheck struct {
 	check.StubCheck
-	intl time.Duration
+	intl     time.Duration
+	schedule check.Schedule
 }
 
 func (c *TestCheck) Interval() time.Duration { return c.intl }
 
+func (c *TestCheck) Schedule() check.Schedule { return c.schedule }
+
 var initialMinAllowedInterval = minAllowedInterval
 
 func consume(c chan check.Check, stop chan bool) {
@@ -196,3 +199,75 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+func TestNextRun(t *testing.T) {
+	s := getScheduler()
+	defer s.Stop()
+
+	c := &TestCheck{intl: 10 * time.Second}
+	_, found := s.NextRun(c.ID())
+	assert.False(t, found)
+
+	start := time.Now()
+	s.Enter(c)
+	s.Run()
+
+	nextRun, found := s.NextRun(c.ID())
+	assert.True(t, found)
+	assert.True(t, nextRun.After(start))
+	assert.True(t, nextRun.Before(start.Add(11*time.Second)))
+
+	nextRuns := expNextRuns(s)().(map[string]int64)
+	assert.Equal(t, nextRun.Unix(), nextRuns[string(c.ID())])
+}
+
+func TestEnterTimedJob(t *testing.T) {
+	ch := make(chan check.Check)
+	s := NewScheduler(ch)
+	defer s.Stop()
+
+	c := &TestCheck{intl: 10 * time.Second, schedule: check.Schedule{OneShot: true, Jitter: 10 * time.Millisecond}}
+	assert.Nil(t, s.Enter(c))
+	assert.True(t, s.IsCheckScheduled(c.ID()))
+	assert.Len(t, s.jobQueues, 0)
+	s.Run()
+
+	select {
+	case scheduled := <-ch:
+		assert.Equal(t, c, scheduled)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the check was not scheduled")
+	}
+
+	// a one-shot check has no next run but stays in the schedule
+	assert.Eventually(t, func() bool {
+		_, found := s.NextRun(c.ID())
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, s.IsCheckScheduled(c.ID()))
+
+	assert.Nil(t, s.Cancel(c.ID()))
+	assert.False(t, s.IsCheckScheduled(c.ID()))
+}
+
+func TestCancelTimedJob(t *testing.T) {
+	ch := make(chan check.Check)
+	s := NewScheduler(ch)
+
+	windows := []check.TimeWindow{{Start: time.Hour, End: 2 * time.Hour}}
+	c := &TestCheck{intl: 10 * time.Second, schedule: check.Schedule{Windows: windows}}
+	assert.Nil(t, s.Enter(c))
+	s.Run()
+
+	assert.Eventually(t, func() bool {
+		_, found := s.NextRun(c.ID())
+		return found
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, s.Cancel(c.ID()))
+	assert.False(t, s.IsCheckScheduled(c.ID()))
+
+	// the goroutine of the job exits even though nothing consumes the checks
+	s.Stop()
+	close(s.checksPipe)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// timedJob schedules a check with scheduling options (cron expression, time
+// windows, jitter or one-shot) at the times computed from its Schedule. Unlike
+// the checks of the job queues, every timedJob runs in its own goroutine.
+type timedJob struct {
+	check    check.Check
+	schedule check.Schedule
+	stop     chan struct{}
+	stopOnce sync.Once
+	nextRun  time.Time
+	mu       sync.RWMutex // to protect nextRun
+}
+
+func newTimedJob(c check.Check) *timedJob {
+	return &timedJob{
+		check:    c,
+		schedule: c.Schedule(),
+		stop:     make(chan struct{}),
+	}
+}
+
+// run sends the check to the execution pipeline at every scheduled time,
+// until the job is stopped or has no next run.
+// Not blocking, runs in a new goroutine.
+func (j *timedJob) run(s *Scheduler) {
+	s.wgTimed.Add(1)
+
+	go func() {
+		defer s.wgTimed.Done()
+
+		runAt := j.schedule.FirstRun(time.Now())
+		for !runAt.IsZero() {
+			scheduledAt := runAt.Add(j.schedule.Delay())
+			j.setNextRun(scheduledAt)
+
+			timer := time.NewTimer(time.Until(scheduledAt))
+			select {
+			case <-timer.C:
+			case <-j.stop:
+				timer.Stop()
+				return
+			}
+
+			select {
+			// blocking, we'll be here as long as it takes
+			case s.checksPipe <- j.check:
+			case <-j.stop:
+				return
+			}
+
+			runAt = j.schedule.NextRun(runAt, j.check.Interval())
+			// don't try to catch up with the runs missed while the pipeline was busy
+			if now := time.Now(); !runAt.IsZero() && runAt.Before(now) {
+				runAt = j.schedule.FirstRun(now)
+			}
+		}
+
+		j.setNextRun(time.Time{})
+		log.Infof("Check %v has no next run in its schedule (%s)", j.check, j.schedule)
+	}()
+}
+
+// stopJob stops the goroutine of the job, it can be called several times
+func (j *timedJob) stopJob() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+}
+
+func (j *timedJob) setNextRun(t time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.nextRun = t
+}
+
+// getNextRun returns the next scheduled run of the check, with its jitter,
+// and whether there is one.
+func (j *timedJob) getNextRun() (time.Time, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.nextRun, !j.nextRun.IsZero()
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+// maxBackoffSkippedRuns is the maximum number of runs skipped after a run which exceeded
+// the resource limits of a check with the backoff action
+const maxBackoffSkippedRuns = 32
+
+// defaultResourceBudgets is shared by the workers of every runner, so a check is
+// skipped whichever worker it's sent to
+var defaultResourceBudgets = newResourceBudgets()
+
+type resourceBudget struct {
+	consecutiveExceeded int // consecutive runs which exceeded the limits
+	skippedRunsLeft     int // runs to skip before the next one
+}
+
+// resourceBudgets tracks the checks which exceeded their resource limits, and
+// the number of runs to skip for each of them
+type resourceBudgets struct {
+	budgets map[check.ID]*resourceBudget
+	m       sync.Mutex
+}
+
+func newResourceBudgets() *resourceBudgets {
+	return &resourceBudgets{
+		budgets: make(map[check.ID]*resourceBudget),
+	}
+}
+
+// addRun records the resource usage of a run, and returns the limits it exceeded
+// and the number of the next runs to skip
+func (b *resourceBudgets) addRun(c check.Check, usage check.ResourceUsage) ([]string, int) {
+	limits := c.ResourceLimits()
+	exceeded := limits.Exceeded(usage)
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(exceeded) == 0 {
+		delete(b.budgets, c.ID())
+		return nil, 0
+	}
+
+	budget, found := b.budgets[c.ID()]
+	if !found {
+		budget = &resourceBudget{}
+		b.budgets[c.ID()] = budget
+	}
+	budget.consecutiveExceeded++
+
+	// The backoff doubles the skipped runs with every consecutive run exceeding the limits
+	budget.skippedRunsLeft = 1
+	if limits.Action == check.ResourceLimitActionBackoff {
+		for i := 1; i < budget.consecutiveExceeded && budget.skippedRunsLeft < maxBackoffSkippedRuns; i++ {
+			budget.skippedRunsLeft *= 2
+		}
+	}
+	return exceeded, budget.skippedRunsLeft
+}
+
+// remove forgets the budget of the check
+func (b *resourceBudgets) remove(id check.ID) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	delete(b.budgets, id)
+}
+
+// ForgetResourceBudget forgets the runs left to skip of a check which exceeded its
+// resource limits. It must be called once the check is unscheduled.
+func ForgetResourceBudget(id check.ID) {
+	defaultResourceBudgets.remove(id)
+}
+
+// shouldSkip returns whether the run of the check must be skipped, and counts it
+func (b *resourceBudgets) shouldSkip(id check.ID) bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	budget, found := b.budgets[id]
+	if !found || budget.skippedRunsLeft == 0 {
+		return false
+	}
+	budget.skippedRunsLeft--
+	return true
+}
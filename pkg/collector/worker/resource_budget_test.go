@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+func countSkippedRuns(b *resourceBudgets, id check.ID) int {
+	skipped := 0
+	for b.shouldSkip(id) {
+		skipped++
+	}
+	return skipped
+}
+
+func TestResourceBudgetsSkip(t *testing.T) {
+	b := newResourceBudgets()
+	c := &testCheck{id: "skip:123", limits: check.ResourceLimits{CPUTime: 10 * time.Millisecond, Action: check.ResourceLimitActionSkip}}
+
+	exceeded, skippedRuns := b.addRun(c, check.ResourceUsage{CPUTime: 5 * time.Millisecond})
+	assert.Empty(t, exceeded)
+	assert.Equal(t, 0, skippedRuns)
+	assert.False(t, b.shouldSkip(c.ID()))
+
+	for i := 0; i < 3; i++ {
+		exceeded, skippedRuns = b.addRun(c, check.ResourceUsage{CPUTime: 11 * time.Millisecond})
+		assert.Equal(t, []string{"CPU time 11ms > 10ms"}, exceeded)
+		assert.Equal(t, 1, skippedRuns)
+		assert.Equal(t, 1, countSkippedRuns(b, c.ID()))
+	}
+}
+
+func TestResourceBudgetsBackoff(t *testing.T) {
+	b := newResourceBudgets()
+	c := &testCheck{id: "backoff:123", limits: check.ResourceLimits{CPUTime: 10 * time.Millisecond, Action: check.ResourceLimitActionBackoff}}
+
+	for _, expected := range []int{1, 2, 4, 8, 16, 32, 32} {
+		_, skippedRuns := b.addRun(c, check.ResourceUsage{CPUTime: 11 * time.Millisecond})
+		assert.Equal(t, expected, skippedRuns)
+		assert.Equal(t, expected, countSkippedRuns(b, c.ID()))
+	}
+
+	// A run within the limits resets the backoff
+	_, skippedRuns := b.addRun(c, check.ResourceUsage{CPUTime: 10 * time.Millisecond})
+	assert.Equal(t, 0, skippedRuns)
+	_, skippedRuns = b.addRun(c, check.ResourceUsage{CPUTime: 11 * time.Millisecond})
+	assert.Equal(t, 1, skippedRuns)
+}
+
+func TestResourceBudgetsRemove(t *testing.T) {
+	b := newResourceBudgets()
+	c := &testCheck{id: "remove:123", limits: check.ResourceLimits{CPUTime: 10 * time.Millisecond}}
+
+	for i := 0; i < 3; i++ {
+		b.addRun(c, check.ResourceUsage{CPUTime: 11 * time.Millisecond})
+	}
+	b.remove(c.ID())
+	assert.Empty(t, b.budgets)
+	assert.False(t, b.shouldSkip(c.ID()))
+
+	// A rescheduled check starts over
+	_, skippedRuns := b.addRun(c, check.ResourceUsage{CPUTime: 11 * time.Millisecond})
+	assert.Equal(t, 1, skippedRuns)
+}
+
+func TestResourceSampler(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	sampler := startResourceSampling(true)
+	allocationSink = make([]byte, 1<<20)
+	for i := 0; i < 3; i++ {
+		go func() { <-stop }()
+	}
+	usage := sampler.stop()
+
+	assert.True(t, usage.AllocatedBytes >= 1<<20)
+	assert.True(t, usage.Allocations >= 1)
+	assert.Equal(t, 3, usage.Goroutines)
+
+	// Only the CPU time is sampled without memory
+	sampler = startResourceSampling(false)
+	allocationSink = make([]byte, 1<<20)
+	go func() { <-stop }()
+	usage = sampler.stop()
+
+	assert.Zero(t, usage.AllocatedBytes)
+	assert.Zero(t, usage.Allocations)
+	assert.Zero(t, usage.Goroutines)
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+// resourceSampler samples the resources used by a check run. The goroutine
+// running the check is locked to its thread, so the CPU time of the thread is
+// the CPU time of the check, except for the goroutines the check starts.
+type resourceSampler struct {
+	cpuTime    time.Duration
+	cpuTimeOK  bool
+	memory     bool // whether the allocations and goroutines are sampled
+	mallocs    uint64
+	totalAlloc uint64
+	goroutines int
+}
+
+// startResourceSampling must be called from the goroutine running the check,
+// and followed by a call to stop from the same goroutine. Sampling the process-wide
+// allocations and goroutines stops the world, so it's only done when memory is true.
+func startResourceSampling(memory bool) *resourceSampler {
+	runtime.LockOSThread()
+
+	s := &resourceSampler{memory: memory}
+	if memory {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		s.mallocs = memStats.Mallocs
+		s.totalAlloc = memStats.TotalAlloc
+		s.goroutines = runtime.NumGoroutine()
+	}
+	s.cpuTime, s.cpuTimeOK = threadCPUTime()
+	return s
+}
+
+// stop returns the resources used since the start of the sampling
+func (s *resourceSampler) stop() check.ResourceUsage {
+	cpuTime, cpuTimeOK := threadCPUTime()
+	defer runtime.UnlockOSThread()
+
+	var usage check.ResourceUsage
+	if s.memory {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		usage.Allocations = memStats.Mallocs - s.mallocs
+		usage.AllocatedBytes = memStats.TotalAlloc - s.totalAlloc
+		usage.Goroutines = runtime.NumGoroutine() - s.goroutines
+	}
+	if s.cpuTimeOK && cpuTimeOK {
+		usage.CPUTime = cpuTime - s.cpuTime
+	}
+	return usage
+}
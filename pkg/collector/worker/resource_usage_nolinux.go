@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !linux
+
+package worker
+
+import "time"
+
+// threadCPUTime is only available on Linux
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
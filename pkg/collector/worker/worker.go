@@ -8,12 +8,14 @@ package worker
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -33,21 +35,29 @@ type Worker struct {
 	ID   int
 	Name string
 
-	checksTracker           *tracker.RunningChecksTracker
-	getDefaultSenderFunc    func() (aggregator.Sender, error)
-	pendingChecksChan       chan check.Check
-	runnerID                int
-	shouldAddCheckStatsFunc func(id check.ID) bool
-	utilizationTracker      UtilizationTracker
+	addReplacementWorkerFunc func(blockedWorkerID int) bool
+	checksTracker            *tracker.RunningChecksTracker
+	discardCheckRunFunc      func(id check.ID)
+	getDefaultSenderFunc     func() (aggregator.Sender, error)
+	pendingChecksChan        chan check.Check
+	resourceAccounting       bool
+	resourceBudgets          *resourceBudgets
+	resumeCheckRunFunc       func(id check.ID)
+	runnerID                 int
+	shouldAddCheckStatsFunc  func(id check.ID) bool
+	utilizationTracker       UtilizationTracker
 }
 
-// NewWorker returns an instance of a `Worker` after parameter sanity checks are passed
+// NewWorker returns an instance of a `Worker` after parameter sanity checks are passed.
+// `addReplacementWorkerFunc` is called when the worker is blocked by a check which exceeded
+// its timeout, it returns whether a worker was added to replace it.
 func NewWorker(
 	runnerID int,
 	ID int,
 	pendingChecksChan chan check.Check,
 	checksTracker *tracker.RunningChecksTracker,
 	shouldAddCheckStatsFunc func(id check.ID) bool,
+	addReplacementWorkerFunc func(blockedWorkerID int) bool,
 ) (*Worker, error) {
 
 	if checksTracker == nil {
@@ -62,12 +72,17 @@ func NewWorker(
 		return nil, fmt.Errorf("worker cannot initialize using a nil shouldAddCheckStatsFunc")
 	}
 
+	if addReplacementWorkerFunc == nil {
+		return nil, fmt.Errorf("worker cannot initialize using a nil addReplacementWorkerFunc")
+	}
+
 	return newWorkerWithOptions(
 		runnerID,
 		ID,
 		pendingChecksChan,
 		checksTracker,
 		shouldAddCheckStatsFunc,
+		addReplacementWorkerFunc,
 		aggregator.GetDefaultSender,
 		windowSize,
 		pollingInterval,
@@ -83,6 +98,7 @@ func newWorkerWithOptions(
 	pendingChecksChan chan check.Check,
 	checksTracker *tracker.RunningChecksTracker,
 	shouldAddCheckStatsFunc func(id check.ID) bool,
+	addReplacementWorkerFunc func(blockedWorkerID int) bool,
 	getDefaultSenderFunc func() (aggregator.Sender, error),
 	windowSize time.Duration,
 	pollingInterval time.Duration,
@@ -99,14 +115,19 @@ func newWorkerWithOptions(
 	}
 
 	return &Worker{
-		ID:                      ID,
-		Name:                    workerName,
-		checksTracker:           checksTracker,
-		pendingChecksChan:       pendingChecksChan,
-		runnerID:                runnerID,
-		shouldAddCheckStatsFunc: shouldAddCheckStatsFunc,
-		getDefaultSenderFunc:    getDefaultSenderFunc,
-		utilizationTracker:      utilizationTracker,
+		ID:                       ID,
+		Name:                     workerName,
+		addReplacementWorkerFunc: addReplacementWorkerFunc,
+		checksTracker:            checksTracker,
+		discardCheckRunFunc:      aggregator.DiscardCheckRun,
+		pendingChecksChan:        pendingChecksChan,
+		resourceAccounting:       config.Datadog.GetBool("check_resource_accounting"),
+		resourceBudgets:          defaultResourceBudgets,
+		resumeCheckRunFunc:       aggregator.ResumeCheckRun,
+		runnerID:                 runnerID,
+		shouldAddCheckStatsFunc:  shouldAddCheckStatsFunc,
+		getDefaultSenderFunc:     getDefaultSenderFunc,
+		utilizationTracker:       utilizationTracker,
 	}, nil
 }
 
@@ -127,6 +148,14 @@ func (w *Worker) Run() {
 		checkLogger := CheckLogger{Check: check}
 		longRunning := check.Interval() == 0
 
+		// Skip the run if the check exceeded its resource limits
+		if w.resourceBudgets.shouldSkip(check.ID()) {
+			checkLogger.Debug("Check exceeded its resource limits, skipping execution...")
+			expvars.AddSkippedRunsCount(1)
+			expvars.AddCheckSkippedRun(check)
+			continue
+		}
+
 		// Add check to tracker if it's not already running
 		if !w.checksTracker.AddCheck(check) {
 			checkLogger.Debug("Check is already running, skipping execution...")
@@ -143,8 +172,7 @@ func (w *Worker) Run() {
 		w.utilizationTracker.CheckStarted(longRunning)
 
 		// Run the check
-		var checkErr error
-		checkErr = check.Run()
+		lateRun, usage, checkErr := w.runCheck(check)
 
 		w.utilizationTracker.CheckFinished()
 
@@ -173,13 +201,30 @@ func (w *Worker) Run() {
 			serviceCheckStatus = metrics.ServiceCheckCritical
 		}
 
+		if lateRun != nil {
+			expvars.AddTimeoutsCount(1)
+		}
+
+		// The budget of a check unscheduled during its run is already forgotten
+		var exceededLimits []string
+		if usage != nil && w.shouldAddCheckStatsFunc(check.ID()) {
+			var skippedRuns int
+			exceededLimits, skippedRuns = w.resourceBudgets.addRun(check, *usage)
+			if len(exceededLimits) != 0 {
+				checkLogger.Warn(fmt.Sprintf("Check exceeded its resource limits (%s), skipping its next %d run(s)",
+					strings.Join(exceededLimits, ", "), skippedRuns))
+			}
+		}
+
 		if sender != nil && !longRunning {
 			sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hostname, serviceCheckTags, "")
 			sender.Commit()
 		}
 
-		// Remove the check from the running list
-		w.checksTracker.DeleteCheck(check.ID())
+		// Remove the check from the running list, unless its run is still in progress
+		if lateRun == nil {
+			w.checksTracker.DeleteCheck(check.ID())
+		}
 
 		// Publish statistics about this run
 		expvars.AddRunningCheckCount(-1)
@@ -191,11 +236,91 @@ func (w *Worker) Run() {
 			if w.shouldAddCheckStatsFunc(check.ID()) {
 				sStats, _ := check.GetSenderStats()
 				expvars.AddCheckStats(check, time.Since(checkStartTime), checkErr, checkWarnings, sStats)
+				if usage != nil && w.resourceAccounting {
+					expvars.AddCheckResourceUsage(check, *usage, exceededLimits)
+				}
 			}
 		}
 
 		checkLogger.CheckFinished()
+
+		if lateRun != nil && w.waitForLateRun(check, lateRun) {
+			log.Debugf("Runner %d, worker %d: Replaced by another worker, exiting.", w.runnerID, w.ID)
+			return
+		}
 	}
 
 	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
 }
+
+// runCheck runs the check, and returns the resources used by the run when they are
+// accounted. If the run exceeds the timeout of the check, the results submitted by
+// the check are discarded and a `check.TimeoutError` is returned with a channel closed
+// once the run completes.
+func (w *Worker) runCheck(c check.Check) (<-chan struct{}, *check.ResourceUsage, error) {
+	// Long running checks are not expected to return
+	if c.Interval() == 0 {
+		return nil, nil, c.Run()
+	}
+
+	timeout := c.Timeout()
+	if timeout <= 0 {
+		usage, err := w.runAndSample(c)
+		return nil, usage, err
+	}
+
+	var usage *check.ResourceUsage
+	var err error
+	done := make(chan struct{})
+	go func() {
+		usage, err = w.runAndSample(c)
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil, usage, err
+	case <-timer.C:
+	}
+
+	// The run can't be interrupted, so what it submits from now on is dropped
+	w.discardCheckRunFunc(c.ID())
+	return done, nil, check.TimeoutError{Timeout: timeout}
+}
+
+// runAndSample runs the check and samples the resources used by the run, if the
+// resource accounting is enabled or the check has resource limits. Without the
+// resource accounting, only the CPU time is sampled to enforce the limits.
+func (w *Worker) runAndSample(c check.Check) (*check.ResourceUsage, error) {
+	if !w.resourceAccounting && c.ResourceLimits().IsZero() {
+		return nil, c.Run()
+	}
+
+	sampler := startResourceSampling(w.resourceAccounting)
+	err := c.Run()
+	usage := sampler.stop()
+	return &usage, err
+}
+
+// waitForLateRun waits for the completion of a check run which exceeded its timeout. The
+// runner is asked for a worker to replace this one in the meantime. It returns whether this
+// worker was replaced, in which case it must stop.
+func (w *Worker) waitForLateRun(c check.Check, lateRun <-chan struct{}) bool {
+	replaced := w.addReplacementWorkerFunc(w.ID)
+	if !replaced {
+		log.Warnf("Runner %d, worker %d: No worker could be added while check %s completes its run, checks may be delayed",
+			w.runnerID, w.ID, c)
+	}
+
+	<-lateRun
+
+	w.resumeCheckRunFunc(c.ID())
+	w.checksTracker.DeleteCheck(c.ID())
+	log.Infof("Runner %d, worker %d: Check %s completed its run after its timeout, its results were discarded",
+		w.runnerID, w.ID, c)
+
+	return replaced
+}
@@ -31,6 +31,8 @@ type testCheck struct {
 	doWarn      bool
 	id          string
 	longRunning bool
+	timeout     time.Duration
+	limits      check.ResourceLimits
 	t           *testing.T
 	runFunc     func(id check.ID)
 	runCount    uint64
@@ -48,6 +50,10 @@ func (c *testCheck) Interval() time.Duration {
 	return 123
 }
 
+func (c *testCheck) Timeout() time.Duration { return c.timeout }
+
+func (c *testCheck) ResourceLimits() check.ResourceLimits { return c.limits }
+
 func (c *testCheck) GetWarnings() []error {
 	if c.doWarn {
 		return []error{fmt.Errorf("Warning")}
@@ -103,6 +109,8 @@ func newCheck(t *testing.T, id string, doErr bool, runFunc func(check.ID)) *test
 	}
 }
 
+func mockAddReplacementWorkerFunc(blockedWorkerID int) bool { return false }
+
 func assertErrorCount(t *testing.T, c check.Check, count int) {
 	stats, found := expvars.CheckStats(c.ID())
 	require.True(t, found)
@@ -116,16 +124,19 @@ func TestWorkerInit(t *testing.T) {
 	pendingChecksChan := make(chan check.Check, 1)
 	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
 
-	_, err := NewWorker(1, 2, nil, checksTracker, mockShouldAddStatsFunc)
+	_, err := NewWorker(1, 2, nil, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
+	require.NotNil(t, err)
+
+	_, err = NewWorker(1, 2, pendingChecksChan, nil, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(1, 2, pendingChecksChan, nil, mockShouldAddStatsFunc)
+	_, err = NewWorker(1, 2, pendingChecksChan, checksTracker, nil, mockAddReplacementWorkerFunc)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(1, 2, pendingChecksChan, checksTracker, nil)
+	_, err = NewWorker(1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, nil)
 	require.NotNil(t, err)
 
-	worker, err := NewWorker(1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	assert.Nil(t, err)
 	assert.NotNil(t, worker)
 }
@@ -144,7 +155,7 @@ func TestWorkerInitExpvarStats(t *testing.T) {
 		go func(idx int) {
 			defer wg.Done()
 
-			worker, err := NewWorker(1, idx, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+			worker, err := NewWorker(1, idx, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 			assert.Nil(t, err)
 
 			worker.Run()
@@ -166,7 +177,7 @@ func TestWorkerName(t *testing.T) {
 
 	for _, id := range []int{1, 100, 500} {
 		expectedName := fmt.Sprintf("worker_%d", id)
-		worker, err := NewWorker(1, id, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+		worker, err := NewWorker(1, id, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 		assert.Nil(t, err)
 		assert.NotNil(t, worker)
 
@@ -218,7 +229,7 @@ func TestWorker(t *testing.T) {
 	pendingChecksChan <- testCheck1
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	require.Nil(t, err)
 
 	wg.Add(1)
@@ -272,6 +283,7 @@ func TestWorkerUtilizationExpvars(t *testing.T) {
 		pendingChecksChan,
 		checksTracker,
 		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
 		func() (aggregator.Sender, error) { return nil, nil },
 		1000*time.Millisecond,
 		100*time.Millisecond,
@@ -344,7 +356,7 @@ func TestWorkerErrorAndWarningHandling(t *testing.T) {
 	}
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	require.Nil(t, err)
 	AssertAsyncWorkerCount(t, 0)
 
@@ -389,7 +401,7 @@ func TestWorkerConcurrentCheckScheduling(t *testing.T) {
 	pendingChecksChan <- testCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -443,7 +455,7 @@ func TestWorkerStatsAddition(t *testing.T) {
 	pendingChecksChan <- squelchedStatsCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, shouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, shouldAddStatsFunc, mockAddReplacementWorkerFunc)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -490,6 +502,7 @@ func TestWorkerServiceCheckSending(t *testing.T) {
 		pendingChecksChan,
 		checksTracker,
 		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
 		func() (aggregator.Sender, error) {
 			return mockSender, nil
 		},
@@ -561,6 +574,7 @@ func TestWorkerSenderNil(t *testing.T) {
 		pendingChecksChan,
 		checksTracker,
 		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
 		func() (aggregator.Sender, error) {
 			return nil, fmt.Errorf("testerr")
 		},
@@ -601,6 +615,7 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 		pendingChecksChan,
 		checksTracker,
 		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
 		func() (aggregator.Sender, error) {
 			return mockSender, nil
 		},
@@ -617,3 +632,270 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 	mockSender.AssertNumberOfCalls(t, "Commit", 0)
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 0)
 }
+
+func TestWorkerTimeout(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	unblock := make(chan struct{})
+	timingOutCheck := newCheck(t, "timingout:123", false, func(check.ID) { <-unblock })
+	timingOutCheck.timeout = 100 * time.Millisecond
+
+	pendingChecksChan <- timingOutCheck
+
+	var replacedWorkerID int
+	mockSender := mocksender.NewMockSender("")
+	mockSender.On("Commit").Return().Times(1)
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		metrics.ServiceCheckCritical,
+		"myhost",
+		[]string{"check:timingout"},
+		"",
+	).Return().Times(1)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		func(blockedWorkerID int) bool {
+			replacedWorkerID = blockedWorkerID
+			return true
+		},
+		func() (aggregator.Sender, error) {
+			return mockSender, nil
+		},
+		windowSize,
+		pollingInterval,
+	)
+	require.Nil(t, err)
+
+	discarded := make(chan check.ID, 1)
+	resumed := make(chan check.ID, 1)
+	worker.discardCheckRunFunc = func(id check.ID) { discarded <- id }
+	worker.resumeCheckRunFunc = func(id check.ID) { resumed <- id }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run()
+	}()
+
+	select {
+	case id := <-discarded:
+		assert.Equal(t, timingOutCheck.ID(), id)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the results of the check were not discarded")
+	}
+
+	// The timeout is reported while the check is still running
+	require.Eventually(t, func() bool { return expvars.GetRunsCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	stats, found := expvars.CheckStats(timingOutCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 1, int(stats.TotalErrors))
+	assert.Equal(t, 1, int(stats.TotalTimeouts))
+	assert.Equal(t, int64(100), stats.Timeout)
+	assert.Contains(t, stats.LastError, "timed out after 100ms")
+	assert.Equal(t, 1, int(expvars.GetTimeoutsCount()))
+	assert.True(t, checksTracker.WithCheck(timingOutCheck.ID(), func(check.Check) {}))
+
+	// The replaced worker exits once the run completes
+	close(unblock)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the replaced worker didn't exit")
+	}
+	assert.Equal(t, timingOutCheck.ID(), <-resumed)
+	assert.Equal(t, 200, replacedWorkerID)
+	assert.False(t, checksTracker.WithCheck(timingOutCheck.ID(), func(check.Check) {}))
+	assert.Equal(t, 1, timingOutCheck.RunCount())
+
+	mockSender.AssertExpectations(t)
+}
+
+func TestWorkerTimeoutWithoutReplacement(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	timingOutCheck := newCheck(t, "timingout:123", false, func(check.ID) { time.Sleep(300 * time.Millisecond) })
+	timingOutCheck.timeout = 100 * time.Millisecond
+	// Runs within its timeout
+	fastCheck := newCheck(t, "fast:123", false, nil)
+	fastCheck.timeout = 5 * time.Second
+
+	pendingChecksChan <- timingOutCheck
+	pendingChecksChan <- fastCheck
+	close(pendingChecksChan)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
+		func() (aggregator.Sender, error) { return nil, nil },
+		windowSize,
+		pollingInterval,
+	)
+	require.Nil(t, err)
+	worker.discardCheckRunFunc = func(check.ID) {}
+	worker.resumeCheckRunFunc = func(check.ID) {}
+
+	// The worker waits for the late run and keeps processing the checks
+	worker.Run()
+
+	assert.Equal(t, 1, timingOutCheck.RunCount())
+	assert.Equal(t, 1, fastCheck.RunCount())
+	assert.Equal(t, 2, int(expvars.GetRunsCount()))
+	assert.Equal(t, 1, int(expvars.GetTimeoutsCount()))
+	assertErrorCount(t, fastCheck, 0)
+	assertErrorCount(t, timingOutCheck, 1)
+}
+
+var allocationSink []byte
+
+// burnCPUTime spins until the current thread used d of CPU time.
+func burnCPUTime(d time.Duration) {
+	start, _ := threadCPUTime()
+	for {
+		if now, _ := threadCPUTime(); now-start >= d {
+			return
+		}
+	}
+}
+
+func TestWorkerResourceLimits(t *testing.T) {
+	if _, ok := threadCPUTime(); !ok {
+		t.Skip("the CPU time of the threads is not available")
+	}
+
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	busyCheck := newCheck(t, "busy:123", false, func(check.ID) { burnCPUTime(20 * time.Millisecond) })
+	busyCheck.limits = check.ResourceLimits{CPUTime: time.Millisecond, Action: check.ResourceLimitActionSkip}
+	// The process-wide allocations don't count toward the limits
+	allocatingCheck := newCheck(t, "allocating:123", false, func(check.ID) { allocationSink = make([]byte, 1<<20) })
+	allocatingCheck.limits = check.ResourceLimits{CPUTime: time.Hour, Action: check.ResourceLimitActionSkip}
+
+	// The second run of the busy check is skipped
+	for i := 0; i < 3; i++ {
+		pendingChecksChan <- busyCheck
+		pendingChecksChan <- allocatingCheck
+	}
+	close(pendingChecksChan)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		mockAddReplacementWorkerFunc,
+		func() (aggregator.Sender, error) { return nil, nil },
+		windowSize,
+		pollingInterval,
+	)
+	require.Nil(t, err)
+	worker.resourceAccounting = true
+	worker.resourceBudgets = newResourceBudgets()
+
+	worker.Run()
+
+	assert.Equal(t, 2, busyCheck.RunCount())
+	assert.Equal(t, 3, allocatingCheck.RunCount())
+	assert.Equal(t, 1, int(expvars.GetSkippedRunsCount()))
+
+	stats, found := expvars.CheckStats(busyCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 2, int(stats.ResourceUsageRuns))
+	assert.Equal(t, 1, int(stats.TotalSkippedRuns))
+	assert.True(t, stats.LastCPUTime >= 20)
+	require.Len(t, stats.ExceededResourceLimits, 1)
+	assert.Contains(t, stats.ExceededResourceLimits[0], "CPU time")
+
+	stats, found = expvars.CheckStats(allocatingCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 3, int(stats.ResourceUsageRuns))
+	assert.True(t, stats.LastProcessAllocBytes >= 1<<20)
+	assert.Empty(t, stats.ExceededResourceLimits)
+}
+
+func TestWorkerResourceAccountingDisabled(t *testing.T) {
+	if _, ok := threadCPUTime(); !ok {
+		t.Skip("the CPU time of the threads is not available")
+	}
+
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	busyCheck := newCheck(t, "busy:123", false, func(check.ID) { burnCPUTime(20 * time.Millisecond) })
+	busyCheck.limits = check.ResourceLimits{CPUTime: time.Millisecond, Action: check.ResourceLimitActionSkip}
+	pendingChecksChan <- busyCheck
+	pendingChecksChan <- busyCheck
+	close(pendingChecksChan)
+
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
+	require.Nil(t, err)
+	worker.resourceAccounting = false
+	worker.resourceBudgets = newResourceBudgets()
+
+	worker.Run()
+
+	// The limits are enforced without the resource accounting, but the usage isn't reported
+	assert.Equal(t, 1, busyCheck.RunCount())
+	assert.Equal(t, 1, int(expvars.GetSkippedRunsCount()))
+	stats, found := expvars.CheckStats(busyCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 0, int(stats.ResourceUsageRuns))
+}
+
+func TestWorkerResourceLimitsUnscheduledCheck(t *testing.T) {
+	if _, ok := threadCPUTime(); !ok {
+		t.Skip("the CPU time of the threads is not available")
+	}
+
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	// The check is unscheduled while it runs
+	mockShouldAddStatsFunc := func(id check.ID) bool { return false }
+
+	busyCheck := newCheck(t, "busy:123", false, func(check.ID) { burnCPUTime(20 * time.Millisecond) })
+	busyCheck.limits = check.ResourceLimits{CPUTime: time.Millisecond, Action: check.ResourceLimitActionSkip}
+	pendingChecksChan <- busyCheck
+	close(pendingChecksChan)
+
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc, mockAddReplacementWorkerFunc)
+	require.Nil(t, err)
+	worker.resourceBudgets = newResourceBudgets()
+
+	worker.Run()
+
+	// The run exceeded the limits, but no budget is kept for the unscheduled check
+	assert.Equal(t, 1, busyCheck.RunCount())
+	assert.Empty(t, worker.resourceBudgets.budgets)
+}
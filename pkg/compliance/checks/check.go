@@ -58,6 +58,18 @@ func (c *complianceCheck) Interval() time.Duration {
 	return c.interval
 }
 
+func (c *complianceCheck) Timeout() time.Duration {
+	return 0
+}
+
+func (c *complianceCheck) Schedule() check.Schedule {
+	return check.Schedule{}
+}
+
+func (c *complianceCheck) ResourceLimits() check.ResourceLimits {
+	return check.ResourceLimits{}
+}
+
 func (c *complianceCheck) ID() check.ID {
 	return check.ID(c.ruleID)
 }
@@ -26,9 +26,17 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.obfuscation.sql_exec_plan_normalize.obfuscate_sql_values")
 	config.SetKnown("apm_config.obfuscation.http.remove_query_string")
 	config.SetKnown("apm_config.obfuscation.http.remove_paths_with_digits")
+	config.SetKnown("apm_config.obfuscation.sql.table_names")
+	config.SetKnown("apm_config.obfuscation.sql.collect_commands")
+	config.SetKnown("apm_config.obfuscation.sql.collect_procedures")
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.scrub_tags.enabled")
+	config.SetKnown("apm_config.scrub_tags.key_patterns")
+	config.SetKnown("apm_config.scrub_tags.credit_cards")
+	config.SetKnown("apm_config.scrub_tags.emails")
+	config.SetKnown("apm_config.scrub_tags.bearer_tokens")
 	config.SetKnown("apm_config.filter_tags.require")
 	config.SetKnown("apm_config.filter_tags.reject")
 	config.SetKnown("apm_config.extra_sample_rate")
@@ -84,6 +92,9 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.filter_tags.reject", "DD_APM_FILTER_TAGS_REJECT")
 	config.BindEnv("apm_config.internal_profiling.enabled", "DD_APM_INTERNAL_PROFILING_ENABLED")
 	config.BindEnv("apm_config.debugger_dd_url", "DD_APM_DEBUGGER_DD_URL")
+	config.BindEnvAndSetDefault("apm_config.capture_enabled", false, "DD_APM_CAPTURE_ENABLED")
+	config.BindEnv("apm_config.capture_path", "DD_APM_CAPTURE_PATH")
+	config.BindEnvAndSetDefault("apm_config.tail_enabled", false, "DD_APM_TAIL_ENABLED")
 
 	config.SetEnvKeyTransformer("apm_config.ignore_resources", func(in string) interface{} {
 		r, err := splitCSVString(in, ',')
@@ -212,6 +212,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_runners_timeout_budget", 4)
+	config.BindEnvAndSetDefault("check_resource_accounting", false)
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -363,6 +365,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_events_stream_payload_serialization", true)
 	config.BindEnvAndSetDefault("enable_sketch_stream_payload_serialization", true)
 	config.BindEnvAndSetDefault("enable_json_stream_shared_compressor_buffers", true)
+	config.BindEnvAndSetDefault("serializer_compressor_kind", "zlib")
+	config.BindEnvAndSetDefault("serializer_compressor_level", -1) // -1 selects the default level of the compressor kind
+	config.SetKnown("serializer_compressor_overrides")
+	config.SetKnown("serializer_compressor_domains")
 
 	// Warning: do not change the two following values. Your payloads will get dropped by Datadog's intake.
 	config.BindEnvAndSetDefault("serializer_max_payload_size", 2*megaByte+megaByte/2)
@@ -370,6 +376,7 @@ func InitConfig(config Config) {
 
 	config.BindEnvAndSetDefault("use_v2_api.events", false)
 	config.BindEnvAndSetDefault("use_v2_api.service_checks", false)
+	config.BindEnvAndSetDefault("use_v2_api.series", false)
 	// Serializer: allow user to blacklist any kind of payload to be sent
 	config.BindEnvAndSetDefault("enable_payloads.events", true)
 	config.BindEnvAndSetDefault("enable_payloads.series", true)
@@ -379,6 +386,7 @@ func InitConfig(config Config) {
 
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
+	config.BindEnvAndSetDefault("forwarder_failover_endpoints", map[string][]string{})
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
 	config.BindEnv("forwarder_retry_queue_max_size")                                                     // Deprecated in favor of `forwarder_retry_queue_payloads_max_size`
 	config.BindEnv("forwarder_retry_queue_payloads_max_size")                                            // Default value is defined inside `NewOptions` in pkg/forwarder/forwarder.go
@@ -386,6 +394,12 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_apikey_validation_interval", DefaultAPIKeyValidationInterval) // in minutes
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
 	config.BindEnvAndSetDefault("forwarder_stop_timeout", 2)
+	// Forwarder adaptive concurrency settings
+	config.BindEnvAndSetDefault("forwarder_adaptive_concurrency_enabled", false)
+	config.BindEnvAndSetDefault("forwarder_adaptive_concurrency_min", 1)
+	config.BindEnvAndSetDefault("forwarder_adaptive_concurrency_max", 10)
+	config.BindEnvAndSetDefault("forwarder_adaptive_concurrency_latency_threshold", 2.0) // in seconds
+	config.BindEnvAndSetDefault("forwarder_adaptive_concurrency_decrease_factor", 0.5)
 	// Forwarder retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
 	config.BindEnvAndSetDefault("forwarder_backoff_base", 2)
@@ -398,8 +412,16 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_outdated_file_in_days", 10)
 	config.BindEnvAndSetDefault("forwarder_flush_to_disk_mem_ratio", 0.5)
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0) // 0 means disabled. This is a BETA feature.
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key", "")
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_keyfile", "")
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80) // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 
+	// Forwarder offline mode
+	config.BindEnvAndSetDefault("forwarder_offline_mode", false)
+	config.BindEnvAndSetDefault("forwarder_spool_path", "")
+	config.BindEnvAndSetDefault("forwarder_spool_max_size_in_bytes", 500*1024*1024)
+	config.BindEnvAndSetDefault("forwarder_spool_outdated_file_in_days", 30)
+
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
 	config.BindEnvAndSetDefault("forwarder_low_prio_buffer_size", 100)
@@ -489,6 +511,16 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
 	config.BindEnvAndSetDefault("autoconfig_include_features", []string{})
 
+	// Workload metadata
+	config.BindEnvAndSetDefault("workloadmeta.process_collection.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.process_collection.interval", 10) // in seconds
+
+	// Tagger
+	config.BindEnvAndSetDefault("tagger.persistence.enabled", false)
+	config.BindEnvAndSetDefault("tagger.persistence.path", "")              // defaults to run_path/tagger_snapshot.json
+	config.BindEnvAndSetDefault("tagger.persistence.snapshot_interval", 60) // in seconds
+	config.BindEnvAndSetDefault("tagger.persistence.stale_ttl", 300)        // in seconds
+
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
 	config.BindEnvAndSetDefault("docker_labels_as_tags", map[string]string{})
@@ -497,6 +529,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_pod_annotations_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_node_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
+	config.BindEnv("kubernetes_tag_extraction_rules") // Defines regexp rules extracting tags from kubernetes labels and annotations
+	config.SetEnvKeyTransformer("kubernetes_tag_extraction_rules", func(in string) interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Warnf(`"kubernetes_tag_extraction_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
 
 	// CRI
@@ -1198,6 +1238,7 @@ func bindEnvAndSetLogsConfigKeys(config Config, prefix string) {
 	config.BindEnv(prefix + "additional_endpoints")
 	config.BindEnvAndSetDefault(prefix+"use_compression", true)
 	config.BindEnvAndSetDefault(prefix+"compression_level", 6) // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault(prefix+"compression_kind", "gzip")
 	config.BindEnvAndSetDefault(prefix+"batch_wait", DefaultBatchWait)
 	config.BindEnvAndSetDefault(prefix+"connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault(prefix+"logs_no_ssl", false)
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// SeriesV2APIRuntimeSetting wraps operations to switch the series payloads between the JSON
+// v1 API and the protobuf v2 API at runtime.
+type SeriesV2APIRuntimeSetting struct {
+}
+
+// Description returns the runtime setting's description
+func (s SeriesV2APIRuntimeSetting) Description() string {
+	return "Send the series as protobuf payloads to the v2 API."
+}
+
+// Hidden returns whether or not this setting is hidden from the list of runtime settings
+func (s SeriesV2APIRuntimeSetting) Hidden() bool {
+	return false
+}
+
+// Name returns the name of the runtime setting
+func (s SeriesV2APIRuntimeSetting) Name() string {
+	return "use_v2_api.series"
+}
+
+// Get returns the current value of the runtime setting
+func (s SeriesV2APIRuntimeSetting) Get() (interface{}, error) {
+	return config.Datadog.GetBool("use_v2_api.series"), nil
+}
+
+// Set changes the value of the runtime setting
+func (s SeriesV2APIRuntimeSetting) Set(v interface{}) error {
+	var newValue bool
+	var err error
+
+	if newValue, err = GetBool(v); err != nil {
+		return fmt.Errorf("SeriesV2APIRuntimeSetting: %v", err)
+	}
+
+	config.Datadog.Set("use_v2_api.series", newValue)
+	return nil
+}
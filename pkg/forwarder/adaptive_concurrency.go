@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"expvar"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// latencyEWMAWeight is the weight of the last transaction in the average latency.
+const latencyEWMAWeight = 0.2
+
+// concurrencyLimiter limits the number of transactions processed at the same time by
+// the workers of a domainForwarder. The limit follows an AIMD (additive increase,
+// multiplicative decrease) controller: it grows by one every `limit` successful
+// transactions and is multiplied by decreaseFactor, at most once per latency period,
+// when a transaction fails or is slower than latencyThreshold.
+//
+// A worker must acquire a slot before taking a transaction and release it once the
+// transaction is processed. All methods are no-ops on a nil concurrencyLimiter, which
+// is used when adaptive concurrency is disabled.
+type concurrencyLimiter struct {
+	domain           string
+	min              int
+	max              int
+	latencyThreshold time.Duration
+	decreaseFactor   float64
+
+	// slots contains the slots not acquired by a worker. Its capacity is max.
+	slots chan struct{}
+
+	m            sync.Mutex
+	limit        int
+	successes    int // the successful transactions since the last change of limit
+	issued       int // the number of slots in `slots` or acquired by a worker
+	inFlight     int
+	latency      time.Duration // the average latency of the transactions
+	lastDecrease time.Time
+
+	limitExpvar    expvar.Int
+	inFlightExpvar expvar.Int
+	latencyExpvar  expvar.Float
+}
+
+func newConcurrencyLimiter(domain string, initial, min, max int, latencyThreshold time.Duration, decreaseFactor float64) *concurrencyLimiter {
+	l := &concurrencyLimiter{
+		domain:           domain,
+		min:              min,
+		max:              max,
+		latencyThreshold: latencyThreshold,
+		decreaseFactor:   decreaseFactor,
+		slots:            make(chan struct{}, max),
+		limit:            initial,
+	}
+	for ; l.issued < initial; l.issued++ {
+		l.slots <- struct{}{}
+	}
+
+	stats := &expvar.Map{}
+	stats.Init()
+	stats.Set("Limit", &l.limitExpvar)
+	stats.Set("InFlight", &l.inFlightExpvar)
+	stats.Set("LatencySeconds", &l.latencyExpvar)
+	concurrencyExpvars.Set(domain, stats)
+	l.updateTelemetry()
+	return l
+}
+
+// newConcurrencyLimiterFromConfig returns the concurrencyLimiter of domain or nil when
+// `forwarder_adaptive_concurrency_enabled` is false. It also returns the number of workers
+// to start for the domain.
+func newConcurrencyLimiterFromConfig(domain string, numberOfWorkers int) (*concurrencyLimiter, int) {
+	if !config.Datadog.GetBool("forwarder_adaptive_concurrency_enabled") {
+		return nil, numberOfWorkers
+	}
+
+	min := config.Datadog.GetInt("forwarder_adaptive_concurrency_min")
+	if min < 1 {
+		log.Warnf("Configured forwarder_adaptive_concurrency_min (%v) is less than 1; 1 will be used", min)
+		min = 1
+	}
+	max := config.Datadog.GetInt("forwarder_adaptive_concurrency_max")
+	if max < min {
+		log.Warnf("Configured forwarder_adaptive_concurrency_max (%v) is less than forwarder_adaptive_concurrency_min; %v will be used", max, min)
+		max = min
+	}
+	latencyThreshold := time.Duration(config.Datadog.GetFloat64("forwarder_adaptive_concurrency_latency_threshold") * float64(time.Second))
+	if latencyThreshold <= 0 {
+		log.Warnf("Configured forwarder_adaptive_concurrency_latency_threshold (%v) is not positive; 2 seconds will be used", latencyThreshold)
+		latencyThreshold = 2 * time.Second
+	}
+	decreaseFactor := config.Datadog.GetFloat64("forwarder_adaptive_concurrency_decrease_factor")
+	if decreaseFactor <= 0 || decreaseFactor >= 1 {
+		log.Warnf("Configured forwarder_adaptive_concurrency_decrease_factor (%v) is not between 0 and 1; 0.5 will be used", decreaseFactor)
+		decreaseFactor = 0.5
+	}
+
+	initial := numberOfWorkers
+	if initial < min {
+		initial = min
+	} else if initial > max {
+		initial = max
+	}
+
+	// Start a worker per slot so the limit can grow up to max.
+	return newConcurrencyLimiter(domain, initial, min, max, latencyThreshold, decreaseFactor), max
+}
+
+// acquire waits for a free slot. It returns false if stop is signaled first.
+func (l *concurrencyLimiter) acquire(stop <-chan struct{}) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case <-l.slots:
+		l.m.Lock()
+		l.inFlight++
+		l.updateTelemetry()
+		l.m.Unlock()
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// release frees a slot acquired by acquire. Slots are removed or added to match the limit.
+func (l *concurrencyLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.inFlight--
+	if l.issued > l.limit {
+		l.issued--
+	} else {
+		l.slots <- struct{}{}
+		for ; l.issued < l.limit; l.issued++ {
+			l.slots <- struct{}{}
+		}
+	}
+	l.updateTelemetry()
+}
+
+// observe updates the limit from the outcome of a transaction.
+func (l *concurrencyLimiter) observe(latency time.Duration, err error) {
+	if l == nil {
+		return
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.latency == 0 {
+		l.latency = latency
+	} else {
+		l.latency = time.Duration(latencyEWMAWeight*float64(latency) + (1-latencyEWMAWeight)*float64(l.latency))
+	}
+
+	now := time.Now()
+	if err != nil || latency > l.latencyThreshold {
+		// Transactions sent at the same time usually fail together: decrease only once
+		// per latency period.
+		if now.Sub(l.lastDecrease) >= l.latency {
+			l.decrease(now, latency, err)
+		}
+	} else if l.limit < l.max {
+		l.successes++
+		if l.successes >= l.limit {
+			l.limit++
+			l.successes = 0
+		}
+	}
+	l.updateTelemetry()
+}
+
+// decrease must be called with l.m locked.
+func (l *concurrencyLimiter) decrease(now time.Time, latency time.Duration, err error) {
+	l.lastDecrease = now
+	l.successes = 0
+	previous := l.limit
+	l.limit = int(math.Floor(float64(l.limit) * l.decreaseFactor))
+	if l.limit < l.min {
+		l.limit = l.min
+	}
+	if l.limit != previous {
+		log.Debugf("Decreasing the concurrency of %q to %d (latency: %v, error: %v)", l.domain, l.limit, latency, err)
+	}
+}
+
+// getLimit returns the current number of slots allowed.
+func (l *concurrencyLimiter) getLimit() int {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.limit
+}
+
+// updateTelemetry must be called with l.m locked.
+func (l *concurrencyLimiter) updateTelemetry() {
+	tlmConcurrencyLimit.Set(float64(l.limit), l.domain)
+	tlmConcurrencyInFlight.Set(float64(l.inFlight), l.domain)
+	tlmConcurrencyLatency.Set(l.latency.Seconds(), l.domain)
+
+	l.limitExpvar.Set(int64(l.limit))
+	l.inFlightExpvar.Set(int64(l.inFlight))
+	l.latencyExpvar.Set(l.latency.Seconds())
+}
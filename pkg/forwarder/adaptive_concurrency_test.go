@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestConcurrencyLimiterNil(t *testing.T) {
+	var l *concurrencyLimiter
+	assert.True(t, l.acquire(nil))
+	l.observe(time.Second, errors.New("error"))
+	l.release()
+}
+
+func TestConcurrencyLimiterAdditiveIncrease(t *testing.T) {
+	l := newConcurrencyLimiter("domain", 2, 1, 4, time.Second, 0.5)
+
+	// The limit grows by one every `limit` successful transactions
+	l.observe(10*time.Millisecond, nil)
+	assert.Equal(t, 2, l.getLimit())
+	l.observe(10*time.Millisecond, nil)
+	assert.Equal(t, 3, l.getLimit())
+	for i := 0; i < 3; i++ {
+		l.observe(10*time.Millisecond, nil)
+	}
+	assert.Equal(t, 4, l.getLimit())
+
+	// up to max
+	for i := 0; i < 100; i++ {
+		l.observe(10*time.Millisecond, nil)
+	}
+	assert.Equal(t, 4, l.getLimit())
+}
+
+func TestConcurrencyLimiterMultiplicativeDecrease(t *testing.T) {
+	l := newConcurrencyLimiter("domain", 8, 1, 10, time.Second, 0.5)
+
+	l.observe(10*time.Millisecond, errors.New("error"))
+	assert.Equal(t, 4, l.getLimit())
+
+	// Only one decrease per latency period
+	l.observe(10*time.Millisecond, errors.New("error"))
+	assert.Equal(t, 4, l.getLimit())
+
+	// Slow transactions decrease the limit
+	l.lastDecrease = time.Time{}
+	l.observe(2*time.Second, nil)
+	assert.Equal(t, 2, l.getLimit())
+
+	// down to min
+	for i := 0; i < 10; i++ {
+		l.lastDecrease = time.Time{}
+		l.observe(10*time.Millisecond, errors.New("error"))
+	}
+	assert.Equal(t, 1, l.getLimit())
+}
+
+func TestConcurrencyLimiterSlots(t *testing.T) {
+	l := newConcurrencyLimiter("domain", 2, 1, 4, time.Second, 0.5)
+	stop := make(chan struct{})
+
+	require.True(t, l.acquire(stop))
+	require.True(t, l.acquire(stop))
+	assert.Equal(t, 2, l.inFlight)
+	assertNoSlot(t, l)
+
+	// The limit grows to 3: releasing a slot adds another one
+	l.observe(10*time.Millisecond, nil)
+	l.observe(10*time.Millisecond, nil)
+	l.release()
+	require.True(t, l.acquire(stop))
+	require.True(t, l.acquire(stop))
+	assertNoSlot(t, l)
+	assert.Equal(t, 3, l.inFlight)
+
+	// The limit decreases to 1: released slots are removed until 1 slot remains
+	l.observe(10*time.Millisecond, errors.New("error"))
+	assert.Equal(t, 1, l.getLimit())
+	l.release()
+	l.release()
+	assertNoSlot(t, l)
+	l.release()
+	require.True(t, l.acquire(stop))
+	assertNoSlot(t, l)
+
+	// acquire returns when stopped
+	done := make(chan bool)
+	go func() { done <- l.acquire(stop) }()
+	stop <- struct{}{}
+	assert.False(t, <-done)
+}
+
+func TestNewConcurrencyLimiterFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+
+	l, numberOfWorkers := newConcurrencyLimiterFromConfig("domain", 3)
+	assert.Nil(t, l)
+	assert.Equal(t, 3, numberOfWorkers)
+
+	mockConfig.Set("forwarder_adaptive_concurrency_enabled", true)
+	mockConfig.Set("forwarder_adaptive_concurrency_min", 2)
+	mockConfig.Set("forwarder_adaptive_concurrency_max", 6)
+	defer mockConfig.Set("forwarder_adaptive_concurrency_enabled", false)
+	defer mockConfig.Set("forwarder_adaptive_concurrency_min", 1)
+	defer mockConfig.Set("forwarder_adaptive_concurrency_max", 10)
+
+	l, numberOfWorkers = newConcurrencyLimiterFromConfig("domain", 1)
+	require.NotNil(t, l)
+	assert.Equal(t, 6, numberOfWorkers)
+	assert.Equal(t, 2, l.getLimit())
+	assert.Equal(t, 2*time.Second, l.latencyThreshold)
+	assert.Equal(t, 0.5, l.decreaseFactor)
+
+	l, _ = newConcurrencyLimiterFromConfig("domain", 8)
+	assert.Equal(t, 6, l.getLimit())
+}
+
+func assertNoSlot(t *testing.T, l *concurrencyLimiter) {
+	select {
+	case <-l.slots:
+		assert.Fail(t, "unexpected free slot")
+	default:
+	}
+}
@@ -92,6 +92,17 @@ func (e *blockedEndpoints) recover(endpoint string) {
 	e.errorPerEndpoint[endpoint] = b
 }
 
+// unblock ends the blocking period of an endpoint without resetting its errors, so the
+// endpoint is blocked again for a longer period on the next error.
+func (e *blockedEndpoints) unblock(endpoint string) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if b, ok := e.errorPerEndpoint[endpoint]; ok {
+		b.until = time.Now()
+	}
+}
+
 func (e *blockedEndpoints) isBlock(endpoint string) bool {
 	e.m.RLock()
 	defer e.m.RUnlock()
@@ -233,6 +233,21 @@ func TestUnblockUnknown(t *testing.T) {
 	assert.True(t, e.errorPerEndpoint["test"].nbError == 0)
 }
 
+func TestUnblockKeepsErrors(t *testing.T) {
+	e := newBlockedEndpoints()
+
+	e.close("test")
+	e.close("test")
+	require.True(t, e.isBlock("test"))
+
+	e.unblock("test")
+	assert.False(t, e.isBlock("test"))
+	assert.Equal(t, 2, e.errorPerEndpoint["test"].nbError)
+
+	e.unblock("unknown")
+	assert.NotContains(t, e.errorPerEndpoint, "unknown")
+}
+
 func TestIsBlock(t *testing.T) {
 	e := newBlockedEndpoints()
 
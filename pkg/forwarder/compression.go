@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+// recompress decompresses a payload according to the Content-Encoding header of extra
+// and compresses it again with c. It returns the new payload along with its headers.
+func recompress(payload *[]byte, extra http.Header, c compression.Compressor) (*[]byte, http.Header, error) {
+	from, err := compression.FromContentEncoding(extra.Get("Content-Encoding"))
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := from.Decompress(*payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	compressed, err := c.Compress(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make(http.Header, len(extra)+1)
+	for key, values := range extra {
+		headers[key] = values
+	}
+	if encoding := c.ContentEncoding(); encoding != "" {
+		headers.Set("Content-Encoding", encoding)
+	} else {
+		headers.Del("Content-Encoding")
+	}
+	return &compressed, headers, nil
+}
@@ -40,6 +40,7 @@ type domainForwarder struct {
 	m                         sync.Mutex // To control Start/Stop races
 	transactionPrioritySorter retry.TransactionPrioritySorter
 	blockedList               *blockedEndpoints
+	concurrency               *concurrencyLimiter
 }
 
 func newDomainForwarder(
@@ -195,8 +196,11 @@ func (f *domainForwarder) Start() error {
 	// reset internal state to purge transactions from past starts
 	f.init()
 
-	for i := 0; i < f.numberOfWorkers; i++ {
+	var numberOfWorkers int
+	f.concurrency, numberOfWorkers = newConcurrencyLimiterFromConfig(f.domain, f.numberOfWorkers)
+	for i := 0; i < numberOfWorkers; i++ {
 		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		w.concurrency = f.concurrency
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// failoverGroup is an ordered list of domains sharing the API keys of the first one,
+// the primary domain. Unlike the additional endpoints, a transaction is sent to a
+// single domain of the group: the first one whose endpoint is not blocked.
+type failoverGroup struct {
+	domains []string
+	// activeDomains is the domain currently used by each endpoint, to log the switches.
+	activeDomains map[string]string
+	m             sync.Mutex
+}
+
+func newFailoverGroup(domains []string) *failoverGroup {
+	return &failoverGroup{
+		domains:       domains,
+		activeDomains: make(map[string]string),
+	}
+}
+
+// route sets the domain of the transaction to the first domain of the group whose
+// endpoint is healthy according to its blocked endpoints. When every domain is
+// blocked, the transaction is routed to the primary domain so it ends up in its
+// retry queue.
+func (g *failoverGroup) route(t *transaction.HTTPTransaction, domainForwarders map[string]*domainForwarder) {
+	primary := g.domains[0]
+	selected := primary
+	for _, domain := range g.domains {
+		t.Domain = domain
+		if df, found := domainForwarders[domain]; !found || !df.blockedList.isBlock(t.GetTarget()) {
+			selected = domain
+			break
+		}
+	}
+	t.Domain = selected
+
+	if selected != primary {
+		transactionsFailover.Add(1)
+		tlmTxFailover.Inc(selected, t.Endpoint.Name)
+	}
+
+	g.m.Lock()
+	defer g.m.Unlock()
+	previous, found := g.activeDomains[t.Endpoint.Name]
+	if !found {
+		previous = primary
+	}
+	if previous != selected {
+		if selected == primary {
+			log.Infof("Endpoint %q recovered on the primary domain %q, switching back from %q", t.Endpoint.Name, primary, previous)
+		} else {
+			log.Warnf("Endpoint %q is blocked on %q, failing over to %q", t.Endpoint.Name, previous, selected)
+		}
+	}
+	g.activeDomains[t.Endpoint.Name] = selected
+}
+
+// getFailoverDomains returns the fallback domains configured for the primary domain,
+// in order. Domains are compared without case as the configuration keys are lower case.
+func getFailoverDomains(failoverDomains map[string][]string, primary string) []string {
+	for domain, fallbacks := range failoverDomains {
+		if strings.EqualFold(strings.TrimRight(domain, "/"), strings.TrimRight(primary, "/")) {
+			return fallbacks
+		}
+	}
+	return nil
+}
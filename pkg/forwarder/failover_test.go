@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+const (
+	testFailoverDomain1 = "http://proxy1.example.com"
+	testFailoverDomain2 = "http://proxy2.example.com"
+)
+
+func newFailoverTestForwarder() *DefaultForwarder {
+	options := NewOptions(keysWithMultipleDomains)
+	options.FailoverDomains = map[string][]string{
+		// the configuration keys are lower case
+		"http://APP.datadoghq.com": {testFailoverDomain1, testFailoverDomain2, "datadog.bar"},
+	}
+	return NewDefaultForwarder(options)
+}
+
+func TestNewDefaultForwarderFailover(t *testing.T) {
+	forwarder := newFailoverTestForwarder()
+
+	assert.Len(t, forwarder.keysPerDomains, 2)
+	require.Len(t, forwarder.domainForwarders, 4)
+	assert.Contains(t, forwarder.domainForwarders, testFailoverDomain1)
+	assert.Contains(t, forwarder.domainForwarders, testFailoverDomain2)
+
+	// datadog.bar is already a configured domain and is not used for failover
+	require.Contains(t, forwarder.failoverGroups, testVersionDomain)
+	assert.Equal(t, []string{testVersionDomain, testFailoverDomain1, testFailoverDomain2}, forwarder.failoverGroups[testVersionDomain].domains)
+	assert.NotContains(t, forwarder.failoverGroups, "datadog.bar")
+}
+
+func TestCreateHTTPTransactionsFailover(t *testing.T) {
+	forwarder := newFailoverTestForwarder()
+	endpoint := transaction.Endpoint{Route: "/api/foo", Name: "foo"}
+	payload := []byte("A payload")
+
+	getDomains := func() map[string]int {
+		domains := map[string]int{}
+		for _, tr := range forwarder.createHTTPTransactions(endpoint, Payloads{&payload}, false, make(http.Header)) {
+			domains[tr.Domain]++
+		}
+		return domains
+	}
+	block := func(domain string) {
+		forwarder.domainForwarders[domain].blockedList.close(domain + endpoint.Route)
+	}
+	unblock := func(domain string) {
+		b := forwarder.domainForwarders[domain].blockedList
+		b.errorPerEndpoint[domain+endpoint.Route].until = time.Now().Add(-time.Second)
+	}
+
+	// The primary domain is healthy
+	assert.Equal(t, map[string]int{testVersionDomain: 2, "datadog.bar": 1}, getDomains())
+
+	block(testVersionDomain)
+	assert.Equal(t, map[string]int{testFailoverDomain1: 2, "datadog.bar": 1}, getDomains())
+
+	block(testFailoverDomain1)
+	assert.Equal(t, map[string]int{testFailoverDomain2: 2, "datadog.bar": 1}, getDomains())
+
+	// Every domain is blocked, the transactions go to the retry queue of the primary domain
+	block(testFailoverDomain2)
+	assert.Equal(t, map[string]int{testVersionDomain: 2, "datadog.bar": 1}, getDomains())
+
+	unblock(testFailoverDomain2)
+	assert.Equal(t, map[string]int{testFailoverDomain2: 2, "datadog.bar": 1}, getDomains())
+
+	// Switch back to the primary domain once it recovers
+	unblock(testVersionDomain)
+	assert.Equal(t, map[string]int{testVersionDomain: 2, "datadog.bar": 1}, getDomains())
+
+	// Other endpoints are not impacted
+	block(testVersionDomain)
+	transactions := forwarder.createHTTPTransactions(transaction.Endpoint{Route: "/api/bar", Name: "bar"}, Payloads{&payload}, false, make(http.Header))
+	for _, tr := range transactions {
+		assert.NotEqual(t, testFailoverDomain1, tr.Domain)
+	}
+}
+
+func TestCreateHTTPTransactionsFailoverAPIKeys(t *testing.T) {
+	forwarder := newFailoverTestForwarder()
+	for _, apiKey := range []string{"api-key-1", "api-key-2"} {
+		tr := transaction.NewHTTPTransaction()
+		tr.Domain = testVersionDomain
+		tr.Endpoint.Route = "/api/foo?api_key=" + apiKey
+		forwarder.domainForwarders[testVersionDomain].blockedList.close(tr.GetTarget())
+	}
+
+	payload := []byte("A payload")
+	transactions := forwarder.createHTTPTransactions(transaction.Endpoint{Route: "/api/foo", Name: "foo"}, Payloads{&payload}, true, make(http.Header))
+	var apiKeys []string
+	for _, tr := range transactions {
+		if tr.Domain == testFailoverDomain1 {
+			apiKeys = append(apiKeys, tr.Headers.Get(apiHTTPHeaderKey))
+		}
+	}
+	assert.ElementsMatch(t, []string{"api-key-1", "api-key-2"}, apiKeys)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -59,6 +60,7 @@ type Forwarder interface {
 	SubmitV1Series(payload Payloads, extra http.Header) error
 	SubmitV1Intake(payload Payloads, extra http.Header) error
 	SubmitV1CheckRuns(payload Payloads, extra http.Header) error
+	SubmitSeries(payload Payloads, extra http.Header) error
 	SubmitEvents(payload Payloads, extra http.Header) error
 	SubmitServiceChecks(payload Payloads, extra http.Header) error
 	SubmitSketchSeries(payload Payloads, extra http.Header) error
@@ -99,8 +101,14 @@ type Options struct {
 	EnabledFeatures                Features
 	APIKeyValidationInterval       time.Duration
 	KeysPerDomain                  map[string][]string
-	ConnectionResetInterval        time.Duration
-	CompletionHandler              transaction.HTTPCompletionHandler
+	// FailoverDomains maps a domain of KeysPerDomain to the ordered list of domains
+	// used in its place, with its API keys, while its endpoints are blocked.
+	FailoverDomains map[string][]string
+	// DomainCompressors maps a domain of KeysPerDomain to the compressor of its payloads,
+	// when it differs from the compression of the submitted payloads.
+	DomainCompressors       map[string]compression.Compressor
+	ConnectionResetInterval time.Duration
+	CompletionHandler       transaction.HTTPCompletionHandler
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -141,6 +149,7 @@ func NewOptions(keysPerDomain map[string][]string) *Options {
 		RetryQueuePayloadsTotalMaxSize: retryQueuePayloadsTotalMaxSize,
 		APIKeyValidationInterval:       time.Duration(validationInterval) * time.Minute,
 		KeysPerDomain:                  keysPerDomain,
+		FailoverDomains:                config.Datadog.GetStringMapStringSlice("forwarder_failover_endpoints"),
 		ConnectionResetInterval:        time.Duration(config.Datadog.GetInt("forwarder_connection_reset_interval")) * time.Second,
 	}
 
@@ -174,22 +183,29 @@ type DefaultForwarder struct {
 	// NumberOfWorkers Number of concurrent HTTP request made by the DefaultForwarder (default 4).
 	NumberOfWorkers int
 
-	domainForwarders map[string]*domainForwarder
-	keysPerDomains   map[string][]string
-	healthChecker    *forwarderHealth
-	internalState    uint32
-	m                sync.Mutex // To control Start/Stop races
+	domainForwarders  map[string]*domainForwarder
+	keysPerDomains    map[string][]string
+	domainCompressors map[string]compression.Compressor
+	failoverGroups    map[string]*failoverGroup
+	healthChecker     *forwarderHealth
+	internalState     uint32
+	m                 sync.Mutex // To control Start/Stop races
 
 	completionHandler transaction.HTTPCompletionHandler
+
+	// spools stores the transactions by domain instead of sending them in offline mode.
+	spools map[string]*retry.Spool
 }
 
 // NewDefaultForwarder returns a new DefaultForwarder.
 func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	f := &DefaultForwarder{
-		NumberOfWorkers:  options.NumberOfWorkers,
-		domainForwarders: map[string]*domainForwarder{},
-		keysPerDomains:   map[string][]string{},
-		internalState:    Stopped,
+		NumberOfWorkers:   options.NumberOfWorkers,
+		domainForwarders:  map[string]*domainForwarder{},
+		keysPerDomains:    map[string][]string{},
+		domainCompressors: map[string]compression.Compressor{},
+		failoverGroups:    map[string]*failoverGroup{},
+		internalState:     Stopped,
 		healthChecker: &forwarderHealth{
 			keysPerDomains:        options.KeysPerDomain,
 			disableAPIKeyChecking: options.DisableAPIKeyChecking,
@@ -197,7 +213,21 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 		},
 		completionHandler: options.CompletionHandler,
 	}
+
+	if config.Datadog.GetBool("forwarder_offline_mode") {
+		if getAgentFolder(options) == "" {
+			log.Infof("Offline mode is disabled because the feature is unavailable for this process.")
+		} else if spools, err := newSpools(options.KeysPerDomain); err != nil {
+			log.Errorf("Offline mode disabled: %v", err)
+		} else {
+			// The API keys cannot be validated without a connection to the backend.
+			f.spools = spools
+			f.healthChecker.disableAPIKeyChecking = true
+		}
+	}
+
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
+	var optionalFileCipher *retry.FileCipher
 	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
 
 	// Disk Persistence is a core-only feature for now.
@@ -221,6 +251,11 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 				log.Errorf("Error when removing outdated files: %v", err)
 			}
 			log.Debugf("Outdated files removed: %v", strings.Join(filesRemoved, ", "))
+
+			optionalFileCipher, err = retry.NewFileCipherFromConfig(storagePath)
+			if err != nil {
+				log.Errorf("Retry queue storage on disk disabled. Cannot initialize the encryption of the retry files: %v", err)
+			}
 		}
 	} else {
 		log.Infof("Retry queue storage on disk is disabled because the feature is unavailable for this process.")
@@ -230,36 +265,62 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
 	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
 
-	for domain, keys := range options.KeysPerDomain {
-		domain, _ := config.AddAgentVersionToDomain(domain, "app")
+	buildDomainForwarder := func(domain string, keys []string) *domainForwarder {
+		var domainFolderPath string
+		var err error
+		if optionalRemovalPolicy != nil {
+			domainFolderPath, err = optionalRemovalPolicy.RegisterDomain(domain)
+			if err != nil {
+				log.Errorf("Retry queue storage on disk disabled. Cannot register the domain '%v': %v", domain, err)
+			}
+		}
+
+		transactionContainer := retry.BuildTransactionRetryQueue(
+			options.RetryQueuePayloadsTotalMaxSize,
+			flushToDiskMemRatio,
+			domainFolderPath,
+			storageMaxSize,
+			optionalFileCipher,
+			transactionContainerSort,
+			domain,
+			keys)
+
+		return newDomainForwarder(
+			domain,
+			transactionContainer,
+			options.NumberOfWorkers,
+			options.ConnectionResetInterval,
+			domainForwarderSort)
+	}
+
+	for configDomain, keys := range options.KeysPerDomain {
+		domain, _ := config.AddAgentVersionToDomain(configDomain, "app")
 		if keys == nil || len(keys) == 0 {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
 		} else {
-			var domainFolderPath string
-			var err error
-			if optionalRemovalPolicy != nil {
-				domainFolderPath, err = optionalRemovalPolicy.RegisterDomain(domain)
-				if err != nil {
-					log.Errorf("Retry queue storage on disk disabled. Cannot register the domain '%v': %v", domain, err)
-				}
+			f.keysPerDomains[domain] = keys
+			if c, found := options.DomainCompressors[configDomain]; found {
+				f.domainCompressors[domain] = c
 			}
+			f.domainForwarders[domain] = buildDomainForwarder(domain, keys)
 
-			transactionContainer := retry.BuildTransactionRetryQueue(
-				options.RetryQueuePayloadsTotalMaxSize,
-				flushToDiskMemRatio,
-				domainFolderPath,
-				storageMaxSize,
-				transactionContainerSort,
-				domain,
-				keys)
-
-			f.keysPerDomains[domain] = keys
-			f.domainForwarders[domain] = newDomainForwarder(
-				domain,
-				transactionContainer,
-				options.NumberOfWorkers,
-				options.ConnectionResetInterval,
-				domainForwarderSort)
+			fallbacks := getFailoverDomains(options.FailoverDomains, configDomain)
+			if len(fallbacks) == 0 || f.spools != nil {
+				continue
+			}
+			group := []string{domain}
+			for _, fallback := range fallbacks {
+				fallback, _ := config.AddAgentVersionToDomain(fallback, "app")
+				if _, found := f.domainForwarders[fallback]; found || isConfiguredDomain(options.KeysPerDomain, fallback) {
+					log.Errorf("The failover domain '%s' of '%s' is already used, ignoring it", fallback, domain)
+					continue
+				}
+				f.domainForwarders[fallback] = buildDomainForwarder(fallback, keys)
+				group = append(group, fallback)
+			}
+			if len(group) > 1 {
+				f.failoverGroups[domain] = newFailoverGroup(group)
+			}
 		}
 	}
 
@@ -274,6 +335,16 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	return f
 }
 
+// isConfiguredDomain returns whether domain is one of the domains of keysPerDomain.
+func isConfiguredDomain(keysPerDomain map[string][]string, domain string) bool {
+	for configDomain := range keysPerDomain {
+		if versionDomain, _ := config.AddAgentVersionToDomain(configDomain, "app"); versionDomain == domain {
+			return true
+		}
+	}
+	return false
+}
+
 func getAgentFolder(options *Options) string {
 	if HasFeature(options.EnabledFeatures, CoreFeatures) {
 		return "core"
@@ -298,11 +369,19 @@ func (f *DefaultForwarder) Start() error {
 	// log endpoints configuration
 	endpointLogs := make([]string, 0, len(f.keysPerDomains))
 	for domain, apiKeys := range f.keysPerDomains {
-		endpointLogs = append(endpointLogs, fmt.Sprintf("\"%s\" (%v api key(s))",
-			domain, len(apiKeys)))
+		endpointLog := fmt.Sprintf("\"%s\" (%v api key(s))", domain, len(apiKeys))
+		if group, found := f.failoverGroups[domain]; found {
+			endpointLog += fmt.Sprintf(" failing over to %s", strings.Join(group.domains[1:], ", "))
+		}
+		endpointLogs = append(endpointLogs, endpointLog)
+	}
+	if f.spools != nil {
+		log.Infof("Forwarder started in offline mode, storing the transactions of %v endpoint(s) in %s: %s",
+			len(endpointLogs), GetSpoolPath(), strings.Join(endpointLogs, " ; "))
+	} else {
+		log.Infof("Forwarder started, sending to %v endpoint(s) with %v worker(s) each: %s",
+			len(endpointLogs), f.NumberOfWorkers, strings.Join(endpointLogs, " ; "))
 	}
-	log.Infof("Forwarder started, sending to %v endpoint(s) with %v worker(s) each: %s",
-		len(endpointLogs), f.NumberOfWorkers, strings.Join(endpointLogs, " ; "))
 
 	f.healthChecker.Start()
 	f.internalState = Started
@@ -377,6 +456,14 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 
 	for _, payload := range payloads {
 		for domain, apiKeys := range f.keysPerDomains {
+			domainPayload, domainExtra := payload, extra
+			if c, found := f.domainCompressors[domain]; found {
+				var err error
+				if domainPayload, domainExtra, err = recompress(payload, extra, c); err != nil {
+					log.Errorf("Could not compress the %s payload with %s for %s, sending it as is: %v", endpoint.Name, c.Kind(), domain, err)
+					domainPayload, domainExtra = payload, extra
+				}
+			}
 			for _, apiKey := range apiKeys {
 				t := transaction.NewHTTPTransaction()
 				t.Domain = domain
@@ -384,7 +471,10 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 				if apiKeyInQueryString {
 					t.Endpoint.Route = fmt.Sprintf("%s?api_key=%s", endpoint.Route, apiKey)
 				}
-				t.Payload = payload
+				if group, found := f.failoverGroups[domain]; found {
+					group.route(t, f.domainForwarders)
+				}
+				t.Payload = domainPayload
 				t.Priority = priority
 				t.StorableOnDisk = storableOnDisk
 				t.Headers.Set(apiHTTPHeaderKey, apiKey)
@@ -403,8 +493,8 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 				transactionsInputCountByEndpoint.Add(endpoint.Name, 1)
 				transactionsInputBytesByEndpoint.Add(endpoint.Name, int64(t.GetPayloadSize()))
 
-				for key := range extra {
-					t.Headers.Set(key, extra.Get(key))
+				for key := range domainExtra {
+					t.Headers.Set(key, domainExtra.Get(key))
 				}
 				transactions = append(transactions, t)
 			}
@@ -418,12 +508,22 @@ func (f *DefaultForwarder) sendHTTPTransactions(transactions []*transaction.HTTP
 		return fmt.Errorf("the forwarder is not started")
 	}
 
+	if f.spools != nil {
+		return f.spoolHTTPTransactions(transactions)
+	}
+
 	for _, t := range transactions {
 		f.domainForwarders[t.Domain].sendHTTPTransactions(t)
 	}
 	return nil
 }
 
+// SubmitSeries will send timeseries to the v2 endpoint.
+func (f *DefaultForwarder) SubmitSeries(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(seriesEndpoint, payload, false, extra)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitEvents will send an event type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitEvents(payload Payloads, extra http.Header) error {
 	transactions := f.createHTTPTransactions(eventsEndpoint, payload, false, extra)
@@ -20,6 +20,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -120,6 +121,7 @@ func TestSubmitIfStopped(t *testing.T) {
 
 	require.NotNil(t, forwarder)
 	require.Equal(t, Stopped, forwarder.State())
+	assert.NotNil(t, forwarder.SubmitSeries(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitEvents(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitServiceChecks(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitSketchSeries(nil, make(http.Header)))
@@ -202,6 +204,39 @@ func TestCreateHTTPTransactionsWithMultipleDomains(t *testing.T) {
 	assert.Equal(t, txBar[0].Endpoint.Route, "/api/foo?api_key=api-key-3")
 }
 
+func TestCreateHTTPTransactionsWithDomainCompressors(t *testing.T) {
+	gzip, err := compression.NewCompressor(compression.GzipKind, compression.DefaultLevel)
+	require.NoError(t, err)
+	options := NewOptions(keysWithMultipleDomains)
+	options.DomainCompressors = map[string]compression.Compressor{"datadog.bar": gzip}
+	forwarder := NewDefaultForwarder(options)
+
+	zlib := compression.DefaultCompressor()
+	raw := []byte("A payload")
+	p1, err := zlib.Compress(raw)
+	require.NoError(t, err)
+	headers := make(http.Header)
+	headers.Set("Content-Encoding", zlib.ContentEncoding())
+	headers.Set("HTTP-MAGIC", "foo")
+
+	transactions := forwarder.createHTTPTransactions(transaction.Endpoint{Route: "/api/foo", Name: "foo"}, Payloads{&p1}, false, headers)
+	require.Len(t, transactions, 3)
+	for _, tr := range transactions {
+		assert.Equal(t, "foo", tr.Headers.Get("HTTP-MAGIC"))
+		if tr.Domain != "datadog.bar" {
+			assert.Equal(t, "deflate", tr.Headers.Get("Content-Encoding"))
+			assert.Equal(t, p1, *tr.Payload)
+			continue
+		}
+		assert.Equal(t, "gzip", tr.Headers.Get("Content-Encoding"))
+		decompressed, err := gzip.Decompress(*tr.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, raw, decompressed)
+	}
+	// The submitted headers are left untouched
+	assert.Equal(t, "deflate", headers.Get("Content-Encoding"))
+}
+
 func TestArbitraryTagsHTTPHeader(t *testing.T) {
 	mockConfig := config.Mock()
 	mockConfig.Set("allow_arbitrary_tags", true)
@@ -297,6 +332,7 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	assert.Nil(t, f.SubmitV1Series(payload, headers))
 	assert.Nil(t, f.SubmitV1Intake(payload, headers))
 	assert.Nil(t, f.SubmitV1CheckRuns(payload, headers))
+	assert.Nil(t, f.SubmitSeries(payload, headers))
 	assert.Nil(t, f.SubmitEvents(payload, headers))
 	assert.Nil(t, f.SubmitServiceChecks(payload, headers))
 	assert.Nil(t, f.SubmitSketchSeries(payload, headers))
@@ -307,10 +343,10 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	<-time.After(1 * time.Second)
 
 	// We should receive the following requests:
-	// - 8 transactions * 2 payloads per transactions * 2 api_keys
+	// - 9 transactions * 2 payloads per transactions * 2 api_keys
 	// - 2 requests to check the validity of the two api_key
 	ts.Close()
-	assert.Equal(t, int64(8*2*2+2), requests)
+	assert.Equal(t, int64(9*2*2+2), requests)
 }
 
 func TestTransactionEventHandlers(t *testing.T) {
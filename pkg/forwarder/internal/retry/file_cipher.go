@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// defaultKeyFilename is the name of the keyfile generated in the storage path when
+	// neither `forwarder_storage_encryption_key` nor `forwarder_storage_encryption_keyfile` is set.
+	defaultKeyFilename = "retry_files.key"
+	keyfileSecretSize  = 32
+	// keyDerivationSalt and keyDerivationInfo make the derived key specific to the retry files.
+	keyDerivationSalt = "datadog-agent forwarder storage"
+	keyDerivationInfo = "retry files encryption v1"
+)
+
+// retryFileMagic starts every encrypted retry file. Its last byte is the format version.
+var retryFileMagic = []byte{'D', 'D', 'R', 'Q', 1}
+
+// errRetryFileIntegrity is returned when a retry file was not written by FileCipher
+// or was modified afterwards.
+var errRetryFileIntegrity = errors.New("retry file is corrupted or was tampered with")
+
+// FileCipher encrypts and authenticates the retry files using AES-256-GCM.
+// An encrypted file is made of retryFileMagic, a random nonce and the sealed content.
+type FileCipher struct {
+	aead cipher.AEAD
+}
+
+// NewFileCipher creates a new instance of FileCipher using a key derived from secret.
+func NewFileCipher(secret []byte) (*FileCipher, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("the encryption secret is empty")
+	}
+	block, err := aes.NewCipher(deriveKey(secret))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileCipher{aead: aead}, nil
+}
+
+// NewFileCipherFromConfig creates a new instance of FileCipher from the secret
+// `forwarder_storage_encryption_key` or, when it is not set, from the keyfile
+// `forwarder_storage_encryption_keyfile`. When no keyfile is configured, a keyfile
+// is generated in storagePath the first time.
+func NewFileCipherFromConfig(storagePath string) (*FileCipher, error) {
+	return newFileCipherFromConfig(storagePath, true)
+}
+
+// LoadFileCipherFromConfig is like NewFileCipherFromConfig but returns an error instead
+// of generating a keyfile, as a new key cannot decrypt existing files.
+func LoadFileCipherFromConfig(storagePath string) (*FileCipher, error) {
+	return newFileCipherFromConfig(storagePath, false)
+}
+
+func newFileCipherFromConfig(storagePath string, createKeyfile bool) (*FileCipher, error) {
+	if secret := config.Datadog.GetString("forwarder_storage_encryption_key"); secret != "" {
+		return NewFileCipher([]byte(secret))
+	}
+
+	keyfile := config.Datadog.GetString("forwarder_storage_encryption_keyfile")
+	if keyfile == "" {
+		keyfile = filepath.Join(storagePath, defaultKeyFilename)
+	}
+	secret, err := readOrCreateKeyfile(keyfile, createKeyfile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the keyfile %s: %v", keyfile, err)
+	}
+	return NewFileCipher(secret)
+}
+
+// Encrypt returns the encrypted and authenticated content.
+func (c *FileCipher) Encrypt(content []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	headerSize := len(retryFileMagic) + nonceSize
+	out := make([]byte, headerSize, headerSize+len(content)+c.aead.Overhead())
+	copy(out, retryFileMagic)
+	if _, err := io.ReadFull(rand.Reader, out[len(retryFileMagic):headerSize]); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(out, out[len(retryFileMagic):headerSize], content, retryFileMagic), nil
+}
+
+// Decrypt returns the content of a file written by Encrypt. It returns
+// errRetryFileIntegrity if the file cannot be authenticated.
+func (c *FileCipher) Decrypt(data []byte) ([]byte, error) {
+	headerSize := len(retryFileMagic) + c.aead.NonceSize()
+	if len(data) < headerSize || !bytes.Equal(data[:len(retryFileMagic)], retryFileMagic) {
+		return nil, errRetryFileIntegrity
+	}
+	content, err := c.aead.Open(nil, data[len(retryFileMagic):headerSize], data[headerSize:], retryFileMagic)
+	if err != nil {
+		return nil, errRetryFileIntegrity
+	}
+	return content, nil
+}
+
+// deriveKey derives a 256-bit key from secret using HKDF-SHA256. A single block is
+// expanded as it is the size of the key.
+func deriveKey(secret []byte) []byte {
+	extract := hmac.New(sha256.New, []byte(keyDerivationSalt))
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(keyDerivationInfo))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+func readOrCreateKeyfile(keyfile string, create bool) ([]byte, error) {
+	secret, err := ioutil.ReadFile(keyfile)
+	if err == nil {
+		secret = []byte(strings.TrimSpace(string(secret)))
+		if len(secret) == 0 {
+			return nil, errors.New("the keyfile is empty")
+		}
+		return secret, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, err
+	}
+
+	secret = make([]byte, keyfileSecretSize)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+	secret = []byte(fmt.Sprintf("%x", secret))
+	if err := os.MkdirAll(filepath.Dir(keyfile), 0700); err != nil {
+		return nil, err
+	}
+	// O_EXCL makes sure a keyfile created concurrently is never overwritten.
+	f, err := os.OpenFile(keyfile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(secret); err != nil {
+		_ = f.Close()
+		_ = os.Remove(keyfile)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	log.Infof("Generated a new keyfile to encrypt the retry files: %s", keyfile)
+	return secret, nil
+}
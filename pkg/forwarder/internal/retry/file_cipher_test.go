@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCipher(t *testing.T) {
+	a := assert.New(t)
+	c, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+
+	content := []byte("content")
+	encrypted, err := c.Encrypt(content)
+	a.NoError(err)
+	a.NotContains(string(encrypted), string(content))
+
+	decrypted, err := c.Decrypt(encrypted)
+	a.NoError(err)
+	a.Equal(content, decrypted)
+
+	// The nonce is random
+	encryptedAgain, err := c.Encrypt(content)
+	a.NoError(err)
+	a.NotEqual(encrypted, encryptedAgain)
+}
+
+func TestFileCipherIntegrity(t *testing.T) {
+	a := assert.New(t)
+	c, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+	encrypted, err := c.Encrypt([]byte("content"))
+	a.NoError(err)
+
+	for i := range encrypted {
+		tampered := append([]byte{}, encrypted...)
+		tampered[i] ^= 0x01
+		_, err := c.Decrypt(tampered)
+		a.Equal(errRetryFileIntegrity, err, "byte %d", i)
+	}
+
+	_, err = c.Decrypt(encrypted[:len(encrypted)-1])
+	a.Equal(errRetryFileIntegrity, err)
+	_, err = c.Decrypt(nil)
+	a.Equal(errRetryFileIntegrity, err)
+
+	other, err := NewFileCipher([]byte("another secret"))
+	a.NoError(err)
+	_, err = other.Decrypt(encrypted)
+	a.Equal(errRetryFileIntegrity, err)
+
+	_, err = NewFileCipher(nil)
+	a.Error(err)
+}
+
+func TestNewFileCipherFromConfig(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+	defer config.Datadog.Set("forwarder_storage_encryption_key", "")
+	defer config.Datadog.Set("forwarder_storage_encryption_keyfile", "")
+
+	// A keyfile is generated in the storage path
+	c, err := NewFileCipherFromConfig(folder)
+	a.NoError(err)
+	keyfile := path.Join(folder, defaultKeyFilename)
+	info, err := os.Stat(keyfile)
+	a.NoError(err)
+	if os.PathSeparator == '/' {
+		a.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+	encrypted, err := c.Encrypt([]byte("content"))
+	a.NoError(err)
+
+	// and it is reused
+	c, err = NewFileCipherFromConfig(folder)
+	a.NoError(err)
+	_, err = c.Decrypt(encrypted)
+	a.NoError(err)
+
+	// A configured keyfile
+	config.Datadog.Set("forwarder_storage_encryption_keyfile", path.Join(folder, "custom.key"))
+	a.NoError(ioutil.WriteFile(path.Join(folder, "custom.key"), []byte("keyfile secret\n"), 0600))
+	c, err = NewFileCipherFromConfig(folder)
+	a.NoError(err)
+	_, err = c.Decrypt(encrypted)
+	a.Equal(errRetryFileIntegrity, err)
+	expected, err := NewFileCipher([]byte("keyfile secret"))
+	a.NoError(err)
+	encrypted, err = expected.Encrypt([]byte("content"))
+	a.NoError(err)
+	_, err = c.Decrypt(encrypted)
+	a.NoError(err)
+
+	// The secret takes precedence over the keyfile
+	config.Datadog.Set("forwarder_storage_encryption_key", "secret")
+	c, err = NewFileCipherFromConfig(folder)
+	a.NoError(err)
+	_, err = c.Decrypt(encrypted)
+	a.Equal(errRetryFileIntegrity, err)
+
+	// An empty keyfile is an error
+	config.Datadog.Set("forwarder_storage_encryption_key", "")
+	a.NoError(ioutil.WriteFile(path.Join(folder, "custom.key"), nil, 0600))
+	_, err = NewFileCipherFromConfig(folder)
+	a.Error(err)
+}
+
+func TestLoadFileCipherFromConfig(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	// No keyfile is generated
+	_, err := LoadFileCipherFromConfig(folder)
+	a.Error(err)
+	a.NoFileExists(path.Join(folder, defaultKeyFilename))
+
+	c, err := NewFileCipherFromConfig(folder)
+	a.NoError(err)
+	encrypted, err := c.Encrypt([]byte("content"))
+	a.NoError(err)
+
+	c, err = LoadFileCipherFromConfig(folder)
+	a.NoError(err)
+	content, err := c.Decrypt(encrypted)
+	a.NoError(err)
+	a.Equal([]byte("content"), content)
+}
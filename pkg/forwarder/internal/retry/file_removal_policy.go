@@ -109,14 +109,16 @@ func (p *FileRemovalPolicy) forEachDomainPath(callback func(folderPath string) (
 }
 
 func (p *FileRemovalPolicy) getFolderPathForDomain(domainName string) (string, error) {
+	return GetDomainFolderPath(p.rootPath, domainName)
+}
+
+func getDomainFolderName(domainName string) (string, error) {
 	// Use md5 for the folder name as the domainName is an url which can contain invalid charaters for a file path.
 	h := md5.New()
 	if _, err := io.WriteString(h, domainName); err != nil {
 		return "", err
 	}
-	folder := fmt.Sprintf("%x", h.Sum(nil))
-
-	return path.Join(p.rootPath, folder), nil
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
 func (p *FileRemovalPolicy) removeUnknownDomain(folderPath string) ([]string, error) {
@@ -128,13 +130,25 @@ func (p *FileRemovalPolicy) removeUnknownDomain(folderPath string) ([]string, er
 }
 
 func (p *FileRemovalPolicy) removeOutdatedRetryFiles(folderPath string) ([]string, error) {
-	return p.removeRetryFiles(folderPath, func(filename string) bool {
+	isOutdated := func(filename string) bool {
 		modTime, err := util.GetFileModTime(filename)
 		if err != nil {
 			return false
 		}
 		return modTime.Before(p.outdatedFileTime)
-	})
+	}
+	files, err := p.removeRetryFiles(folderPath, isOutdated)
+	if err != nil {
+		return files, err
+	}
+
+	// Quarantined files are kept for inspection until they are outdated.
+	quarantinePath := path.Join(folderPath, quarantineFolder)
+	if _, err := os.Stat(quarantinePath); err != nil {
+		return files, nil
+	}
+	quarantinedFiles, err := p.removeRetryFiles(quarantinePath, isOutdated)
+	return append(files, quarantinedFiles...), err
 }
 
 func (p *FileRemovalPolicy) removeRetryFiles(folderPath string, shouldRemove func(string) bool) ([]string, error) {
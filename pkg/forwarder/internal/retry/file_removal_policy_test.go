@@ -61,6 +61,29 @@ func TestFileRemovalPolicyOutdatedFiles(t *testing.T) {
 	assertFilenamesEqual(a, []string{file1, file3}, getRemainingFiles(a, root))
 }
 
+func TestFileRemovalPolicyOutdatedQuarantinedFiles(t *testing.T) {
+	a := assert.New(t)
+	root, clean := createTmpFolder(a)
+	defer clean()
+	p, err := NewFileRemovalPolicy(root, 2, FileRemovalPolicyTelemetry{})
+	a.NoError(err)
+
+	domain, err := p.RegisterDomain("domain")
+	a.NoError(err)
+
+	file1 := createRetryFile(a, domain, "file1")
+	file2 := createRetryFile(a, path.Join(domain, quarantineFolder), "file2")
+	file3 := createRetryFile(a, path.Join(domain, quarantineFolder), "file3")
+
+	modTime := time.Now().Add(time.Duration(-3*24) * time.Hour)
+	a.NoError(os.Chtimes(file2, modTime, modTime))
+
+	pathsRemoved, err := p.RemoveOutdatedFiles()
+	a.NoError(err)
+	assertFilenamesEqual(a, []string{file2}, pathsRemoved)
+	assertFilenamesEqual(a, []string{file1, file3}, getRemainingFiles(a, root))
+}
+
 func TestFileRemovalPolicyExistingDomain(t *testing.T) {
 	a := assert.New(t)
 	root, clean := createTmpFolder(a)
@@ -202,6 +202,28 @@ func toTransactionPriorityProto(priority transaction.Priority) (TransactionPrior
 	}
 }
 
+// MaskAPIKeyPlaceholders replaces the API key placeholders of a serialized transaction
+// by a printable mask containing the index of the API key.
+func MaskAPIKeyPlaceholders(str string) string {
+	var builder strings.Builder
+	for {
+		start := strings.Index(str, placeHolderPrefix)
+		if start < 0 {
+			break
+		}
+		index := str[start+len(placeHolderPrefix):]
+		end := strings.Index(index, squareChar)
+		if end < 0 {
+			break
+		}
+		builder.WriteString(str[:start])
+		builder.WriteString(fmt.Sprintf("***API_KEY_%s***", index[:end]))
+		str = index[end+len(squareChar):]
+	}
+	builder.WriteString(str)
+	return builder.String()
+}
+
 func createReplacers(apiKeys []string) (*strings.Replacer, *strings.Replacer) {
 	// Copy to not modify apiKeys order
 	keys := make([]string, len(apiKeys))
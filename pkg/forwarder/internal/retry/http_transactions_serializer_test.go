@@ -87,6 +87,15 @@ func TestHTTPTransactionSerializerMissingAPIKey(t *testing.T) {
 	r.Equal(1, errorCount)
 }
 
+func TestMaskAPIKeyPlaceholders(t *testing.T) {
+	serializer := NewHTTPTransactionsSerializer(domain, []string{apiKey1, apiKey2})
+	route := serializer.replaceAPIKeys("/api?api_key=" + apiKey2 + "&key=" + apiKey1)
+
+	assert.Equal(t, "/api?api_key=***API_KEY_1***&key=***API_KEY_0***", MaskAPIKeyPlaceholders(route))
+	assert.Equal(t, "value", MaskAPIKeyPlaceholders("value"))
+	assert.Equal(t, placeHolderPrefix+"0", MaskAPIKeyPlaceholders(placeHolderPrefix+"0"))
+}
+
 func TestHTTPTransactionFieldsCount(t *testing.T) {
 	tr := transaction.HTTPTransaction{}
 	transactionType := reflect.TypeOf(tr)
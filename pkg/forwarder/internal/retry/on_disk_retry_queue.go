@@ -17,13 +17,19 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/hashicorp/go-multierror"
 )
 
 const retryTransactionsExtension = ".retry"
 const retryFileFormat = "2006_01_02__15_04_05_"
 
+// quarantineFolder is the folder, relative to the storage path, where the retry files
+// which cannot be decrypted or deserialized are moved for later inspection.
+const quarantineFolder = "quarantine"
+
 type onDiskRetryQueue struct {
 	serializer         *HTTPTransactionsSerializer
+	cipher             *FileCipher
 	storagePath        string
 	diskUsageLimit     *diskUsageLimit
 	filenames          []string
@@ -33,6 +39,7 @@ type onDiskRetryQueue struct {
 
 func newOnDiskRetryQueue(
 	serializer *HTTPTransactionsSerializer,
+	cipher *FileCipher,
 	storagePath string,
 	diskUsageLimit *diskUsageLimit,
 	telemetry onDiskRetryQueueTelemetry) (*onDiskRetryQueue, error) {
@@ -43,6 +50,7 @@ func newOnDiskRetryQueue(
 
 	storage := &onDiskRetryQueue{
 		serializer:     serializer,
+		cipher:         cipher,
 		storagePath:    storagePath,
 		diskUsageLimit: diskUsageLimit,
 		telemetry:      telemetry,
@@ -63,17 +71,7 @@ func newOnDiskRetryQueue(
 func (s *onDiskRetryQueue) Serialize(transactions []transaction.Transaction) error {
 	s.telemetry.addSerializeCount()
 
-	// Reset the serializer in case some transactions were serialized
-	// but `GetBytesAndReset` was not called because of an error.
-	_, _ = s.serializer.GetBytesAndReset()
-
-	for _, t := range transactions {
-		if err := t.SerializeTo(s.serializer); err != nil {
-			return err
-		}
-	}
-
-	bytes, err := s.serializer.GetBytesAndReset()
+	bytes, err := s.serializeAndEncrypt(transactions)
 	if err != nil {
 		return err
 	}
@@ -112,25 +110,162 @@ func (s *onDiskRetryQueue) Deserialize() ([]transaction.Transaction, error) {
 	index := len(s.filenames) - 1
 	path := s.filenames[index]
 	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		// Remove the file even in case of a read failure.
+		if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
+			return nil, errRemoveFile
+		}
+		return nil, err
+	}
+
+	transactions, errorsCount, err := s.decryptAndDeserialize(bytes)
+	if err != nil {
+		if errQuarantine := s.quarantineFileAt(index); errQuarantine != nil {
+			log.Errorf("Cannot quarantine the retry file %s, removing it: %v", path, errQuarantine)
+			// Remove the file so that it is not read again on the next call.
+			if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
+				log.Errorf("Cannot remove the retry file %s: %v", path, errRemoveFile)
+			}
+		}
+		s.telemetry.setCurrentSizeInBytes(s.getCurrentSizeInBytes())
+		s.telemetry.setFilesCount(s.getFilesCount())
+		return nil, fmt.Errorf("cannot read the retry file %s: %v", path, err)
+	}
 
-	// Remove the file even in case of a read failure.
 	if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
 		return nil, errRemoveFile
 	}
 
+	s.telemetry.addDeserializeErrorsCount(errorsCount)
+	s.telemetry.addDeserializeTransactionsCount(len(transactions))
+	s.telemetry.setCurrentSizeInBytes(s.getCurrentSizeInBytes())
+	s.telemetry.setFilesCount(s.getFilesCount())
+	return transactions, nil
+}
+
+// List returns the transactions of the retry files, the oldest file first, without
+// removing them. The files which cannot be read are skipped.
+func (s *onDiskRetryQueue) List() ([]transaction.Transaction, error) {
+	var transactions []transaction.Transaction
+	for _, filename := range s.filenames {
+		fileTransactions, err := s.readFile(filename)
+		if err != nil {
+			log.Warnf("Cannot read the retry file %s: %v", filename, err)
+			continue
+		}
+		transactions = append(transactions, fileTransactions...)
+	}
+	return transactions, nil
+}
+
+// Extract removes the transactions matching filter from the retry files and returns them.
+// A file is removed when all its transactions are extracted, otherwise it is rewritten
+// with the remaining transactions.
+func (s *onDiskRetryQueue) Extract(filter func(transaction.Transaction) bool) ([]transaction.Transaction, error) {
+	var extracted []transaction.Transaction
+	var errs error
+	for i := len(s.filenames) - 1; i >= 0; i-- {
+		filename := s.filenames[i]
+		transactions, err := s.readFile(filename)
+		if err != nil {
+			log.Warnf("Cannot read the retry file %s: %v", filename, err)
+			continue
+		}
+
+		var kept []transaction.Transaction
+		for _, t := range transactions {
+			if filter(t) {
+				extracted = append(extracted, t)
+			} else {
+				kept = append(kept, t)
+			}
+		}
+
+		if len(kept) == len(transactions) {
+			continue
+		}
+		if len(kept) == 0 {
+			err = s.removeFileAt(i)
+		} else {
+			err = s.rewriteFile(filename, kept)
+		}
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	s.telemetry.setCurrentSizeInBytes(s.getCurrentSizeInBytes())
+	s.telemetry.setFilesCount(s.getFilesCount())
+	return extracted, errs
+}
+
+func (s *onDiskRetryQueue) readFile(filename string) ([]transaction.Transaction, error) {
+	bytes, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	transactions, _, err := s.decryptAndDeserialize(bytes)
+	return transactions, err
+}
+
+// rewriteFile replaces the content of a retry file by transactions. The modification
+// time is kept so the order of the files does not change when they are reloaded.
+func (s *onDiskRetryQueue) rewriteFile(filename string, transactions []transaction.Transaction) error {
+	bytes, err := s.serializeAndEncrypt(transactions)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
 
-	transactions, errorsCount, err := s.serializer.Deserialize(bytes)
+	file, err := ioutil.TempFile(s.storagePath, filepath.Base(filename)+"*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(bytes)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	_ = os.Chtimes(filename, info.ModTime(), info.ModTime())
+
+	s.currentSizeInBytes += int64(len(bytes)) - info.Size()
+	return nil
+}
+
+func (s *onDiskRetryQueue) serializeAndEncrypt(transactions []transaction.Transaction) ([]byte, error) {
+	// Reset the serializer in case some transactions were serialized
+	// but `GetBytesAndReset` was not called because of an error.
+	_, _ = s.serializer.GetBytesAndReset()
+
+	for _, t := range transactions {
+		if err := t.SerializeTo(s.serializer); err != nil {
+			return nil, err
+		}
+	}
+
+	bytes, err := s.serializer.GetBytesAndReset()
 	if err != nil {
 		return nil, err
 	}
-	s.telemetry.addDeserializeErrorsCount(errorsCount)
-	s.telemetry.addDeserializeTransactionsCount(len(transactions))
-	s.telemetry.setCurrentSizeInBytes(s.getCurrentSizeInBytes())
-	s.telemetry.setFilesCount(s.getFilesCount())
-	return transactions, err
+	return s.cipher.Encrypt(bytes)
+}
+
+func (s *onDiskRetryQueue) decryptAndDeserialize(bytes []byte) ([]transaction.Transaction, int, error) {
+	content, err := s.cipher.Decrypt(bytes)
+	if err != nil {
+		s.telemetry.addDecryptionErrorsCount()
+		return nil, 0, err
+	}
+	return s.serializer.Deserialize(content)
 }
 
 // GetFileCount returns the current files count.
@@ -186,6 +321,33 @@ func (s *onDiskRetryQueue) removeFileAt(index int) error {
 	return nil
 }
 
+// quarantineFileAt moves a retry file to the quarantine folder instead of removing it,
+// so it can be inspected later. The file is not taken into account by the disk limits
+// anymore and is removed with the other outdated files. The file is still tracked
+// if it cannot be moved.
+func (s *onDiskRetryQueue) quarantineFileAt(index int) error {
+	filename := s.filenames[index]
+
+	size, err := util.GetFileSize(filename)
+	if err != nil {
+		return err
+	}
+
+	quarantinePath := path.Join(s.storagePath, quarantineFolder)
+	if err := os.MkdirAll(quarantinePath, 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(filename, path.Join(quarantinePath, filepath.Base(filename))); err != nil {
+		return err
+	}
+
+	s.filenames = append(s.filenames[:index], s.filenames[index+1:]...)
+	s.currentSizeInBytes -= size
+	s.telemetry.addQuarantinedFilesCount()
+	log.Warnf("The retry file %s cannot be read and was moved to %s", filename, quarantinePath)
+	return nil
+}
+
 func (s *onDiskRetryQueue) reloadExistingRetryFiles() error {
 	files, sizeInBytes, err := s.getExistingRetryFiles()
 	if err != nil {
@@ -196,16 +358,46 @@ func (s *onDiskRetryQueue) reloadExistingRetryFiles() error {
 	sort.Slice(files, func(i, j int) bool {
 		return files[i].ModTime().Before(files[j].ModTime())
 	})
-	var filenames []string
 	for _, file := range files {
 		fullPath := path.Join(s.storagePath, file.Name())
-		filenames = append(filenames, fullPath)
+		s.filenames = append(s.filenames, fullPath)
+	}
+
+	// Check the integrity of the retry files now rather than when they are deserialized
+	// to report the files written by another key or modified outside of the Agent.
+	quarantinedCount := 0
+	for i := len(s.filenames) - 1; i >= 0; i-- {
+		if s.isValidRetryFile(s.filenames[i]) {
+			continue
+		}
+		filename := s.filenames[i]
+		if err := s.quarantineFileAt(i); err != nil {
+			log.Errorf("Cannot quarantine the retry file %s: %v", filename, err)
+			continue
+		}
+		quarantinedCount++
 	}
-	s.telemetry.setReloadedRetryFilesCount(len(filenames))
-	s.filenames = append(s.filenames, filenames...)
+	if quarantinedCount > 0 {
+		log.Warnf("%d retry files cannot be decrypted and were quarantined in %s", quarantinedCount, path.Join(s.storagePath, quarantineFolder))
+	}
+
+	s.telemetry.setReloadedRetryFilesCount(len(s.filenames))
 	return nil
 }
 
+func (s *onDiskRetryQueue) isValidRetryFile(filename string) bool {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		// The file is removed when it is deserialized.
+		return true
+	}
+	if _, err := s.cipher.Decrypt(bytes); err != nil {
+		s.telemetry.addDecryptionErrorsCount()
+		return false
+	}
+	return true
+}
+
 func (s *onDiskRetryQueue) getExistingRetryFiles() ([]os.FileInfo, int64, error) {
 	entries, err := ioutil.ReadDir(s.storagePath)
 	if err != nil {
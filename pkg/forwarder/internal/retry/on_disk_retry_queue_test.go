@@ -8,10 +8,12 @@ package retry
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"strconv"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
 	"github.com/stretchr/testify/assert"
 )
@@ -47,7 +49,7 @@ func TestOnDiskRetryQueueMaxSize(t *testing.T) {
 	path, clean := createTmpFolder(a)
 	defer clean()
 
-	maxSizeInBytes := int64(100)
+	maxSizeInBytes := int64(200)
 	q := newTestOnDiskRetryQueue(a, path, maxSizeInBytes)
 
 	i := 0
@@ -90,6 +92,150 @@ func TestOnDiskRetryQueueReloadExistingRetryFiles(t *testing.T) {
 	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
 }
 
+func TestOnDiskRetryQueueEncryptsFiles(t *testing.T) {
+	a := assert.New(t)
+	path, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, path, 1000)
+	tr := transaction.NewHTTPTransaction()
+	tr.Domain = domainName
+	tr.Endpoint.Name = "endpoint1"
+	payload := []byte("a secret payload")
+	tr.Payload = &payload
+	a.NoError(q.Serialize([]transaction.Transaction{tr}))
+
+	content, err := ioutil.ReadFile(q.filenames[0])
+	a.NoError(err)
+	a.NotContains(string(content), "a secret payload")
+	a.NotContains(string(content), "endpoint1")
+}
+
+func TestOnDiskRetryQueueReloadQuarantinesInvalidFiles(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, folder, 1000)
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1")))
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint2")))
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint3")))
+
+	// Tamper with the second file and replace the third one by a plain text file.
+	tampered := q.filenames[1]
+	content, err := ioutil.ReadFile(tampered)
+	a.NoError(err)
+	content[len(content)-1] ^= 0xff
+	a.NoError(ioutil.WriteFile(tampered, content, 0600))
+	plainText := q.filenames[2]
+	a.NoError(ioutil.WriteFile(plainText, []byte("plain text"), 0600))
+
+	newQueue := newTestOnDiskRetryQueue(a, folder, 1000)
+	a.Equal(1, newQueue.getFilesCount())
+	a.Equal(q.filenames[:1], newQueue.filenames)
+	size, err := util.GetFileSize(q.filenames[0])
+	a.NoError(err)
+	a.Equal(size, newQueue.getCurrentSizeInBytes())
+	a.FileExists(path.Join(folder, quarantineFolder, path.Base(tampered)))
+	a.FileExists(path.Join(folder, quarantineFolder, path.Base(plainText)))
+
+	transactions, err := newQueue.Deserialize()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1"}, getEndpointsFromTransactions(transactions))
+}
+
+func TestOnDiskRetryQueueDeserializeQuarantinesInvalidFile(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, folder, 1000)
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1")))
+	filename := q.filenames[0]
+
+	// A file encrypted with another key cannot be authenticated.
+	otherCipher, err := NewFileCipher([]byte("another secret"))
+	a.NoError(err)
+	content, err := otherCipher.Encrypt([]byte("content"))
+	a.NoError(err)
+	a.NoError(ioutil.WriteFile(filename, content, 0600))
+
+	transactions, err := q.Deserialize()
+	a.Error(err)
+	a.Nil(transactions)
+	a.Equal(0, q.getFilesCount())
+	a.NoFileExists(filename)
+	a.FileExists(path.Join(folder, quarantineFolder, path.Base(filename)))
+}
+
+func TestOnDiskRetryQueueQuarantineFailure(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, folder, 1000)
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1")))
+	filename := q.filenames[0]
+	a.NoError(ioutil.WriteFile(filename, []byte("plain text"), 0600))
+	size, err := util.GetFileSize(filename)
+	a.NoError(err)
+	q.currentSizeInBytes = size
+
+	// The quarantine folder cannot be created when a file has its name.
+	a.NoError(ioutil.WriteFile(path.Join(folder, quarantineFolder), nil, 0600))
+
+	a.Error(q.quarantineFileAt(0))
+	a.Equal([]string{filename}, q.filenames)
+	a.Equal(size, q.getCurrentSizeInBytes())
+
+	// Deserialize removes the file instead, so that it is not read again.
+	_, err = q.Deserialize()
+	a.Error(err)
+	a.Equal(0, q.getFilesCount())
+	a.Equal(int64(0), q.getCurrentSizeInBytes())
+	a.NoFileExists(filename)
+}
+
+func TestOnDiskRetryQueueExtract(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, folder, 1000)
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1")))
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint3")))
+	firstFile := q.filenames[0]
+	info, err := os.Stat(firstFile)
+	a.NoError(err)
+
+	transactions, err := q.List()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint2", "endpoint1", "endpoint3"}, getEndpointsFromTransactions(transactions))
+
+	transactions, err = q.Extract(func(t transaction.Transaction) bool {
+		return t.GetEndpointName() == "endpoint1"
+	})
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint1"}, getEndpointsFromTransactions(transactions))
+
+	// The second file is removed and the first one is rewritten in place
+	a.Equal(2, q.getFilesCount())
+	a.Equal(firstFile, q.filenames[0])
+	newInfo, err := os.Stat(firstFile)
+	a.NoError(err)
+	a.Equal(info.ModTime(), newInfo.ModTime())
+	size, err := util.GetFileSize(q.filenames[0])
+	a.NoError(err)
+	size2, err := util.GetFileSize(q.filenames[1])
+	a.NoError(err)
+	a.Equal(size+size2, q.getCurrentSizeInBytes())
+
+	transactions, err = q.List()
+	a.NoError(err)
+	a.Equal([]string{"endpoint2", "endpoint3"}, getEndpointsFromTransactions(transactions))
+}
+
 func createHTTPTransactionCollectionTests(endpoints ...string) []transaction.Transaction {
 	var transactions []transaction.Transaction
 
@@ -105,7 +251,7 @@ func createHTTPTransactionCollectionTests(endpoints ...string) []transaction.Tra
 func createTmpFolder(a *assert.Assertions) (string, func()) {
 	path, err := ioutil.TempDir("", "tests")
 	a.NoError(err)
-	return path, func() { _ = os.Remove(path) }
+	return path, func() { _ = os.RemoveAll(path) }
 }
 
 func getEndpointsFromTransactions(transactions []transaction.Transaction) []string {
@@ -125,7 +271,9 @@ func newTestOnDiskRetryQueue(a *assert.Assertions, path string, maxSizeInBytes i
 			Total:     10000,
 		}}
 	diskUsageLimit := newDiskUsageLimit("", disk, maxSizeInBytes, 1)
-	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(domainName, nil), path, diskUsageLimit, telemetry)
+	cipher, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(domainName, nil), cipher, path, diskUsageLimit, telemetry)
 	a.NoError(err)
 	return storage
 }
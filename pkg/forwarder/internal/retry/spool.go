@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"io/ioutil"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Spool stores the transactions of a domain on disk instead of sending them. The spool
+// files use the same format as the retry files so they can be uploaded later, from
+// another host, with the same API keys.
+type Spool struct {
+	queue *onDiskRetryQueue
+	m     sync.Mutex
+}
+
+// NewSpool creates a new instance of Spool. When maxSizeInBytes is reached, the oldest
+// spool files are removed.
+func NewSpool(
+	domainFolderPath string,
+	maxSizeInBytes int64,
+	cipher *FileCipher,
+	domain string,
+	apiKeys []string) (*Spool, error) {
+	serializer := NewHTTPTransactionsSerializer(domain, apiKeys)
+	diskRatio := config.Datadog.GetFloat64("forwarder_storage_max_disk_ratio")
+	diskUsageLimit := newDiskUsageLimit(domainFolderPath, filesystem.NewDisk(), maxSizeInBytes, diskRatio)
+
+	queue, err := newOnDiskRetryQueue(serializer, cipher, domainFolderPath, diskUsageLimit, newOnDiskRetryQueueTelemetry(domain))
+	if err != nil {
+		return nil, err
+	}
+	return &Spool{queue: queue}, nil
+}
+
+// Store writes the transactions to a new spool file.
+func (s *Spool) Store(transactions []transaction.Transaction) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.queue.Serialize(transactions)
+}
+
+// GetFilesCount returns the number of spool files.
+func (s *Spool) GetFilesCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.queue.getFilesCount()
+}
+
+// GetDomainFolderPath returns the folder of rootPath used to store the files of domainName.
+func GetDomainFolderPath(rootPath string, domainName string) (string, error) {
+	folder, err := getDomainFolderName(domainName)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(rootPath, folder), nil
+}
+
+// ListSpoolFiles returns the spool files of a domain folder, the oldest first.
+func ListSpoolFiles(domainFolderPath string) ([]string, error) {
+	queue := &onDiskRetryQueue{storagePath: domainFolderPath}
+	files, _, err := queue.getExistingRetryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	filenames := make([]string, 0, len(files))
+	for _, file := range files {
+		filenames = append(filenames, path.Join(domainFolderPath, file.Name()))
+	}
+	return filenames, nil
+}
+
+// ReadSpoolFile returns the raw content of a spool file. The API keys are replaced by
+// placeholders in the routes and in the headers.
+func ReadSpoolFile(filename string, cipher *FileCipher) (*HttpTransactionProtoCollection, error) {
+	content, err := readAndDecrypt(filename, cipher)
+	if err != nil {
+		return nil, err
+	}
+	collection := &HttpTransactionProtoCollection{}
+	if err := proto.Unmarshal(content, collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// DeserializeSpoolFile returns the transactions of a spool file for domain. apiKeys must
+// be the API keys configured for the domain when the file was written. It also returns
+// the number of transactions which cannot be restored.
+func DeserializeSpoolFile(filename string, cipher *FileCipher, domain string, apiKeys []string) ([]transaction.Transaction, int, error) {
+	content, err := readAndDecrypt(filename, cipher)
+	if err != nil {
+		return nil, 0, err
+	}
+	return NewHTTPTransactionsSerializer(domain, apiKeys).Deserialize(content)
+}
+
+func readAndDecrypt(filename string, cipher *FileCipher) ([]byte, error) {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.Decrypt(bytes)
+}
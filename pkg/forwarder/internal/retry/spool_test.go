@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpool(t *testing.T) {
+	a := assert.New(t)
+	root, clean := createTmpFolder(a)
+	defer clean()
+	cipher, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+
+	folder, err := GetDomainFolderPath(root, domainName)
+	a.NoError(err)
+	spool, err := NewSpool(folder, 10000, cipher, domainName, []string{"api_key1", "api_key2"})
+	a.NoError(err)
+
+	tr := transaction.NewHTTPTransaction()
+	tr.Domain = domainName
+	tr.Endpoint = transaction.Endpoint{Route: "/api/v1/series?api_key=api_key2", Name: "series_v1"}
+	tr.Headers.Set("DD-Api-Key", "api_key2")
+	tr.CreatedAt = time.Unix(1600000000, 0)
+	a.NoError(spool.Store([]transaction.Transaction{tr}))
+	files, err := ListSpoolFiles(folder)
+	a.NoError(err)
+	a.Len(files, 1)
+	a.NoError(os.Chtimes(files[0], time.Now(), time.Now().Add(-time.Hour)))
+
+	a.NoError(spool.Store(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+	a.Equal(2, spool.GetFilesCount())
+
+	// The files are sorted by modification time
+	files, err = ListSpoolFiles(folder)
+	a.NoError(err)
+	a.Len(files, 2)
+
+	// The API keys are not stored in the spool files
+	collection, err := ReadSpoolFile(files[0], cipher)
+	a.NoError(err)
+	a.Len(collection.Values, 1)
+	a.Equal("/api/v1/series?api_key="+placeHolderPrefix+"1"+squareChar, collection.Values[0].Endpoint.Route)
+	a.Equal(int64(1600000000), collection.Values[0].CreatedAt)
+
+	// The transactions are restored with the API keys of the domain
+	transactions, errorsCount, err := DeserializeSpoolFile(files[0], cipher, "other_domain", []string{"api_key2", "api_key1"})
+	a.NoError(err)
+	a.Equal(0, errorsCount)
+	a.Len(transactions, 1)
+	restored := transactions[0].(*transaction.HTTPTransaction)
+	a.Equal("other_domain", restored.Domain)
+	a.Equal("/api/v1/series?api_key=api_key2", restored.Endpoint.Route)
+	a.Equal("api_key2", restored.Headers.Get("DD-Api-Key"))
+	a.Equal(tr.CreatedAt, restored.CreatedAt)
+
+	transactions, _, err = DeserializeSpoolFile(files[1], cipher, domainName, nil)
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
+
+	// A spool file cannot be read with another key
+	otherCipher, err := NewFileCipher([]byte("other secret"))
+	a.NoError(err)
+	_, err = ReadSpoolFile(files[0], otherCipher)
+	a.Equal(errRetryFileIntegrity, err)
+}
+
+func TestSpoolReload(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+	cipher, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+
+	spool, err := NewSpool(folder, 10000, cipher, domainName, nil)
+	a.NoError(err)
+	a.NoError(spool.Store(createHTTPTransactionCollectionTests("endpoint1")))
+
+	spool, err = NewSpool(folder, 10000, cipher, domainName, nil)
+	a.NoError(err)
+	a.Equal(1, spool.GetFilesCount())
+}
+
+func TestListSpoolFilesIgnoresOtherFiles(t *testing.T) {
+	a := assert.New(t)
+	folder, clean := createTmpFolder(a)
+	defer clean()
+
+	createRetryFile(a, folder, "file1")
+	createFile(a, folder, "file2.txt")
+	a.NoError(os.Mkdir(path.Join(folder, quarantineFolder), 0700))
+	createRetryFile(a, path.Join(folder, quarantineFolder), "file3")
+
+	files, err := ListSpoolFiles(folder)
+	a.NoError(err)
+	assertFilenamesEqual(a, []string{"file1.retry"}, files)
+}
@@ -74,6 +74,8 @@ var (
 	filesRemovedCountTelemetry              *counterExpvar
 	deserializeErrorsCountTelemetry         *counterExpvar
 	deserializeTransactionsCountTelemetry   *counterExpvar
+	decryptionErrorsCountTelemetry          *counterExpvar
+	quarantinedFilesCountTelemetry          *counterExpvar
 )
 
 func init() {
@@ -185,6 +187,18 @@ func init() {
 		domainTag,
 		"The number of transactions read from the disk",
 		&fileStorageExpvar)
+	decryptionErrorsCountTelemetry = newCounterExpvar(
+		"file_storage",
+		"decryption_errors_count",
+		domainTag,
+		"The number of retry files which cannot be decrypted or authenticated",
+		&fileStorageExpvar)
+	quarantinedFilesCountTelemetry = newCounterExpvar(
+		"file_storage",
+		"quarantined_files_count",
+		domainTag,
+		"The number of retry files moved to the quarantine folder",
+		&fileStorageExpvar)
 }
 
 // FileRemovalPolicyTelemetry handles the telemetry for FileRemovalPolicy.
@@ -279,6 +293,14 @@ func (t onDiskRetryQueueTelemetry) addDeserializeTransactionsCount(count int) {
 	deserializeTransactionsCountTelemetry.add(float64(count), t.domainName)
 }
 
+func (t onDiskRetryQueueTelemetry) addDecryptionErrorsCount() {
+	decryptionErrorsCountTelemetry.add(1, t.domainName)
+}
+
+func (t onDiskRetryQueueTelemetry) addQuarantinedFilesCount() {
+	quarantinedFilesCountTelemetry.add(1, t.domainName)
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	var camelCase string
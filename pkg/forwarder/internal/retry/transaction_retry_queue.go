@@ -20,6 +20,8 @@ import (
 type TransactionSerializer interface {
 	Serialize([]transaction.Transaction) error
 	Deserialize() ([]transaction.Transaction, error)
+	List() ([]transaction.Transaction, error)
+	Extract(filter func(transaction.Transaction) bool) ([]transaction.Transaction, error)
 }
 
 // TransactionPrioritySorter is an interface to sort transactions.
@@ -46,18 +48,20 @@ func BuildTransactionRetryQueue(
 	flushToStorageRatio float64,
 	optionalDomainFolderPath string,
 	storageMaxSize int64,
+	optionalFileCipher *FileCipher,
 	dropPrioritySorter TransactionPrioritySorter,
 	domain string,
 	apiKeys []string) *TransactionRetryQueue {
 	var storage TransactionSerializer
 	var err error
 
-	if optionalDomainFolderPath != "" && storageMaxSize > 0 {
+	// Transactions are never stored on disk without encryption as they contain API keys.
+	if optionalDomainFolderPath != "" && storageMaxSize > 0 && optionalFileCipher != nil {
 		serializer := NewHTTPTransactionsSerializer(domain, apiKeys)
 		diskRatio := config.Datadog.GetFloat64("forwarder_storage_max_disk_ratio")
 
 		diskUsageLimit := newDiskUsageLimit(optionalDomainFolderPath, filesystem.NewDisk(), storageMaxSize, diskRatio)
-		storage, err = newOnDiskRetryQueue(serializer, optionalDomainFolderPath, diskUsageLimit, newOnDiskRetryQueueTelemetry(domain))
+		storage, err = newOnDiskRetryQueue(serializer, optionalFileCipher, optionalDomainFolderPath, diskUsageLimit, newOnDiskRetryQueueTelemetry(domain))
 
 		// If the storage on disk cannot be used, log the error and continue.
 		// Returning `nil, err` would mean not using `TransactionRetryQueue` and so not using `forwarder_retry_queue_payloads_max_size` config.
@@ -162,6 +166,55 @@ func (tc *TransactionRetryQueue) ExtractTransactions() ([]transaction.Transactio
 	return transactions, nil
 }
 
+// ListTransactions returns the transactions in memory and the transactions stored on disk
+// without removing them.
+func (tc *TransactionRetryQueue) ListTransactions() ([]transaction.Transaction, []transaction.Transaction, error) {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	inMemory := make([]transaction.Transaction, len(tc.transactions))
+	copy(inMemory, tc.transactions)
+
+	var onDisk []transaction.Transaction
+	var err error
+	if tc.optionalTransactionSerializer != nil {
+		onDisk, err = tc.optionalTransactionSerializer.List()
+	}
+	return inMemory, onDisk, err
+}
+
+// ExtractTransactionsMatching removes the transactions matching filter, in memory
+// and on disk, and returns them.
+func (tc *TransactionRetryQueue) ExtractTransactionsMatching(filter func(t transaction.Transaction, onDisk bool) bool) ([]transaction.Transaction, error) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	var extracted []transaction.Transaction
+	var kept []transaction.Transaction
+	for _, t := range tc.transactions {
+		if filter(t, false) {
+			extracted = append(extracted, t)
+			tc.currentMemSizeInBytes -= t.GetPayloadSize()
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	tc.transactions = kept
+	tc.telemetry.setCurrentMemSizeInBytes(tc.currentMemSizeInBytes)
+	tc.telemetry.setTransactionsCount(len(tc.transactions))
+
+	if tc.optionalTransactionSerializer == nil {
+		return extracted, nil
+	}
+	onDisk, err := tc.optionalTransactionSerializer.Extract(func(t transaction.Transaction) bool {
+		return filter(t, true)
+	})
+	if err != nil {
+		tc.telemetry.incErrorsCount()
+	}
+	return append(extracted, onDisk...), err
+}
+
 // GetCurrentMemSizeInBytes gets the current memory usage in bytes
 func (tc *TransactionRetryQueue) getCurrentMemSizeInBytes() int {
 	tc.mutex.RLock()
@@ -96,6 +96,70 @@ func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 	a.Equal(1, inMemTrDropped)
 }
 
+func TestTransactionRetryQueueListTransactions(t *testing.T) {
+	a := assert.New(t)
+	q, clean := newOnDiskRetryQueueTest(a)
+	defer clean()
+
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 50, 0.1, NewTransactionRetryQueueTelemetry("domain"))
+	for _, payloadSize := range []int{9, 10, 11, 40} {
+		container.Add(createTransactionWithPayloadSize(payloadSize))
+	}
+
+	inMemory, onDisk, err := container.ListTransactions()
+	a.NoError(err)
+	a.Equal([]int{40}, getPayloadSizes(inMemory))
+	a.Equal([]int{9, 10, 11}, getPayloadSizes(onDisk))
+
+	// The transactions are not removed
+	a.Equal(1, container.GetTransactionCount())
+	a.Equal(3, q.getFilesCount())
+}
+
+func TestTransactionRetryQueueExtractTransactionsMatching(t *testing.T) {
+	a := assert.New(t)
+	q, clean := newOnDiskRetryQueueTest(a)
+	defer clean()
+
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 50, 0.6, NewTransactionRetryQueueTelemetry("domain"))
+	// Flush [9, 10, 11] and [12] to disk when adding `40`
+	for _, payloadSize := range []int{9, 10, 11, 12, 40} {
+		container.Add(createTransactionWithPayloadSize(payloadSize))
+	}
+	a.Equal(2, q.getFilesCount())
+
+	// Extract the transactions with an even payload size
+	transactions, err := container.ExtractTransactionsMatching(func(t transaction.Transaction, onDisk bool) bool {
+		return t.GetPayloadSize()%2 == 0
+	})
+	a.NoError(err)
+	a.ElementsMatch([]int{10, 12, 40}, getPayloadSizes(transactions))
+	a.Equal(0, container.getCurrentMemSizeInBytes())
+
+	inMemory, onDisk, err := container.ListTransactions()
+	a.NoError(err)
+	a.Empty(inMemory)
+	a.Equal([]int{9, 11}, getPayloadSizes(onDisk))
+	a.Equal(1, q.getFilesCount())
+
+	// Extract the transactions on disk only
+	transactions, err = container.ExtractTransactionsMatching(func(t transaction.Transaction, onDisk bool) bool {
+		return onDisk
+	})
+	a.NoError(err)
+	a.Equal([]int{9, 11}, getPayloadSizes(transactions))
+	a.Equal(0, q.getFilesCount())
+	a.Equal(int64(0), q.getCurrentSizeInBytes())
+}
+
+func getPayloadSizes(transactions []transaction.Transaction) []int {
+	var payloadSizes []int
+	for _, t := range transactions {
+		payloadSizes = append(payloadSizes, t.GetPayloadSize())
+	}
+	return payloadSizes
+}
+
 func createTransactionWithPayloadSize(payloadSize int) *transaction.HTTPTransaction {
 	tr := transaction.NewHTTPTransaction()
 	payload := make([]byte, payloadSize)
@@ -131,7 +195,9 @@ func newOnDiskRetryQueueTest(a *assert.Assertions) (*onDiskRetryQueue, func()) {
 			Total:     10000,
 		}}
 	diskUsageLimit := newDiskUsageLimit("", disk, 1000, 1)
-	q, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer("", nil), path, diskUsageLimit, newOnDiskRetryQueueTelemetry("domain"))
+	cipher, err := NewFileCipher([]byte("secret"))
+	a.NoError(err)
+	q, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer("", nil), cipher, path, diskUsageLimit, newOnDiskRetryQueueTelemetry("domain"))
 	a.NoError(err)
 	return q, clean
 }
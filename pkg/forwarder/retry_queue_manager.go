@@ -0,0 +1,298 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// RetryQueueLocationMemory is the location of the transactions of a retry queue kept in memory.
+	RetryQueueLocationMemory = "memory"
+	// RetryQueueLocationDisk is the location of the transactions of a retry queue stored on disk.
+	RetryQueueLocationDisk = "disk"
+)
+
+// RetryQueueManager is implemented by the forwarders whose retry queues can be
+// inspected and managed at runtime.
+type RetryQueueManager interface {
+	// ListRetryQueue returns the transactions of the retry queues matching filter.
+	ListRetryQueue(filter RetryQueueFilter) ([]RetryQueueTransaction, error)
+	// PurgeRetryQueue removes the transactions of the retry queues matching filter.
+	PurgeRetryQueue(filter RetryQueueFilter) (int, error)
+	// RetryRetryQueue sends again, without waiting for the backoff, the transactions of the
+	// retry queues matching filter.
+	RetryRetryQueue(filter RetryQueueFilter) (int, error)
+}
+
+// Compile-time check to ensure that DefaultForwarder implements the RetryQueueManager interface
+var _ RetryQueueManager = &DefaultForwarder{}
+
+// RetryQueueFilter selects the transactions of the retry queues. The empty fields
+// match every transaction.
+type RetryQueueFilter struct {
+	// Domain is a configured domain or the domain of a domainForwarder.
+	Domain   string
+	Endpoint string
+	// Priority is "normal" or "high".
+	Priority string
+	// Location is RetryQueueLocationMemory or RetryQueueLocationDisk.
+	Location  string
+	OlderThan time.Duration
+	NewerThan time.Duration
+}
+
+// RetryQueueTransaction describes a transaction of a retry queue.
+type RetryQueueTransaction struct {
+	Domain      string    `json:"domain"`
+	Endpoint    string    `json:"endpoint"`
+	Priority    string    `json:"priority"`
+	Location    string    `json:"location"`
+	CreatedAt   time.Time `json:"created_at"`
+	PayloadSize int       `json:"payload_size"`
+}
+
+// RetryQueueFilterFromQuery returns the filter encoded in query by RetryQueueFilter.ToQuery.
+func RetryQueueFilterFromQuery(query url.Values) (RetryQueueFilter, error) {
+	filter := RetryQueueFilter{
+		Domain:   query.Get("domain"),
+		Endpoint: query.Get("endpoint"),
+		Priority: query.Get("priority"),
+		Location: query.Get("location"),
+	}
+
+	switch filter.Priority {
+	case "", transaction.TransactionPriorityNormal.String(), transaction.TransactionPriorityHigh.String():
+	default:
+		return filter, fmt.Errorf("invalid priority %q: the priority must be %q or %q", filter.Priority,
+			transaction.TransactionPriorityNormal, transaction.TransactionPriorityHigh)
+	}
+	switch filter.Location {
+	case "", RetryQueueLocationMemory, RetryQueueLocationDisk:
+	default:
+		return filter, fmt.Errorf("invalid location %q: the location must be %q or %q", filter.Location,
+			RetryQueueLocationMemory, RetryQueueLocationDisk)
+	}
+
+	var err error
+	if olderThan := query.Get("older_than"); olderThan != "" {
+		if filter.OlderThan, err = time.ParseDuration(olderThan); err != nil {
+			return filter, fmt.Errorf("invalid older_than: %v", err)
+		}
+	}
+	if newerThan := query.Get("newer_than"); newerThan != "" {
+		if filter.NewerThan, err = time.ParseDuration(newerThan); err != nil {
+			return filter, fmt.Errorf("invalid newer_than: %v", err)
+		}
+	}
+	return filter, nil
+}
+
+// ToQuery encodes the filter in a query string.
+func (filter RetryQueueFilter) ToQuery() url.Values {
+	query := url.Values{}
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	setIfNotEmpty("domain", filter.Domain)
+	setIfNotEmpty("endpoint", filter.Endpoint)
+	setIfNotEmpty("priority", filter.Priority)
+	setIfNotEmpty("location", filter.Location)
+	if filter.OlderThan != 0 {
+		query.Set("older_than", filter.OlderThan.String())
+	}
+	if filter.NewerThan != 0 {
+		query.Set("newer_than", filter.NewerThan.String())
+	}
+	return query
+}
+
+// IsEmpty returns whether the filter matches every transaction.
+func (filter RetryQueueFilter) IsEmpty() bool {
+	return filter == RetryQueueFilter{}
+}
+
+func (filter RetryQueueFilter) matchDomain(domain string) bool {
+	if filter.Domain == "" || filter.Domain == domain {
+		return true
+	}
+	versionDomain, _ := config.AddAgentVersionToDomain(filter.Domain, "app")
+	return versionDomain == domain
+}
+
+func (filter RetryQueueFilter) match(t transaction.Transaction, onDisk bool, now time.Time) bool {
+	if filter.Endpoint != "" && filter.Endpoint != t.GetEndpointName() {
+		return false
+	}
+	if filter.Priority != "" && filter.Priority != t.GetPriority().String() {
+		return false
+	}
+	if filter.Location != "" && filter.Location != getRetryQueueLocation(onDisk) {
+		return false
+	}
+	age := now.Sub(t.GetCreatedAt())
+	if filter.OlderThan != 0 && age < filter.OlderThan {
+		return false
+	}
+	if filter.NewerThan != 0 && age > filter.NewerThan {
+		return false
+	}
+	return true
+}
+
+func getRetryQueueLocation(onDisk bool) string {
+	if onDisk {
+		return RetryQueueLocationDisk
+	}
+	return RetryQueueLocationMemory
+}
+
+// ListRetryQueue returns the transactions of the retry queues matching filter.
+func (f *DefaultForwarder) ListRetryQueue(filter RetryQueueFilter) ([]RetryQueueTransaction, error) {
+	var transactions []RetryQueueTransaction
+	err := f.forEachDomainForwarder(filter, func(df *domainForwarder) error {
+		domainTransactions, err := df.listRetryQueue(filter)
+		transactions = append(transactions, domainTransactions...)
+		return err
+	})
+	return transactions, err
+}
+
+// PurgeRetryQueue removes the transactions of the retry queues matching filter.
+func (f *DefaultForwarder) PurgeRetryQueue(filter RetryQueueFilter) (int, error) {
+	count := 0
+	err := f.forEachDomainForwarder(filter, func(df *domainForwarder) error {
+		purged, err := df.purgeRetryQueue(filter)
+		count += purged
+		return err
+	})
+	return count, err
+}
+
+// RetryRetryQueue sends again the transactions of the retry queues matching filter. The
+// endpoints of these transactions are unblocked.
+func (f *DefaultForwarder) RetryRetryQueue(filter RetryQueueFilter) (int, error) {
+	count := 0
+	err := f.forEachDomainForwarder(filter, func(df *domainForwarder) error {
+		retried, err := df.retryRetryQueue(filter)
+		count += retried
+		return err
+	})
+	return count, err
+}
+
+func (f *DefaultForwarder) forEachDomainForwarder(filter RetryQueueFilter, callback func(df *domainForwarder) error) error {
+	// Lock so the domain forwarders are not replaced while they are used
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	var errs error
+	for domain, df := range f.domainForwarders {
+		if !filter.matchDomain(domain) {
+			continue
+		}
+		if err := callback(df); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %v", domain, err))
+		}
+	}
+	return errs
+}
+
+func (f *domainForwarder) listRetryQueue(filter RetryQueueFilter) ([]RetryQueueTransaction, error) {
+	inMemory, onDisk, err := f.retryQueue.ListTransactions()
+
+	now := time.Now()
+	var transactions []RetryQueueTransaction
+	for _, location := range []struct {
+		transactions []transaction.Transaction
+		onDisk       bool
+	}{{inMemory, false}, {onDisk, true}} {
+		for _, t := range location.transactions {
+			if !filter.match(t, location.onDisk, now) {
+				continue
+			}
+			transactions = append(transactions, RetryQueueTransaction{
+				Domain:      f.domain,
+				Endpoint:    t.GetEndpointName(),
+				Priority:    t.GetPriority().String(),
+				Location:    getRetryQueueLocation(location.onDisk),
+				CreatedAt:   t.GetCreatedAt(),
+				PayloadSize: t.GetPayloadSize(),
+			})
+		}
+	}
+	return transactions, err
+}
+
+func (f *domainForwarder) extractRetryQueue(filter RetryQueueFilter) ([]transaction.Transaction, error) {
+	now := time.Now()
+	transactions, err := f.retryQueue.ExtractTransactionsMatching(func(t transaction.Transaction, onDisk bool) bool {
+		return filter.match(t, onDisk, now)
+	})
+
+	transactionCount := f.retryQueue.GetTransactionCount()
+	transactionsRetryQueueSize.Set(int64(transactionCount))
+	tlmTxRetryQueueSize.Set(float64(transactionCount), f.domain)
+	return transactions, err
+}
+
+func (f *domainForwarder) purgeRetryQueue(filter RetryQueueFilter) (int, error) {
+	transactions, err := f.extractRetryQueue(filter)
+	for _, t := range transactions {
+		transactionEndpointName := t.GetEndpointName()
+		transactionsPurgedByEndpoint.Add(transactionEndpointName, 1)
+		transactionsPurged.Add(1)
+		tlmTxPurged.Inc(f.domain, transactionEndpointName)
+	}
+	if len(transactions) > 0 {
+		log.Infof("Purged %d transactions from the retry queue of %q", len(transactions), f.domain)
+	}
+	return len(transactions), err
+}
+
+func (f *domainForwarder) retryRetryQueue(filter RetryQueueFilter) (int, error) {
+	// Lock so the domainForwarder is not stopped while the transactions are sent to the workers
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState != Started {
+		return 0, fmt.Errorf("the forwarder is not started")
+	}
+
+	transactions, err := f.extractRetryQueue(filter)
+	f.transactionPrioritySorter.Sort(transactions)
+
+	retried := 0
+	for _, t := range transactions {
+		f.blockedList.unblock(t.GetTarget())
+
+		transactionEndpointName := t.GetEndpointName()
+		select {
+		case f.lowPrio <- t:
+			retried++
+			transactionsRetriedByEndpoint.Add(transactionEndpointName, 1)
+			transactionsRetried.Add(1)
+			tlmTxRetried.Inc(f.domain, transactionEndpointName)
+		default:
+			// The workers are too busy, the transaction will be retried with the next ones.
+			f.addToTransactionRetryQueue(t)
+		}
+	}
+	if len(transactions) > 0 {
+		log.Infof("Retrying %d transactions of the retry queue of %q, %d were requeued because the workers are busy",
+			retried, f.domain, len(transactions)-retried)
+	}
+	return retried, err
+}
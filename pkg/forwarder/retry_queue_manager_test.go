@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+func newRetryQueueTransaction(domain string, endpoint string, priority transaction.Priority, age time.Duration) *transaction.HTTPTransaction {
+	t := transaction.NewHTTPTransaction()
+	t.Domain = domain
+	t.Endpoint = transaction.Endpoint{Route: "/" + endpoint, Name: endpoint}
+	t.Priority = priority
+	t.CreatedAt = time.Now().Add(-age)
+	payload := []byte("payload")
+	t.Payload = &payload
+	return t
+}
+
+func TestRetryQueueFilterQuery(t *testing.T) {
+	filter := RetryQueueFilter{
+		Domain:    testDomain,
+		Endpoint:  "series_v1",
+		Priority:  "high",
+		Location:  RetryQueueLocationDisk,
+		OlderThan: time.Minute,
+		NewerThan: time.Hour,
+	}
+	parsed, err := RetryQueueFilterFromQuery(filter.ToQuery())
+	require.NoError(t, err)
+	assert.Equal(t, filter, parsed)
+
+	parsed, err = RetryQueueFilterFromQuery(url.Values{})
+	require.NoError(t, err)
+	assert.True(t, parsed.IsEmpty())
+
+	for _, query := range []url.Values{
+		{"priority": {"low"}},
+		{"location": {"cloud"}},
+		{"older_than": {"1"}},
+		{"newer_than": {"yesterday"}},
+	} {
+		_, err := RetryQueueFilterFromQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestListAndPurgeRetryQueue(t *testing.T) {
+	forwarder := NewDefaultForwarder(NewOptions(keysWithMultipleDomains))
+	df := forwarder.domainForwarders[testVersionDomain]
+	df.addToTransactionRetryQueue(newRetryQueueTransaction(testVersionDomain, "series_v1", transaction.TransactionPriorityNormal, time.Hour))
+	df.addToTransactionRetryQueue(newRetryQueueTransaction(testVersionDomain, "series_v1", transaction.TransactionPriorityHigh, time.Minute))
+	df.addToTransactionRetryQueue(newRetryQueueTransaction(testVersionDomain, "intake", transaction.TransactionPriorityNormal, time.Second))
+	forwarder.domainForwarders["datadog.bar"].addToTransactionRetryQueue(newRetryQueueTransaction("datadog.bar", "series_v1", transaction.TransactionPriorityNormal, time.Hour))
+
+	transactions, err := forwarder.ListRetryQueue(RetryQueueFilter{})
+	require.NoError(t, err)
+	assert.Len(t, transactions, 4)
+
+	// The configured domain selects the domainForwarder of the versioned domain
+	transactions, err = forwarder.ListRetryQueue(RetryQueueFilter{Domain: testDomain, Endpoint: "series_v1"})
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	for _, tr := range transactions {
+		assert.Equal(t, testVersionDomain, tr.Domain)
+		assert.Equal(t, RetryQueueLocationMemory, tr.Location)
+		assert.Equal(t, len("payload"), tr.PayloadSize)
+	}
+
+	transactions, err = forwarder.ListRetryQueue(RetryQueueFilter{Priority: "high"})
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "series_v1", transactions[0].Endpoint)
+
+	transactions, err = forwarder.ListRetryQueue(RetryQueueFilter{OlderThan: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	transactions, err = forwarder.ListRetryQueue(RetryQueueFilter{NewerThan: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	transactions, err = forwarder.ListRetryQueue(RetryQueueFilter{Location: RetryQueueLocationDisk})
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	count, err := forwarder.PurgeRetryQueue(RetryQueueFilter{Endpoint: "series_v1", OlderThan: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, df.retryQueue.GetTransactionCount())
+	assert.Equal(t, 0, forwarder.domainForwarders["datadog.bar"].retryQueue.GetTransactionCount())
+}
+
+func TestRetryRetryQueue(t *testing.T) {
+	requests := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ignore the API key validation of the health checker
+		if r.URL.Path != "/api/v1/validate" {
+			requests <- r.URL.Path
+		}
+	}))
+	defer ts.Close()
+
+	forwarder := NewDefaultForwarder(NewOptions(map[string][]string{ts.URL: {"api-key"}}))
+	df := forwarder.domainForwarders[ts.URL]
+
+	_, err := forwarder.RetryRetryQueue(RetryQueueFilter{})
+	assert.Error(t, err, "the forwarder is not started")
+
+	require.NoError(t, forwarder.Start())
+	defer forwarder.Stop()
+
+	series := newRetryQueueTransaction(ts.URL, "series_v1", transaction.TransactionPriorityNormal, time.Minute)
+	df.blockedList.close(series.GetTarget())
+	df.addToTransactionRetryQueue(series)
+	df.addToTransactionRetryQueue(newRetryQueueTransaction(ts.URL, "intake", transaction.TransactionPriorityNormal, time.Minute))
+
+	count, err := forwarder.RetryRetryQueue(RetryQueueFilter{Endpoint: "series_v1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.False(t, df.blockedList.isBlock(series.GetTarget()))
+
+	select {
+	case path := <-requests:
+		assert.Equal(t, "/series_v1", path)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the transaction was not retried")
+	}
+	assert.Equal(t, 1, df.retryQueue.GetTransactionCount())
+}
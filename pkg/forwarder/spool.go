@@ -0,0 +1,365 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/hashicorp/go-multierror"
+)
+
+// GetSpoolPath returns the folder where the transactions are stored in offline mode.
+func GetSpoolPath() string {
+	spoolPath := config.Datadog.GetString("forwarder_spool_path")
+	if spoolPath == "" {
+		spoolPath = path.Join(config.Datadog.GetString("run_path"), "transactions_spool")
+	}
+	return spoolPath
+}
+
+// newSpools creates a spool for each domain of keysPerDomain. The spools are indexed by
+// the domain of the transactions.
+func newSpools(keysPerDomain map[string][]string) (map[string]*retry.Spool, error) {
+	spoolPath := GetSpoolPath()
+	outdatedFileInDays := config.Datadog.GetInt("forwarder_spool_outdated_file_in_days")
+	maxSizeInBytes := config.Datadog.GetInt64("forwarder_spool_max_size_in_bytes")
+
+	removalPolicy, err := retry.NewFileRemovalPolicy(spoolPath, outdatedFileInDays, retry.FileRemovalPolicyTelemetry{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize the removal policy: %v", err)
+	}
+	filesRemoved, err := removalPolicy.RemoveOutdatedFiles()
+	if err != nil {
+		log.Errorf("Error when removing outdated spool files: %v", err)
+	}
+	log.Debugf("Outdated spool files removed: %v", strings.Join(filesRemoved, ", "))
+
+	cipher, err := retry.NewFileCipherFromConfig(spoolPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize the encryption of the spool files: %v", err)
+	}
+
+	spools := make(map[string]*retry.Spool)
+	for configDomain, keys := range keysPerDomain {
+		if len(keys) == 0 {
+			continue
+		}
+		// The folder does not depend on the Agent version so the spool can be uploaded
+		// by any version of the Agent.
+		folder, err := removalPolicy.RegisterDomain(configDomain)
+		if err != nil {
+			return nil, fmt.Errorf("cannot register the domain '%v': %v", configDomain, err)
+		}
+		domain, _ := config.AddAgentVersionToDomain(configDomain, "app")
+		spool, err := retry.NewSpool(folder, maxSizeInBytes, cipher, domain, keys)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the spool of the domain '%v': %v", configDomain, err)
+		}
+		if filesCount := spool.GetFilesCount(); filesCount > 0 {
+			log.Infof("The spool of %q contains %d files to upload", configDomain, filesCount)
+		}
+		spools[domain] = spool
+	}
+	return spools, nil
+}
+
+// spoolHTTPTransactions stores the transactions in the spool of their domain.
+// The transactions which cannot be stored on disk are dropped.
+func (f *DefaultForwarder) spoolHTTPTransactions(transactions []*transaction.HTTPTransaction) error {
+	transactionsPerDomain := make(map[string][]transaction.Transaction)
+	for _, t := range transactions {
+		if _, found := f.spools[t.Domain]; !found || !t.StorableOnDisk {
+			log.Debugf("Offline mode: dropping a transaction to %q as it cannot be stored in the spool", t.Endpoint.Name)
+			transactionsSpoolDropped.Add(1)
+			tlmTxSpoolDropped.Inc(t.Domain, t.Endpoint.Name)
+			continue
+		}
+		transactionsPerDomain[t.Domain] = append(transactionsPerDomain[t.Domain], t)
+	}
+
+	var errs error
+	for domain, domainTransactions := range transactionsPerDomain {
+		err := f.spools[domain].Store(domainTransactions)
+		for _, t := range domainTransactions {
+			endpointName := t.GetEndpointName()
+			if err != nil {
+				transactionsSpoolDropped.Add(1)
+				tlmTxSpoolDropped.Inc(domain, endpointName)
+			} else {
+				transactionsSpooled.Add(1)
+				tlmTxSpooled.Inc(domain, endpointName)
+			}
+		}
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("cannot store the transactions of %q in the spool: %v", domain, err))
+		}
+	}
+	return errs
+}
+
+// SpoolSummary describes the spool files of a domain.
+type SpoolSummary struct {
+	// Domain is the configured domain of the spool or its folder name when the domain is unknown.
+	Domain                  string
+	Folder                  string
+	Files                   int
+	SizeInBytes             int64
+	UnreadableFiles         int
+	Transactions            int
+	TransactionsPerEndpoint map[string]int
+	OldestTransaction       time.Time
+	NewestTransaction       time.Time
+}
+
+// InspectSpool returns a summary of each domain folder of spoolPath. keysPerDomain is used
+// to find the domain of the folders.
+func InspectSpool(spoolPath string, keysPerDomain map[string][]string) ([]SpoolSummary, error) {
+	cipher, err := retry.LoadFileCipherFromConfig(spoolPath)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := getSpoolFolders(spoolPath, keysPerDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []SpoolSummary
+	for folder, domain := range folders {
+		files, err := retry.ListSpoolFiles(folder)
+		if err != nil {
+			return nil, err
+		}
+		summary := SpoolSummary{
+			Domain:                  domain,
+			Folder:                  folder,
+			Files:                   len(files),
+			TransactionsPerEndpoint: make(map[string]int),
+		}
+		for _, file := range files {
+			if size, err := util.GetFileSize(file); err == nil {
+				summary.SizeInBytes += size
+			}
+			collection, err := retry.ReadSpoolFile(file, cipher)
+			if err != nil {
+				log.Warnf("Cannot read the spool file %s: %v", file, err)
+				summary.UnreadableFiles++
+				continue
+			}
+			for _, t := range collection.Values {
+				createdAt := time.Unix(t.CreatedAt, 0)
+				if summary.OldestTransaction.IsZero() || createdAt.Before(summary.OldestTransaction) {
+					summary.OldestTransaction = createdAt
+				}
+				if createdAt.After(summary.NewestTransaction) {
+					summary.NewestTransaction = createdAt
+				}
+				summary.Transactions++
+				summary.TransactionsPerEndpoint[t.Endpoint.GetName()]++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Domain < summaries[j].Domain
+	})
+	return summaries, nil
+}
+
+// spoolExportedTransaction is the JSON representation of a spooled transaction.
+// The API keys are masked.
+type spoolExportedTransaction struct {
+	Domain    string              `json:"domain"`
+	File      string              `json:"file"`
+	Endpoint  string              `json:"endpoint"`
+	Route     string              `json:"route"`
+	Headers   map[string][]string `json:"headers"`
+	CreatedAt time.Time           `json:"created_at"`
+	Priority  string              `json:"priority"`
+	Payload   []byte              `json:"payload"`
+}
+
+// ExportSpool writes the transactions of spoolPath to w as JSON, one transaction per line.
+// The payloads are written as they were sent, encoded in base64.
+func ExportSpool(spoolPath string, keysPerDomain map[string][]string, w io.Writer) error {
+	cipher, err := retry.LoadFileCipherFromConfig(spoolPath)
+	if err != nil {
+		return err
+	}
+	folders, err := getSpoolFolders(spoolPath, keysPerDomain)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for folder, domain := range folders {
+		files, err := retry.ListSpoolFiles(folder)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			collection, err := retry.ReadSpoolFile(file, cipher)
+			if err != nil {
+				return fmt.Errorf("cannot read the spool file %s: %v", file, err)
+			}
+			for _, t := range collection.Values {
+				headers := make(map[string][]string)
+				for key, values := range t.Headers {
+					for _, value := range values.Values {
+						headers[key] = append(headers[key], retry.MaskAPIKeyPlaceholders(value))
+					}
+				}
+				exported := spoolExportedTransaction{
+					Domain:    domain,
+					File:      file,
+					Endpoint:  t.Endpoint.GetName(),
+					Route:     retry.MaskAPIKeyPlaceholders(t.Endpoint.GetRoute()),
+					Headers:   headers,
+					CreatedAt: time.Unix(t.CreatedAt, 0).UTC(),
+					Priority:  t.Priority.String(),
+					Payload:   t.Payload,
+				}
+				if err := encoder.Encode(exported); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SpoolUploadResult is the result of the upload of the spool of a domain.
+type SpoolUploadResult struct {
+	Domain string
+	// Files is the number of spool files uploaded and removed.
+	Files int
+	// Transactions is the number of transactions sent successfully.
+	Transactions int
+	// Dropped is the number of transactions rejected by the domain.
+	Dropped int
+	// Err is the error which stopped the upload, if any.
+	Err error
+}
+
+// UploadSpool sends the transactions of spoolPath to the domains of keysPerDomain. The API
+// keys of a domain must be the ones configured when the spool was written. A spool file
+// is removed once all its transactions are sent or rejected; the upload of a domain stops
+// at the first error so the remaining files are kept for a later upload. The transactions
+// of a file partially sent are sent again by the next upload.
+func UploadSpool(ctx context.Context, spoolPath string, keysPerDomain map[string][]string) ([]SpoolUploadResult, error) {
+	cipher, err := retry.LoadFileCipherFromConfig(spoolPath)
+	if err != nil {
+		return nil, err
+	}
+
+	client := newHTTPClient()
+	var results []SpoolUploadResult
+	for configDomain, keys := range keysPerDomain {
+		folder, err := retry.GetDomainFolderPath(spoolPath, configDomain)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(folder); err != nil {
+			continue
+		}
+		domain, _ := config.AddAgentVersionToDomain(configDomain, "app")
+		result := SpoolUploadResult{Domain: configDomain}
+		result.Err = uploadSpoolFolder(ctx, client, cipher, folder, domain, keys, &result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func uploadSpoolFolder(ctx context.Context, client *http.Client, cipher *retry.FileCipher, folder string, domain string, apiKeys []string, result *SpoolUploadResult) error {
+	files, err := retry.ListSpoolFiles(folder)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		transactions, errorsCount, err := retry.DeserializeSpoolFile(file, cipher, domain, apiKeys)
+		if err != nil {
+			return fmt.Errorf("cannot read the spool file %s: %v", file, err)
+		}
+		if errorsCount > 0 {
+			return fmt.Errorf("cannot restore %d transactions of the spool file %s: the API keys are not the ones used to write it", errorsCount, file)
+		}
+
+		for _, t := range transactions {
+			httpTransaction := t.(*transaction.HTTPTransaction)
+			rejected := false
+			httpTransaction.CompletionHandler = func(_ *transaction.HTTPTransaction, statusCode int, _ []byte, _ error) {
+				rejected = statusCode >= 400
+			}
+			if err := httpTransaction.Process(ctx, client); err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if rejected {
+				result.Dropped++
+			} else {
+				result.Transactions++
+			}
+		}
+
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		result.Files++
+	}
+
+	// Remove the folder once it is empty
+	if entries, err := ioutil.ReadDir(folder); err == nil && len(entries) == 0 {
+		_ = os.Remove(folder)
+	}
+	return nil
+}
+
+// getSpoolFolders returns the domain folders of spoolPath indexed by their folder path.
+func getSpoolFolders(spoolPath string, keysPerDomain map[string][]string) (map[string]string, error) {
+	knownFolders := make(map[string]string)
+	for configDomain := range keysPerDomain {
+		folder, err := retry.GetDomainFolderPath(spoolPath, configDomain)
+		if err != nil {
+			return nil, err
+		}
+		knownFolders[folder] = configDomain
+	}
+
+	entries, err := ioutil.ReadDir(spoolPath)
+	if err != nil {
+		return nil, err
+	}
+	folders := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		folder := path.Join(spoolPath, entry.Name())
+		if domain, found := knownFolders[folder]; found {
+			folders[folder] = domain
+		} else {
+			folders[folder] = entry.Name()
+		}
+	}
+	return folders, nil
+}
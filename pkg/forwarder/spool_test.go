@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func setupOfflineMode(t *testing.T) string {
+	spoolPath, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("forwarder_offline_mode", true)
+	mockConfig.Set("forwarder_spool_path", spoolPath)
+	t.Cleanup(func() {
+		mockConfig.Set("forwarder_offline_mode", false)
+		mockConfig.Set("forwarder_spool_path", "")
+		os.RemoveAll(spoolPath)
+	})
+	return spoolPath
+}
+
+func newOfflineForwarder(t *testing.T, keysPerDomain map[string][]string) *DefaultForwarder {
+	options := NewOptions(keysPerDomain)
+	options.EnabledFeatures = SetFeature(options.EnabledFeatures, CoreFeatures)
+	f := NewDefaultForwarder(options)
+	require.NoError(t, f.Start())
+	t.Cleanup(f.Stop)
+	return f
+}
+
+func TestOfflineModeSpoolsTransactions(t *testing.T) {
+	spoolPath := setupOfflineMode(t)
+	var requests int
+	var m sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		requests++
+	}))
+	defer ts.Close()
+
+	f := newOfflineForwarder(t, map[string][]string{ts.URL: {"api-key-1", "api-key-2"}})
+	require.Contains(t, f.spools, ts.URL)
+	assert.True(t, f.healthChecker.disableAPIKeyChecking)
+
+	payload := []byte("payload")
+	require.NoError(t, f.SubmitV1Series(Payloads{&payload}, make(http.Header)))
+	require.NoError(t, f.SubmitSketchSeries(Payloads{&payload}, make(http.Header)))
+	// The host metadata contains the API key and cannot be stored
+	require.NoError(t, f.SubmitHostMetadata(Payloads{&payload}, make(http.Header)))
+
+	assert.Equal(t, 2, f.spools[ts.URL].GetFilesCount())
+	m.Lock()
+	assert.Equal(t, 0, requests)
+	m.Unlock()
+
+	summaries, err := InspectSpool(spoolPath, map[string][]string{ts.URL: nil})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	summary := summaries[0]
+	assert.Equal(t, ts.URL, summary.Domain)
+	assert.Equal(t, 2, summary.Files)
+	assert.Equal(t, 4, summary.Transactions)
+	assert.Equal(t, map[string]int{"series_v1": 2, "sketches_v2": 2}, summary.TransactionsPerEndpoint)
+	assert.Equal(t, 0, summary.UnreadableFiles)
+	assert.False(t, summary.OldestTransaction.IsZero())
+
+	// The folders of unknown domains are reported with their name
+	summaries, err = InspectSpool(spoolPath, nil)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.NotEqual(t, ts.URL, summaries[0].Domain)
+}
+
+func TestExportSpool(t *testing.T) {
+	spoolPath := setupOfflineMode(t)
+	f := newOfflineForwarder(t, map[string][]string{testDomain: {"api-key-1"}})
+
+	payload := []byte("payload")
+	require.NoError(t, f.SubmitV1Series(Payloads{&payload}, make(http.Header)))
+
+	var out bytes.Buffer
+	require.NoError(t, ExportSpool(spoolPath, map[string][]string{testDomain: nil}, &out))
+	assert.NotContains(t, out.String(), "api-key-1")
+
+	var exported spoolExportedTransaction
+	require.NoError(t, json.Unmarshal(out.Bytes(), &exported))
+	assert.Equal(t, testDomain, exported.Domain)
+	assert.Equal(t, "series_v1", exported.Endpoint)
+	assert.Equal(t, "/api/v1/series?api_key=***API_KEY_0***", exported.Route)
+	assert.Equal(t, []string{"***API_KEY_0***"}, exported.Headers[http.CanonicalHeaderKey(apiHTTPHeaderKey)])
+	assert.Equal(t, payload, exported.Payload)
+}
+
+func TestUploadSpool(t *testing.T) {
+	spoolPath := setupOfflineMode(t)
+	var m sync.Mutex
+	var apiKeys []string
+	fail := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/beta/sketches") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		apiKeys = append(apiKeys, r.URL.Query().Get("api_key"))
+	}))
+	defer ts.Close()
+
+	keysPerDomain := map[string][]string{ts.URL: {"api-key-1", "api-key-2"}}
+	f := newOfflineForwarder(t, keysPerDomain)
+	payload := []byte("payload")
+	require.NoError(t, f.SubmitV1Series(Payloads{&payload}, make(http.Header)))
+	require.NoError(t, f.SubmitSketchSeries(Payloads{&payload}, make(http.Header)))
+	f.Stop()
+
+	// The API keys must be the ones used to write the spool
+	results, err := UploadSpool(context.Background(), spoolPath, map[string][]string{ts.URL: {"other-key"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 0, results[0].Files)
+
+	// The files are kept when the domain is unavailable
+	m.Lock()
+	fail = true
+	m.Unlock()
+	results, err = UploadSpool(context.Background(), spoolPath, keysPerDomain)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 0, results[0].Files)
+
+	m.Lock()
+	fail = false
+	m.Unlock()
+	results, err = UploadSpool(context.Background(), spoolPath, keysPerDomain)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, SpoolUploadResult{Domain: ts.URL, Files: 2, Transactions: 2, Dropped: 2}, results[0])
+	assert.ElementsMatch(t, []string{"api-key-1", "api-key-2"}, apiKeys)
+
+	summaries, err := InspectSpool(spoolPath, keysPerDomain)
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestSpoolWithoutKeyfile(t *testing.T) {
+	spoolPath, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(spoolPath)
+
+	_, err = InspectSpool(spoolPath, nil)
+	assert.Error(t, err)
+	_, err = UploadSpool(context.Background(), spoolPath, nil)
+	assert.Error(t, err)
+}
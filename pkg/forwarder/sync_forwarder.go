@@ -84,6 +84,12 @@ func (f *SyncForwarder) SubmitEvents(payload Payloads, extra http.Header) error
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitSeries will send timeseries to the v2 endpoint.
+func (f *SyncForwarder) SubmitSeries(payload Payloads, extra http.Header) error {
+	transactions := f.defaultForwarder.createHTTPTransactions(seriesEndpoint, payload, false, extra)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitServiceChecks will send a service check type payload to Datadog backend.
 func (f *SyncForwarder) SubmitServiceChecks(payload Payloads, extra http.Header) error {
 	transactions := f.defaultForwarder.createHTTPTransactions(serviceChecksEndpoint, payload, false, extra)
@@ -46,6 +46,12 @@ var (
 	transactionsRetried              = expvar.Int{}
 	transactionsRetriedByEndpoint    = expvar.Map{}
 	transactionsRetryQueueSize       = expvar.Int{}
+	transactionsFailover             = expvar.Int{}
+	transactionsSpooled              = expvar.Int{}
+	transactionsSpoolDropped         = expvar.Int{}
+	transactionsPurged               = expvar.Int{}
+	transactionsPurgedByEndpoint     = expvar.Map{}
+	concurrencyExpvars               = expvar.Map{}
 
 	tlmTxInputBytes = telemetry.NewCounter("transactions", "input_bytes",
 		[]string{"domain", "endpoint"}, "Incoming transaction sizes in bytes")
@@ -57,6 +63,20 @@ var (
 		[]string{"domain", "endpoint"}, "Transaction requeue count")
 	tlmTxRetried = telemetry.NewCounter("transactions", "retries",
 		[]string{"domain", "endpoint"}, "Transaction retry count")
+	tlmTxFailover = telemetry.NewCounter("transactions", "failover",
+		[]string{"domain", "endpoint"}, "Count of transactions sent to a failover domain because the primary domain is blocked")
+	tlmTxPurged = telemetry.NewCounter("transactions", "purged",
+		[]string{"domain", "endpoint"}, "Count of transactions purged from the retry queue on request")
+	tlmTxSpooled = telemetry.NewCounter("transactions", "spooled",
+		[]string{"domain", "endpoint"}, "Count of transactions stored in the spool in offline mode")
+	tlmTxSpoolDropped = telemetry.NewCounter("transactions", "spool_dropped",
+		[]string{"domain", "endpoint"}, "Count of transactions dropped in offline mode because they cannot be stored in the spool")
+	tlmConcurrencyLimit = telemetry.NewGauge("forwarder_concurrency", "limit",
+		[]string{"domain"}, "Maximum number of transactions sent at the same time with adaptive concurrency")
+	tlmConcurrencyInFlight = telemetry.NewGauge("forwarder_concurrency", "in_flight",
+		[]string{"domain"}, "Number of transactions being sent with adaptive concurrency")
+	tlmConcurrencyLatency = telemetry.NewGauge("forwarder_concurrency", "latency_seconds",
+		[]string{"domain"}, "Average latency of the transactions with adaptive concurrency")
 	tlmTxRetryQueueSize = telemetry.NewGauge("transactions", "retry_queue_size",
 		[]string{"domain"}, "Retry queue size")
 )
@@ -66,6 +86,12 @@ func init() {
 	initTransactionsExpvars()
 	initForwarderHealthExpvars()
 	initEndpointExpvars()
+	initConcurrencyExpvars()
+}
+
+func initConcurrencyExpvars() {
+	concurrencyExpvars.Init()
+	transaction.ForwarderExpvars.Set("Concurrency", &concurrencyExpvars)
 }
 
 func initEndpointExpvars() {
@@ -115,6 +141,7 @@ func initTransactionsExpvars() {
 	transactionsInputCountByEndpoint.Init()
 	transactionsRequeuedByEndpoint.Init()
 	transactionsRetriedByEndpoint.Init()
+	transactionsPurgedByEndpoint.Init()
 	transaction.TransactionsExpvars.Set("InputCountByEndpoint", &transactionsInputCountByEndpoint)
 	transaction.TransactionsExpvars.Set("InputBytesByEndpoint", &transactionsInputBytesByEndpoint)
 	transaction.TransactionsExpvars.Set("HighPriorityQueueFull", &highPriorityQueueFull)
@@ -123,4 +150,9 @@ func initTransactionsExpvars() {
 	transaction.TransactionsExpvars.Set("Retried", &transactionsRetried)
 	transaction.TransactionsExpvars.Set("RetriedByEndpoint", &transactionsRetriedByEndpoint)
 	transaction.TransactionsExpvars.Set("RetryQueueSize", &transactionsRetryQueueSize)
+	transaction.TransactionsExpvars.Set("Failover", &transactionsFailover)
+	transaction.TransactionsExpvars.Set("Spooled", &transactionsSpooled)
+	transaction.TransactionsExpvars.Set("Purged", &transactionsPurged)
+	transaction.TransactionsExpvars.Set("PurgedByEndpoint", &transactionsPurgedByEndpoint)
+	transaction.TransactionsExpvars.Set("SpoolDropped", &transactionsSpoolDropped)
 }
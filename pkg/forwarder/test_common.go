@@ -102,6 +102,11 @@ func (tf *MockedForwarder) SubmitV1CheckRuns(payload Payloads, extra http.Header
 	return tf.Called(payload, extra).Error(0)
 }
 
+// SubmitSeries updates the internal mock struct
+func (tf *MockedForwarder) SubmitSeries(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
 // SubmitEvents updates the internal mock struct
 func (tf *MockedForwarder) SubmitEvents(payload Payloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
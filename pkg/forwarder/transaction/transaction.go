@@ -163,6 +163,18 @@ const (
 	TransactionPriorityHigh Priority = iota
 )
 
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case TransactionPriorityNormal:
+		return "normal"
+	case TransactionPriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
 // HTTPTransaction represents one Payload for one Endpoint on one Domain.
 type HTTPTransaction struct {
 	// Domain represents the domain target by the HTTPTransaction.
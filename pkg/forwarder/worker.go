@@ -36,6 +36,9 @@ type Worker struct {
 	stopChan            chan struct{}
 	stopped             chan struct{}
 	blockedList         *blockedEndpoints
+	// concurrency limits the transactions processed at the same time by the workers
+	// of a domain. It is nil when adaptive concurrency is disabled.
+	concurrency *concurrencyLimiter
 }
 
 // NewWorker returns a new worker to consume Transaction from inputChan
@@ -94,34 +97,48 @@ func (w *Worker) Start() {
 		defer close(w.stopped)
 
 		for {
+			// wait for a slot when adaptive concurrency is enabled
+			if !w.concurrency.acquire(w.stopChan) {
+				return
+			}
+
 			// handling high priority transactions first
 			select {
 			case t := <-w.HighPrio:
-				if w.callProcess(t) == nil {
+				if w.callProcessAndRelease(t) == nil {
 					continue
 				}
 				return
 			case <-w.stopChan:
+				w.concurrency.release()
 				return
 			default:
 			}
 
 			select {
 			case t := <-w.HighPrio:
-				if w.callProcess(t) != nil {
+				if w.callProcessAndRelease(t) != nil {
 					return
 				}
 			case t := <-w.LowPrio:
-				if w.callProcess(t) != nil {
+				if w.callProcessAndRelease(t) != nil {
 					return
 				}
 			case <-w.stopChan:
+				w.concurrency.release()
 				return
 			}
 		}
 	}()
 }
 
+// callProcessAndRelease processes a transaction and releases the concurrency slot
+// acquired to process it.
+func (w *Worker) callProcessAndRelease(t transaction.Transaction) error {
+	defer w.concurrency.release()
+	return w.callProcess(t)
+}
+
 // ScheduleConnectionReset allows signaling the worker that all connections should
 // be recreated before sending the next transaction. Returns immediately.
 func (w *Worker) ScheduleConnectionReset() {
@@ -177,12 +194,17 @@ func (w *Worker) process(ctx context.Context, t transaction.Transaction) {
 	if w.blockedList.isBlock(target) {
 		requeue()
 		log.Errorf("Too many errors for endpoint '%s': retrying later", target)
-	} else if err := t.Process(ctx, w.Client); err != nil {
-		w.blockedList.close(target)
-		requeue()
-		log.Errorf("Error while processing transaction: %v", err)
 	} else {
-		w.blockedList.recover(target)
+		start := time.Now()
+		err := t.Process(ctx, w.Client)
+		w.concurrency.observe(time.Since(start), err)
+		if err != nil {
+			w.blockedList.close(target)
+			requeue()
+			log.Errorf("Error while processing transaction: %v", err)
+		} else {
+			w.blockedList.recover(target)
+		}
 	}
 }
 
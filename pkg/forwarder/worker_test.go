@@ -113,6 +113,40 @@ func TestWorkerRetryBlockedTransaction(t *testing.T) {
 	assert.True(t, w.blockedList.isBlock("error_url"))
 }
 
+func TestWorkerAdaptiveConcurrency(t *testing.T) {
+	highPrio := make(chan transaction.Transaction)
+	lowPrio := make(chan transaction.Transaction)
+	requeue := make(chan transaction.Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.concurrency = newConcurrencyLimiter("domain", 4, 1, 4, time.Minute, 0.5)
+
+	failed := newTestTransaction()
+	failed.On("Process", w.Client).Return(fmt.Errorf("some kind of error")).Times(1)
+	failed.On("GetTarget").Return("error_url").Times(1)
+
+	succeeded := newTestTransaction()
+	succeeded.On("Process", w.Client).Return(nil).Times(1)
+	succeeded.On("GetTarget").Return("success_url").Times(1)
+
+	w.Start()
+	highPrio <- failed
+	<-requeue
+	// The worker only takes the next transaction once it has released the slot of
+	// the failed one, which removed one of the extra slots.
+	highPrio <- succeeded
+	<-succeeded.processed
+	w.Stop(false)
+	failed.AssertExpectations(t)
+	succeeded.AssertExpectations(t)
+
+	// The failed transaction halved the limit, and the slot of the succeeded one was
+	// removed too. The slot acquired while stopping is released without changing
+	// the number of slots, which now matches the limit.
+	assert.Equal(t, 2, w.concurrency.getLimit())
+	assert.Equal(t, 0, w.concurrency.inFlight)
+	assert.Equal(t, 2, w.concurrency.issued)
+}
+
 func TestWorkerResetConnections(t *testing.T) {
 	highPrio := make(chan transaction.Transaction)
 	lowPrio := make(chan transaction.Transaction)
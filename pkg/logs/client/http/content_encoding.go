@@ -6,8 +6,7 @@
 package http
 
 import (
-	"bytes"
-	"compress/gzip"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // ContentEncoding encodes the payload
@@ -29,45 +28,25 @@ func (c *identityContentType) encode(payload []byte) ([]byte, error) {
 	return payload, nil
 }
 
-// GzipContentEncoding encodes the payload using gzip algorithm
-type GzipContentEncoding struct {
-	level int
+// CompressionContentEncoding encodes the payload using a compression algorithm
+type CompressionContentEncoding struct {
+	compressor compression.Compressor
 }
 
-// NewGzipContentEncoding creates a new Gzip content type
-func NewGzipContentEncoding(level int) *GzipContentEncoding {
-	if level < gzip.NoCompression {
-		level = gzip.NoCompression
-	} else if level > gzip.BestCompression {
-		level = gzip.BestCompression
-	}
-
-	return &GzipContentEncoding{
-		level,
+// NewCompressionContentEncoding creates a new content type compressing payloads with c
+func NewCompressionContentEncoding(c compression.Compressor) *CompressionContentEncoding {
+	return &CompressionContentEncoding{
+		compressor: c,
 	}
 }
 
-func (c *GzipContentEncoding) name() string {
-	return "gzip"
+func (c *CompressionContentEncoding) name() string {
+	if encoding := c.compressor.ContentEncoding(); encoding != "" {
+		return encoding
+	}
+	return IdentityContentType.name()
 }
 
-func (c *GzipContentEncoding) encode(payload []byte) ([]byte, error) {
-	var compressedPayload bytes.Buffer
-	gzipWriter, err := gzip.NewWriterLevel(&compressedPayload, c.level)
-	if err != nil {
-		return nil, err
-	}
-	_, err = gzipWriter.Write(payload)
-	if err != nil {
-		return nil, err
-	}
-	err = gzipWriter.Flush()
-	if err != nil {
-		return nil, err
-	}
-	err = gzipWriter.Close()
-	if err != nil {
-		return nil, err
-	}
-	return compressedPayload.Bytes(), nil
+func (c *CompressionContentEncoding) encode(payload []byte) ([]byte, error) {
+	return c.compressor.Compress(payload)
 }
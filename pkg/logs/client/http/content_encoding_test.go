@@ -11,6 +11,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestIdentityContentType(t *testing.T) {
@@ -26,10 +29,40 @@ func TestIdentityContentTypeName(t *testing.T) {
 	assert.Equal(t, IdentityContentType.name(), "identity")
 }
 
+func TestCompressionContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	for _, tt := range []struct {
+		kind string
+		name string
+	}{
+		{compression.GzipKind, "gzip"},
+		{compression.ZlibKind, "deflate"},
+		{compression.ZstdKind, "zstd"},
+		{compression.NoneKind, "identity"},
+	} {
+		c, err := compression.NewCompressor(tt.kind, compression.DefaultLevel)
+		assert.Nil(t, err)
+		encoding := NewCompressionContentEncoding(c)
+		assert.Equal(t, tt.name, encoding.name())
+
+		encodedPayload, err := encoding.encode(payload)
+		assert.Nil(t, err)
+
+		decompressedPayload, err := c.Decompress(encodedPayload)
+		assert.Nil(t, err)
+
+		assert.Equal(t, payload, decompressedPayload)
+	}
+}
+
 func TestGzipContentEncoding(t *testing.T) {
 	payload := []byte("my payload")
 
-	encodedPayload, err := NewGzipContentEncoding(gzip.BestCompression).encode(payload)
+	encoding := buildContentEncoding(config.Endpoint{UseCompression: true, CompressionLevel: gzip.BestCompression})
+	assert.Equal(t, "gzip", encoding.name())
+
+	encodedPayload, err := encoding.encode(payload)
 	assert.Nil(t, err)
 
 	decompressedPayload, err := decompress(encodedPayload)
@@ -38,8 +71,13 @@ func TestGzipContentEncoding(t *testing.T) {
 	assert.Equal(t, payload, decompressedPayload)
 }
 
-func TestGzipContentEncodingName(t *testing.T) {
-	assert.Equal(t, NewGzipContentEncoding(gzip.BestCompression).name(), "gzip")
+func TestBuildContentEncoding(t *testing.T) {
+	assert.Equal(t, IdentityContentType, buildContentEncoding(config.Endpoint{UseCompression: false, CompressionKind: compression.ZstdKind}))
+	assert.Equal(t, "zstd", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: compression.ZstdKind, CompressionLevel: 3}).name())
+	// invalid levels fall back to the default level of the compression kind
+	assert.Equal(t, "zstd", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: compression.ZstdKind, CompressionLevel: 42}).name())
+	// invalid kinds fall back to gzip
+	assert.Equal(t, "gzip", buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: "lz4", CompressionLevel: 6}).name())
 }
 
 func decompress(payload []byte) ([]byte, error) {
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/backoff"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -264,10 +265,22 @@ func buildURL(endpoint config.Endpoint) string {
 }
 
 func buildContentEncoding(endpoint config.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
-		return NewGzipContentEncoding(endpoint.CompressionLevel)
+	if !endpoint.UseCompression {
+		return IdentityContentType
 	}
-	return IdentityContentType
+	kind := endpoint.CompressionKind
+	if kind == "" {
+		kind = compression.GzipKind
+	}
+	c, err := compression.NewCompressor(kind, endpoint.CompressionLevel)
+	if err != nil {
+		log.Warnf("Invalid compression settings for logs endpoint %s, using the default level: %v", endpoint.Host, err)
+		if c, err = compression.NewCompressor(kind, compression.DefaultLevel); err != nil {
+			log.Warnf("Invalid compression settings for logs endpoint %s, using %s: %v", endpoint.Host, compression.GzipKind, err)
+			c, _ = compression.NewCompressor(compression.GzipKind, compression.DefaultLevel)
+		}
+	}
+	return NewCompressionContentEncoding(c)
 }
 
 // CheckConnectivity check if sending logs through HTTP works
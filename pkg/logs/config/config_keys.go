@@ -97,6 +97,10 @@ func (l *LogsConfigKeys) compressionLevel() int {
 	return l.getConfig().GetInt(l.getConfigKey("compression_level"))
 }
 
+func (l *LogsConfigKeys) compressionKind() string {
+	return l.getConfig().GetString(l.getConfigKey("compression_kind"))
+}
+
 func (l *LogsConfigKeys) useCompression() bool {
 	return l.getConfig().GetBool(l.getConfigKey("use_compression"))
 }
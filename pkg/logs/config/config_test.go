@@ -168,6 +168,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
+		CompressionKind:  "gzip",
 		CompressionLevel: 6,
 		BackoffFactor:    3,
 		BackoffBase:      1.0,
@@ -268,6 +269,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
+		CompressionKind:  "gzip",
 		CompressionLevel: 6,
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
@@ -333,6 +335,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig2() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
+		CompressionKind:  "gzip",
 		CompressionLevel: 6,
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
@@ -430,6 +433,7 @@ func (suite *ConfigTestSuite) TestEndpointsSetLogsDDUrl() {
 			Port:             443,
 			UseSSL:           true,
 			UseCompression:   true,
+			CompressionKind:  "gzip",
 			CompressionLevel: 6,
 			BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 			BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
@@ -472,6 +476,7 @@ func (suite *ConfigTestSuite) TestEndpointsSetDDSite() {
 			Port:             0,
 			UseSSL:           true,
 			UseCompression:   true,
+			CompressionKind:  "gzip",
 			CompressionLevel: 6,
 			BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 			BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
@@ -504,6 +509,7 @@ func (suite *ConfigTestSuite) TestBuildServerlessEndpoints() {
 			Port:             0,
 			UseSSL:           true,
 			UseCompression:   true,
+			CompressionKind:  "gzip",
 			CompressionLevel: 6,
 			BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 			BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
@@ -145,6 +145,7 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldSucceedWithValidHTTPCon
 
 	endpoint = endpoints.Main
 	suite.True(endpoint.UseCompression)
+	suite.Equal("gzip", endpoint.CompressionKind)
 	suite.Equal(endpoint.CompressionLevel, 6)
 }
 
@@ -156,6 +157,7 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldSucceedWithValidHTTPCon
 	suite.config.Set("logs_config.use_http", true)
 	suite.config.Set("logs_config.use_compression", true)
 	suite.config.Set("logs_config.compression_level", 1)
+	suite.config.Set("logs_config.compression_kind", "zstd")
 
 	endpoints, err = BuildEndpoints(HTTPConnectivityFailure, "test-track", "test-proto", "test-source")
 	suite.Nil(err)
@@ -163,6 +165,7 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldSucceedWithValidHTTPCon
 
 	endpoint = endpoints.Main
 	suite.True(endpoint.UseCompression)
+	suite.Equal("zstd", endpoint.CompressionKind)
 	suite.Equal(endpoint.CompressionLevel, 1)
 }
 
@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib
-
 package metrics
 
 import (
@@ -23,6 +21,7 @@ import (
 	agentpayload "github.com/DataDog/agent-payload/gogen"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestMarshal(t *testing.T) {
@@ -328,7 +327,7 @@ func benchmarkCreateSingleMarshaler(b *testing.B, createEvents func(numberOfItem
 		b.ResetTimer()
 
 		for n := 0; n < b.N; n++ {
-			payloadBuilder.Build(events.CreateSingleMarshaler())
+			payloadBuilder.Build(events.CreateSingleMarshaler(), compression.DefaultCompressor())
 		}
 	})
 }
@@ -342,7 +341,7 @@ func BenchmarkCreateMarshalersBySourceType(b *testing.B) {
 
 		for n := 0; n < b.N; n++ {
 			for _, m := range events.CreateMarshalersBySourceType() {
-				payloadBuilder.Build(m)
+				payloadBuilder.Build(m, compression.DefaultCompressor())
 			}
 		}
 	})
@@ -367,9 +366,9 @@ func BenchmarkCreateMarshalersSeveralSourceTypes(b *testing.B) {
 		for n := 0; n < b.N; n++ {
 			// As CreateMarshalersBySourceType is called only after CreateSingleMarshaler,
 			// we also call CreateSingleMarshaler in this benchmark.
-			payloadBuilder.Build(events.CreateSingleMarshaler())
+			payloadBuilder.Build(events.CreateSingleMarshaler(), compression.DefaultCompressor())
 			for _, m := range events.CreateMarshalersBySourceType() {
-				payloadBuilder.Build(m)
+				payloadBuilder.Build(m, compression.DefaultCompressor())
 			}
 		}
 	})
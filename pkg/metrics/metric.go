@@ -31,6 +31,21 @@ func (a APIMetricType) String() string {
 	}
 }
 
+// SeriesAPIV2Enum returns the value of the MetricType enum of the series v2 API for the
+// metric type, 0 (unspecified) for unknown types
+func (a APIMetricType) SeriesAPIV2Enum() int32 {
+	switch a {
+	case APICountType:
+		return 1
+	case APIRateType:
+		return 2
+	case APIGaugeType:
+		return 3
+	default:
+		return 0
+	}
+}
+
 // MarshalText implements the encoding.TextMarshal interface to marshal
 // an APIMetricType to a serialized byte slice
 func (a APIMetricType) MarshalText() ([]byte, error) {
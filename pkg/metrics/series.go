@@ -16,19 +16,54 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/richardartoul/molecule"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 )
 
 var (
-	seriesExpvar = expvar.NewMap("series")
+	seriesExpvar                     = expvar.NewMap("series")
+	expvarsSeriesItemTooBig          = expvar.Int{}
+	expvarsSeriesPayloadFull         = expvar.Int{}
+	expvarsSeriesUnexpectedItemDrops = expvar.Int{}
 
 	tlmSeries = telemetry.NewCounter("metrics", "series_split",
 		[]string{"action"}, "Series split")
+	tlmSeriesItemTooBig = telemetry.NewCounter("series", "series_too_big",
+		nil, "Number of series dropped because they were too big for the stream compressor")
+	tlmSeriesPayloadFull = telemetry.NewCounter("series", "payload_full",
+		nil, "How many times we've hit a 'payload is full' in the stream compressor")
+	tlmSeriesUnexpectedItemDrops = telemetry.NewCounter("series", "unexpected_item_drops",
+		nil, "Items dropped in the stream compressor")
 )
 
+// constants for the protobuf data we will be writing, taken from the MetricPayload message of
+// https://github.com/DataDog/agent-payload/blob/master/proto/metrics/agent_payload.proto
+// Unused fields are commented out
+const payloadSeries = 1
+const serieResources = 1
+const serieMetric = 2
+const serieTags = 3
+const seriePoints = 4
+const serieType = 5
+
+// const serieUnit = 6
+const serieSourceTypeName = 7
+const serieInterval = 8
+const resourceType = 1
+const resourceName = 2
+const pointValue = 1
+const pointTimestamp = 2
+
+func init() {
+	seriesExpvar.Set("ItemTooBig", &expvarsSeriesItemTooBig)
+	seriesExpvar.Set("PayloadFull", &expvarsSeriesPayloadFull)
+	seriesExpvar.Set("UnexpectedItemDrops", &expvarsSeriesUnexpectedItemDrops)
+}
+
 // Point represents a metric value at a specific time
 type Point struct {
 	Ts    float64
@@ -59,9 +94,16 @@ type Serie struct {
 // Series represents a list of Serie ready to be serialize
 type Series []*Serie
 
-// Marshal serialize timeseries using protobuf
+// Marshal serializes timeseries to a protobuf MetricPayload so it can be sent to the v2 endpoint
 func (series Series) Marshal() ([]byte, error) {
-	return nil, fmt.Errorf("Series payload serialization is not implemented")
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	ps := molecule.NewProtoStream(buf)
+	for _, serie := range series {
+		if err := writeSerieProtobuf(serie, ps); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
 }
 
 // MarshalStrings converts the timeseries to a sorted slice of string slices
@@ -205,9 +247,155 @@ func (series Series) SplitPayload(times int) ([]marshaler.Marshaler, error) {
 	return payloads, nil
 }
 
-// MarshalSplitCompress not implemented
+// MarshalSplitCompress uses the stream compressor to marshal and compress series payloads.
+// If a compressed payload is larger than the max, a new payload will be generated. This method
+// returns a slice of compressed protobuf MetricPayload objects, built one serie at a time. A serie
+// too big to fit in a payload is dropped. The resulting payloads (when decompressed) are binary
+// equal to the result of Marshal on the series they contain.
 func (series Series) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
-	return nil, fmt.Errorf("Series MarshalSplitCompress is not implemented")
+	var compressor *stream.Compressor
+	buf := bufferContext.PrecompressionBuf
+	ps := molecule.NewProtoStream(buf)
+	payloads := []*[]byte{}
+
+	// Prepare to write the next payload
+	startPayload := func() error {
+		var err error
+
+		bufferContext.CompressorInput.Reset()
+		bufferContext.CompressorOutput.Reset()
+
+		compressor, err = stream.NewCompressor(bufferContext.CompressorInput, bufferContext.CompressorOutput, []byte{}, []byte{}, []byte{}, bufferContext.Compressor)
+		return err
+	}
+
+	finishPayload := func() error {
+		payload, err := compressor.Close()
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, &payload)
+		return nil
+	}
+
+	// start things off
+	if err := startPayload(); err != nil {
+		return nil, err
+	}
+
+	for _, serie := range series {
+		buf.Reset()
+		if err := writeSerieProtobuf(serie, ps); err != nil {
+			return nil, err
+		}
+
+		// Compress the marshaled serie
+		err := compressor.AddItem(buf.Bytes())
+		if err == stream.ErrPayloadFull {
+			expvarsSeriesPayloadFull.Add(1)
+			tlmSeriesPayloadFull.Inc()
+
+			// Since the compression buffer is full - flush it and start a new one
+			if err = finishPayload(); err != nil {
+				return nil, err
+			}
+			if err = startPayload(); err != nil {
+				return nil, err
+			}
+
+			// Add it to the new compression buffer
+			err = compressor.AddItem(buf.Bytes())
+		}
+
+		switch err {
+		case nil:
+		case stream.ErrItemTooBig:
+			// Item was too big, drop it
+			expvarsSeriesItemTooBig.Add(1)
+			tlmSeriesItemTooBig.Inc()
+		default:
+			// Unexpected error bail out
+			expvarsSeriesUnexpectedItemDrops.Add(1)
+			tlmSeriesUnexpectedItemDrops.Inc()
+			return nil, err
+		}
+	}
+
+	if err := finishPayload(); err != nil {
+		return nil, err
+	}
+
+	return payloads, nil
+}
+
+// writeSerieProtobuf writes serie as an element of the series field of a MetricPayload. The
+// host and the device are sent as resources.
+func writeSerieProtobuf(serie *Serie, ps *molecule.ProtoStream) error {
+	populateDeviceField(serie)
+
+	return ps.Embedded(payloadSeries, func(ps *molecule.ProtoStream) error {
+		var err error
+
+		err = writeResourceProtobuf(ps, "host", serie.Host)
+		if err != nil {
+			return err
+		}
+
+		if serie.Device != "" {
+			err = writeResourceProtobuf(ps, "device", serie.Device)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = ps.String(serieMetric, serie.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range serie.Tags {
+			err = ps.String(serieTags, tag)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, p := range serie.Points {
+			err = ps.Embedded(seriePoints, func(ps *molecule.ProtoStream) error {
+				err := ps.Double(pointValue, p.Value)
+				if err != nil {
+					return err
+				}
+				return ps.Int64(pointTimestamp, int64(p.Ts))
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = ps.Int32(serieType, serie.MType.SeriesAPIV2Enum())
+		if err != nil {
+			return err
+		}
+
+		err = ps.String(serieSourceTypeName, serie.SourceTypeName)
+		if err != nil {
+			return err
+		}
+
+		return ps.Int64(serieInterval, serie.Interval)
+	})
+}
+
+func writeResourceProtobuf(ps *molecule.ProtoStream, typ string, name string) error {
+	return ps.Embedded(serieResources, func(ps *molecule.ProtoStream) error {
+		err := ps.String(resourceType, typ)
+		if err != nil {
+			return err
+		}
+		return ps.String(resourceName, name)
+	})
 }
 
 // UnmarshalJSON is a custom unmarshaller for Point (used for testing)
@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib
-
 package metrics
 
 import (
@@ -16,9 +14,14 @@ import (
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/richardartoul/molecule"
+	"github.com/richardartoul/molecule/src/codec"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -377,7 +380,7 @@ func TestPayloadsSeries(t *testing.T) {
 
 	originalLength := len(testSeries)
 	builder := stream.NewJSONPayloadBuilder(true)
-	payloads, err := builder.Build(testSeries)
+	payloads, err := builder.Build(testSeries, compression.DefaultCompressor())
 	require.Nil(t, err)
 	var splitSeries = []Series{}
 	for _, compressedPayload := range payloads {
@@ -424,7 +427,7 @@ func BenchmarkPayloadsSeries(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		// always record the result of Payloads to prevent
 		// the compiler eliminating the function call.
-		r, _ = builder.Build(testSeries)
+		r, _ = builder.Build(testSeries, compression.DefaultCompressor())
 	}
 	// ensure we actually had to split
 	if len(r) != 13 {
@@ -459,3 +462,179 @@ func decompressPayload(payload []byte) ([]byte, error) {
 	}
 	return dst, nil
 }
+
+// decodedSerie holds the fields of a MetricSeries protobuf message
+type decodedSerie struct {
+	resources      [][2]string
+	metric         string
+	tags           []string
+	points         []Point
+	mtype          int32
+	sourceTypeName string
+	interval       int64
+}
+
+func decodeSeriesPayload(t *testing.T, payload []byte) []decodedSerie {
+	var series []decodedSerie
+	err := molecule.MessageEach(codec.NewBuffer(payload), func(fieldNum int32, value molecule.Value) (bool, error) {
+		require.Equal(t, int32(payloadSeries), fieldNum)
+		var serie decodedSerie
+		err := molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+			var err error
+			switch fieldNum {
+			case serieResources:
+				var resource [2]string
+				err = molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+					resource[fieldNum-1], err = value.AsStringSafe()
+					return true, err
+				})
+				serie.resources = append(serie.resources, resource)
+			case serieMetric:
+				serie.metric, err = value.AsStringSafe()
+			case serieTags:
+				var tag string
+				tag, err = value.AsStringSafe()
+				serie.tags = append(serie.tags, tag)
+			case seriePoints:
+				var point Point
+				err = molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+					if fieldNum == pointValue {
+						point.Value, err = value.AsDouble()
+					} else {
+						var ts int64
+						ts, err = value.AsInt64()
+						point.Ts = float64(ts)
+					}
+					return true, err
+				})
+				serie.points = append(serie.points, point)
+			case serieType:
+				serie.mtype, err = value.AsInt32()
+			case serieSourceTypeName:
+				serie.sourceTypeName, err = value.AsStringSafe()
+			case serieInterval:
+				serie.interval, err = value.AsInt64()
+			default:
+				t.Errorf("unexpected field %d", fieldNum)
+			}
+			return true, err
+		})
+		series = append(series, serie)
+		return true, err
+	})
+	require.NoError(t, err)
+	return series
+}
+
+func TestSeriesMarshal(t *testing.T) {
+	series := Series{
+		{
+			Points:         []Point{{Ts: 12345, Value: 21.21}, {Ts: 67890, Value: 0}},
+			MType:          APIRateType,
+			Name:           "test.metrics",
+			Interval:       15,
+			Host:           "localHost",
+			Tags:           []string{"tag1", "device:/dev/sda1", "tag2:yes"},
+			SourceTypeName: "System",
+		},
+		{
+			Points: []Point{{Ts: 12345, Value: 1}},
+			MType:  APICountType,
+			Name:   "test.count",
+		},
+	}
+
+	payload, err := series.Marshal()
+	require.NoError(t, err)
+
+	decoded := decodeSeriesPayload(t, payload)
+	require.Len(t, decoded, 2)
+	assert.Equal(t, decodedSerie{
+		resources:      [][2]string{{"host", "localHost"}, {"device", "/dev/sda1"}},
+		metric:         "test.metrics",
+		tags:           []string{"tag1", "tag2:yes"},
+		points:         []Point{{Ts: 12345, Value: 21.21}, {Ts: 67890, Value: 0}},
+		mtype:          2,
+		sourceTypeName: "System",
+		interval:       15,
+	}, decoded[0])
+	assert.Equal(t, decodedSerie{
+		resources: [][2]string{{"host", ""}},
+		metric:    "test.count",
+		points:    []Point{{Ts: 12345, Value: 1}},
+		mtype:     1,
+	}, decoded[1])
+}
+
+func TestSeriesMarshalSplitCompress(t *testing.T) {
+	series := Series{}
+	for i := 0; i < 10; i++ {
+		series = append(series, &Serie{
+			Points: []Point{{Ts: float64(i), Value: float64(i)}},
+			MType:  APIGaugeType,
+			Name:   fmt.Sprintf("test.metrics%d", i),
+			Host:   "localHost",
+			Tags:   []string{"tag1", "tag2:yes"},
+		})
+	}
+
+	payload, err := series.Marshal()
+	require.NoError(t, err)
+	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decompressed, err := decompressPayload(*payloads[0])
+	require.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+	assert.Len(t, decodeSeriesPayload(t, decompressed), 10)
+}
+
+func TestSeriesMarshalSplitCompressEmpty(t *testing.T) {
+	payloads, err := Series{}.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decompressed, err := decompressPayload(*payloads[0])
+	require.NoError(t, err)
+	assert.Empty(t, decompressed)
+}
+
+func TestSeriesMarshalSplitCompressSplit(t *testing.T) {
+	mockConfig := config.Mock()
+	oldSetting := mockConfig.Get("serializer_max_uncompressed_payload_size")
+	defer mockConfig.Set("serializer_max_uncompressed_payload_size", oldSetting)
+	mockConfig.Set("serializer_max_uncompressed_payload_size", 500)
+
+	tooBig := &Serie{Name: "too.big"}
+	for i := 0; i < 100; i++ {
+		tooBig.Tags = append(tooBig.Tags, fmt.Sprintf("tag%d:value", i))
+	}
+	// A serie too big for a payload is dropped
+	series := Series{tooBig}
+	for i := 0; i < 20; i++ {
+		series = append(series, &Serie{
+			Points: []Point{{Ts: float64(i), Value: float64(i)}},
+			Name:   fmt.Sprintf("test.metrics%d", i),
+			Host:   "localHost",
+		})
+	}
+
+	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	require.NoError(t, err)
+	assert.Greater(t, len(payloads), 1)
+
+	var names []string
+	for _, payload := range payloads {
+		decompressed, err := decompressPayload(*payload)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(decompressed), 500)
+		for _, serie := range decodeSeriesPayload(t, decompressed) {
+			names = append(names, serie.metric)
+		}
+	}
+	require.Len(t, names, 20)
+	for i, name := range names {
+		assert.Equal(t, fmt.Sprintf("test.metrics%d", i), name)
+	}
+}
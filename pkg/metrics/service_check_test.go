@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib
-
 package metrics
 
 import (
@@ -21,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestMarshalServiceChecks(t *testing.T) {
@@ -104,7 +103,7 @@ func createServiceCheck(checkName string) *ServiceCheck {
 
 func buildPayload(t *testing.T, m marshaler.StreamJSONMarshaler) [][]byte {
 	builder := stream.NewJSONPayloadBuilder(true)
-	payloads, err := builder.Build(m)
+	payloads, err := builder.Build(m, compression.DefaultCompressor())
 	assert.NoError(t, err)
 	var uncompressedPayloads [][]byte
 
@@ -185,7 +184,7 @@ func benchmarkJSONPayloadBuilderServiceCheck(b *testing.B, numberOfItem int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		payloadBuilder.Build(serviceChecks)
+		payloadBuilder.Build(serviceChecks, compression.DefaultCompressor())
 	}
 }
 
@@ -220,7 +219,7 @@ func benchmarkPayloadsServiceCheck(b *testing.B, numberOfItem int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		split.Payloads(serviceChecks, true, split.MarshalJSON)
+		split.Payloads(serviceChecks, compression.DefaultCompressor(), split.MarshalJSON)
 	}
 }
 
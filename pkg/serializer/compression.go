@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Payload types, i.e. the forwarder endpoints whose compression can be overridden
+// through serializer_compressor_overrides.
+const (
+	seriesPayloadType        = "series"
+	sketchesPayloadType      = "sketches"
+	eventsPayloadType        = "events"
+	serviceChecksPayloadType = "service_checks"
+	metadataPayloadType      = "metadata"
+)
+
+var payloadTypes = []string{
+	seriesPayloadType,
+	sketchesPayloadType,
+	eventsPayloadType,
+	serviceChecksPayloadType,
+	metadataPayloadType,
+}
+
+// compressorOverride holds the compression settings of a payload type or a domain. Unset fields
+// are inherited from serializer_compressor_kind and serializer_compressor_level.
+type compressorOverride struct {
+	Kind  string `mapstructure:"kind"`
+	Level *int   `mapstructure:"level"`
+}
+
+// payloadCompression holds the compressor of a payload type, along with the extra
+// headers of its compressed payloads.
+type payloadCompression struct {
+	compressor      compression.Compressor
+	jsonHeaders     http.Header
+	protobufHeaders http.Header
+}
+
+func newPayloadCompression(c compression.Compressor) *payloadCompression {
+	pc := &payloadCompression{
+		compressor:      c,
+		jsonHeaders:     jsonExtraHeaders.Clone(),
+		protobufHeaders: protobufExtraHeaders.Clone(),
+	}
+	if encoding := c.ContentEncoding(); encoding != "" {
+		pc.jsonHeaders.Set("Content-Encoding", encoding)
+		pc.protobufHeaders.Set("Content-Encoding", encoding)
+	}
+	return pc
+}
+
+// headers returns the extra headers of the payloads compressed with pc.
+func (pc *payloadCompression) headers(useV1API bool) http.Header {
+	if useV1API {
+		return pc.jsonHeaders
+	}
+	return pc.protobufHeaders
+}
+
+// payloadCompressionsFromConfig returns the compression of each payload type. Invalid
+// settings are reported and replaced by the default compressor.
+func payloadCompressionsFromConfig(cfg config.Config) map[string]*payloadCompression {
+	kind := cfg.GetString("serializer_compressor_kind")
+	level := cfg.GetInt("serializer_compressor_level")
+	main, err := compression.NewCompressor(kind, level)
+	if err != nil {
+		log.Errorf("Invalid serializer compression settings, using %s: %v", compression.DefaultCompressor().Kind(), err)
+		main, level = compression.DefaultCompressor(), compression.DefaultLevel
+	}
+
+	var overrides map[string]compressorOverride
+	if err := cfg.UnmarshalKey("serializer_compressor_overrides", &overrides); err != nil {
+		log.Errorf("Could not parse serializer_compressor_overrides, ignoring it: %v", err)
+	}
+
+	compressions := make(map[string]*payloadCompression, len(payloadTypes))
+	for _, t := range payloadTypes {
+		compressions[t] = newPayloadCompression(main)
+	}
+	for t, o := range overrides {
+		if _, ok := compressions[t]; !ok {
+			log.Warnf("Unknown payload type %q in serializer_compressor_overrides, must be one of %v", t, payloadTypes)
+			continue
+		}
+		c, err := o.compressor(main.Kind(), level)
+		if err != nil {
+			log.Errorf("Invalid compression settings for %s payloads, using %s: %v", t, main.Kind(), err)
+			continue
+		}
+		compressions[t] = newPayloadCompression(c)
+	}
+	return compressions
+}
+
+// DomainCompressorsFromConfig returns the compressors of the domains of keysPerDomain, i.e.
+// the main endpoint and the additional_endpoints, whose compression is overridden through
+// serializer_compressor_domains. The forwarder compresses the payloads sent to these domains
+// again with their compressor. Invalid settings are reported and ignored.
+func DomainCompressorsFromConfig(cfg config.Config, keysPerDomain map[string][]string) map[string]compression.Compressor {
+	var overrides map[string]compressorOverride
+	if err := cfg.UnmarshalKey("serializer_compressor_domains", &overrides); err != nil {
+		log.Errorf("Could not parse serializer_compressor_domains, ignoring it: %v", err)
+		return nil
+	}
+
+	kind := cfg.GetString("serializer_compressor_kind")
+	level := cfg.GetInt("serializer_compressor_level")
+	compressors := make(map[string]compression.Compressor, len(overrides))
+	for domain, o := range overrides {
+		if _, ok := keysPerDomain[domain]; !ok {
+			log.Warnf("Unknown domain %q in serializer_compressor_domains, it must be the main endpoint or one of the additional_endpoints", domain)
+			continue
+		}
+		c, err := o.compressor(kind, level)
+		if err != nil {
+			log.Errorf("Invalid compression settings for %s, ignoring them: %v", domain, err)
+			continue
+		}
+		compressors[domain] = c
+	}
+	return compressors
+}
+
+// compressor returns the compressor of o, inheriting the unset settings from the given
+// kind and level. The level is only inherited along with the kind.
+func (o compressorOverride) compressor(kind string, level int) (compression.Compressor, error) {
+	if o.Kind != "" && o.Kind != kind {
+		kind, level = o.Kind, compression.DefaultLevel
+	}
+	if o.Level != nil {
+		level = *o.Level
+	}
+	return compression.NewCompressor(kind, level)
+}
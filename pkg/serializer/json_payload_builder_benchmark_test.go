@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib
-
 package serializer
 
 import (
@@ -13,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func generateData(points int, items int, tags int) metrics.Series {
@@ -50,7 +49,7 @@ func benchmarkJSONPayloadBuilderUsage(b *testing.B, points int, items int, tags
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		payloadBuilder.Build(series)
+		payloadBuilder.Build(series, compression.DefaultCompressor())
 	}
 }
 
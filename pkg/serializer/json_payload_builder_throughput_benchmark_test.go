@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib,optional_benchmarks
+//+build optional_benchmarks
 
 package serializer
 
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func benchmarkJSONPayloadBuilderThroughput(points int, items int, tags int, runs int) { //nolint:unuse
@@ -26,7 +27,7 @@ func benchmarkJSONPayloadBuilderThroughput(points int, items int, tags int, runs
 
 	for i := 0; i < runs; i++ {
 		start := time.Now()
-		payloadBuilder.Build(series)
+		payloadBuilder.Build(series, compression.DefaultCompressor())
 		totalTime += time.Since(start)
 	}
 	avgTime := int64(totalTime) / int64(runs)
@@ -9,6 +9,8 @@ import (
 	"bytes"
 
 	jsoniter "github.com/json-iterator/go"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // Marshaler is an interface for metrics that are able to serialize themselves to JSON and protobuf
@@ -29,18 +31,26 @@ type StreamJSONMarshaler interface {
 	DescribeItem(i int) string
 }
 
-// BufferContext contains the buffers used for MarshalSplitCompress so they can be shared between invocations
+// BufferContext contains the buffers used for MarshalSplitCompress so they can be shared between invocations,
+// along with the compressor to use
 type BufferContext struct {
 	CompressorInput   *bytes.Buffer
 	CompressorOutput  *bytes.Buffer
 	PrecompressionBuf *bytes.Buffer
+	Compressor        compression.Compressor
 }
 
-// DefaultBufferContext initialize the default compression buffers
+// DefaultBufferContext initialize the default compression buffers, using the default compressor
 func DefaultBufferContext() *BufferContext {
+	return NewBufferContext(compression.DefaultCompressor())
+}
+
+// NewBufferContext initialize the compression buffers, using the given compressor
+func NewBufferContext(c compression.Compressor) *BufferContext {
 	return &BufferContext{
-		bytes.NewBuffer(make([]byte, 0, 1024)),
-		bytes.NewBuffer(make([]byte, 0, 1024)),
-		bytes.NewBuffer(make([]byte, 0, 1024)),
+		CompressorInput:   bytes.NewBuffer(make([]byte, 0, 1024)),
+		CompressorOutput:  bytes.NewBuffer(make([]byte, 0, 1024)),
+		PrecompressionBuf: bytes.NewBuffer(make([]byte, 0, 1024)),
+		Compressor:        c,
 	}
 }
@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-//+build zlib
-
 package serializer
 
 import (
@@ -14,8 +12,10 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func buildSeries(numberOfSeries int) metrics.Series {
@@ -45,7 +45,19 @@ func benchmarkJSONStream(b *testing.B, passes int, sharedBuffers bool, numberOfS
 
 	for n := 0; n < b.N; n++ {
 		for i := 0; i < passes; i++ {
-			results, _ = payloadBuilder.Build(series)
+			results, _ = payloadBuilder.Build(series, compression.DefaultCompressor())
+		}
+	}
+}
+
+func benchmarkProtobufStream(b *testing.B, passes int, numberOfSeries int) {
+	series := buildSeries(numberOfSeries)
+	bufferContext := marshaler.DefaultBufferContext()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < passes; i++ {
+			results, _ = series.MarshalSplitCompress(bufferContext)
 		}
 	}
 }
@@ -55,7 +67,7 @@ func benchmarkSplit(b *testing.B, numberOfSeries int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		results, _ = split.Payloads(series, true, split.MarshalJSON)
+		results, _ = split.Payloads(series, compression.DefaultCompressor(), split.MarshalJSON)
 	}
 }
 
@@ -113,6 +125,35 @@ func BenchmarkJSONStreamSharedSmall100(b *testing.B)     { benchmarkJSONStream(b
 func BenchmarkJSONStreamSharedSmall1000(b *testing.B)    { benchmarkJSONStream(b, 1000, true, 100) }
 func BenchmarkJSONStreamSharedSmall10000(b *testing.B)   { benchmarkJSONStream(b, 10000, true, 100) }
 
+func BenchmarkProtobufStream1(b *testing.B)        { benchmarkProtobufStream(b, 1, 1) }
+func BenchmarkProtobufStream10(b *testing.B)       { benchmarkProtobufStream(b, 1, 10) }
+func BenchmarkProtobufStream100(b *testing.B)      { benchmarkProtobufStream(b, 1, 100) }
+func BenchmarkProtobufStream1000(b *testing.B)     { benchmarkProtobufStream(b, 1, 1000) }
+func BenchmarkProtobufStream10000(b *testing.B)    { benchmarkProtobufStream(b, 1, 10000) }
+func BenchmarkProtobufStream100000(b *testing.B)   { benchmarkProtobufStream(b, 1, 100000) }
+func BenchmarkProtobufStream1000000(b *testing.B)  { benchmarkProtobufStream(b, 1, 1000000) }
+func BenchmarkProtobufStream10000000(b *testing.B) { benchmarkProtobufStream(b, 1, 10000000) }
+
+// Large payloads
+func BenchmarkProtobufStreamLarge1(b *testing.B)    { benchmarkProtobufStream(b, 1, 100000) }
+func BenchmarkProtobufStreamLarge10(b *testing.B)   { benchmarkProtobufStream(b, 10, 100000) }
+func BenchmarkProtobufStreamLarge100(b *testing.B)  { benchmarkProtobufStream(b, 100, 100000) }
+func BenchmarkProtobufStreamLarge1000(b *testing.B) { benchmarkProtobufStream(b, 1000, 100000) }
+
+// Medium payloads
+func BenchmarkProtobufStreamMed1(b *testing.B)     { benchmarkProtobufStream(b, 1, 10000) }
+func BenchmarkProtobufStreamMed10(b *testing.B)    { benchmarkProtobufStream(b, 10, 10000) }
+func BenchmarkProtobufStreamMed100(b *testing.B)   { benchmarkProtobufStream(b, 100, 10000) }
+func BenchmarkProtobufStreamMed1000(b *testing.B)  { benchmarkProtobufStream(b, 1000, 10000) }
+func BenchmarkProtobufStreamMed10000(b *testing.B) { benchmarkProtobufStream(b, 10000, 10000) }
+
+// Small payloads
+func BenchmarkProtobufStreamSmall1(b *testing.B)     { benchmarkProtobufStream(b, 1, 100) }
+func BenchmarkProtobufStreamSmall10(b *testing.B)    { benchmarkProtobufStream(b, 10, 100) }
+func BenchmarkProtobufStreamSmall100(b *testing.B)   { benchmarkProtobufStream(b, 100, 100) }
+func BenchmarkProtobufStreamSmall1000(b *testing.B)  { benchmarkProtobufStream(b, 1000, 100) }
+func BenchmarkProtobufStreamSmall10000(b *testing.B) { benchmarkProtobufStream(b, 10000, 100) }
+
 func BenchmarkSplit1(b *testing.B)        { benchmarkSplit(b, 1) }
 func BenchmarkSplit10(b *testing.B)       { benchmarkSplit(b, 10) }
 func BenchmarkSplit100(b *testing.B)      { benchmarkSplit(b, 100) }
@@ -23,17 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
-var initialContentEncoding = compression.ContentEncoding
-
-func resetContentEncoding() {
-	compression.ContentEncoding = initialContentEncoding
-	initExtraHeaders()
-}
-
-func TestInitExtraHeadersNoopCompression(t *testing.T) {
-	compression.ContentEncoding = ""
-	defer resetContentEncoding()
-
+func TestInitExtraHeaders(t *testing.T) {
 	initExtraHeaders()
 
 	expected := make(http.Header)
@@ -44,44 +34,107 @@ func TestInitExtraHeadersNoopCompression(t *testing.T) {
 	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
 	expected.Set("Content-Type", protobufContentType)
 	assert.Equal(t, expected, protobufExtraHeaders)
-
-	// No "Content-Encoding" header
-	expected = make(http.Header)
-	expected.Set("Content-Type", jsonContentType)
-	assert.Equal(t, expected, jsonExtraHeadersWithCompression)
-
-	expected = make(http.Header)
-	expected.Set("Content-Type", protobufContentType)
-	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeadersWithCompression)
 }
 
-func TestInitExtraHeadersWithCompression(t *testing.T) {
-	compression.ContentEncoding = "zstd"
-	defer resetContentEncoding()
+func TestPayloadCompressionHeaders(t *testing.T) {
+	none, err := compression.NewCompressor(compression.NoneKind, compression.DefaultLevel)
+	require.NoError(t, err)
+	pc := newPayloadCompression(none)
 
-	initExtraHeaders()
+	// No "Content-Encoding" header
+	assert.Equal(t, jsonExtraHeaders, pc.headers(true))
+	assert.Equal(t, protobufExtraHeaders, pc.headers(false))
+
+	zstd, err := compression.NewCompressor(compression.ZstdKind, compression.DefaultLevel)
+	require.NoError(t, err)
+	pc = newPayloadCompression(zstd)
 
+	// "Content-Encoding" header present with correct value
 	expected := make(http.Header)
 	expected.Set("Content-Type", jsonContentType)
-	assert.Equal(t, expected, jsonExtraHeaders)
+	expected.Set("Content-Encoding", "zstd")
+	assert.Equal(t, expected, pc.headers(true))
 
 	expected = make(http.Header)
 	expected.Set("Content-Type", protobufContentType)
+	expected.Set("Content-Encoding", "zstd")
 	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeaders)
+	assert.Equal(t, expected, pc.headers(false))
 
-	// "Content-Encoding" header present with correct value
-	expected = make(http.Header)
-	expected.Set("Content-Type", jsonContentType)
-	expected.Set("Content-Encoding", compression.ContentEncoding)
-	assert.Equal(t, expected, jsonExtraHeadersWithCompression)
+	// the shared headers are left untouched
+	assert.Empty(t, jsonExtraHeaders.Get("Content-Encoding"))
+	assert.Empty(t, protobufExtraHeaders.Get("Content-Encoding"))
+}
 
-	expected = make(http.Header)
-	expected.Set("Content-Type", protobufContentType)
-	expected.Set("Content-Encoding", compression.ContentEncoding)
-	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeadersWithCompression)
+func TestPayloadCompressionsFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+
+	kinds := func() map[string]string {
+		out := make(map[string]string)
+		for t, pc := range payloadCompressionsFromConfig(mockConfig) {
+			out[t] = pc.compressor.Kind()
+		}
+		return out
+	}
+
+	assert.Equal(t, map[string]string{
+		"series":         "zlib",
+		"sketches":       "zlib",
+		"events":         "zlib",
+		"service_checks": "zlib",
+		"metadata":       "zlib",
+	}, kinds())
+
+	mockConfig.Set("serializer_compressor_kind", "gzip")
+	mockConfig.Set("serializer_compressor_overrides", map[string]interface{}{
+		"sketches": map[string]interface{}{"kind": "zstd", "level": 3},
+		"series":   map[string]interface{}{"level": 9},
+		"events":   map[string]interface{}{"kind": "zstd", "level": 42}, // invalid, uses the main compressor
+		"unknown":  map[string]interface{}{"kind": "none"},
+	})
+	defer mockConfig.Set("serializer_compressor_kind", nil)
+	defer mockConfig.Set("serializer_compressor_overrides", nil)
+
+	assert.Equal(t, map[string]string{
+		"series":         "gzip",
+		"sketches":       "zstd",
+		"events":         "gzip",
+		"service_checks": "gzip",
+		"metadata":       "gzip",
+	}, kinds())
+
+	mockConfig.Set("serializer_compressor_kind", "lz4")
+	assert.Equal(t, "zlib", kinds()["service_checks"])
+}
+
+func TestDomainCompressorsFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	keysPerDomain := map[string][]string{
+		"https://app.datadoghq.com": {"key1"},
+		"https://app.datadoghq.eu":  {"key2"},
+		"https://intake.example":    {"key3"},
+	}
+
+	assert.Empty(t, DomainCompressorsFromConfig(mockConfig, keysPerDomain))
+
+	mockConfig.Set("serializer_compressor_kind", "gzip")
+	mockConfig.Set("serializer_compressor_domains", map[string]interface{}{
+		"https://app.datadoghq.eu":  map[string]interface{}{"level": 9},
+		"https://intake.example":    map[string]interface{}{"kind": "none"},
+		"https://unknown.example":   map[string]interface{}{"kind": "zlib"},
+		"https://app.datadoghq.com": map[string]interface{}{"kind": "lz4"}, // invalid, ignored
+	})
+	defer mockConfig.Set("serializer_compressor_kind", nil)
+	defer mockConfig.Set("serializer_compressor_domains", nil)
+
+	kinds := make(map[string]string)
+	for domain, c := range DomainCompressorsFromConfig(mockConfig, keysPerDomain) {
+		kinds[domain] = c.Kind()
+	}
+	assert.Equal(t, map[string]string{
+		"https://app.datadoghq.eu": "gzip",
+		"https://intake.example":   "none",
+	}, kinds)
 }
 
 func TestAgentPayloadVersion(t *testing.T) {
@@ -98,7 +151,16 @@ var (
 	protobufString   = []byte("TO PROTOBUF")
 )
 
+var (
+	jsonExtraHeadersWithCompression     http.Header
+	protobufExtraHeadersWithCompression http.Header
+)
+
 func init() {
+	pc := newPayloadCompression(compression.DefaultCompressor())
+	jsonExtraHeadersWithCompression = pc.jsonHeaders
+	protobufExtraHeadersWithCompression = pc.protobufHeaders
+
 	jsonPayloads, _ = mkPayloads(jsonString, true)
 	protobufPayloads, _ = mkPayloads(protobufString, true)
 }
@@ -109,7 +171,7 @@ func (p *testPayload) MarshalJSON() ([]byte, error) { return jsonString, nil }
 func (p *testPayload) Marshal() ([]byte, error)     { return protobufString, nil }
 func (p *testPayload) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
 	payloads := forwarder.Payloads{}
-	payload, err := compression.Compress(nil, protobufString)
+	payload, err := bufferContext.Compressor.Compress(protobufString)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +226,7 @@ func mkPayloads(payload []byte, compress bool) (forwarder.Payloads, error) {
 	payloads := forwarder.Payloads{}
 	var err error
 	if compress {
-		payload, err = compression.Compress(nil, payload)
+		payload, err = compression.DefaultCompressor().Compress(payload)
 		if err != nil {
 			return nil, err
 		}
@@ -331,6 +393,26 @@ func TestSendV1Series(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSendSeries(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	payloads, _ := mkPayloads(protobufString, true)
+	f.On("SubmitSeries", payloads, protobufExtraHeadersWithCompression).Return(nil).Times(1)
+	config.Datadog.Set("use_v2_api.series", true)
+	defer config.Datadog.Set("use_v2_api.series", nil)
+
+	s := NewSerializer(f, nil)
+
+	payload := &testPayload{}
+	err := s.SendSeries(payload)
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+	f.AssertNotCalled(t, "SubmitV1Series")
+
+	errPayload := &testErrorPayload{}
+	err = s.SendSeries(errPayload)
+	require.NotNil(t, err)
+}
+
 func TestSendSketch(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	payloads, _ := mkPayloads(protobufString, true)
@@ -426,6 +508,7 @@ func TestSendWithDisabledKind(t *testing.T) {
 	f.AssertNotCalled(t, "SubmitV1CheckRuns")
 	f.AssertNotCalled(t, "SubmitServiceChecks")
 	f.AssertNotCalled(t, "SubmitV1Series")
+	f.AssertNotCalled(t, "SubmitSeries")
 	f.AssertNotCalled(t, "SubmitSketchSeries")
 
 	// We never disable metadata
@@ -55,10 +55,10 @@ func init() {
 
 }
 
-// CheckSizeAndSerialize Check the size of a payload and marshall it (optionally compress it)
+// CheckSizeAndSerialize Check the size of a payload and marshall it (and compress it with c)
 // The dual role makes sense as you will never serialize without checking the size of the payload
-func CheckSizeAndSerialize(m marshaler.Marshaler, compress bool, mType MarshalType) (bool, []byte, []byte, error) {
-	compressedPayload, payload, err := serializeMarshaller(m, compress, mType)
+func CheckSizeAndSerialize(m marshaler.Marshaler, c compression.Compressor, mType MarshalType) (bool, []byte, []byte, error) {
+	compressedPayload, payload, err := serializeMarshaller(m, c, mType)
 	if err != nil {
 		return false, nil, nil, err
 	}
@@ -68,11 +68,11 @@ func CheckSizeAndSerialize(m marshaler.Marshaler, compress bool, mType MarshalTy
 	return mustBeSplit, compressedPayload, payload, nil
 }
 
-// Payloads serializes a metadata payload and sends it to the forwarder
-func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarder.Payloads, error) {
+// Payloads serializes a metadata payload, compressed with c, and sends it to the forwarder
+func Payloads(m marshaler.Marshaler, c compression.Compressor, mType MarshalType) (forwarder.Payloads, error) {
 	marshallers := []marshaler.Marshaler{m}
 	smallEnoughPayloads := forwarder.Payloads{}
-	tooBig, compressedPayload, _, err := CheckSizeAndSerialize(m, compress, mType)
+	tooBig, compressedPayload, _, err := CheckSizeAndSerialize(m, c, mType)
 	if err != nil {
 		return smallEnoughPayloads, err
 	}
@@ -99,7 +99,7 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 		for _, toSplit := range tempSlice {
 			var e error
 			// we have to do this every time to get the proper payload
-			compressedPayload, payload, e := serializeMarshaller(toSplit, compress, mType)
+			compressedPayload, payload, e := serializeMarshaller(toSplit, c, mType)
 			if e != nil {
 				return smallEnoughPayloads, e
 			}
@@ -121,7 +121,7 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 			// after the payload has been split, loop through the chunks
 			for _, chunk := range chunks {
 				// serialize the payload
-				tooBigChunk, compressedPayload, _, err := CheckSizeAndSerialize(chunk, compress, mType)
+				tooBigChunk, compressedPayload, _, err := CheckSizeAndSerialize(chunk, c, mType)
 				if err != nil {
 					log.Debugf("Error serializing a chunk: %s", err)
 					continue
@@ -155,20 +155,17 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 }
 
 // serializeMarshaller serializes the marshaller and returns both the compressed and uncompressed payloads
-func serializeMarshaller(m marshaler.Marshaler, compress bool, mType MarshalType) ([]byte, []byte, error) {
+func serializeMarshaller(m marshaler.Marshaler, c compression.Compressor, mType MarshalType) ([]byte, []byte, error) {
 	var payload []byte
 	var compressedPayload []byte
 	var err error
 	payload, err = marshal(m, mType)
-	compressedPayload = payload
 	if err != nil {
 		return nil, nil, err
 	}
-	if compress {
-		compressedPayload, err = compression.Compress(nil, payload)
-		if err != nil {
-			return nil, nil, err
-		}
+	compressedPayload, err = c.Compress(payload)
+	if err != nil {
+		return nil, nil, err
 	}
 	return compressedPayload, payload, nil
 }
@@ -69,7 +69,7 @@ func testSplitPayloadsSeries(t *testing.T, numPoints int, compress bool) {
 		testSeries = append(testSeries, &point)
 	}
 
-	payloads, err := Payloads(testSeries, compress, MarshalJSON)
+	payloads, err := Payloads(testSeries, testCompressor(compress), MarshalJSON)
 	require.Nil(t, err)
 
 	originalLength := len(testSeries)
@@ -78,7 +78,7 @@ func testSplitPayloadsSeries(t *testing.T, numPoints int, compress bool) {
 		var s = map[string]metrics.Series{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compression.DefaultCompressor().Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -97,6 +97,15 @@ func testSplitPayloadsSeries(t *testing.T, numPoints int, compress bool) {
 	require.Equal(t, originalLength, newLength)
 }
 
+// testCompressor returns the default compressor, or one which does not compress if compress is false.
+func testCompressor(compress bool) compression.Compressor {
+	if compress {
+		return compression.DefaultCompressor()
+	}
+	c, _ := compression.NewCompressor(compression.NoneKind, compression.DefaultLevel)
+	return c
+}
+
 var result forwarder.Payloads
 
 func BenchmarkSplitPayloadsSeries(b *testing.B) {
@@ -119,7 +128,7 @@ func BenchmarkSplitPayloadsSeries(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		// always record the result of Payloads to prevent
 		// the compiler eliminating the function call.
-		r, _ = Payloads(testSeries, true, MarshalJSON)
+		r, _ = Payloads(testSeries, compression.DefaultCompressor(), MarshalJSON)
 
 	}
 	// ensure we actually had to split
@@ -181,7 +190,7 @@ func testSplitPayloadsEvents(t *testing.T, numPoints int, compress bool) {
 		testEvent = append(testEvent, &event)
 	}
 
-	payloads, err := Payloads(testEvent, compress, MarshalJSON)
+	payloads, err := Payloads(testEvent, testCompressor(compress), MarshalJSON)
 	require.Nil(t, err)
 
 	originalLength := len(testEvent)
@@ -190,7 +199,7 @@ func testSplitPayloadsEvents(t *testing.T, numPoints int, compress bool) {
 		var s map[string]interface{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compression.DefaultCompressor().Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -246,7 +255,7 @@ func testSplitPayloadsServiceChecks(t *testing.T, numPoints int, compress bool)
 		testServiceChecks = append(testServiceChecks, &sc)
 	}
 
-	payloads, err := Payloads(testServiceChecks, compress, MarshalJSON)
+	payloads, err := Payloads(testServiceChecks, testCompressor(compress), MarshalJSON)
 	require.Nil(t, err)
 
 	originalLength := len(testServiceChecks)
@@ -255,7 +264,7 @@ func testSplitPayloadsServiceChecks(t *testing.T, numPoints int, compress bool)
 		var s []interface{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compression.DefaultCompressor().Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -301,7 +310,7 @@ func testSplitPayloadsSketches(t *testing.T, numPoints int, compress bool) {
 		testSketchSeries[i] = metrics.Makeseries(i)
 	}
 
-	payloads, err := Payloads(testSketchSeries, compress, MarshalJSON)
+	payloads, err := Payloads(testSketchSeries, testCompressor(compress), MarshalJSON)
 	require.Nil(t, err)
 
 	var splitSketches = []metrics.SketchSeriesList{}
@@ -309,7 +318,7 @@ func testSplitPayloadsSketches(t *testing.T, numPoints int, compress bool) {
 		var s = map[string]metrics.SketchSeriesList{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compression.DefaultCompressor().Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -3,13 +3,10 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2018-present Datadog, Inc.
 
-//+build zlib
-
 package stream
 
 import (
 	"bytes"
-	"compress/zlib"
 	"errors"
 	"expvar"
 
@@ -18,11 +15,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
-const (
-	// Available is true if the code is compiled in
-	Available = true
-)
-
 var (
 	compressorExpvars    = expvar.NewMap("compressor")
 	expvarsTotalPayloads = expvar.Int{}
@@ -63,7 +55,8 @@ func init() {
 type Compressor struct {
 	input               *bytes.Buffer // temporary buffer for data that has not been compressed yet
 	compressed          *bytes.Buffer // output buffer containing the compressed payload
-	zipper              *zlib.Writer
+	compressor          compression.Compressor
+	zipper              compression.StreamCompressor
 	header              []byte // json header to print at the beginning of the payload
 	footer              []byte // json footer to append at the end of the payload
 	uncompressedWritten int    // uncompressed bytes written
@@ -76,29 +69,31 @@ type Compressor struct {
 	separator           []byte
 }
 
-func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte) (*Compressor, error) {
+// NewCompressor returns a new Compressor writing the payload compressed with c to output.
+func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte, c compression.Compressor) (*Compressor, error) {
 	// the backend accepts payloads up to 3MB compressed / 50MB uncompressed but
 	// prefers small uncompressed payloads of ~4MB
 	maxPayloadSize := config.Datadog.GetInt("serializer_max_payload_size")
 	maxUncompressedSize := config.Datadog.GetInt("serializer_max_uncompressed_payload_size")
-	c := &Compressor{
+	cmp := &Compressor{
 		header:              header,
 		footer:              footer,
 		input:               input,
 		compressed:          output,
+		compressor:          c,
 		firstItem:           true,
 		maxPayloadSize:      maxPayloadSize,
 		maxUncompressedSize: maxUncompressedSize,
 		maxUnzippedItemSize: maxPayloadSize - len(footer) - len(header),
-		maxZippedItemSize:   maxUncompressedSize - compression.CompressBound(len(footer)+len(header)),
+		maxZippedItemSize:   maxUncompressedSize - c.CompressBound(len(footer)+len(header)),
 		separator:           separator,
 	}
 
-	c.zipper = zlib.NewWriter(c.compressed)
-	n, err := c.zipper.Write(header)
-	c.uncompressedWritten += n
+	cmp.zipper = c.NewStreamCompressor(cmp.compressed)
+	n, err := cmp.zipper.Write(header)
+	cmp.uncompressedWritten += n
 
-	return c, err
+	return cmp, err
 }
 
 // checkItemSize checks that the item can fit in a payload. Worst case is used to
@@ -106,7 +101,7 @@ func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator
 // that could actually fit after compression. That said it is probably impossible
 // to have a 2MB+ item that is valid for the backend.
 func (c *Compressor) checkItemSize(data []byte) bool {
-	return len(data) < c.maxUnzippedItemSize && compression.CompressBound(len(data)) < c.maxZippedItemSize
+	return len(data) < c.maxUnzippedItemSize && c.compressor.CompressBound(len(data)) < c.maxZippedItemSize
 }
 
 // hasRoomForItem checks if the current payload has enough room to store the given item
@@ -115,7 +110,7 @@ func (c *Compressor) hasRoomForItem(item []byte) bool {
 	if !c.firstItem {
 		uncompressedDataSize += len(c.separator)
 	}
-	return compression.CompressBound(uncompressedDataSize) <= c.remainingSpace() && c.uncompressedWritten+uncompressedDataSize <= c.maxUncompressedSize
+	return c.compressor.CompressBound(uncompressedDataSize) <= c.remainingSpace() && c.uncompressedWritten+uncompressedDataSize <= c.maxUncompressedSize
 }
 
 // pack flushes the temporary uncompressed buffer input to the compression writer
@@ -127,7 +122,9 @@ func (c *Compressor) pack() error {
 		return err
 	}
 	c.uncompressedWritten += int(n)
-	c.zipper.Flush()
+	if err := c.zipper.Flush(); err != nil {
+		return err
+	}
 	c.input.Reset()
 	return nil
 }
@@ -188,7 +185,7 @@ func (c *Compressor) Close() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	// Add zlib footer and close
+	// Add compression footer and close
 	err = c.zipper.Close()
 	if err != nil {
 		return nil, err
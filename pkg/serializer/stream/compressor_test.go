@@ -3,16 +3,12 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2018-present Datadog, Inc.
 
-//+build zlib
-
 package stream
 
 import (
 	"bytes"
-	"compress/zlib"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
@@ -20,6 +16,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 var (
@@ -81,22 +78,8 @@ func (d *dummyMarshaller) MarshalSplitCompress(bufferContext *marshaler.BufferCo
 	return nil, fmt.Errorf("not implemented")
 }
 
-func decompressPayload(payload []byte) ([]byte, error) {
-	r, err := zlib.NewReader(bytes.NewReader(payload))
-	if err != nil {
-		return nil, err
-	}
-	defer r.Close()
-
-	dst, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	return dst, nil
-}
-
 func payloadToString(payload []byte) string {
-	p, err := decompressPayload(payload)
+	p, err := compression.DefaultCompressor().Decompress(payload)
 	if err != nil {
 		return err.Error()
 	}
@@ -104,7 +87,7 @@ func payloadToString(payload []byte) string {
 }
 
 func TestCompressorSimple(t *testing.T) {
-	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","))
+	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","), compression.DefaultCompressor())
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
@@ -124,7 +107,7 @@ func TestOnePayloadSimple(t *testing.T) {
 	}
 
 	builder := NewJSONPayloadBuilder(true)
-	payloads, err := builder.Build(m)
+	payloads, err := builder.Build(m, compression.DefaultCompressor())
 	require.NoError(t, err)
 	require.Len(t, payloads, 1)
 
@@ -141,7 +124,7 @@ func TestMaxCompressedSizePayload(t *testing.T) {
 	defer resetDefaults()
 
 	builder := NewJSONPayloadBuilder(true)
-	payloads, err := builder.Build(m)
+	payloads, err := builder.Build(m, compression.DefaultCompressor())
 	require.NoError(t, err)
 	require.Len(t, payloads, 1)
 
@@ -158,7 +141,7 @@ func TestTwoPayload(t *testing.T) {
 	defer resetDefaults()
 
 	builder := NewJSONPayloadBuilder(true)
-	payloads, err := builder.Build(m)
+	payloads, err := builder.Build(m, compression.DefaultCompressor())
 	require.NoError(t, err)
 	require.Len(t, payloads, 2)
 
@@ -176,10 +159,32 @@ func TestLockedCompressorProducesSamePayloads(t *testing.T) {
 
 	builderLocked := NewJSONPayloadBuilder(true)
 	builderUnLocked := NewJSONPayloadBuilder(false)
-	payloads1, err := builderLocked.Build(m)
+	payloads1, err := builderLocked.Build(m, compression.DefaultCompressor())
 	require.NoError(t, err)
-	payloads2, err := builderUnLocked.Build(m)
+	payloads2, err := builderUnLocked.Build(m, compression.DefaultCompressor())
 	require.NoError(t, err)
 
 	require.Equal(t, payloadToString(*payloads1[0]), payloadToString(*payloads2[0]))
 }
+
+func TestCompressorKinds(t *testing.T) {
+	m := &dummyMarshaller{
+		items:  []string{"A", "B", "C", "D", "E", "F"},
+		header: "{[",
+		footer: "]}",
+	}
+	for _, kind := range []string{compression.NoneKind, compression.ZlibKind, compression.GzipKind, compression.ZstdKind} {
+		t.Run(kind, func(t *testing.T) {
+			c, err := compression.NewCompressor(kind, compression.DefaultLevel)
+			require.NoError(t, err)
+
+			payloads, err := NewJSONPayloadBuilder(true).Build(m, c)
+			require.NoError(t, err)
+			require.Len(t, payloads, 1)
+
+			p, err := c.Decompress(*payloads[0])
+			require.NoError(t, err)
+			require.Equal(t, "{[A,B,C,D,E,F]}", string(p))
+		})
+	}
+}
@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2019-present Datadog, Inc.
 
-//+build zlib
-
 package stream
 
 import (
@@ -18,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -96,15 +95,16 @@ const (
 	FailOnErrItemTooBig
 )
 
-// Build serializes a metadata payload and sends it to the forwarder
-func (b *JSONPayloadBuilder) Build(m marshaler.StreamJSONMarshaler) (forwarder.Payloads, error) {
-	return b.BuildWithOnErrItemTooBigPolicy(m, DropItemOnErrItemTooBig)
+// Build serializes a metadata payload, compressed with c, and sends it to the forwarder
+func (b *JSONPayloadBuilder) Build(m marshaler.StreamJSONMarshaler, c compression.Compressor) (forwarder.Payloads, error) {
+	return b.BuildWithOnErrItemTooBigPolicy(m, DropItemOnErrItemTooBig, c)
 }
 
-// BuildWithOnErrItemTooBigPolicy serializes a metadata payload and sends it to the forwarder
+// BuildWithOnErrItemTooBigPolicy serializes a metadata payload, compressed with c, and sends it to the forwarder
 func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 	m marshaler.StreamJSONMarshaler,
-	policy OnErrItemTooBigPolicy) (forwarder.Payloads, error) {
+	policy OnErrItemTooBigPolicy,
+	c compression.Compressor) (forwarder.Payloads, error) {
 
 	var input, output *bytes.Buffer
 	if b.shareAndLockBuffers {
@@ -151,7 +151,7 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 		return nil, err
 	}
 
-	compressor, err := NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","))
+	compressor, err := NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","), c)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 			payloads = append(payloads, &payload)
 			input.Reset()
 			output.Reset()
-			compressor, err = NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","))
+			compressor, err = NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","), c)
 			if err != nil {
 				return nil, err
 			}
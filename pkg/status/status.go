@@ -330,6 +330,12 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 	json.Unmarshal(checkSchedulerStatsJSON, &checkSchedulerStats) //nolint:errcheck
 	stats["checkSchedulerStats"] = checkSchedulerStats
 
+	schedulerStats := make(map[string]interface{})
+	if schedulerVar := expvar.Get("scheduler"); schedulerVar != nil {
+		json.Unmarshal([]byte(schedulerVar.String()), &schedulerStats) //nolint:errcheck
+	}
+	stats["schedulerStats"] = schedulerStats
+
 	aggregatorStatsJSON := []byte(expvar.Get("aggregator").String())
 	aggregatorStats := make(map[string]interface{})
 	json.Unmarshal(aggregatorStatsJSON, &aggregatorStats) //nolint:errcheck
@@ -46,6 +46,7 @@ type KubeMetadataCollector struct {
 
 	namespaceLabelsAsTags map[string]string
 	globNamespaceLabels   map[string]glob.Glob
+	extractionRules       utils.TagExtractionRules
 }
 
 // Detect tries to connect to the kubelet and the API Server if the DCA is not used or the DCA.
@@ -103,6 +104,7 @@ func (c *KubeMetadataCollector) Detect(_ context.Context, out chan<- []*TagInfo)
 	c.namespaceLabelsAsTags, c.globNamespaceLabels = utils.InitMetadataAsTags(
 		config.Datadog.GetStringMapString("kubernetes_namespace_labels_as_tags"),
 	)
+	c.extractionRules = utils.GetTagExtractionRules()
 
 	return PullCollection, nil
 }
@@ -217,7 +219,7 @@ func (c *KubeMetadataCollector) isClusterAgentEnabled() bool {
 }
 
 func (c *KubeMetadataCollector) hasNamespaceLabelsAsTags() bool {
-	return len(c.namespaceLabelsAsTags) != 0 || len(c.globNamespaceLabels) != 0
+	return len(c.namespaceLabelsAsTags) != 0 || len(c.globNamespaceLabels) != 0 || c.extractionRules.HasSource(utils.NamespaceLabelsSource)
 }
 
 func kubernetesFactory() Collector {
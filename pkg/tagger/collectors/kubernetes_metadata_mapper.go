@@ -197,6 +197,7 @@ func (c *KubeMetadataCollector) getNamespaceTags(getNamespaceLabelsFromAPIServer
 	tags := utils.NewTagList()
 	for name, value := range labels {
 		utils.AddMetadataAsTags(name, value, c.namespaceLabelsAsTags, c.globNamespaceLabels, tags)
+		c.extractionRules.Apply(utils.NamespaceLabelsSource, name, value, tags)
 	}
 	return tags
 }
@@ -291,6 +291,7 @@ func TestKubeMetadataCollector_getNamespaceTags(t *testing.T) {
 		fields                fields
 		args                  args
 		namespaceLabelsAsTags map[string]string
+		extractionRules       []utils.TagExtractionRuleConfig
 		wantLow               []string
 		wantHigh              []string
 		wantOrch              []string
@@ -363,6 +364,37 @@ func TestKubeMetadataCollector_getNamespaceTags(t *testing.T) {
 				"label": "tag",
 			},
 		},
+		{
+			name: "extraction rules only",
+			args: args{
+				getNamespaceLabelsFromAPIServerFunc: func(string) (map[string]string, error) {
+					return map[string]string{
+						"cost-center": "eng-1234",
+					}, nil
+				},
+			},
+			fields: fields{
+				clusterAgentEnabled: false,
+				dcaClient:           &FakeDCAClient{},
+			},
+			extractionRules: []utils.TagExtractionRuleConfig{
+				{
+					Source:  utils.NamespaceLabelsSource,
+					Key:     "cost-center",
+					Pattern: `^([a-z]+)-([0-9]+)$`,
+					Tags:    map[string]string{"department": "$1"},
+				},
+				{
+					Source:      utils.NamespaceLabelsSource,
+					Key:         "cost-center",
+					Pattern:     `^([a-z]+)-([0-9]+)$`,
+					Tags:        map[string]string{"cost_center": "$2"},
+					Cardinality: "orchestrator",
+				},
+			},
+			wantLow:  []string{"department:eng"},
+			wantOrch: []string{"cost_center:1234"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -371,6 +403,7 @@ func TestKubeMetadataCollector_getNamespaceTags(t *testing.T) {
 				clusterAgentEnabled: tt.fields.clusterAgentEnabled,
 			}
 			c.namespaceLabelsAsTags, c.globNamespaceLabels = utils.InitMetadataAsTags(tt.namespaceLabelsAsTags)
+			c.extractionRules = utils.CompileTagExtractionRules(tt.extractionRules)
 			tags := c.getNamespaceTags(tt.args.getNamespaceLabelsFromAPIServerFunc, "foo")
 			var low, orch, high, standard []string
 			if tags != nil {
@@ -83,6 +83,7 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 
 	for name, value := range pod.Annotations {
 		utils.AddMetadataAsTags(name, value, c.annotationsAsTags, c.globAnnotations, tags)
+		c.extractionRules.Apply(utils.PodAnnotationsSource, name, value, tags)
 	}
 
 	if podTags, found := extractTagsFromMap(podTagsAnnotation, pod.Annotations); found {
@@ -166,6 +167,7 @@ func (c *WorkloadMetaCollector) extractTagsFromPodLabels(pod workloadmeta.Kubern
 		}
 
 		utils.AddMetadataAsTags(name, value, c.labelsAsTags, c.globLabels, tags)
+		c.extractionRules.Apply(utils.PodLabelsSource, name, value, tags)
 	}
 }
 
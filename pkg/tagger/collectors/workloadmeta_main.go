@@ -38,6 +38,7 @@ type WorkloadMetaCollector struct {
 	annotationsAsTags map[string]string
 	globLabels        map[string]glob.Glob
 	globAnnotations   map[string]glob.Glob
+	extractionRules   utils.TagExtractionRules
 }
 
 // Detect initializes the WorkloadMetaCollector.
@@ -48,6 +49,7 @@ func (c *WorkloadMetaCollector) Detect(ctx context.Context, out chan<- []*TagInf
 	labelsAsTags := config.Datadog.GetStringMapString("kubernetes_pod_labels_as_tags")
 	annotationsAsTags := config.Datadog.GetStringMapString("kubernetes_pod_annotations_as_tags")
 	c.init(labelsAsTags, annotationsAsTags)
+	c.extractionRules = utils.GetTagExtractionRules()
 
 	return StreamCollection, nil
 }
@@ -63,7 +65,15 @@ func (c *WorkloadMetaCollector) Stream() error {
 	const name = "tagger-workloadmeta"
 	health := health.RegisterLiveness(name)
 
-	ch := c.store.Subscribe(name, nil)
+	// processes have no tags of their own
+	ch := c.store.Subscribe(name, workloadmeta.NewFilter(
+		[]workloadmeta.Kind{
+			workloadmeta.KindContainer,
+			workloadmeta.KindKubernetesPod,
+			workloadmeta.KindECSTask,
+		},
+		nil,
+	))
 
 	for {
 		select {
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 	"github.com/stretchr/testify/assert"
@@ -100,9 +101,65 @@ func TestHandleKubePod(t *testing.T) {
 		name              string
 		labelsAsTags      map[string]string
 		annotationsAsTags map[string]string
+		extractionRules   []utils.TagExtractionRuleConfig
 		pod               workloadmeta.KubernetesPod
 		expected          []*TagInfo
 	}{
+		{
+			name: "pod with extraction rules",
+			extractionRules: []utils.TagExtractionRuleConfig{
+				{
+					Source:  utils.PodLabelsSource,
+					Key:     "team",
+					Pattern: `^(?P<org>[a-z]+)-(?P<team>[a-z]+)$`,
+					Tags:    map[string]string{"org": "${org}", "team": "${team}"},
+				},
+				{
+					Source:      utils.PodAnnotationsSource,
+					Key:         "example.com/*",
+					Pattern:     `^build-([0-9]+)$`,
+					Tags:        map[string]string{"%%annotation%%": "$1"},
+					Cardinality: "orchestrator",
+				},
+				{
+					Source:      utils.PodAnnotationsSource,
+					Key:         "example.com/owner",
+					Tags:        map[string]string{"owner": "$0"},
+					Cardinality: "high",
+				},
+			},
+			pod: workloadmeta.KubernetesPod{
+				EntityID: podEntityID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name:      podName,
+					Namespace: podNamespace,
+					Annotations: map[string]string{
+						"example.com/build": "build-42",
+						"example.com/owner": "jdoe",
+					},
+					Labels: map[string]string{
+						"team": "payments-checkout",
+					},
+				},
+			},
+			expected: []*TagInfo{
+				{
+					Source:       workloadmetaCollectorName,
+					Entity:       podTaggerEntityID,
+					HighCardTags: []string{"owner:jdoe"},
+					OrchestratorCardTags: []string{
+						fmt.Sprintf("pod_name:%s", podName),
+						"example.com/build:42",
+					},
+					LowCardTags: []string{
+						fmt.Sprintf("kube_namespace:%s", podNamespace),
+						"org:payments",
+						"team:checkout",
+					},
+					StandardTags: []string{},
+				},
+			},
+		},
 		{
 			name: "fully formed pod (no containers)",
 			annotationsAsTags: map[string]string{
@@ -326,6 +383,7 @@ func TestHandleKubePod(t *testing.T) {
 				store: store,
 			}
 			collector.init(tt.labelsAsTags, tt.annotationsAsTags)
+			collector.extractionRules = utils.CompileTagExtractionRules(tt.extractionRules)
 
 			actual := collector.handleKubePod(workloadmeta.Event{
 				Type:   workloadmeta.EventTypeSet,
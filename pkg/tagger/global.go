@@ -207,6 +207,10 @@ func OrchestratorScopeTagBuilder(tb types.TagsBuilder) error {
 
 // Stop queues a stop signal to the defaultTagger
 func Stop() error {
+	if defaultTagger == nil {
+		return nil
+	}
+
 	return defaultTagger.Stop()
 }
 
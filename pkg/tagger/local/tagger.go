@@ -8,6 +8,7 @@ package local
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -42,6 +43,10 @@ type Tagger struct {
 	store       *tagstore.TagStore
 	retryTicker *time.Ticker
 
+	snapshotPath     string
+	snapshotInterval time.Duration
+	staleTTL         time.Duration
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -84,12 +89,34 @@ func NewTagger(catalog collectors.Catalog) *Tagger {
 	return t
 }
 
+// EnablePersistence makes the tagger restore its store from the snapshot at
+// path on Init, and save it there every snapshotInterval and on Stop. Restored
+// tags are used until the collectors catch up, and expire after staleTTL if
+// they are not collected again. It must be called before Init.
+func (t *Tagger) EnablePersistence(path string, snapshotInterval, staleTTL time.Duration) {
+	t.snapshotPath = path
+	t.snapshotInterval = snapshotInterval
+	t.staleTTL = staleTTL
+}
+
 // Init goes through a catalog and tries to detect which are relevant
 // for this host. It then starts the collection logic and is ready for
 // requests.
 func (t *Tagger) Init() error {
 	t.retryTicker = time.NewTicker(30 * time.Second)
 
+	if t.snapshotPath != "" {
+		// restore the snapshot before starting the collectors, so
+		// that the tags they collect replace the restored ones
+		if _, err := os.Stat(t.snapshotPath); os.IsNotExist(err) {
+			log.Debugf("no tagger snapshot to restore at %s", t.snapshotPath)
+		} else if err := t.store.LoadSnapshot(t.snapshotPath, t.staleTTL); err != nil {
+			log.Warnf("error restoring the tagger snapshot: %s", err)
+		}
+
+		go t.runSnapshotter(t.ctx)
+	}
+
 	t.startCollectors(t.ctx)
 
 	go t.runPuller(t.ctx)
@@ -149,6 +176,28 @@ func (t *Tagger) runPuller(ctx context.Context) {
 	}
 }
 
+func (t *Tagger) runSnapshotter(ctx context.Context) {
+	snapshotTicker := time.NewTicker(t.snapshotInterval)
+	defer snapshotTicker.Stop()
+
+	for {
+		select {
+		case <-snapshotTicker.C:
+			t.saveSnapshot()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *Tagger) saveSnapshot() {
+	err := t.store.SaveSnapshot(t.snapshotPath)
+	if err != nil {
+		log.Warnf("error saving the tagger snapshot: %s", err)
+	}
+}
+
 // startCollectors iterates over the listener candidates and tries initializing them.
 // If the collector implements Retryer and return a FailWillRetry, we keep them in
 // the map and will retry at the next tick.
@@ -250,6 +299,11 @@ func (t *Tagger) pull(ctx context.Context) {
 // Stop queues a shutdown of Tagger
 func (t *Tagger) Stop() error {
 	t.cancel()
+
+	if t.snapshotPath != "" {
+		t.saveSnapshot()
+	}
+
 	return nil
 }
 
@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// snapshotVersion is bumped whenever the snapshot format changes in a way
+// older agents can't read
+const snapshotVersion = 1
+
+// snapshot is the on-disk representation of the store
+type snapshot struct {
+	Version   int                                    `json:"version"`
+	Timestamp time.Time                              `json:"timestamp"`
+	Entities  map[string]map[string]snapshotTagEntry `json:"entities"`
+}
+
+// snapshotTagEntry holds the tags of an entity collected from a single source
+type snapshotTagEntry struct {
+	LowCardTags          []string `json:"low_card_tags,omitempty"`
+	OrchestratorCardTags []string `json:"orchestrator_card_tags,omitempty"`
+	HighCardTags         []string `json:"high_card_tags,omitempty"`
+	StandardTags         []string `json:"standard_tags,omitempty"`
+}
+
+// SaveSnapshot writes the tags of the store to path, replacing the previous
+// snapshot atomically. Stale tags are left out, so that tags never confirmed
+// by a collector don't survive more than one restart.
+func (s *TagStore) SaveSnapshot(path string) error {
+	snap := s.snapshot()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the tagger snapshot: %w", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+"*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create the tagger snapshot: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+
+	err = os.Rename(tmpName, path)
+	if err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+
+	log.Debugf("Saved the tags of %d entities to %s", len(snap.Entities), path)
+
+	return nil
+}
+
+func (s *TagStore) snapshot() snapshot {
+	s.RLock()
+	defer s.RUnlock()
+
+	snap := snapshot{
+		Version:   snapshotVersion,
+		Timestamp: s.clock.Now(),
+		Entities:  make(map[string]map[string]snapshotTagEntry, len(s.store)),
+	}
+
+	for entityID, storedTags := range s.store {
+		entries := make(map[string]snapshotTagEntry, len(storedTags.sourceTags))
+
+		for source, st := range storedTags.sourceTags {
+			// tags with an expiry date belong to deleted entities or
+			// to failed lookups, which aren't worth restoring
+			if st.stale || st.isEmpty() || !st.expiryDate.IsZero() {
+				continue
+			}
+
+			entries[source] = snapshotTagEntry{
+				LowCardTags:          st.lowCardTags,
+				OrchestratorCardTags: st.orchestratorCardTags,
+				HighCardTags:         st.highCardTags,
+				StandardTags:         st.standardTags,
+			}
+		}
+
+		if len(entries) > 0 {
+			snap.Entities[entityID] = entries
+		}
+	}
+
+	return snap
+}
+
+// LoadSnapshot restores the tags saved to path by SaveSnapshot. Restored tags
+// are marked as stale until their source collects them again, and expire after
+// staleTTL otherwise. Snapshots older than staleTTL are ignored. Tags already
+// collected take precedence over the restored ones.
+func (s *TagStore) LoadSnapshot(path string, staleTTL time.Duration) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the tagger snapshot: %w", err)
+	}
+
+	snap := snapshot{}
+	err = json.Unmarshal(data, &snap)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal the tagger snapshot: %w", err)
+	}
+
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported tagger snapshot version %d", snap.Version)
+	}
+
+	now := s.clock.Now()
+	if snap.Timestamp.Add(staleTTL).Before(now) {
+		return fmt.Errorf("the tagger snapshot is outdated, it was taken at %s", snap.Timestamp)
+	}
+
+	expiryDate := now.Add(staleTTL)
+	events := []types.EntityEvent{}
+
+	s.Lock()
+	defer s.Unlock()
+
+	for entityID, entries := range snap.Entities {
+		storedTags, exist := s.store[entityID]
+		eventType := types.EventTypeModified
+		if !exist {
+			eventType = types.EventTypeAdded
+			storedTags = newEntityTags(entityID)
+		}
+
+		restored := false
+		for source, entry := range entries {
+			if _, found := storedTags.sourceTags[source]; found {
+				continue
+			}
+
+			storedTags.sourceTags[source] = sourceTags{
+				lowCardTags:          entry.LowCardTags,
+				orchestratorCardTags: entry.OrchestratorCardTags,
+				highCardTags:         entry.HighCardTags,
+				standardTags:         entry.StandardTags,
+				expiryDate:           expiryDate,
+				stale:                true,
+			}
+			restored = true
+		}
+
+		if !restored {
+			continue
+		}
+
+		storedTags.cacheValid = false
+		s.store[entityID] = storedTags
+		events = append(events, types.EntityEvent{
+			EventType: eventType,
+			Entity:    storedTags.toEntity(),
+		})
+	}
+
+	if len(events) > 0 {
+		s.notifySubscribers(events)
+	}
+
+	log.Infof("Restored the tags of %d entities from %s", len(events), path)
+
+	return nil
+}
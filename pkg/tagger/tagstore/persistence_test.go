@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tagger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tagger_snapshot.json")
+
+	clock := &fakeClock{now: time.Now()}
+	store := NewTagStore()
+	store.clock = clock
+
+	store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:               "source1",
+			Entity:               "container_id://1",
+			LowCardTags:          []string{"image_name:redis"},
+			OrchestratorCardTags: []string{"pod_name:redis-0"},
+			HighCardTags:         []string{"container_id:1"},
+			StandardTags:         []string{"service:redis"},
+		},
+		{
+			Source:      "source2",
+			Entity:      "container_id://1",
+			LowCardTags: []string{"kube_namespace:default"},
+		},
+		{
+			Source:      "source1",
+			Entity:      "container_id://2",
+			LowCardTags: []string{"image_name:nginx"},
+		},
+		{
+			// failed lookups aren't saved
+			Source:      "source1",
+			Entity:      "container_id://3",
+			LowCardTags: []string{"image_name:nginx"},
+			ExpiryDate:  clock.now.Add(time.Minute),
+		},
+	})
+
+	// deleted entities aren't saved
+	store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:       "source1",
+			Entity:       "container_id://2",
+			DeleteEntity: true,
+		},
+	})
+
+	require.NoError(t, store.SaveSnapshot(path))
+
+	restored := NewTagStore()
+	restored.clock = clock
+
+	// tags collected before the snapshot is loaded take precedence
+	restored.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "source2",
+			Entity:      "container_id://1",
+			LowCardTags: []string{"kube_namespace:prod"},
+		},
+	})
+
+	require.NoError(t, restored.LoadSnapshot(path, 5*time.Minute))
+
+	assert.Len(t, restored.store, 1)
+	tags, sources := restored.Lookup("container_id://1", collectors.HighCardinality)
+	assert.ElementsMatch(t, []string{"image_name:redis", "pod_name:redis-0", "container_id:1", "kube_namespace:prod"}, tags)
+	assert.ElementsMatch(t, []string{"source1", "source2"}, sources)
+
+	standard, err := restored.LookupStandard("container_id://1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service:redis"}, standard)
+
+	// restored tags are stale until they are collected again
+	assert.True(t, restored.store["container_id://1"].sourceTags["source1"].stale)
+	assert.False(t, restored.store["container_id://1"].sourceTags["source2"].stale)
+
+	// stale tags aren't saved again
+	require.NoError(t, restored.SaveSnapshot(path))
+	empty := NewTagStore()
+	empty.clock = clock
+	require.NoError(t, empty.LoadSnapshot(path, 5*time.Minute))
+	tags, _ = empty.Lookup("container_id://1", collectors.HighCardinality)
+	assert.Equal(t, []string{"kube_namespace:prod"}, tags)
+}
+
+func TestSnapshotStaleTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tagger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tagger_snapshot.json")
+
+	clock := &fakeClock{now: time.Now()}
+	store := NewTagStore()
+	store.clock = clock
+
+	store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "source1",
+			Entity:      "container_id://1",
+			LowCardTags: []string{"image_name:redis"},
+		},
+		{
+			Source:      "source1",
+			Entity:      "container_id://2",
+			LowCardTags: []string{"image_name:nginx"},
+		},
+	})
+	require.NoError(t, store.SaveSnapshot(path))
+
+	restored := NewTagStore()
+	restored.clock = clock
+	require.NoError(t, restored.LoadSnapshot(path, 5*time.Minute))
+
+	// the collector confirms the first container only
+	restored.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "source1",
+			Entity:      "container_id://1",
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+	assert.False(t, restored.store["container_id://1"].sourceTags["source1"].stale)
+
+	clock.now = clock.now.Add(time.Minute)
+	restored.Prune()
+	assert.Len(t, restored.store, 2)
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	restored.Prune()
+	assert.Len(t, restored.store, 1)
+	assert.Contains(t, restored.store, "container_id://1")
+
+	// outdated snapshots aren't loaded
+	outdated := NewTagStore()
+	outdated.clock = clock
+	assert.Error(t, outdated.LoadSnapshot(path, 5*time.Minute))
+	assert.Empty(t, outdated.store)
+}
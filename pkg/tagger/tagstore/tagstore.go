@@ -60,6 +60,10 @@ type sourceTags struct {
 	highCardTags         []string
 	standardTags         []string
 	expiryDate           time.Time
+
+	// stale is set on tags restored from a snapshot, until the source
+	// collects them again
+	stale bool
 }
 
 // TagStore stores entity tags in memory and handles search and collation.
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Kubernetes metadata that tag extraction rules apply to
+const (
+	PodLabelsSource       = "pod_labels"
+	PodAnnotationsSource  = "pod_annotations"
+	NodeLabelsSource      = "node_labels"
+	NamespaceLabelsSource = "namespace_labels"
+)
+
+// Cardinalities of the tags of extraction rules
+const (
+	lowCardinality          = "low"
+	orchestratorCardinality = "orchestrator"
+	highCardinality         = "high"
+)
+
+// TagExtractionRuleConfig is an entry of kubernetes_tag_extraction_rules.
+type TagExtractionRuleConfig struct {
+	// Source is the metadata the rule applies to, one of pod_labels,
+	// pod_annotations, node_labels and namespace_labels
+	Source string `mapstructure:"source" json:"source"`
+	// Key is the name of the label or annotation, with * wildcards
+	Key string `mapstructure:"key" json:"key"`
+	// Pattern is the regexp the value must match, the whole value by default
+	Pattern string `mapstructure:"pattern" json:"pattern"`
+	// Tags maps tag name templates to tag value templates, which can refer
+	// to the capture groups of Pattern as $1 or ${name}, and to the name of
+	// the label or annotation as %%label%%
+	Tags map[string]string `mapstructure:"tags" json:"tags"`
+	// Cardinality is the cardinality of the tags, low by default
+	Cardinality string `mapstructure:"cardinality" json:"cardinality"`
+}
+
+// TagExtractionRule is a compiled TagExtractionRuleConfig
+type TagExtractionRule struct {
+	key         string
+	keyGlob     glob.Glob
+	pattern     *regexp.Regexp
+	tags        map[string]string
+	cardinality string
+}
+
+// TagExtractionRules holds compiled extraction rules, indexed by source
+type TagExtractionRules map[string][]*TagExtractionRule
+
+// GetTagExtractionRules compiles the rules of kubernetes_tag_extraction_rules.
+// Invalid rules are logged and skipped.
+func GetTagExtractionRules() TagExtractionRules {
+	var configs []TagExtractionRuleConfig
+	if err := config.Datadog.UnmarshalKey("kubernetes_tag_extraction_rules", &configs); err != nil {
+		log.Errorf("Failed to read kubernetes_tag_extraction_rules: %v", err)
+		return nil
+	}
+
+	return CompileTagExtractionRules(configs)
+}
+
+// CompileTagExtractionRules compiles extraction rules. Invalid rules are
+// logged and skipped.
+func CompileTagExtractionRules(configs []TagExtractionRuleConfig) TagExtractionRules {
+	rules := make(TagExtractionRules)
+
+	for i, cfg := range configs {
+		rule, err := compileTagExtractionRule(cfg)
+		if err != nil {
+			log.Errorf("Skipping tag extraction rule #%d: %v", i, err)
+			continue
+		}
+
+		rules[cfg.Source] = append(rules[cfg.Source], rule)
+	}
+
+	return rules
+}
+
+func compileTagExtractionRule(cfg TagExtractionRuleConfig) (*TagExtractionRule, error) {
+	switch cfg.Source {
+	case PodLabelsSource, PodAnnotationsSource, NodeLabelsSource, NamespaceLabelsSource:
+	default:
+		return nil, fmt.Errorf("unknown source %q", cfg.Source)
+	}
+
+	if cfg.Key == "" {
+		return nil, fmt.Errorf("missing key")
+	}
+
+	if len(cfg.Tags) == 0 {
+		return nil, fmt.Errorf("missing tags")
+	}
+
+	rule := &TagExtractionRule{
+		// label and annotation names are matched case-insensitively,
+		// like in the *_as_tags options
+		key:         strings.ToLower(cfg.Key),
+		tags:        cfg.Tags,
+		cardinality: strings.ToLower(cfg.Cardinality),
+	}
+
+	switch rule.cardinality {
+	case "":
+		rule.cardinality = lowCardinality
+	case lowCardinality, orchestratorCardinality, highCardinality:
+	default:
+		return nil, fmt.Errorf("unknown cardinality %q", cfg.Cardinality)
+	}
+
+	if strings.Contains(rule.key, "*") {
+		g, err := glob.Compile(rule.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", cfg.Key, err)
+		}
+		rule.keyGlob = g
+	}
+
+	pattern := cfg.Pattern
+	if pattern == "" {
+		pattern = "^.*$"
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", cfg.Pattern, err)
+	}
+	rule.pattern = re
+
+	return rule, nil
+}
+
+// Apply adds to tags the tags extracted from a label or an annotation of the
+// given source by the matching rules.
+func (r TagExtractionRules) Apply(source, name, value string, tags *TagList) {
+	for _, rule := range r[source] {
+		rule.apply(name, value, tags)
+	}
+}
+
+// HasSource returns whether rules apply to the given source.
+func (r TagExtractionRules) HasSource(source string) bool {
+	return len(r[source]) > 0
+}
+
+func (r *TagExtractionRule) apply(name, value string, tags *TagList) {
+	n := strings.ToLower(name)
+	if r.keyGlob != nil {
+		if !r.keyGlob.Match(n) {
+			return
+		}
+	} else if r.key != n {
+		return
+	}
+
+	match := r.pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return
+	}
+
+	for nameTmpl, valueTmpl := range r.tags {
+		tagName := string(r.pattern.ExpandString(nil, resolveTag(nameTmpl, name), value, match))
+		tagValue := string(r.pattern.ExpandString(nil, valueTmpl, value, match))
+
+		switch r.cardinality {
+		case highCardinality:
+			tags.AddHigh(tagName, tagValue)
+		case orchestratorCardinality:
+			tags.AddOrchestrator(tagName, tagValue)
+		default:
+			tags.AddLow(tagName, tagValue)
+		}
+	}
+}
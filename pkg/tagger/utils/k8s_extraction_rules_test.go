@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileTagExtractionRules(t *testing.T) {
+	rules := CompileTagExtractionRules([]TagExtractionRuleConfig{
+		{Source: PodLabelsSource, Key: "app", Tags: map[string]string{"app": "$0"}},
+		{Source: NodeLabelsSource, Key: "topology.kubernetes.io/*", Tags: map[string]string{"zone": "$0"}, Cardinality: "HIGH"},
+		// invalid rules are skipped
+		{Source: "container_labels", Key: "app", Tags: map[string]string{"app": "$0"}},
+		{Source: PodLabelsSource, Tags: map[string]string{"app": "$0"}},
+		{Source: PodLabelsSource, Key: "app"},
+		{Source: PodLabelsSource, Key: "app", Pattern: "(", Tags: map[string]string{"app": "$0"}},
+		{Source: PodLabelsSource, Key: "app", Tags: map[string]string{"app": "$0"}, Cardinality: "medium"},
+		{Source: PodLabelsSource, Key: "[*", Tags: map[string]string{"app": "$0"}},
+	})
+
+	assert.Len(t, rules, 2)
+	assert.Len(t, rules[PodLabelsSource], 1)
+	assert.Equal(t, lowCardinality, rules[PodLabelsSource][0].cardinality)
+	assert.Len(t, rules[NodeLabelsSource], 1)
+	assert.Equal(t, highCardinality, rules[NodeLabelsSource][0].cardinality)
+	assert.True(t, rules.HasSource(NodeLabelsSource))
+	assert.False(t, rules.HasSource(NamespaceLabelsSource))
+}
+
+func TestTagExtractionRulesApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     TagExtractionRuleConfig
+		k        string
+		v        string
+		wantLow  []string
+		wantOrch []string
+		wantHigh []string
+	}{
+		{
+			name:    "whole value",
+			rule:    TagExtractionRuleConfig{Key: "app", Tags: map[string]string{"application": "$0"}},
+			k:       "app",
+			v:       "redis",
+			wantLow: []string{"application:redis"},
+		},
+		{
+			name:    "case insensitive key",
+			rule:    TagExtractionRuleConfig{Key: "App", Tags: map[string]string{"application": "$0"}},
+			k:       "aPP",
+			v:       "redis",
+			wantLow: []string{"application:redis"},
+		},
+		{
+			name: "key mismatch",
+			rule: TagExtractionRuleConfig{Key: "app", Tags: map[string]string{"application": "$0"}},
+			k:    "tier",
+			v:    "redis",
+		},
+		{
+			name: "pattern mismatch",
+			rule: TagExtractionRuleConfig{Key: "app", Pattern: "^[0-9]+$", Tags: map[string]string{"application": "$0"}},
+			k:    "app",
+			v:    "redis",
+		},
+		{
+			name: "numbered capture groups",
+			rule: TagExtractionRuleConfig{
+				Key:     "team",
+				Pattern: `^([a-z]+)-([a-z]+)$`,
+				Tags:    map[string]string{"org": "$1", "team": "$2", "full_team": "${1}_${2}"},
+			},
+			k:       "team",
+			v:       "payments-checkout",
+			wantLow: []string{"org:payments", "team:checkout", "full_team:payments_checkout"},
+		},
+		{
+			name: "named capture groups in tag names",
+			rule: TagExtractionRuleConfig{
+				Key:     "topology",
+				Pattern: `^(?P<kind>zone|region)=(?P<value>.+)$`,
+				Tags:    map[string]string{"${kind}": "${value}"},
+			},
+			k:       "topology",
+			v:       "zone=us-east-1a",
+			wantLow: []string{"zone:us-east-1a"},
+		},
+		{
+			name: "glob key with label template",
+			rule: TagExtractionRuleConfig{
+				Key:         "example.com/*",
+				Pattern:     `^v([0-9]+)\.`,
+				Tags:        map[string]string{"%%label%%_major": "$1"},
+				Cardinality: "orchestrator",
+			},
+			k:        "example.com/version",
+			v:        "v2.3.1",
+			wantOrch: []string{"example.com/version_major:2"},
+		},
+		{
+			name: "high cardinality",
+			rule: TagExtractionRuleConfig{
+				Key:         "commit",
+				Pattern:     `^[0-9a-f]{7}`,
+				Tags:        map[string]string{"short_commit": "$0"},
+				Cardinality: "high",
+			},
+			k:        "commit",
+			v:        "3b8efe0c50e8d9",
+			wantHigh: []string{"short_commit:3b8efe0"},
+		},
+		{
+			name: "empty group",
+			rule: TagExtractionRuleConfig{
+				Key:     "app",
+				Pattern: `^([a-z]*)[0-9]*$`,
+				Tags:    map[string]string{"application": "$1"},
+			},
+			k: "app",
+			v: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Source = PodLabelsSource
+			rules := CompileTagExtractionRules([]TagExtractionRuleConfig{tt.rule})
+
+			tags := NewTagList()
+			rules.Apply(PodLabelsSource, tt.k, tt.v, tags)
+			// rules only apply to their source
+			rules.Apply(PodAnnotationsSource, tt.k, tt.v, tags)
+
+			low, orch, high, _ := tags.Compute()
+			assert.ElementsMatch(t, tt.wantLow, low)
+			assert.ElementsMatch(t, tt.wantOrch, orch)
+			assert.ElementsMatch(t, tt.wantHigh, high)
+		})
+	}
+}
+
+func TestGetTagExtractionRulesEnv(t *testing.T) {
+	env := "DD_KUBERNETES_TAG_EXTRACTION_RULES"
+	err := os.Setenv(env, `[{"source":"pod_labels","key":"team","pattern":"^([a-z]+)-","tags":{"org":"$1"},"cardinality":"orchestrator"}]`)
+	assert.Nil(t, err)
+	defer os.Unsetenv(env)
+
+	rules := GetTagExtractionRules()
+	assert.Len(t, rules[PodLabelsSource], 1)
+
+	tags := NewTagList()
+	rules.Apply(PodLabelsSource, "team", "payments-checkout", tags)
+	_, orch, _, _ := tags.Compute()
+	assert.Equal(t, []string{"org:payments"}, orch)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 	"github.com/DataDog/datadog-agent/pkg/trace/stats"
+	"github.com/DataDog/datadog-agent/pkg/trace/tail"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/writer"
 	"github.com/DataDog/datadog-agent/pkg/util/fargate"
@@ -34,6 +35,21 @@ const (
 	tagContainersTags = "_dd.tags.container"
 )
 
+// Reasons of the sampling decisions, as reported by the live trace tail.
+const (
+	// samplingReasonUserReject is used for traces dropped by the tracer or the user.
+	samplingReasonUserReject = "user_reject"
+	// samplingReasonPriority is used for traces with a sampling priority set, which were
+	// neither caught by the errors sampler nor by the exception sampler.
+	samplingReasonPriority = "priority"
+	// samplingReasonNoPriority is used for traces without sampling priority and errors.
+	samplingReasonNoPriority = "no_priority"
+	// samplingReasonError is used for traces containing errors.
+	samplingReasonError = "error"
+	// samplingReasonRare is used for traces kept by the exception sampler.
+	samplingReasonRare = "rare"
+)
+
 // Agent struct holds all the sub-routines structs and make the data flow between them
 type Agent struct {
 	Receiver              *api.HTTPReceiver
@@ -42,6 +58,7 @@ type Agent struct {
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	Scrubber              *filters.Scrubber
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	ExceptionSampler      *sampler.ExceptionSampler
@@ -76,6 +93,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsChan),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		Scrubber:              filters.NewScrubber(conf.ScrubTags),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
 		ExceptionSampler:      sampler.NewExceptionSampler(),
@@ -233,6 +251,7 @@ func (a *Agent) Process(p *api.Payload) {
 			}
 		}
 		a.Replacer.Replace(t)
+		a.Scrubber.Scrub(t, ts.TagsScrubbed)
 
 		{
 			// this section sets up any necessary tags on the root:
@@ -266,7 +285,8 @@ func (a *Agent) Process(p *api.Payload) {
 			ClientDroppedP0s: p.ClientDroppedP0s > 0,
 		}
 
-		events, keep := a.sample(ts, pt)
+		events, keep, reason := a.sample(ts, pt)
+		a.Receiver.Tail.Handle(t, env, tail.Sampling{Keep: keep, Reason: reason})
 		if !p.ClientComputedStats {
 			if envtraces == nil {
 				envtraces = make([]stats.EnvTrace, 0, len(p.Traces))
@@ -361,8 +381,8 @@ func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang, tracerVersion strin
 }
 
 // sample decides whether the trace will be kept and extracts any APM events
-// from it.
-func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span, keep bool) {
+// from it. It also returns the reason of the sampling decision.
+func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span, keep bool, reason string) {
 	priority, hasPriority := sampler.GetSamplingPriority(pt.Root)
 
 	if hasPriority {
@@ -372,22 +392,22 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span,
 	}
 
 	if priority < 0 {
-		return nil, false
+		return nil, false, samplingReasonUserReject
 	}
 
-	sampled := a.runSamplers(pt, hasPriority)
+	sampled, reason := a.runSamplers(pt, hasPriority)
 
 	events, numExtracted := a.EventProcessor.Process(pt.Root, pt.Trace)
 
 	atomic.AddInt64(&ts.EventsExtracted, int64(numExtracted))
 	atomic.AddInt64(&ts.EventsSampled, int64(len(events)))
 
-	return events, sampled
+	return events, sampled, reason
 }
 
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
-// along with the sampling rate.
-func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) bool {
+// along with its reason.
+func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) (bool, string) {
 	if hasPriority {
 		return a.samplePriorityTrace(pt)
 	}
@@ -397,23 +417,26 @@ func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) bool {
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
 // ErrorSampler are run in parallel. The ExceptionSampler catches traces with rare top-level
 // or measured spans that are not caught by PrioritySampler and ErrorSampler.
-func (a *Agent) samplePriorityTrace(pt ProcessedTrace) bool {
+func (a *Agent) samplePriorityTrace(pt ProcessedTrace) (bool, string) {
 	if a.PrioritySampler.Sample(pt.Trace, pt.Root, pt.Env, pt.ClientDroppedP0s) {
-		return true
+		return true, samplingReasonPriority
 	}
 	if traceContainsError(pt.Trace) {
-		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env)
+		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env), samplingReasonError
+	}
+	if a.ExceptionSampler.Sample(pt.Trace, pt.Root, pt.Env) {
+		return true, samplingReasonRare
 	}
-	return a.ExceptionSampler.Sample(pt.Trace, pt.Root, pt.Env)
+	return false, samplingReasonPriority
 }
 
 // sampleNoPriorityTrace samples traces with no priority set on them. The traces
 // get sampled by either the score sampler or the error sampler if they have an error.
-func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) bool {
+func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) (bool, string) {
 	if traceContainsError(pt.Trace) {
-		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env)
+		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env), samplingReasonError
 	}
-	return a.NoPrioritySampler.Sample(pt.Trace, pt.Root, pt.Env)
+	return a.NoPrioritySampler.Sample(pt.Trace, pt.Root, pt.Env), samplingReasonNoPriority
 }
 
 func traceContainsError(trace pb.Trace) bool {
@@ -563,34 +563,42 @@ func TestSampling(t *testing.T) {
 
 		// wantSampled is the expected result
 		wantSampled bool
+		// wantReason is the expected reason of the sampling decision
+		wantReason string
 	}{
 		"nopriority-unsampled": {
 			noPrioritySampled: false,
 			wantSampled:       false,
+			wantReason:        samplingReasonNoPriority,
 		},
 		"nopriority-sampled": {
 			noPrioritySampled: true,
 			wantSampled:       true,
+			wantReason:        samplingReasonNoPriority,
 		},
 		"prio-unsampled": {
 			hasPriority:     true,
 			prioritySampled: false,
 			wantSampled:     false,
+			wantReason:      samplingReasonPriority,
 		},
 		"prio-sampled": {
 			hasPriority:     true,
 			prioritySampled: true,
 			wantSampled:     true,
+			wantReason:      samplingReasonPriority,
 		},
 		"error-unsampled": {
 			hasErrors:     true,
 			errorsSampled: false,
 			wantSampled:   false,
+			wantReason:    samplingReasonError,
 		},
 		"error-sampled": {
 			hasErrors:     true,
 			errorsSampled: true,
 			wantSampled:   true,
+			wantReason:    samplingReasonError,
 		},
 		"error-sampled-prio-unsampled": {
 			hasErrors:       true,
@@ -598,6 +606,7 @@ func TestSampling(t *testing.T) {
 			errorsSampled:   true,
 			prioritySampled: false,
 			wantSampled:     true,
+			wantReason:      samplingReasonError,
 		},
 		"error-unsampled-prio-sampled": {
 			hasErrors:       true,
@@ -605,6 +614,7 @@ func TestSampling(t *testing.T) {
 			errorsSampled:   false,
 			prioritySampled: true,
 			wantSampled:     true,
+			wantReason:      samplingReasonPriority,
 		},
 		"error-prio-sampled": {
 			hasErrors:       true,
@@ -612,6 +622,7 @@ func TestSampling(t *testing.T) {
 			errorsSampled:   true,
 			prioritySampled: true,
 			wantSampled:     true,
+			wantReason:      samplingReasonPriority,
 		},
 		"error-prio-unsampled": {
 			hasErrors:       true,
@@ -619,6 +630,7 @@ func TestSampling(t *testing.T) {
 			errorsSampled:   false,
 			prioritySampled: false,
 			wantSampled:     false,
+			wantReason:      samplingReasonError,
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -655,8 +667,9 @@ func TestSampling(t *testing.T) {
 				}
 			}
 
-			sampled := a.runSamplers(pt, tt.hasPriority)
+			sampled, reason := a.runSamplers(pt, tt.hasPriority)
 			assert.EqualValues(t, tt.wantSampled, sampled)
+			assert.Equal(t, tt.wantReason, reason)
 		})
 	}
 }
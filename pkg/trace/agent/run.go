@@ -27,6 +27,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+	"github.com/DataDog/datadog-agent/pkg/trace/tail"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -78,6 +80,36 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if flags.Tail {
+		filters := tail.Filters{
+			Service:    flags.TailService,
+			Resource:   flags.TailResource,
+			ErrorsOnly: flags.TailErrors,
+		}
+		if err := tail.Stream(ctx, os.Stdout, cfg, filters); err != nil {
+			osutil.Exitf("Failed to stream traces: %s", err)
+		}
+		return
+	}
+
+	if flags.Capture {
+		path, err := replay.StartCapture(cfg, flags.CaptureDuration, flags.CaptureCompressed)
+		if err != nil {
+			osutil.Exitf("Failed to start capture: %s", err)
+		}
+		fmt.Printf("Capturing payloads for %s to %s\n", flags.CaptureDuration, path)
+		return
+	}
+
+	if flags.Replay != "" {
+		sent, refused, err := replay.ReplayFile(ctx, cfg, flags.Replay, flags.ReplaySpeed)
+		fmt.Printf("Replayed %d payloads (%d refused by the agent)\n", sent, refused)
+		if err != nil {
+			osutil.Exitf("Failed to replay %s: %s", flags.Replay, err)
+		}
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		cfg.LogLevel,
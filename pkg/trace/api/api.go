@@ -44,7 +44,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/tail"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -70,6 +72,8 @@ func putBuffer(buffer *bytes.Buffer) {
 type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
+	Tail        *tail.Receiver
+	Capture     *replay.Capture
 
 	out              chan *Payload
 	conf             *config.AgentConfig
@@ -99,6 +103,8 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 	return &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
+		Tail:        tail.NewReceiver(),
+		Capture:     replay.NewCapture(conf.CapturePath),
 
 		out:              out,
 		statsProcessor:   statsProcessor,
@@ -154,6 +160,10 @@ func (r *HTTPReceiver) Start() {
 		WriteTimeout: timeout,
 		ErrorLog:     stdlog.New(httpLogger, "http.Server: ", 0),
 		Handler:      mux,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			// allows long-lived handlers to reset the connection deadlines
+			return context.WithValue(ctx, connContextKey, c)
+		},
 	}
 
 	addr := fmt.Sprintf("%s:%d", r.conf.ReceiverHost, r.conf.ReceiverPort)
@@ -232,6 +242,13 @@ func (r *HTTPReceiver) attachDebugHandlers(mux *http.ServeMux) {
 		runtime.SetBlockProfileRate(0)
 	})
 
+	if r.conf.TailEnabled {
+		mux.HandleFunc(tail.Path, r.handleTail)
+	}
+	if r.conf.CaptureEnabled {
+		mux.HandleFunc(replay.Path, r.handleCapture)
+	}
+
 	mux.Handle("/debug/vars", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// allow the GUI to call this endpoint so that the status can be reported
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+mainconfig.Datadog.GetString("GUI_port"))
@@ -284,6 +301,8 @@ func (r *HTTPReceiver) Stop() error {
 	<-r.exit
 
 	r.RateLimiter.Stop()
+	r.Tail.Close()
+	r.Capture.Stop()
 
 	expiry := time.Now().Add(5 * time.Second) // give it 5 seconds
 	ctx, cancel := context.WithDeadline(context.Background(), expiry)
@@ -305,6 +324,7 @@ func (r *HTTPReceiver) handleWithVersion(v Version, f func(Version, http.Respons
 		}
 
 		// TODO(x): replace with http.MaxBytesReader?
+		r.captureRequest(req)
 		req.Body = apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 
 		f(v, w, req)
@@ -445,6 +465,7 @@ func (r *HTTPReceiver) handleStats(w http.ResponseWriter, req *http.Request) {
 	defer timing.Since("datadog.trace_agent.receiver.stats_process_ms", time.Now())
 
 	ts := r.tagStats(v06, req.Header)
+	r.captureRequest(req)
 	rd := apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 	req.Header.Set("Accept", "application/msgpack")
 	var in pb.ClientStatsPayload
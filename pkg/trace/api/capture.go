@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+)
+
+// defaultCaptureDuration specifies the duration of captures started without an explicit duration.
+const defaultCaptureDuration = time.Minute
+
+// handleCapture starts capturing the incoming payloads to a file, for the duration and
+// with the compression specified in the query string. It replies with the file path.
+func (r *HTTPReceiver) handleCapture(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := req.URL.Query()
+	d := defaultCaptureDuration
+	if v := q.Get("duration"); v != "" {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			http.Error(w, "duration must be a valid duration (e.g. 30s)", http.StatusBadRequest)
+			return
+		}
+	}
+	var compressed bool
+	if v := q.Get("compressed"); v != "" {
+		var err error
+		if compressed, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "compressed must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+	path, err := r.Capture.Start(d, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(replay.CaptureResponse{Path: path})
+}
+
+// captureRequest adds req to the ongoing capture, if any. The body is read ahead of the
+// handler, so req.Body is replaced with a reader returning the same contents and error.
+func (r *HTTPReceiver) captureRequest(req *http.Request) {
+	if !r.Capture.IsOngoing() {
+		return
+	}
+	body, err := ioutil.ReadAll(apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes))
+	req.Body = capturedBody{
+		Reader: io.MultiReader(bytes.NewReader(body), errReader{err}),
+		Closer: req.Body,
+	}
+	if err != nil {
+		// incomplete payloads are not captured
+		return
+	}
+	r.Capture.Record(req, body)
+}
+
+// capturedBody is a request body which was read ahead for capture.
+type capturedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader is a reader which always returns err, or io.EOF if err is nil.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err == nil {
+		return 0, io.EOF
+	}
+	return 0, r.err
+}
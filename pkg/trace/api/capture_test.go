@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/replay"
+	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.CapturePath = t.TempDir()
+	r := newTestReceiverFromConfig(conf)
+
+	t.Run("bad-request", func(t *testing.T) {
+		for _, tt := range []struct {
+			method, url string
+			code        int
+		}{
+			{http.MethodGet, replay.Path, http.StatusMethodNotAllowed},
+			{http.MethodPost, replay.Path + "?duration=forever", http.StatusBadRequest},
+			{http.MethodPost, replay.Path + "?duration=-1s", http.StatusBadRequest},
+			{http.MethodPost, replay.Path + "?compressed=maybe", http.StatusBadRequest},
+		} {
+			rr := httptest.NewRecorder()
+			r.handleCapture(rr, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.code, rr.Code, tt.url)
+		}
+		assert.False(t, r.Capture.IsOngoing())
+	})
+
+	rr := httptest.NewRecorder()
+	r.handleCapture(rr, httptest.NewRequest(http.MethodPost, replay.Path+"?duration=1m", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp replay.CaptureResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.True(t, r.Capture.IsOngoing())
+
+	// the handlers keep receiving the whole payload
+	bts, err := testutil.GetTestTraces(2, 2, true).MarshalMsg(nil)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/v0.4/traces", bytes.NewReader(bts))
+	req.Header.Set("Content-Type", "application/msgpack")
+	req.Header.Set("Datadog-Meta-Lang", "go")
+	rr = httptest.NewRecorder()
+	r.handleWithVersion(v04, r.handleTraces).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	p := <-r.out
+	assert.Len(t, p.Traces, 2)
+
+	// payloads over the size limit are refused as usual, and not captured
+	r.conf.MaxRequestBytes = 10
+	req = httptest.NewRequest(http.MethodPost, "/v0.4/traces", bytes.NewReader(bts))
+	req.Header.Set("Content-Type", "application/msgpack")
+	rr = httptest.NewRecorder()
+	r.handleWithVersion(v04, r.handleTraces).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	r.Capture.Stop()
+	f, err := os.Open(resp.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	rd, err := replay.NewReader(f)
+	require.NoError(t, err)
+	rec, err := rd.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "/v0.4/traces", rec.Path)
+	assert.Equal(t, "go", rec.Header.Get("Datadog-Meta-Lang"))
+	assert.Equal(t, bts, rec.Body)
+	_, err = rd.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCaptureEndpointDisabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		conf := newTestReceiverConfig()
+		conf.CaptureEnabled = enabled
+		r := newTestReceiverFromConfig(conf)
+		_, pattern := r.buildMux().Handler(httptest.NewRequest(http.MethodPost, replay.Path, nil))
+		if enabled {
+			assert.Equal(t, replay.Path, pattern)
+		} else {
+			assert.Empty(t, pattern)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/tail"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// contextKey is the type of the keys used to store values in request contexts.
+type contextKey struct{ name string }
+
+// connContextKey references the connection of an HTTP request in its context.
+var connContextKey = &contextKey{"http-connection"}
+
+// handleTail streams the traces processed by the agent which match the filters
+// found in the query string, as newline-delimited JSON. Only one client may
+// stream at a time.
+func (r *HTTPReceiver) handleTail(w http.ResponseWriter, req *http.Request) {
+	filters, err := tail.FiltersFromQuery(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	traces, ok := r.Tail.Subscribe(filters)
+	if !ok {
+		http.Error(w, "Another client is already streaming traces.", http.StatusConflict)
+		return
+	}
+	log.Infof("Started streaming traces to %s.", req.RemoteAddr)
+	defer func() {
+		dropped := r.Tail.Unsubscribe(traces)
+		log.Infof("Stopped streaming traces to %s (%d traces dropped).", req.RemoteAddr, dropped)
+	}()
+
+	// the stream outlives the server timeouts
+	if conn, ok := req.Context().Value(connContextKey).(net.Conn); ok {
+		_ = conn.SetDeadline(time.Time{})
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case b, ok := <-traces:
+			if !ok {
+				// the receiver is stopping
+				return
+			}
+			if _, err := w.Write(b); err != nil {
+				return
+			}
+			if len(traces) == 0 {
+				// flush once caught up, so that the client is up to date
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/tail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTail(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	srv := httptest.NewServer(http.HandlerFunc(r.handleTail))
+	defer srv.Close()
+
+	t.Run("bad-filters", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + tail.Path + "?errors_only=maybe")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	resp, err := http.Get(srv.URL + tail.Path + "?service=web")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	require.True(t, r.Tail.Enabled())
+
+	t.Run("conflict", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + tail.Path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	r.Tail.Handle(pb.Trace{{Service: "db"}}, "prod", tail.Sampling{})
+	r.Tail.Handle(pb.Trace{{Service: "web", Resource: "GET /"}}, "prod", tail.Sampling{Keep: true, Reason: "priority"})
+
+	var got tail.Trace
+	line, err := bufio.NewReader(resp.Body).ReadBytes('\n')
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(line, &got))
+	assert.Equal(t, "web", got.Spans[0].Service)
+	assert.Equal(t, tail.Sampling{Keep: true, Reason: "priority"}, got.Sampling)
+
+	resp.Body.Close()
+	assert.Eventually(t, func() bool { return !r.Tail.Enabled() }, time.Second, 10*time.Millisecond)
+}
+
+func TestTailEndpointDisabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		conf := newTestReceiverConfig()
+		conf.TailEnabled = enabled
+		r := newTestReceiverFromConfig(conf)
+		_, pattern := r.buildMux().Handler(httptest.NewRequest("GET", tail.Path, nil))
+		if enabled {
+			assert.Equal(t, tail.Path, pattern)
+		} else {
+			assert.Empty(t, pattern)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -62,6 +63,9 @@ type ObfuscationConfig struct {
 	// HTTP holds the obfuscation settings for HTTP URLs.
 	HTTP HTTPObfuscationConfig `mapstructure:"http"`
 
+	// SQL holds the obfuscation settings for SQL queries.
+	SQL SQLObfuscationConfig `mapstructure:"sql"`
+
 	// RemoveStackTraces specifies whether stack traces should be removed.
 	// More specifically "error.stack" tag values will be cleared.
 	RemoveStackTraces bool `mapstructure:"remove_stack_traces"`
@@ -84,6 +88,44 @@ type HTTPObfuscationConfig struct {
 	RemovePathDigits bool `mapstructure:"remove_paths_with_digits" json:"remove_path_digits"`
 }
 
+// SQLObfuscationConfig holds the configuration settings for SQL obfuscation.
+type SQLObfuscationConfig struct {
+	// TableNames specifies whether the names of the tables addressed by queries should be
+	// collected into the "sql.tables" tag.
+	TableNames bool `mapstructure:"table_names"`
+
+	// CollectCommands specifies whether the commands run by queries (e.g. SELECT, INSERT)
+	// should be collected into the "sql.commands" tag.
+	CollectCommands bool `mapstructure:"collect_commands"`
+
+	// CollectProcedures specifies whether the names of the procedures called by queries
+	// should be collected into the "sql.procedures" tag.
+	CollectProcedures bool `mapstructure:"collect_procedures"`
+}
+
+// ScrubConfig holds the configuration for scrubbing personally identifiable
+// information from span meta and metrics.
+type ScrubConfig struct {
+	// Enabled specifies whether scrubbing is enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeyPatterns specifies glob patterns (e.g. "*password*" or "*.token") matching the
+	// keys of tags that should be scrubbed entirely. Matching is case insensitive.
+	KeyPatterns []string `mapstructure:"key_patterns"`
+
+	// KeyRes holds the compiled KeyPatterns and is only used internally.
+	KeyRes []*regexp.Regexp `mapstructure:"-"`
+
+	// CreditCards specifies whether credit card numbers passing the Luhn check should be scrubbed.
+	CreditCards bool `mapstructure:"credit_cards"`
+
+	// Emails specifies whether email addresses should be scrubbed.
+	Emails bool `mapstructure:"emails"`
+
+	// BearerTokens specifies whether bearer tokens (e.g. "Bearer abc.def") should be scrubbed.
+	BearerTokens bool `mapstructure:"bearer_tokens"`
+}
+
 // Enablable can represent any option that has an "enabled" boolean sub-field.
 type Enablable struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -250,6 +292,12 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if k := "apm_config.max_payload_size"; config.Datadog.IsSet(k) {
 		c.MaxRequestBytes = config.Datadog.GetInt64(k)
 	}
+	c.CapturePath = config.Datadog.GetString("apm_config.capture_path")
+	if c.CapturePath == "" {
+		c.CapturePath = filepath.Join(config.Datadog.GetString("run_path"), "trace_capture")
+	}
+	c.CaptureEnabled = config.Datadog.GetBool("apm_config.capture_enabled")
+	c.TailEnabled = config.Datadog.GetBool("apm_config.tail_enabled")
 	if k := "apm_config.replace_tags"; config.Datadog.IsSet(k) {
 		rt := make([]*ReplaceRule, 0)
 		if err := config.Datadog.UnmarshalKey(k, &rt); err != nil {
@@ -300,6 +348,18 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if k := "apm_config.scrub_tags"; config.Datadog.IsSet(k) {
+		var sc ScrubConfig
+		if err := config.Datadog.UnmarshalKey(k, &sc); err != nil {
+			log.Errorf("Bad format for %q: %v", k, err)
+		} else {
+			if err := compileScrubKeyPatterns(&sc); err != nil {
+				osutil.Exitf("scrub_tags: %s", err)
+			}
+			c.ScrubTags = &sc
+		}
+	}
+
 	if config.Datadog.IsSet("apm_config.filter_tags.require") {
 		tags := config.Datadog.GetStringSlice("apm_config.filter_tags.require")
 		for _, tag := range tags {
@@ -446,6 +506,24 @@ func compileReplaceRules(rules []*ReplaceRule) error {
 	return nil
 }
 
+// compileScrubKeyPatterns compiles the glob key patterns found in the scrub configuration.
+// If it fails it returns the first error.
+func compileScrubKeyPatterns(sc *ScrubConfig) error {
+	sc.KeyRes = make([]*regexp.Regexp, 0, len(sc.KeyPatterns))
+	for _, p := range sc.KeyPatterns {
+		if p == "" {
+			return errors.New("key patterns must not be empty")
+		}
+		expr := strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(regexp.QuoteMeta(p))
+		re, err := regexp.Compile("(?i)^" + expr + "$")
+		if err != nil {
+			return fmt.Errorf("key pattern %q: %s", p, err)
+		}
+		sc.KeyRes = append(sc.KeyRes, re)
+	}
+	return nil
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
@@ -28,6 +28,32 @@ func TestParseRepaceRules(t *testing.T) {
 	}
 }
 
+func TestCompileScrubKeyPatterns(t *testing.T) {
+	assert := assert.New(t)
+	sc := &ScrubConfig{KeyPatterns: []string{"*password*", "user.?sn", "api_key"}}
+	assert.NoError(compileScrubKeyPatterns(sc))
+	assert.Len(sc.KeyRes, 3)
+	for k, want := range map[string]int{
+		"db.password":    0,
+		"PASSWORD":       0,
+		"user.ssn":       1,
+		"user.xsn":       1,
+		"userxssn":       -1,
+		"api_key":        2,
+		"api_key.length": -1,
+	} {
+		got := -1
+		for i, re := range sc.KeyRes {
+			if re.MatchString(k) {
+				got = i
+				break
+			}
+		}
+		assert.Equal(want, got, k)
+	}
+	assert.Error(compileScrubKeyPatterns(&ScrubConfig{KeyPatterns: []string{""}}))
+}
+
 func TestSplitTag(t *testing.T) {
 	for _, tt := range []struct {
 		tag string
@@ -77,7 +77,10 @@ type AgentConfig struct {
 	ReceiverSocket  string // if not empty, UDS will be enabled on unix://<receiver_socket>
 	ConnectionLimit int    // for rate-limiting, how many unique connections to allow in a lease period (30s)
 	ReceiverTimeout int
-	MaxRequestBytes int64 // specifies the maximum allowed request size for incoming trace payloads
+	MaxRequestBytes int64  // specifies the maximum allowed request size for incoming trace payloads
+	CapturePath     string // directory where captures of the incoming payloads are written
+	CaptureEnabled  bool   // enables the debug endpoint starting captures of the incoming payloads
+	TailEnabled     bool   // enables the debug endpoint streaming the processed traces
 
 	// Writers
 	SynchronousFlushing     bool // Mode where traces are only submitted when FlushAsync is called, used for Serverless Extension
@@ -110,6 +113,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// ScrubTags holds the settings for scrubbing personally identifiable information
+	// from span tags. It is nil when not configured.
+	ScrubTags *ScrubConfig
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// scrubbedValue is the value replacing scrubbed information.
+const scrubbedValue = "?"
+
+var (
+	// emailRe matches email addresses.
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// bearerTokenRe matches bearer tokens as found in Authorization headers, capturing the scheme.
+	bearerTokenRe = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	// creditCardRe matches credit card number candidates: 13 to 19 digits, optionally
+	// separated by spaces or dashes. Candidates are further validated using the Luhn algorithm.
+	creditCardRe = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+)
+
+// Scrubber is a filter which removes personally identifiable information from span
+// tags, either based on the tag keys or on the tag values. It keeps all spans.
+type Scrubber struct {
+	keys         []*regexp.Regexp
+	creditCards  bool
+	emails       bool
+	bearerTokens bool
+}
+
+// NewScrubber returns a new Scrubber using the given configuration. It returns nil
+// if the configuration is nil or disabled. A nil Scrubber does nothing.
+func NewScrubber(conf *config.ScrubConfig) *Scrubber {
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+	return &Scrubber{
+		keys:         conf.KeyRes,
+		creditCards:  conf.CreditCards,
+		emails:       conf.Emails,
+		bearerTokens: conf.BearerTokens,
+	}
+}
+
+// Scrub scrubs the meta and metrics of all the spans in the given trace, counting
+// scrubbed tags by rule into stats.
+func (s *Scrubber) Scrub(trace pb.Trace, stats *info.TagsScrubbed) {
+	if s == nil {
+		return
+	}
+	if stats == nil {
+		stats = &info.TagsScrubbed{}
+	}
+	for _, span := range trace {
+		for k, v := range span.Meta {
+			if isInternalTag(k) {
+				continue
+			}
+			if s.matchesKey(k) {
+				if v != scrubbedValue {
+					span.Meta[k] = scrubbedValue
+					atomic.AddInt64(&stats.KeyPattern, 1)
+				}
+				continue
+			}
+			if v2 := s.scrubValue(v, stats); v2 != v {
+				span.Meta[k] = v2
+			}
+		}
+		for k, v := range span.Metrics {
+			if isInternalTag(k) {
+				continue
+			}
+			if s.matchesKey(k) {
+				delete(span.Metrics, k)
+				atomic.AddInt64(&stats.KeyPattern, 1)
+				continue
+			}
+			if s.creditCards && isCreditCardNumber(v) {
+				delete(span.Metrics, k)
+				atomic.AddInt64(&stats.CreditCard, 1)
+			}
+		}
+	}
+}
+
+// isInternalTag reports whether the tag key k is reserved for internal use by
+// tracers and the agent, in which case it is never scrubbed.
+func isInternalTag(k string) bool {
+	return strings.HasPrefix(k, "_")
+}
+
+// matchesKey reports whether the tag key k matches one of the configured key patterns.
+func (s *Scrubber) matchesKey(k string) bool {
+	for _, re := range s.keys {
+		if re.MatchString(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubValue returns v with all the personally identifiable information it contains replaced.
+func (s *Scrubber) scrubValue(v string, stats *info.TagsScrubbed) string {
+	if s.emails && strings.IndexByte(v, '@') != -1 && emailRe.MatchString(v) {
+		v = emailRe.ReplaceAllLiteralString(v, scrubbedValue)
+		atomic.AddInt64(&stats.Email, 1)
+	}
+	if s.bearerTokens && len(v) > 7 && bearerTokenRe.MatchString(v) {
+		v = bearerTokenRe.ReplaceAllString(v, "${1}"+scrubbedValue)
+		atomic.AddInt64(&stats.BearerToken, 1)
+	}
+	if s.creditCards && countDigits(v) >= 13 {
+		var found bool
+		v = creditCardRe.ReplaceAllStringFunc(v, func(m string) string {
+			if !luhnValid(m) {
+				return m
+			}
+			found = true
+			return scrubbedValue
+		})
+		if found {
+			atomic.AddInt64(&stats.CreditCard, 1)
+		}
+	}
+	return v
+}
+
+// countDigits returns the number of ASCII digits in v.
+func countDigits(v string) int {
+	var n int
+	for i := 0; i < len(v); i++ {
+		if '0' <= v[i] && v[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// isCreditCardNumber reports whether the metric value f is an integer which is a valid
+// credit card number.
+func isCreditCardNumber(f float64) bool {
+	if f < 1e12 || f >= 1e19 || f != math.Trunc(f) {
+		return false
+	}
+	return luhnValid(strconv.FormatUint(uint64(f), 10))
+}
+
+// luhnValid reports whether the digits found in number (ignoring spaces and dashes) are
+// between 13 and 19 and pass the Luhn checksum.
+// See: https://en.wikipedia.org/wiki/Luhn_algorithm
+func luhnValid(number string) bool {
+	var (
+		sum    int
+		digits int
+		double bool
+	)
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
@@ -5,7 +5,10 @@
 
 package flags
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 var (
 	// ConfigPath specifies the path to the configuration file.
@@ -23,6 +26,30 @@ var (
 	// Info will display information about a running agent.
 	Info bool
 
+	// Tail will stream the traces processed by a running agent.
+	Tail bool
+
+	// TailService, TailResource and TailErrors filter the traces streamed by Tail
+	// by service, resource and error status.
+	TailService  string
+	TailResource string
+	TailErrors   bool
+
+	// Capture will start capturing the payloads received by a running agent.
+	Capture bool
+
+	// CaptureDuration specifies the duration of the capture started by Capture.
+	CaptureDuration time.Duration
+
+	// CaptureCompressed will compress the file written by Capture.
+	CaptureCompressed bool
+
+	// Replay specifies the path of a capture file to replay against a running agent.
+	Replay string
+
+	// ReplaySpeed specifies the speed at which Replay sends the captured payloads.
+	ReplaySpeed float64
+
 	// CPUProfile specifies the path to output CPU profiling information to.
 	// When empty, CPU profiling is disabled.
 	CPUProfile string
@@ -47,6 +74,19 @@ func init() {
 	flag.BoolVar(&Version, "version", false, "Show version information and exit")
 	flag.BoolVar(&Info, "info", false, "Show info about running trace agent process and exit")
 
+	// live trace tail
+	flag.BoolVar(&Tail, "tail", false, "Stream the traces processed by the running trace agent as JSON")
+	flag.StringVar(&TailService, "tail-service", "", "Only stream traces having a span with this service (used with -tail)")
+	flag.StringVar(&TailResource, "tail-resource", "", "Only stream traces having a span whose resource contains this string (used with -tail)")
+	flag.BoolVar(&TailErrors, "tail-errors", false, "Only stream traces containing errors (used with -tail)")
+
+	// payload capture and replay
+	flag.BoolVar(&Capture, "capture", false, "Start capturing the payloads received by the running trace agent to a file and exit")
+	flag.DurationVar(&CaptureDuration, "capture-duration", time.Minute, "Duration of the capture (used with -capture)")
+	flag.BoolVar(&CaptureCompressed, "capture-compressed", false, "Compress the capture file (used with -capture)")
+	flag.StringVar(&Replay, "replay", "", "Replay the payloads found in the given capture `file` against the running trace agent and exit")
+	flag.Float64Var(&ReplaySpeed, "replay-speed", 1, "Replay speed relative to the original pace; 0 replays as fast as possible (used with -replay)")
+
 	// profiling
 	flag.StringVar(&CPUProfile, "cpuprofile", "", "Write cpu profile to file")
 	flag.StringVar(&MemProfile, "memprofile", "", "Write memory profile to `file`")
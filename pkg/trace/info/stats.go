@@ -124,7 +124,7 @@ type TagStats struct {
 }
 
 func newTagStats(tags Tags) *TagStats {
-	return &TagStats{tags, Stats{TracesDropped: &TracesDropped{}, SpansMalformed: &SpansMalformed{}}}
+	return &TagStats{tags, Stats{TracesDropped: &TracesDropped{}, SpansMalformed: &SpansMalformed{}, TagsScrubbed: &TagsScrubbed{}}}
 }
 
 // AsTags returns all the tags contained in the TagStats.
@@ -172,6 +172,9 @@ func (ts *TagStats) publish() {
 	for reason, count := range ts.SpansMalformed.tagValues() {
 		metrics.Count("datadog.trace_agent.normalizer.spans_malformed", count, append(tags, "reason:"+reason), 1)
 	}
+	for rule, count := range ts.TagsScrubbed.tagValues() {
+		metrics.Count("datadog.trace_agent.receiver.tags_scrubbed", count, append(tags, "rule:"+rule), 1)
+	}
 	for priority, count := range ts.TracesPerSamplingPriority.TagValues() {
 		metrics.Count("datadog.trace_agent.receiver.traces_priority", count, append(tags, "priority:"+priority), 1)
 	}
@@ -286,6 +289,36 @@ func (s *SpansMalformed) String() string {
 	return mapToString(s.tagValues())
 }
 
+// TagsScrubbed contains counts of span tags scrubbed because they contained
+// personally identifiable information, by scrubbing rule.
+type TagsScrubbed struct {
+	// KeyPattern is when a tag key matches one of the configured key patterns
+	KeyPattern int64
+	// CreditCard is when a tag value contains a credit card number
+	CreditCard int64
+	// Email is when a tag value contains an email address
+	Email int64
+	// BearerToken is when a tag value contains a bearer token
+	BearerToken int64
+}
+
+// tagValues converts TagsScrubbed into a map representation with keys matching standardized names for all rules
+func (s *TagsScrubbed) tagValues() map[string]int64 {
+	if s == nil {
+		return nil
+	}
+	return map[string]int64{
+		"key_pattern":  atomic.LoadInt64(&s.KeyPattern),
+		"credit_card":  atomic.LoadInt64(&s.CreditCard),
+		"email":        atomic.LoadInt64(&s.Email),
+		"bearer_token": atomic.LoadInt64(&s.BearerToken),
+	}
+}
+
+func (s *TagsScrubbed) String() string {
+	return mapToString(s.tagValues())
+}
+
 // maxAbsPriority specifies the absolute maximum priority for stats purposes. For example, with a value
 // of 10, the range of priorities reported will be [-10, 10].
 const maxAbsPriority = 10
@@ -339,6 +372,8 @@ type Stats struct {
 	TracesDropped *TracesDropped
 	// SpansMalformed contains stats about the count of malformed traces by reason
 	SpansMalformed *SpansMalformed
+	// TagsScrubbed contains stats about the count of scrubbed span tags by rule
+	TagsScrubbed *TagsScrubbed
 	// TracesFiltered is the number of traces filtered.
 	TracesFiltered int64
 	// TracesPriorityNone is the number of traces with no sampling priority.
@@ -386,6 +421,12 @@ func (s *Stats) update(recent *Stats) {
 	atomic.AddInt64(&s.SpansMalformed.InvalidStartDate, atomic.LoadInt64(&recent.SpansMalformed.InvalidStartDate))
 	atomic.AddInt64(&s.SpansMalformed.InvalidDuration, atomic.LoadInt64(&recent.SpansMalformed.InvalidDuration))
 	atomic.AddInt64(&s.SpansMalformed.InvalidHTTPStatusCode, atomic.LoadInt64(&recent.SpansMalformed.InvalidHTTPStatusCode))
+	if s.TagsScrubbed != nil && recent.TagsScrubbed != nil {
+		atomic.AddInt64(&s.TagsScrubbed.KeyPattern, atomic.LoadInt64(&recent.TagsScrubbed.KeyPattern))
+		atomic.AddInt64(&s.TagsScrubbed.CreditCard, atomic.LoadInt64(&recent.TagsScrubbed.CreditCard))
+		atomic.AddInt64(&s.TagsScrubbed.Email, atomic.LoadInt64(&recent.TagsScrubbed.Email))
+		atomic.AddInt64(&s.TagsScrubbed.BearerToken, atomic.LoadInt64(&recent.TagsScrubbed.BearerToken))
+	}
 	atomic.AddInt64(&s.TracesFiltered, atomic.LoadInt64(&recent.TracesFiltered))
 	atomic.AddInt64(&s.ClientDroppedP0Traces, atomic.LoadInt64(&recent.ClientDroppedP0Traces))
 	atomic.AddInt64(&s.ClientDroppedP0Spans, atomic.LoadInt64(&recent.ClientDroppedP0Spans))
@@ -422,6 +463,12 @@ func (s *Stats) reset() {
 	atomic.StoreInt64(&s.SpansMalformed.InvalidStartDate, 0)
 	atomic.StoreInt64(&s.SpansMalformed.InvalidDuration, 0)
 	atomic.StoreInt64(&s.SpansMalformed.InvalidHTTPStatusCode, 0)
+	if s.TagsScrubbed != nil {
+		atomic.StoreInt64(&s.TagsScrubbed.KeyPattern, 0)
+		atomic.StoreInt64(&s.TagsScrubbed.CreditCard, 0)
+		atomic.StoreInt64(&s.TagsScrubbed.Email, 0)
+		atomic.StoreInt64(&s.TagsScrubbed.BearerToken, 0)
+	}
 	atomic.StoreInt64(&s.TracesFiltered, 0)
 	atomic.StoreInt64(&s.TracesPriorityNone, 0)
 	atomic.StoreInt64(&s.ClientDroppedP0Traces, 0)
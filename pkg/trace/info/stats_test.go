@@ -70,6 +70,26 @@ func TestSpansMalformed(t *testing.T) {
 	})
 }
 
+func TestTagsScrubbed(t *testing.T) {
+	s := TagsScrubbed{
+		KeyPattern: 2,
+		Email:      1,
+	}
+
+	t.Run("tagValues", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{
+			"key_pattern":  2,
+			"credit_card":  0,
+			"email":        1,
+			"bearer_token": 0,
+		}, s.tagValues())
+	})
+
+	t.Run("String", func(t *testing.T) {
+		assert.Equal(t, "email:1, key_pattern:2", s.String())
+	})
+}
+
 func TestStatsTags(t *testing.T) {
 	assert.Equal(t, (&Tags{
 		Lang:            "go",
@@ -47,6 +47,13 @@ func (c *measuredCache) statsLoop() {
 	}
 }
 
+// queryCacheKey returns the key under which the obfuscated version of the given query in the
+// given language is cached. This prevents collisions between identical inputs obfuscated
+// differently. SQL queries are keyed by the query itself.
+func queryCacheKey(lang, query string) string {
+	return lang + "\x00" + query
+}
+
 // newMeasuredCache returns a new measuredCache.
 func newMeasuredCache() *measuredCache {
 	if !features.Has("sql_cache") {
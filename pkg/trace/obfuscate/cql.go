@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// cqlLiteralRe matches CQL constants which the SQL tokenizer would otherwise split into
+// multiple tokens, leaking parts of them: UUIDs (e.g. 123e4567-e89b-12d3-a456-426614174000)
+// and durations (e.g. 1h30m or -2d).
+var cqlLiteralRe = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b|-?\b(\d+(y|mo|w|d|h|m|s|ms|us|µs|ns))+\b`)
+
+// ObfuscateCQLString quantizes and obfuscates the given Cassandra (CQL) query. CQL is
+// obfuscated similarly to SQL, with the addition that collection literals (lists, sets,
+// maps and user-defined types), UUIDs and durations are replaced by a single "?".
+func (o *Obfuscator) ObfuscateCQLString(in string) (*ObfuscatedQuery, error) {
+	key := queryCacheKey("cql", in)
+	if v, ok := o.queryCache.Get(key); ok {
+		return v.(*ObfuscatedQuery), nil
+	}
+	query := cqlLiteralRe.ReplaceAllLiteralString(collapseCollections(in, false), "?")
+	// CQL escapes quotes by doubling them; backslashes are always literal.
+	tok := NewSQLTokenizer(query, true)
+	oq, err := attemptObfuscationWithOptions(tok, o.defaultSQLOptions())
+	if err != nil {
+		return nil, err
+	}
+	o.queryCache.Set(key, oq, oq.Cost())
+	return oq, nil
+}
+
+func (o *Obfuscator) obfuscateCQL(span *pb.Span) {
+	o.obfuscateQuery(span, o.ObfuscateCQLString)
+}
+
+// collapseCollections replaces all collection literals in the given query with a single
+// "?", regardless of their content. Lists ([...]) and sets, maps or tuples ({...}) are
+// always collapsed. If bags is true, PartiQL bags (<<...>>) are collapsed too.
+// Quoted strings and identifiers are skipped.
+func collapseCollections(in string, bags bool) string {
+	if !strings.ContainsAny(in, "[{<") {
+		return in
+	}
+	var (
+		out   strings.Builder
+		depth int  // nesting level of collections
+		quote byte // quote character of the string currently being scanned, if any
+	)
+	out.Grow(len(in))
+	for i := 0; i < len(in); i++ {
+		ch := in[i]
+		if quote != 0 {
+			if ch == quote {
+				if i+1 < len(in) && in[i+1] == quote {
+					// escaped quote
+					if depth == 0 {
+						out.WriteByte(ch)
+					}
+					i++
+				} else {
+					quote = 0
+				}
+			}
+			if depth == 0 {
+				out.WriteByte(ch)
+			}
+			continue
+		}
+		switch {
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '[' || ch == '{' || bags && ch == '<' && strings.HasPrefix(in[i:], "<<"):
+			if depth == 0 {
+				out.WriteByte('?')
+			}
+			if ch == '<' {
+				i++
+			}
+			depth++
+			continue
+		case depth > 0 && (ch == ']' || ch == '}' || bags && ch == '>' && strings.HasPrefix(in[i:], ">>")):
+			if ch == '>' {
+				i++
+			}
+			depth--
+			continue
+		}
+		if depth == 0 {
+			out.WriteByte(ch)
+		}
+	}
+	return out.String()
+}
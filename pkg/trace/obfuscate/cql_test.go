@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCQLObfuscation(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			"SELECT * FROM users WHERE id = 123e4567-e89b-12d3-a456-426614174000",
+			"SELECT * FROM users WHERE id = ?",
+		},
+		{
+			"UPDATE ks.events SET ttl = 1h30m, tags = {'a': 'b', 'c': {1, 2}}, items = [1, 2] WHERE id = 1",
+			"UPDATE ks.events SET ttl = ? tags = ? items = ? WHERE id = ?",
+		},
+		{
+			"INSERT INTO ks.t (id, v, b) VALUES (?, 'it''s {not} a map', 0xCAFE) USING TTL 86400",
+			"INSERT INTO ks.t ( id, v, b ) VALUES ( ? ) USING TTL ?",
+		},
+		{
+			"SELECT * FROM t WHERE c CONTAINS 'secret' AND d > -2d ALLOW FILTERING",
+			"SELECT * FROM t WHERE c CONTAINS ? AND d > ? ALLOW FILTERING",
+		},
+		{
+			`SELECT "Name" FROM t WHERE path = 'C:\dir\'`,
+			`SELECT Name FROM t WHERE path = ?`,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			oq, err := o.ObfuscateCQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+		})
+	}
+}
+
+func TestCQLDispatch(t *testing.T) {
+	query := "SELECT * FROM users WHERE tags = {'a': 'b'}"
+	span := &pb.Span{
+		Type:     "sql",
+		Resource: query,
+		Meta:     map[string]string{dbSystemTag: "cassandra"},
+	}
+	NewObfuscator(nil).Obfuscate(span)
+	assert.Equal(t, "SELECT * FROM users WHERE tags = ?", span.Resource)
+	assert.Equal(t, "SELECT * FROM users WHERE tags = ?", span.Meta[sqlQueryTag])
+}
+
+func TestCollapseCollections(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		bags bool
+		out  string
+	}{
+		{"a = [1, [2]]", false, "a = ?"},
+		{"a = {'k': {'x': '}'}} AND b = 1", false, "a = ? AND b = 1"},
+		{`"col[0]" = ['a''b]']`, false, `"col[0]" = ?`},
+		{"a = <<1, 2>>", false, "a = <<1, 2>>"},
+		{"a = <<1, [2], <<3>>>>", true, "a = ?"},
+		{"a < b", true, "a < b"},
+	} {
+		assert.Equal(t, tt.out, collapseCollections(tt.in, tt.bags), tt.in)
+	}
+}
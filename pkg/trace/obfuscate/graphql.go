@@ -0,0 +1,395 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// graphqlQueryTag specifies the tag holding the GraphQL document sent by the client.
+const graphqlQueryTag = "graphql.source"
+
+const nonParsableGraphQLResource = "Non-parsable GraphQL query"
+
+// graphqlTokenKind specifies the kind of a token returned by the graphqlTokenizer.
+type graphqlTokenKind int
+
+const (
+	graphqlEOF graphqlTokenKind = iota
+	graphqlPunctuator
+	graphqlName
+	graphqlNumber
+	graphqlString
+	graphqlError
+)
+
+// graphqlTokenizer tokenizes a GraphQL document as described in the specification's
+// lexical grammar. Commas, white space and comments are ignored.
+// See: https://spec.graphql.org/June2018/#sec-Appendix-Grammar-Summary.Lexical-Tokens
+type graphqlTokenizer struct {
+	data string
+	off  int
+	err  error
+}
+
+// scan returns the next token kind along with its value.
+func (t *graphqlTokenizer) scan() (graphqlTokenKind, string) {
+	t.skipIgnored()
+	if t.off >= len(t.data) {
+		return graphqlEOF, ""
+	}
+	start := t.off
+	switch ch := t.data[t.off]; {
+	case ch == '.':
+		if !strings.HasPrefix(t.data[t.off:], "...") {
+			t.err = fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+			return graphqlError, ""
+		}
+		t.off += 3
+		return graphqlPunctuator, "..."
+	case strings.IndexByte("!$&():=@[]{|}", ch) != -1:
+		t.off++
+		return graphqlPunctuator, t.data[start:t.off]
+	case isGraphQLNameStart(ch):
+		for t.off < len(t.data) && isGraphQLNameContinue(t.data[t.off]) {
+			t.off++
+		}
+		return graphqlName, t.data[start:t.off]
+	case ch == '-' || isDigit(rune(ch)):
+		t.off++
+		for t.off < len(t.data) && isGraphQLNumberPart(t.data[t.off]) {
+			t.off++
+		}
+		return graphqlNumber, t.data[start:t.off]
+	case ch == '"':
+		t.scanString()
+		return graphqlString, t.data[start:t.off]
+	default:
+		t.err = fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+		return graphqlError, ""
+	}
+}
+
+// skipIgnored advances the tokenizer past white space, line terminators, commas
+// and comments.
+func (t *graphqlTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case ' ', '\t', '\n', '\r', ',':
+			t.off++
+		case '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		default:
+			if strings.HasPrefix(t.data[t.off:], "\ufeff") {
+				// unicode byte order mark
+				t.off += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+// scanString advances the tokenizer past a string or block string value. Unterminated
+// strings (e.g. truncated by tracers) are consumed until the end of the input.
+func (t *graphqlTokenizer) scanString() {
+	if strings.HasPrefix(t.data[t.off:], `"""`) {
+		t.off += 3
+		for t.off < len(t.data) {
+			switch {
+			case strings.HasPrefix(t.data[t.off:], `\"""`):
+				t.off += 4
+			case strings.HasPrefix(t.data[t.off:], `"""`):
+				t.off += 3
+				return
+			default:
+				t.off++
+			}
+		}
+		return
+	}
+	t.off++
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '\\':
+			t.off += 2
+		case '"':
+			t.off++
+			return
+		default:
+			t.off++
+		}
+	}
+	t.off = len(t.data)
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+func isGraphQLNameContinue(ch byte) bool {
+	return isGraphQLNameStart(ch) || isDigit(rune(ch))
+}
+
+func isGraphQLNumberPart(ch byte) bool {
+	return isDigit(rune(ch)) || ch == '.' || ch == 'e' || ch == 'E' || ch == '+' || ch == '-'
+}
+
+// graphqlContext specifies the syntactic context that the obfuscator is in
+// while walking a GraphQL document.
+type graphqlContext int
+
+const (
+	// graphqlContextSelection is a selection set, e.g. "{ user { name } }".
+	graphqlContextSelection graphqlContext = iota
+	// graphqlContextArguments is a list of arguments, e.g. "user(id: 1)".
+	graphqlContextArguments
+	// graphqlContextVariables is a list of variable definitions, e.g. "query Q($id: ID = 1)".
+	graphqlContextVariables
+	// graphqlContextObject is an input object value, e.g. "{ name: "x" }".
+	graphqlContextObject
+	// graphqlContextList is a list value, e.g. "[1, 2]".
+	graphqlContextList
+	// graphqlContextListType is a list type in a variable definition, e.g. "[ID!]".
+	graphqlContextListType
+)
+
+// graphqlFrame holds the state of an opened pair of brackets.
+type graphqlFrame struct {
+	ctx graphqlContext
+	// literal reports whether a literal was already written in this list value,
+	// so that consecutive literals are collapsed into a single "?".
+	literal bool
+}
+
+// graphqlObfuscator obfuscates a single GraphQL document.
+type graphqlObfuscator struct {
+	tok   graphqlTokenizer
+	out   strings.Builder
+	stack []graphqlFrame
+	// expectValue reports whether the next token starts a value.
+	expectValue bool
+	// header reports whether we are between an operation or fragment keyword
+	// and the opening of its selection set.
+	header bool
+	// last and prev hold the last two tokens read from the input.
+	last, prev string
+}
+
+// ObfuscateGraphQLString obfuscates the given GraphQL document by replacing all literal
+// values found in arguments, input objects and variable default values with "?". The
+// structure of the operation (fields, aliases, fragments, directives and variables) is
+// kept, and the document is normalized so that it can be used for grouping stats.
+func (o *Obfuscator) ObfuscateGraphQLString(in string) (*ObfuscatedQuery, error) {
+	key := queryCacheKey("graphql", in)
+	if v, ok := o.queryCache.Get(key); ok {
+		return v.(*ObfuscatedQuery), nil
+	}
+	g := graphqlObfuscator{tok: graphqlTokenizer{data: in}}
+	out, err := g.obfuscate()
+	if err != nil {
+		return nil, err
+	}
+	oq := &ObfuscatedQuery{Query: out}
+	o.queryCache.Set(key, oq, oq.Cost())
+	return oq, nil
+}
+
+func (g *graphqlObfuscator) obfuscate() (string, error) {
+	for {
+		kind, tok := g.tok.scan()
+		switch kind {
+		case graphqlEOF:
+			if g.out.Len() == 0 {
+				return "", errors.New("result is empty")
+			}
+			return g.out.String(), nil
+		case graphqlError:
+			return "", g.tok.err
+		case graphqlPunctuator:
+			if err := g.punctuator(tok); err != nil {
+				return "", err
+			}
+		default:
+			g.operand(kind, tok)
+		}
+		g.prev, g.last = g.last, tok
+	}
+}
+
+// top returns the innermost opened frame, or nil at the top level of the document.
+func (g *graphqlObfuscator) top() *graphqlFrame {
+	if len(g.stack) == 0 {
+		return nil
+	}
+	return &g.stack[len(g.stack)-1]
+}
+
+// inValue reports whether the current token is part of a value.
+func (g *graphqlObfuscator) inValue() bool {
+	if g.expectValue {
+		return true
+	}
+	f := g.top()
+	return f != nil && f.ctx == graphqlContextList
+}
+
+// operand handles names, numbers and strings.
+func (g *graphqlObfuscator) operand(kind graphqlTokenKind, tok string) {
+	if g.last == "$" || !g.inValue() {
+		// variable reference, or part of the document structure
+		if len(g.stack) == 0 && kind == graphqlName {
+			switch tok {
+			case "query", "mutation", "subscription", "fragment":
+				g.header = true
+			}
+		}
+		g.write(tok)
+		g.expectValue = false
+		return
+	}
+	if kind == graphqlName {
+		switch tok {
+		case "true", "false", "null":
+		default:
+			// enum values are part of the schema
+			g.write(tok)
+			g.expectValue = false
+			return
+		}
+	}
+	if f := g.top(); !g.expectValue && f.ctx == graphqlContextList {
+		if f.literal {
+			return
+		}
+		f.literal = true
+	}
+	g.write("?")
+	g.expectValue = false
+}
+
+// punctuator handles all punctuators, maintaining the context stack.
+func (g *graphqlObfuscator) punctuator(tok string) error {
+	f := g.top()
+	switch tok {
+	case ":":
+		if f != nil && (f.ctx == graphqlContextArguments || f.ctx == graphqlContextObject) {
+			g.expectValue = true
+		}
+	case "=":
+		if f != nil && f.ctx == graphqlContextVariables {
+			g.expectValue = true
+		}
+	case "(":
+		ctx := graphqlContextArguments
+		if len(g.stack) == 0 && g.header && g.prev != "@" {
+			// operation variables, as opposed to directive arguments
+			ctx = graphqlContextVariables
+		}
+		g.stack = append(g.stack, graphqlFrame{ctx: ctx})
+	case "[":
+		ctx := graphqlContextListType
+		if g.inValue() {
+			ctx = graphqlContextList
+			g.markListElement()
+		}
+		g.expectValue = false
+		g.stack = append(g.stack, graphqlFrame{ctx: ctx})
+	case "{":
+		ctx := graphqlContextSelection
+		if g.inValue() {
+			ctx = graphqlContextObject
+			g.markListElement()
+		}
+		if len(g.stack) == 0 {
+			g.header = false
+		}
+		g.expectValue = false
+		g.stack = append(g.stack, graphqlFrame{ctx: ctx})
+	case ")", "]", "}":
+		if f == nil || closingBracket(f.ctx) != tok {
+			return fmt.Errorf("unexpected %q at position %d", tok, g.tok.off-1)
+		}
+		g.stack = g.stack[:len(g.stack)-1]
+		g.expectValue = false
+	}
+	g.write(tok)
+	return nil
+}
+
+// markListElement resets the literal collapsing of the enclosing list value when
+// a nested list or object is found in it.
+func (g *graphqlObfuscator) markListElement() {
+	if f := g.top(); f != nil && f.ctx == graphqlContextList {
+		f.literal = false
+	}
+}
+
+// closingBracket returns the bracket that closes the given context.
+func closingBracket(ctx graphqlContext) string {
+	switch ctx {
+	case graphqlContextArguments, graphqlContextVariables:
+		return ")"
+	case graphqlContextList, graphqlContextListType:
+		return "]"
+	default:
+		return "}"
+	}
+}
+
+// write appends tok to the output, separating it from the previous token with a
+// single space where needed.
+func (g *graphqlObfuscator) write(tok string) {
+	if g.out.Len() > 0 && !graphqlNoSpaceBefore(tok) {
+		switch g.out.String()[g.out.Len()-1] {
+		case '(', '[', '$', '@':
+		default:
+			if !strings.HasSuffix(g.out.String(), "...") {
+				g.out.WriteByte(' ')
+			}
+		}
+	}
+	g.out.WriteString(tok)
+}
+
+func graphqlNoSpaceBefore(tok string) bool {
+	switch tok {
+	case ")", "]", ":", "!", "(":
+		return true
+	}
+	return false
+}
+
+func (o *Obfuscator) obfuscateGraphQL(span *pb.Span) {
+	if span.Resource != "" && strings.ContainsRune(span.Resource, '{') {
+		// the resource holds the document rather than the operation name
+		oq, err := o.ObfuscateGraphQLString(span.Resource)
+		if err != nil {
+			log.Debugf("Error parsing GraphQL query: %v. Resource: %q", err, span.Resource)
+			span.Resource = nonParsableGraphQLResource
+		} else {
+			span.Resource = oq.Query
+		}
+	}
+	if span.Meta == nil || span.Meta[graphqlQueryTag] == "" {
+		return
+	}
+	oq, err := o.ObfuscateGraphQLString(span.Meta[graphqlQueryTag])
+	if err != nil {
+		log.Debugf("Error parsing GraphQL query: %v. Query: %q", err, span.Meta[graphqlQueryTag])
+		traceutil.SetMeta(span, graphqlQueryTag, nonParsableGraphQLResource)
+		return
+	}
+	traceutil.SetMeta(span, graphqlQueryTag, oq.Query)
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLObfuscation(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`{ hero { name } }`,
+			`{ hero { name } }`,
+		},
+		{
+			`query GetUser($id: ID! = "abc", $ids: [Int!] = [1, 2]) { user(id: $id, name: "John", age: 42) { name } }`,
+			`query GetUser($id: ID! = ? $ids: [Int!] = [?]) { user(id: $id name: ? age: ?) { name } }`,
+		},
+		{
+			// input objects keep their field names, lists are collapsed
+			`{ users(filter: {email: "a@b.c", tags: ["x", "y", "z"]}) { id } }`,
+			`{ users(filter: { email: ? tags: [?] }) { id } }`,
+		},
+		{
+			// enum values are kept, booleans and null are not
+			`{ friends(first: 10, order: DESC, active: true, ref: null) { id } }`,
+			`{ friends(first: ? order: DESC active: ? ref: ?) { id } }`,
+		},
+		{
+			"mutation {\n  create(input: {bio: \"\"\"block \"string\" \"\"\", score: -1.5e3}) {\n    id # comment\n  }\n}",
+			`mutation { create(input: { bio: ? score: ? }) { id } }`,
+		},
+		{
+			`fragment UserFields on User @include(if: true) { id }`,
+			`fragment UserFields on User @include(if: ?) { id }`,
+		},
+		{
+			`query Q @cached(ttl: 30) { user { ...UserFields ... on Admin { level } } }`,
+			`query Q @cached(ttl: ?) { user { ...UserFields ...on Admin { level } } }`,
+		},
+		{
+			`{ a(x: [[1, 2], [3]], y: [{a: 1}, {a: 2}]) }`,
+			`{ a(x: [[?] [?]] y: [{ a: ? } { a: ? }]) }`,
+		},
+		{
+			// aliases
+			`{ small: picture(size: 64) large: picture(size: 1024) }`,
+			`{ small: picture(size: ?) large: picture(size: ?) }`,
+		},
+		{
+			// truncated query
+			`{ user(name: "unterminated`,
+			`{ user(name: ?`,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			oq, err := o.ObfuscateGraphQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+		})
+	}
+}
+
+func TestGraphQLObfuscationErrors(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	for _, in := range []string{
+		`{ a } }`,
+		`{ a(x: 1] }`,
+		`{ a(x: 'single') }`,
+		"  # only a comment",
+	} {
+		_, err := o.ObfuscateGraphQLString(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestGraphQLSpan(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	t.Run("document", func(t *testing.T) {
+		query := `query { user(id: 42) { name } }`
+		span := &pb.Span{
+			Type:     "graphql",
+			Resource: query,
+			Meta:     map[string]string{graphqlQueryTag: query},
+		}
+		o.Obfuscate(span)
+		assert.Equal(t, "query { user(id: ?) { name } }", span.Resource)
+		assert.Equal(t, "query { user(id: ?) { name } }", span.Meta[graphqlQueryTag])
+	})
+
+	t.Run("operation-name", func(t *testing.T) {
+		span := &pb.Span{
+			Type:     "graphql",
+			Resource: "GetUser",
+			Meta:     map[string]string{graphqlQueryTag: `query GetUser { user(id: "x") { name } }`},
+		}
+		o.Obfuscate(span)
+		assert.Equal(t, "GetUser", span.Resource)
+		assert.Equal(t, `query GetUser { user(id: ?) { name } }`, span.Meta[graphqlQueryTag])
+	})
+
+	t.Run("error", func(t *testing.T) {
+		span := &pb.Span{
+			Type:     "graphql",
+			Resource: "{ a } }",
+			Meta:     map[string]string{graphqlQueryTag: "{ a } }"},
+		}
+		o.Obfuscate(span)
+		assert.Equal(t, nonParsableGraphQLResource, span.Resource)
+		assert.Equal(t, nonParsableGraphQLResource, span.Meta[graphqlQueryTag])
+	})
+}
+
+func BenchmarkObfuscateGraphQLString(b *testing.B) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+	query := `query GetUser($id: ID! = "abc") { user(id: $id, name: "John", age: 42, filter: {email: "a@b.c", tags: ["x", "y"]}) { name friends(first: 10) { ...UserFields } } }`
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g := graphqlObfuscator{tok: graphqlTokenizer{data: query}}
+		if _, err := g.obfuscate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ package obfuscate
 
 import (
 	"bytes"
+	"strings"
 	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
@@ -40,6 +41,31 @@ type Obfuscator struct {
 type SQLOptions struct {
 	// ReplaceDigits causes the obfuscator to replace digits in identifiers and table names with question marks.
 	ReplaceDigits bool `json:"replace_digits"`
+
+	// TableNames causes the obfuscator to collect the names of the tables addressed by the query.
+	TableNames bool `json:"table_names"`
+
+	// CollectCommands causes the obfuscator to collect the commands run by the query (e.g. SELECT, INSERT).
+	CollectCommands bool `json:"collect_commands"`
+
+	// CollectProcedures causes the obfuscator to collect the names of the procedures called by the query.
+	CollectProcedures bool `json:"collect_procedures"`
+}
+
+// cacheKey returns the key under which the given query obfuscated using these options is
+// stored in the query cache. Queries obfuscated using the default options are keyed by
+// the query itself.
+func (opts SQLOptions) cacheKey(query string) string {
+	if opts == (SQLOptions{}) {
+		return query
+	}
+	var flags byte
+	for i, on := range []bool{opts.ReplaceDigits, opts.TableNames, opts.CollectCommands, opts.CollectProcedures} {
+		if on {
+			flags |= 1 << i
+		}
+	}
+	return queryCacheKey("sql"+string('0'+flags), query)
 }
 
 // SetSQLLiteralEscapes sets whether or not escape characters should be treated literally by the SQL obfuscator.
@@ -83,12 +109,43 @@ func NewObfuscator(cfg *config.ObfuscationConfig) *Obfuscator {
 // Stop cleans up after a finished Obfuscator.
 func (o *Obfuscator) Stop() { o.queryCache.Close() }
 
+const (
+	// dbSystemTag specifies the tag holding the database system identifier,
+	// e.g. "cassandra" or "dynamodb".
+	dbSystemTag = "db.system"
+
+	// dbStatementTag specifies the tag holding the database statement.
+	dbStatementTag = "db.statement"
+)
+
+// spanType returns the type by which the span is dispatched to an obfuscator. For database
+// spans, the "db.system" tag takes precedence over the span type so that queries are
+// obfuscated according to the right language.
+func spanType(span *pb.Span) string {
+	switch span.Type {
+	case "sql", "db":
+		if span.Meta[dbSystemTag] == "cassandra" {
+			return "cassandra"
+		}
+	}
+	return span.Type
+}
+
 // Obfuscate may obfuscate span's properties based on its type and on the Obfuscator's
 // configuration.
 func (o *Obfuscator) Obfuscate(span *pb.Span) {
-	switch span.Type {
-	case "sql", "cassandra":
+	if span.Meta[dbSystemTag] == "dynamodb" {
+		// DynamoDB spans are usually HTTP client spans, which need
+		// to be obfuscated further below.
+		o.obfuscatePartiQL(span)
+	}
+	switch spanType(span) {
+	case "sql":
 		o.obfuscateSQL(span)
+	case "cassandra":
+		o.obfuscateCQL(span)
+	case "graphql":
+		o.obfuscateGraphQL(span)
 	case "redis":
 		o.quantizeRedis(span)
 		if o.opts.Redis.Enabled {
@@ -110,19 +167,30 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 // ObfuscateStatsGroup obfuscates the given stats bucket group.
 func (o *Obfuscator) ObfuscateStatsGroup(b *pb.ClientGroupedStats) {
 	switch b.Type {
-	case "sql", "cassandra":
-		oq, err := o.ObfuscateSQLString(b.Resource)
-		if err != nil {
-			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
-			b.Resource = nonParsableResource
-		} else {
-			b.Resource = oq.Query
+	case "sql":
+		b.Resource = obfuscateStatsGroupResource(b.Resource, o.ObfuscateSQLString, nonParsableResource)
+	case "cassandra":
+		b.Resource = obfuscateStatsGroupResource(b.Resource, o.ObfuscateCQLString, nonParsableResource)
+	case "graphql":
+		if strings.ContainsRune(b.Resource, '{') {
+			b.Resource = obfuscateStatsGroupResource(b.Resource, o.ObfuscateGraphQLString, nonParsableGraphQLResource)
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
 	}
 }
 
+// obfuscateStatsGroupResource obfuscates the given stats group resource using the obfuscate
+// function, returning nonParsable if it fails.
+func obfuscateStatsGroupResource(resource string, obfuscate func(string) (*ObfuscatedQuery, error), nonParsable string) string {
+	oq, err := obfuscate(resource)
+	if err != nil {
+		log.Errorf("Error obfuscating stats group resource %q: %v", resource, err)
+		return nonParsable
+	}
+	return oq.Query
+}
+
 // compactWhitespaces compacts all whitespaces in t.
 func compactWhitespaces(t string) string {
 	n := len(t)
@@ -139,6 +139,9 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("sql", "SELECT 1 FROM db"), "SELECT ? FROM db"},
 		{statsGroup("sql", "SELECT 1\nFROM Blogs AS [b\nORDER BY [b]"), nonParsableResource},
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
+		{statsGroup("cassandra", "SELECT * FROM t WHERE c = {1, 2}"), "SELECT * FROM t WHERE c = ?"},
+		{statsGroup("graphql", "{ user(id: 1) { name } }"), "{ user(id: ?) { name } }"},
+		{statsGroup("graphql", "GetUser"), "GetUser"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
 	} {
 		o.ObfuscateStatsGroup(tt.in)
@@ -281,7 +284,7 @@ func TestObfuscateConfig(t *testing.T) {
 // TestSQLObfuscationOptionsDeserializationMethod checks if the use of easyjson results in the same deserialization
 // output as encoding/json.
 func TestSQLObfuscationOptionsDeserializationMethod(t *testing.T) {
-	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true})
+	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true, TableNames: true, CollectCommands: true, CollectProcedures: true})
 	require.NoError(t, err)
 
 	var in, out SQLOptions
@@ -306,7 +309,7 @@ func BenchmarkSQLObfuscationOptionsEasyJSONDeserialization(b *testing.B) {
 
 func benchmarkSQLObfuscationOptionsEasyJSONDeserialization(b *testing.B) {
 	b.ReportAllocs()
-	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true})
+	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true, TableNames: true, CollectCommands: true, CollectProcedures: true})
 	require.NoError(b, err)
 	for i := 0; i < b.N; i++ {
 		var sqlCfg SQLOptions
@@ -325,7 +328,7 @@ func BenchmarkSQLObfuscationOptionsRegularJSONDeserialization(b *testing.B) {
 
 func benchmarkSQLObfuscationOptionsRegularJSONDeserialization(b *testing.B) {
 	b.ReportAllocs()
-	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true})
+	opts, err := json.Marshal(SQLOptions{ReplaceDigits: true, TableNames: true, CollectCommands: true, CollectProcedures: true})
 	require.NoError(b, err)
 	for i := 0; i < b.N; i++ {
 		var sqlCfg SQLOptions
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const nonParsablePartiQLStatement = "Non-parsable PartiQL statement"
+
+// ObfuscatePartiQLString quantizes and obfuscates the given DynamoDB PartiQL statement.
+// PartiQL is obfuscated similarly to SQL, with the addition that lists ([...]), tuples
+// ({...}) and bags (<<...>>) are replaced by a single "?". Double-quoted strings are
+// identifiers in PartiQL and are kept.
+func (o *Obfuscator) ObfuscatePartiQLString(in string) (*ObfuscatedQuery, error) {
+	key := queryCacheKey("partiql", in)
+	if v, ok := o.queryCache.Get(key); ok {
+		return v.(*ObfuscatedQuery), nil
+	}
+	tok := NewSQLTokenizer(collapseCollections(in, true), true)
+	oq, err := attemptObfuscationWithOptions(tok, SQLOptions{})
+	if err != nil {
+		return nil, err
+	}
+	o.queryCache.Set(key, oq, oq.Cost())
+	return oq, nil
+}
+
+// obfuscatePartiQL obfuscates the statement found in the "db.statement" tag of DynamoDB
+// spans. The resource is obfuscated too when it holds the same statement.
+func (o *Obfuscator) obfuscatePartiQL(span *pb.Span) {
+	stmt := span.Meta[dbStatementTag]
+	if stmt == "" {
+		return
+	}
+	out := nonParsablePartiQLStatement
+	if oq, err := o.ObfuscatePartiQLString(stmt); err != nil {
+		log.Debugf("Error parsing PartiQL statement: %v. Statement: %q", err, stmt)
+	} else {
+		out = oq.Query
+	}
+	if span.Resource == stmt {
+		span.Resource = out
+	}
+	traceutil.SetMeta(span, dbStatementTag, out)
+}
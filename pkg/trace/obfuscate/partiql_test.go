@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartiQLObfuscation(t *testing.T) {
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`SELECT * FROM "Music" WHERE Artist='Acme Band' AND Year = 2020`,
+			`SELECT * FROM Music WHERE Artist = ? AND Year = ?`,
+		},
+		{
+			`INSERT INTO "Music" VALUE {'Artist':'Acme Band','Songs': <<'a', 'b'>>, 'N': [1, {'x': '}'}]}`,
+			`INSERT INTO Music VALUE ?`,
+		},
+		{
+			`UPDATE "Music" SET AwardsWon=1 SET AwardDetail={'Grammys':[2020, 2018]} WHERE Artist='Acme Band'`,
+			`UPDATE Music SET AwardsWon = ? SET AwardDetail = ? WHERE Artist = ?`,
+		},
+		{
+			`SELECT OrderID FROM "Orders" WHERE OrderID IN [100, 300, 234] AND Total > ?`,
+			`SELECT OrderID FROM Orders WHERE OrderID IN ? AND Total > ?`,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			oq, err := o.ObfuscatePartiQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+		})
+	}
+}
+
+func TestPartiQLSpan(t *testing.T) {
+	stmt := `SELECT * FROM "Music" WHERE Artist='Acme Band'`
+	span := &pb.Span{
+		Type:     "http",
+		Resource: "DynamoDB.ExecuteStatement",
+		Meta: map[string]string{
+			dbSystemTag:    "dynamodb",
+			dbStatementTag: stmt,
+			"http.url":     "https://dynamodb.us-east-1.amazonaws.com/",
+		},
+	}
+	NewObfuscator(nil).Obfuscate(span)
+	assert.Equal(t, "DynamoDB.ExecuteStatement", span.Resource)
+	assert.Equal(t, "SELECT * FROM Music WHERE Artist = ?", span.Meta[dbStatementTag])
+
+	span.Resource = "SELECT * FROM \"Music\" WHERE Artist=!'x'"
+	span.Meta[dbStatementTag] = span.Resource
+	NewObfuscator(nil).Obfuscate(span)
+	assert.Equal(t, nonParsablePartiQLStatement, span.Resource)
+	assert.Equal(t, nonParsablePartiQLStatement, span.Meta[dbStatementTag])
+}
@@ -209,21 +209,33 @@ func (f *groupingFilter) Reset() {
 // some elements such as comments and aliases and obfuscation attempts to hide sensitive information
 // in strings and numbers by redacting them.
 func (o *Obfuscator) ObfuscateSQLString(in string) (*ObfuscatedQuery, error) {
-	return o.ObfuscateSQLStringWithOptions(in, SQLOptions{ReplaceDigits: features.Has("quantize_sql_tables") || features.Has("replace_sql_digits")})
+	return o.ObfuscateSQLStringWithOptions(in, o.defaultSQLOptions())
+}
+
+// defaultSQLOptions returns the SQL options resulting from the Obfuscator's configuration
+// and the enabled features.
+func (o *Obfuscator) defaultSQLOptions() SQLOptions {
+	return SQLOptions{
+		ReplaceDigits:     features.Has("quantize_sql_tables") || features.Has("replace_sql_digits"),
+		TableNames:        o.opts.SQL.TableNames,
+		CollectCommands:   o.opts.SQL.CollectCommands,
+		CollectProcedures: o.opts.SQL.CollectProcedures,
+	}
 }
 
 // ObfuscateSQLStringWithOptions accepts an optional SQLOptions to change the behavior of the obfuscator
 // to quantize and obfuscate the given input SQL query string. Quantization removes some elements such as comments
 // and aliases and obfuscation attempts to hide sensitive information in strings and numbers by redacting them.
 func (o *Obfuscator) ObfuscateSQLStringWithOptions(in string, opts SQLOptions) (*ObfuscatedQuery, error) {
-	if v, ok := o.queryCache.Get(in); ok {
+	key := opts.cacheKey(in)
+	if v, ok := o.queryCache.Get(key); ok {
 		return v.(*ObfuscatedQuery), nil
 	}
 	oq, err := o.obfuscateSQLString(in, opts)
 	if err != nil {
 		return oq, err
 	}
-	o.queryCache.Set(in, oq, oq.Cost())
+	o.queryCache.Set(key, oq, oq.Cost())
 	return oq, nil
 }
 
@@ -245,18 +257,46 @@ func (o *Obfuscator) obfuscateSQLString(in string, opts SQLOptions) (*Obfuscated
 	return out, err
 }
 
-// tableFinderFilter is a filter which attempts to identify the table name as it goes through each
-// token in a query.
-type tableFinderFilter struct {
-	storeTableNames bool
-	// seen keeps track of unique table names encountered by the filter.
-	seen map[string]struct{}
-	// csv specifies a comma-separated list of tables
-	csv strings.Builder
+// metadataFinderFilter is a filter which attempts to collect metadata about a query as it goes
+// through each token: the names of the tables it addresses, the commands it runs (SELECT, INSERT,
+// etc.) and the procedures it calls.
+type metadataFinderFilter struct {
+	collectTableNames bool
+	collectCommands   bool
+	collectProcedures bool
+
+	tables, commands, procedures csvSet
+
+	// keyword holds the last seen keyword which precedes a table or procedure name
+	// (e.g. TABLE or CALL), until that name is found.
+	keyword string
+	// bracketed holds the parts read so far of an SQL Server bracketed identifier
+	// (e.g. [dbo].[users]) along with the set that it should be stored in.
+	bracketed     []byte
+	bracketedInto *csvSet
+}
+
+// sqlCommands holds the set of upper-cased SQL commands that are collected by the metadataFinderFilter.
+var sqlCommands = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "GRANT": true, "REVOKE": true,
+	"CALL": true, "EXEC": true, "EXECUTE": true, "BEGIN": true, "COMMIT": true, "ROLLBACK": true,
 }
 
 // Filter implements tokenFilter.
-func (f *tableFinderFilter) Filter(token, lastToken TokenKind, buffer []byte) (TokenKind, []byte, error) {
+func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte) (TokenKind, []byte, error) {
+	if f.bracketedInto != nil {
+		switch {
+		case token == ID && (lastToken == '[' || lastToken == '.'),
+			token == '.' && lastToken == ']':
+			f.bracketed = append(f.bracketed, buffer...)
+			return token, buffer, nil
+		case token == ']' && lastToken == ID,
+			token == '[' && lastToken == '.':
+			return token, buffer, nil
+		}
+		f.flush()
+	}
 	switch lastToken {
 	case From, Join:
 		// SELECT ... FROM [tableName]
@@ -264,57 +304,198 @@ func (f *tableFinderFilter) Filter(token, lastToken TokenKind, buffer []byte) (T
 		// ... JOIN [tableName]
 		if r, _ := utf8.DecodeRune(buffer); !unicode.IsLetter(r) {
 			// first character in buffer is not a letter; we might have a nested
-			// query like SELECT * FROM (SELECT ...)
+			// query like SELECT * FROM (SELECT ...) or a bracketed identifier
+			if token == '[' && f.collectTableNames {
+				f.startBracketed(&f.tables)
+			}
 			break
 		}
 		fallthrough
 	case Update, Into:
 		// UPDATE [tableName]
 		// INSERT INTO [tableName]
-		if f.storeTableNames {
-			f.storeName(string(buffer))
+		if token == '[' {
+			if f.collectTableNames {
+				f.startBracketed(&f.tables)
+			}
+			break
+		}
+		if f.collectTableNames {
+			f.tables.add(string(buffer))
 		}
 		return TableName, buffer, nil
 	}
+	switch token {
+	case '[':
+		// EXEC [dbo].[procedureName]
+		if f.keyword != "TABLE" && f.keyword != "" && f.collectProcedures {
+			f.startBracketed(&f.procedures)
+		}
+		f.keyword = ""
+		return token, buffer, nil
+	case DollarQuotedFunc:
+		// the function body is an obfuscated query of its own
+		f.collectFrom(bytes.TrimSuffix(bytes.TrimPrefix(buffer, []byte("$func$")), []byte("$func$")))
+		return token, buffer, nil
+	case Insert, Update:
+		if f.collectCommands && isStatementStart(lastToken) {
+			f.commands.add(string(bytes.ToUpper(buffer)))
+		}
+		return token, buffer, nil
+	case ID:
+		// handled below
+	default:
+		return token, buffer, nil
+	}
+	keyword := string(bytes.ToUpper(buffer))
+	if f.collectCommands && sqlCommands[keyword] && isStatementStart(lastToken) {
+		f.commands.add(keyword)
+	}
+	switch f.keyword {
+	case "TABLE":
+		// CREATE TABLE [tableName]
+		// TRUNCATE TABLE [tableName]
+		switch keyword {
+		case "IF", "NOT", "EXISTS", "ONLY":
+			return token, buffer, nil
+		}
+		f.keyword = ""
+		if f.collectTableNames {
+			f.tables.add(string(buffer))
+		}
+		return TableName, buffer, nil
+	case "CALL", "EXEC", "EXECUTE":
+		// CALL [procedureName]
+		f.keyword = ""
+		if f.collectProcedures {
+			f.procedures.add(string(buffer))
+		}
+		return token, buffer, nil
+	}
+	switch keyword {
+	case "TABLE", "CALL", "EXEC", "EXECUTE":
+		f.keyword = keyword
+	}
 	return token, buffer, nil
 }
 
-// storeName marks the given table name as seen in the internal storage.
-func (f *tableFinderFilter) storeName(name string) {
-	if _, ok := f.seen[name]; ok {
-		return
+// isStatementStart reports whether a token following lastToken could be the beginning
+// of a statement or of a nested query.
+func isStatementStart(lastToken TokenKind) bool {
+	switch lastToken {
+	case 0, '(', ')', FilteredGroupable, FilteredGroupableParenthesis:
+		// FilteredGroupable marks discarded semicolons and comments
+		return true
 	}
-	if f.seen == nil {
-		f.seen = make(map[string]struct{}, 1)
+	return false
+}
+
+// startBracketed starts reading an SQL Server bracketed identifier which will be added to set.
+func (f *metadataFinderFilter) startBracketed(set *csvSet) {
+	f.bracketed = f.bracketed[:0]
+	f.bracketedInto = set
+}
+
+// flush stores the bracketed identifier that is currently being read, if any.
+func (f *metadataFinderFilter) flush() {
+	if f.bracketedInto == nil {
+		return
 	}
-	f.seen[name] = struct{}{}
-	if f.csv.Len() > 0 {
-		f.csv.WriteByte(',')
+	if len(f.bracketed) > 0 {
+		f.bracketedInto.add(string(f.bracketed))
 	}
-	f.csv.WriteString(name)
+	f.bracketedInto = nil
 }
 
-// CSV returns a comma-separated list of the tables seen by the filter.
-func (f *tableFinderFilter) CSV() string { return f.csv.String() }
+// collectFrom collects the metadata found in the given (already obfuscated) query.
+func (f *metadataFinderFilter) collectFrom(query []byte) {
+	var (
+		tok       = NewSQLTokenizer(string(query), true)
+		discard   discardFilter
+		inner     = metadataFinderFilter{collectTableNames: f.collectTableNames, collectCommands: f.collectCommands, collectProcedures: f.collectProcedures}
+		lastToken TokenKind
+	)
+	for {
+		token, buff := tok.Scan()
+		if token == EndChar || token == LexError {
+			break
+		}
+		token, buff, _ = discard.Filter(token, lastToken, buff)
+		token, _, _ = inner.Filter(token, lastToken, buff)
+		lastToken = token
+	}
+	inner.flush()
+	f.tables.merge(&inner.tables)
+	f.commands.merge(&inner.commands)
+	f.procedures.merge(&inner.procedures)
+}
 
 // Reset implements tokenFilter.
-func (f *tableFinderFilter) Reset() {
-	for k := range f.seen {
-		delete(f.seen, k)
+func (f *metadataFinderFilter) Reset() {
+	f.tables.reset()
+	f.commands.reset()
+	f.procedures.reset()
+	f.keyword = ""
+	f.bracketed = f.bracketed[:0]
+	f.bracketedInto = nil
+}
+
+// csvSet is a set of unique strings which keeps insertion order and can be
+// rendered as a comma-separated list.
+type csvSet struct {
+	// seen keeps track of unique values added to the set.
+	seen map[string]struct{}
+	// csv specifies a comma-separated list of values
+	csv strings.Builder
+}
+
+// add adds name to the set, unless it was already added.
+func (s *csvSet) add(name string) {
+	if _, ok := s.seen[name]; ok {
+		return
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]struct{}, 1)
+	}
+	s.seen[name] = struct{}{}
+	if s.csv.Len() > 0 {
+		s.csv.WriteByte(',')
+	}
+	s.csv.WriteString(name)
+}
+
+// merge adds all the values found in other to the set.
+func (s *csvSet) merge(other *csvSet) {
+	if other.csv.Len() == 0 {
+		return
+	}
+	for _, name := range strings.Split(other.csv.String(), ",") {
+		s.add(name)
 	}
-	f.csv.Reset()
+}
+
+// CSV returns a comma-separated list of the values in the set.
+func (s *csvSet) CSV() string { return s.csv.String() }
+
+func (s *csvSet) reset() {
+	for k := range s.seen {
+		delete(s.seen, k)
+	}
+	s.csv.Reset()
 }
 
 // ObfuscatedQuery specifies information about an obfuscated SQL query.
 type ObfuscatedQuery struct {
-	Query     string // the obfuscated SQL query
-	TablesCSV string // comma-separated list of tables that the query addresses
+	Query         string // the obfuscated SQL query
+	TablesCSV     string // comma-separated list of tables that the query addresses
+	CommandsCSV   string // comma-separated list of commands that the query runs (e.g. SELECT,INSERT)
+	ProceduresCSV string // comma-separated list of procedures that the query calls
 }
 
 // Cost returns the number of bytes needed to store all the fields
 // of this ObfuscatedQuery.
 func (oq *ObfuscatedQuery) Cost() int64 {
-	return int64(len(oq.Query) + len(oq.TablesCSV))
+	return int64(len(oq.Query) + len(oq.TablesCSV) + len(oq.CommandsCSV) + len(oq.ProceduresCSV))
 }
 
 // attemptObfuscation attempts to obfuscate the SQL query loaded into the tokenizer, using the given set of filters.
@@ -326,14 +507,19 @@ func attemptObfuscation(tokenizer *SQLTokenizer) (*ObfuscatedQuery, error) {
 // set of filters. An optional SQLOptions may be given to change the behavior.
 func attemptObfuscationWithOptions(tokenizer *SQLTokenizer, opts SQLOptions) (*ObfuscatedQuery, error) {
 	var (
-		storeTableNames = features.Has("table_names")
+		tableNames      = opts.TableNames || features.Has("table_names")
+		collectMetadata = tableNames || opts.CollectCommands || opts.CollectProcedures
 		out             = bytes.NewBuffer(make([]byte, 0, len(tokenizer.buf)))
 		err             error
 		lastToken       TokenKind
 		discard         discardFilter
 		replace         = replaceFilter{replaceDigits: opts.ReplaceDigits}
 		grouping        groupingFilter
-		tableFinder     = tableFinderFilter{storeTableNames: storeTableNames}
+		metadataFinder  = metadataFinderFilter{
+			collectTableNames: tableNames,
+			collectCommands:   opts.CollectCommands,
+			collectProcedures: opts.CollectProcedures,
+		}
 	)
 	// call Scan() function until tokens are available or if a LEX_ERROR is raised. After
 	// retrieving a token, send it to the tokenFilter chains so that the token is discarded
@@ -350,8 +536,8 @@ func attemptObfuscationWithOptions(tokenizer *SQLTokenizer, opts SQLOptions) (*O
 		if token, buff, err = discard.Filter(token, lastToken, buff); err != nil {
 			return nil, err
 		}
-		if storeTableNames {
-			if token, buff, err = tableFinder.Filter(token, lastToken, buff); err != nil {
+		if collectMetadata {
+			if token, buff, err = metadataFinder.Filter(token, lastToken, buff); err != nil {
 				return nil, err
 			}
 		}
@@ -383,17 +569,26 @@ func attemptObfuscationWithOptions(tokenizer *SQLTokenizer, opts SQLOptions) (*O
 	if out.Len() == 0 {
 		return nil, errors.New("result is empty")
 	}
+	metadataFinder.flush()
 	return &ObfuscatedQuery{
-		Query:     out.String(),
-		TablesCSV: tableFinder.CSV(),
+		Query:         out.String(),
+		TablesCSV:     metadataFinder.tables.CSV(),
+		CommandsCSV:   metadataFinder.commands.CSV(),
+		ProceduresCSV: metadataFinder.procedures.CSV(),
 	}, nil
 }
 
 func (o *Obfuscator) obfuscateSQL(span *pb.Span) {
+	o.obfuscateQuery(span, o.ObfuscateSQLString)
+}
+
+// obfuscateQuery obfuscates the resource of the given span using the obfuscate function,
+// setting the "sql.query" and "sql.tables" tags.
+func (o *Obfuscator) obfuscateQuery(span *pb.Span, obfuscate func(string) (*ObfuscatedQuery, error)) {
 	if span.Resource == "" {
 		return
 	}
-	oq, err := o.ObfuscateSQLString(span.Resource)
+	oq, err := obfuscate(span.Resource)
 	if err != nil {
 		// we have an error, discard the SQL to avoid polluting user resources.
 		log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
@@ -412,6 +607,12 @@ func (o *Obfuscator) obfuscateSQL(span *pb.Span) {
 	if len(oq.TablesCSV) > 0 {
 		traceutil.SetMeta(span, "sql.tables", oq.TablesCSV)
 	}
+	if len(oq.CommandsCSV) > 0 {
+		traceutil.SetMeta(span, "sql.commands", oq.CommandsCSV)
+	}
+	if len(oq.ProceduresCSV) > 0 {
+		traceutil.SetMeta(span, "sql.procedures", oq.ProceduresCSV)
+	}
 	if span.Meta != nil && span.Meta[sqlQueryTag] != "" {
 		// "sql.query" tag already set by user, do not change it.
 		return
@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
 	"github.com/stretchr/testify/assert"
@@ -254,6 +255,114 @@ func TestSQLTableNames(t *testing.T) {
 	})
 }
 
+func TestSQLMetadata(t *testing.T) {
+	for _, tt := range []struct {
+		query      string
+		tables     string
+		commands   string
+		procedures string
+	}{
+		{
+			"SELECT * FROM users WHERE id = 42",
+			"users",
+			"SELECT",
+			"",
+		},
+		{
+			"INSERT INTO `orders` (id, total) SELECT id, total FROM `carts`",
+			"orders,carts",
+			"INSERT,SELECT",
+			"",
+		},
+		{
+			"UPDATE [dbo].[users] SET name = 'x' WHERE id = 1; DELETE FROM [dbo].[sessions]",
+			"dbo.users,dbo.sessions",
+			"UPDATE,DELETE",
+			"",
+		},
+		{
+			"SELECT * FROM users WHERE id IN (SELECT user_id FROM sessions) FOR UPDATE",
+			"users,sessions",
+			"SELECT",
+			"",
+		},
+		{
+			"CREATE TABLE IF NOT EXISTS events (id INT)",
+			"events",
+			"CREATE",
+			"",
+		},
+		{
+			"EXEC [dbo].[sp_cleanup] @days = 30",
+			"",
+			"EXEC",
+			"dbo.sp_cleanup",
+		},
+		{
+			"/* comment */ call compute_totals(42, 'x')",
+			"",
+			"CALL",
+			"compute_totals",
+		},
+		{
+			"{call refresh_stats(?)}",
+			"",
+			"",
+			"refresh_stats",
+		},
+		{
+			"SELECT $tag$SELECT * FROM secrets$tag$ FROM notes",
+			"notes",
+			"SELECT",
+			"",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			assert := assert.New(t)
+			o := NewObfuscator(&config.ObfuscationConfig{
+				SQL: config.SQLObfuscationConfig{TableNames: true, CollectCommands: true, CollectProcedures: true},
+			})
+			defer o.Stop()
+			span := SQLSpan(tt.query)
+			o.Obfuscate(span)
+			assert.Equal(tt.tables, span.Meta["sql.tables"])
+			assert.Equal(tt.commands, span.Meta["sql.commands"])
+			assert.Equal(tt.procedures, span.Meta["sql.procedures"])
+		})
+	}
+
+	t.Run("dollar-quoted-func", func(t *testing.T) {
+		defer testutil.WithFeatures("keep_sql_alias,dollar_quoted_func")()
+
+		o := NewObfuscator(&config.ObfuscationConfig{
+			SQL: config.SQLObfuscationConfig{TableNames: true, CollectCommands: true},
+		})
+		defer o.Stop()
+		span := SQLSpan("CREATE OR REPLACE FUNCTION f() RETURNS void AS $func$DELETE FROM audit WHERE ts < 'x'$func$ LANGUAGE sql")
+		o.Obfuscate(span)
+		assert.Equal(t, "audit", span.Meta["sql.tables"])
+		assert.Equal(t, "CREATE,DELETE", span.Meta["sql.commands"])
+	})
+
+	t.Run("off", func(t *testing.T) {
+		span := SQLSpan("CALL compute_totals(42)")
+		NewObfuscator(nil).Obfuscate(span)
+		assert.NotContains(t, span.Meta, "sql.commands")
+		assert.NotContains(t, span.Meta, "sql.procedures")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		o := NewObfuscator(nil)
+		defer o.Stop()
+		oq, err := o.ObfuscateSQLStringWithOptions("SELECT * FROM users", SQLOptions{CollectCommands: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT", oq.CommandsCSV)
+		oq, err = o.ObfuscateSQLString("SELECT * FROM users")
+		assert.NoError(t, err)
+		assert.Empty(t, oq.CommandsCSV)
+	})
+}
+
 func TestSQLReplaceDigits(t *testing.T) {
 	t.Run("on", func(t *testing.T) {
 		for _, tt := range []struct {
@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// fileTemplate specifies the name of capture files, given the capture start time.
+	fileTemplate = "datadog-trace-capture-%d"
+
+	// captureDepth specifies the number of requests which can be queued for writing
+	// before requests start being dropped from the capture.
+	captureDepth = 100
+
+	// MaxDuration specifies the maximum duration of a capture.
+	MaxDuration = time.Hour
+)
+
+// Capture captures the requests received by the trace-agent to a file. A nil Capture
+// is valid and never captures anything.
+type Capture struct {
+	dir string
+
+	mu      sync.RWMutex
+	ongoing bool
+	in      chan *Record
+	stop    chan struct{} // closed to stop the ongoing capture
+	done    chan struct{} // closed once the ongoing capture's file is written
+	dropped int64         // atomic; number of requests missing from the ongoing capture
+}
+
+// NewCapture returns a new Capture writing capture files into the directory dir.
+func NewCapture(dir string) *Capture {
+	return &Capture{dir: dir}
+}
+
+// IsOngoing reports whether a capture is ongoing.
+func (c *Capture) IsOngoing() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ongoing
+}
+
+// Start starts capturing requests for the duration d, returning the path of the capture
+// file. If compressed is true, the file is compressed using gzip.
+func (c *Capture) Start(d time.Duration, compressed bool) (string, error) {
+	if d <= 0 || d > MaxDuration {
+		return "", fmt.Errorf("capture duration must be positive and at most %s", MaxDuration)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ongoing {
+		return "", errors.New("ongoing capture in progress")
+	}
+	if c.dir == "" {
+		return "", errors.New("no capture path configured")
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.dir, fmt.Sprintf(fileTemplate, time.Now().UnixNano()))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0660)
+	if err != nil {
+		return "", err
+	}
+	var (
+		w  io.Writer = f
+		zw *gzip.Writer
+	)
+	if compressed {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+	bw := bufio.NewWriter(w)
+	if err := writeHeader(bw); err != nil {
+		f.Close()
+		return "", err
+	}
+	c.in = make(chan *Record, captureDepth)
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	c.ongoing = true
+	atomic.StoreInt64(&c.dropped, 0)
+	go c.run(d, c.in, c.stop, c.done, f, zw, bw)
+	log.Infof("Started capturing trace-agent payloads to %s for %s.", path, d)
+	return path, nil
+}
+
+// run writes the requests received on in to the file until stop is closed or d elapses,
+// closing done once the file is written.
+func (c *Capture) run(d time.Duration, in chan *Record, stop, done chan struct{}, f *os.File, zw *gzip.Writer, bw *bufio.Writer) {
+	defer close(done)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	rw := recordWriter{w: bw}
+	var n int
+loop:
+	for {
+		select {
+		case rec := <-in:
+			rw.write(rec)
+			n++
+		case <-timer.C:
+			c.stopCapture(stop)
+		case <-stop:
+			break loop
+		}
+	}
+	// no more requests are enqueued once stopped; write the remaining ones.
+	for len(in) > 0 {
+		rw.write(<-in)
+		n++
+	}
+	err := bw.Flush()
+	if zw != nil {
+		if err2 := zw.Close(); err == nil {
+			err = err2
+		}
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		log.Errorf("Error writing capture file %s, it may be corrupt: %v", f.Name(), err)
+		return
+	}
+	log.Infof("Capture stopped: wrote %d requests to %s (%d dropped).", n, f.Name(), atomic.LoadInt64(&c.dropped))
+}
+
+// Stop stops the ongoing capture, if any, and waits for its file to be written.
+func (c *Capture) Stop() {
+	if c == nil {
+		return
+	}
+	if done := c.stopCapture(nil); done != nil {
+		<-done
+	}
+}
+
+// stopCapture stops the ongoing capture and returns the channel closed once its file
+// is written. If stop is not nil, the capture is only stopped if it is the one
+// identified by this stop channel.
+func (c *Capture) stopCapture(stop chan struct{}) (done chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.ongoing || stop != nil && stop != c.stop {
+		return nil
+	}
+	c.ongoing = false
+	close(c.stop)
+	return c.done
+}
+
+// Record adds the request req, with the given body, to the ongoing capture. It never
+// blocks: the request is dropped from the capture if the file is not written fast enough.
+func (c *Capture) Record(req *http.Request, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.ongoing {
+		return
+	}
+	rec := &Record{
+		Time:   time.Now(),
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Header: make(http.Header),
+		Body:   body,
+	}
+	for k, vs := range req.Header {
+		if capturedHeader(k) {
+			rec.Header[k] = vs
+		}
+	}
+	select {
+	case c.in <- rec:
+	default:
+		atomic.AddInt64(&c.dropped, 1)
+	}
+}
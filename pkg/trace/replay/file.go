@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package replay provides a way to capture the payloads received by the trace-agent
+// to a file and to replay them later against a running trace-agent.
+//
+// A capture file starts with a header made of the fileMagic bytes followed by the file
+// format version, and is followed by records. Each record holds a single request and
+// is made of its timestamp, method, path, headers and body. Integers are encoded as
+// varints, and strings and byte slices are prefixed by their length. Capture files
+// may be compressed as a whole using gzip.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// fileMagic identifies trace-agent capture files.
+	fileMagic = []byte("DDTRACECAP")
+
+	// gzipMagic identifies gzip-compressed files.
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+const (
+	// fileVersion specifies the version of the capture file format.
+	fileVersion uint8 = 1
+
+	// maxFieldSize specifies the maximum size of a record field, used to detect corrupt
+	// files before allocating memory.
+	maxFieldSize = 1 << 30
+
+	// maxHeaders specifies the maximum number of header values in a record.
+	maxHeaders = 1024
+)
+
+// Record holds a request captured by the trace-agent.
+type Record struct {
+	// Time specifies the time at which the request was received.
+	Time time.Time
+	// Method and Path specify the HTTP method and path of the request.
+	Method string
+	Path   string
+	// Header holds the captured request headers.
+	Header http.Header
+	// Body holds the request body.
+	Body []byte
+}
+
+// capturedHeader reports whether the request header k is stored in capture files. These
+// are the headers which the receiver relies on, such as the tracer language and version,
+// the container ID and the trace count.
+func capturedHeader(k string) bool {
+	k = http.CanonicalHeaderKey(k)
+	return k == "Content-Type" || strings.HasPrefix(k, "Datadog-") || strings.HasPrefix(k, "X-Datadog-")
+}
+
+// writeHeader writes the capture file header to w.
+func writeHeader(w io.Writer) error {
+	_, err := w.Write(append(append([]byte{}, fileMagic...), fileVersion))
+	return err
+}
+
+// readHeader reads the capture file header from r and returns an error if it is not
+// a supported capture file.
+func readHeader(r io.Reader) error {
+	hdr := make([]byte, len(fileMagic)+1)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return fmt.Errorf("not a trace capture file: %v", err)
+	}
+	if !bytes.Equal(hdr[:len(fileMagic)], fileMagic) {
+		return errors.New("not a trace capture file")
+	}
+	if v := hdr[len(fileMagic)]; v != fileVersion {
+		return fmt.Errorf("unsupported trace capture file version %d", v)
+	}
+	return nil
+}
+
+// recordWriter encodes records.
+type recordWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (rw *recordWriter) writeUvarint(v uint64) {
+	n := binary.PutUvarint(rw.buf[:], v)
+	rw.w.Write(rw.buf[:n])
+}
+
+func (rw *recordWriter) writeBytes(b []byte) {
+	rw.writeUvarint(uint64(len(b)))
+	rw.w.Write(b)
+}
+
+func (rw *recordWriter) writeString(s string) {
+	rw.writeUvarint(uint64(len(s)))
+	rw.w.WriteString(s)
+}
+
+// write encodes rec. Write errors are sticky, and reported by the next flush.
+func (rw *recordWriter) write(rec *Record) {
+	n := binary.PutVarint(rw.buf[:], rec.Time.UnixNano())
+	rw.w.Write(rw.buf[:n])
+	rw.writeString(rec.Method)
+	rw.writeString(rec.Path)
+	var nvalues int
+	for _, vs := range rec.Header {
+		nvalues += len(vs)
+	}
+	rw.writeUvarint(uint64(nvalues))
+	for k, vs := range rec.Header {
+		for _, v := range vs {
+			rw.writeString(k)
+			rw.writeString(v)
+		}
+	}
+	rw.writeBytes(rec.Body)
+}
+
+// Reader reads the records of a capture file.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading the capture file from r. Compressed capture files
+// are detected and decompressed transparently.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(zr)
+	}
+	if err := readHeader(br); err != nil {
+		return nil, err
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next record in the file. It returns io.EOF once all records were read.
+func (r *Reader) Next() (*Record, error) {
+	ts, err := binary.ReadVarint(r.r)
+	if err != nil {
+		// a clean end of file can only happen between records
+		return nil, err
+	}
+	rec := Record{Time: time.Unix(0, ts)}
+	if rec.Method, err = r.readString(); err != nil {
+		return nil, err
+	}
+	if rec.Path, err = r.readString(); err != nil {
+		return nil, err
+	}
+	n, err := r.readSize()
+	if err != nil {
+		return nil, err
+	}
+	if n > maxHeaders {
+		return nil, fmt.Errorf("corrupt capture file: too many headers (%d)", n)
+	}
+	rec.Header = make(http.Header, n)
+	for i := 0; i < n; i++ {
+		k, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		v, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		rec.Header.Add(k, v)
+	}
+	if rec.Body, err = r.readBytes(); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *Reader) readSize() (int, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	if n > maxFieldSize {
+		return 0, fmt.Errorf("corrupt capture file: field size %d is too large", n)
+	}
+	return int(n), nil
+}
+
+func (r *Reader) readBytes() ([]byte, error) {
+	n, err := r.readSize()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return b, nil
+}
+
+func (r *Reader) readString() (string, error) {
+	b, err := r.readBytes()
+	return string(b), err
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF, for use in the middle of a record.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// Path specifies the path of the debug endpoint starting captures.
+const Path = "/debug/capture"
+
+// CaptureResponse is the response of the capture endpoint.
+type CaptureResponse struct {
+	// Path specifies the path of the capture file written by the trace-agent.
+	Path string `json:"path"`
+}
+
+// StartCapture asks the trace-agent running with the given configuration to capture the
+// requests it receives for the duration d, and returns the path of the capture file.
+func StartCapture(conf *config.AgentConfig, d time.Duration, compressed bool) (string, error) {
+	q := url.Values{}
+	q.Set("duration", d.String())
+	q.Set("compressed", strconv.FormatBool(compressed))
+	u := url.URL{
+		Scheme:   "http",
+		Host:     receiverAddr(conf),
+		Path:     Path,
+		RawQuery: q.Encode(),
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(u.String(), "", nil)
+	if err != nil {
+		return "", fmt.Errorf("could not reach the trace-agent at %s, make sure it is running: %v", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.New("the trace-agent does not allow captures, set apm_config.capture_enabled to true to enable it")
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var out CaptureResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	return out.Path, nil
+}
+
+// ReplayFile sends the requests found in the capture file at path to the trace-agent
+// running with the given configuration. See Replay.
+func ReplayFile(ctx context.Context, conf *config.AgentConfig, path string, speed float64) (sent, refused int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return Replay(ctx, r, "http://"+receiverAddr(conf), speed)
+}
+
+// Replay sends the requests read from r to the trace-agent listening at baseURL. The
+// requests are sent at their original pace multiplied by speed (e.g. 2 replays twice as
+// fast); a speed of 0 sends them as fast as possible. It returns the number of requests
+// sent, and how many of them were refused by the agent. Refused requests do not stop
+// the replay.
+func Replay(ctx context.Context, r *Reader, baseURL string, speed float64) (sent, refused int, err error) {
+	if speed < 0 {
+		return 0, 0, fmt.Errorf("invalid replay speed %v", speed)
+	}
+	var (
+		first time.Time // time of the first record
+		start time.Time // time at which the first record was sent
+	)
+	client := http.Client{Timeout: 10 * time.Second}
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return sent, refused, nil
+		}
+		if err != nil {
+			return sent, refused, err
+		}
+		if sent == 0 {
+			first, start = rec.Time, time.Now()
+		} else if speed > 0 {
+			wait := time.Duration(float64(rec.Time.Sub(first))/speed) - time.Since(start)
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return sent, refused, ctx.Err()
+				}
+			}
+		}
+		ok, err := send(ctx, &client, baseURL, rec)
+		if err != nil {
+			return sent, refused, err
+		}
+		sent++
+		if !ok {
+			refused++
+		}
+	}
+}
+
+// send sends the captured request rec to the agent at baseURL and reports whether
+// it was accepted.
+func send(ctx context.Context, client *http.Client, baseURL string, rec *Record) (bool, error) {
+	req, err := http.NewRequest(rec.Method, baseURL+rec.Path, bytes.NewReader(rec.Body))
+	if err != nil {
+		return false, err
+	}
+	for k, vs := range rec.Header {
+		req.Header[k] = vs
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode/100 == 2, nil
+}
+
+func receiverAddr(conf *config.AgentConfig) string {
+	return fmt.Sprintf("%s:%d", conf.ReceiverHost, conf.ReceiverPort)
+}
@@ -0,0 +1,235 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecords() []*Record {
+	now := time.Now()
+	return []*Record{
+		{
+			Time:   now,
+			Method: http.MethodPost,
+			Path:   "/v0.4/traces",
+			Header: http.Header{
+				"Content-Type":                 []string{"application/msgpack"},
+				"Datadog-Meta-Lang":            []string{"go"},
+				"Datadog-Meta-Tracer-Version":  []string{"1.33.0"},
+				"Datadog-Container-Id":         []string{"abc123"},
+				"X-Datadog-Trace-Count":        []string{"2"},
+				"X-Datadog-Unknown-Multivalue": []string{"a", "b"},
+			},
+			Body: []byte{0x91, 0x90},
+		},
+		{
+			Time:   now.Add(50 * time.Millisecond),
+			Method: http.MethodPut,
+			Path:   "/v0.6/stats?a=b",
+			Header: http.Header{},
+			Body:   []byte{},
+		},
+	}
+}
+
+func writeTestFile(t *testing.T, recs []*Record) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, writeHeader(w))
+	rw := recordWriter{w: w}
+	for _, rec := range recs {
+		rw.write(rec)
+	}
+	require.NoError(t, w.Flush())
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, r io.Reader) []*Record {
+	rd, err := NewReader(r)
+	require.NoError(t, err)
+	var recs []*Record
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			return recs
+		}
+		require.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func assertRecordsEqual(t *testing.T, want, got []*Record) {
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, want[i].Time.Equal(got[i].Time))
+		assert.Equal(t, want[i].Method, got[i].Method)
+		assert.Equal(t, want[i].Path, got[i].Path)
+		assert.Equal(t, want[i].Header, got[i].Header)
+		assert.Equal(t, want[i].Body, got[i].Body)
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		recs := testRecords()
+		assertRecordsEqual(t, recs, readAll(t, bytes.NewReader(writeTestFile(t, recs))))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, readAll(t, bytes.NewReader(writeTestFile(t, nil))))
+	})
+
+	t.Run("not-a-capture", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader([]byte("DDTRACE")))
+		assert.Error(t, err)
+		_, err = NewReader(bytes.NewReader([]byte("DATADOG0F1FF0000...")))
+		assert.Error(t, err)
+	})
+
+	t.Run("version", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader(append(append([]byte{}, fileMagic...), fileVersion+1)))
+		assert.EqualError(t, err, "unsupported trace capture file version 2")
+		_, err = NewReader(bytes.NewReader(append(append([]byte{}, fileMagic...), 0)))
+		assert.EqualError(t, err, "unsupported trace capture file version 0")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		b := writeTestFile(t, testRecords())
+		rd, err := NewReader(bytes.NewReader(b[:len(b)-3]))
+		require.NoError(t, err)
+		_, err = rd.Next()
+		assert.NoError(t, err)
+		_, err = rd.Next()
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var c *Capture
+		assert.False(t, c.IsOngoing())
+		c.Record(httptest.NewRequest(http.MethodPost, "/v0.4/traces", nil), nil)
+		c.Stop()
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewCapture(t.TempDir()).Start(0, false)
+		assert.Error(t, err)
+		_, err = NewCapture(t.TempDir()).Start(MaxDuration+time.Second, false)
+		assert.Error(t, err)
+		_, err = NewCapture("").Start(time.Second, false)
+		assert.EqualError(t, err, "no capture path configured")
+	})
+
+	for _, compressed := range []bool{false, true} {
+		name := "uncompressed"
+		if compressed {
+			name = "compressed"
+		}
+		t.Run(name, func(t *testing.T) {
+			c := NewCapture(t.TempDir())
+			path, err := c.Start(time.Minute, compressed)
+			require.NoError(t, err)
+			assert.True(t, c.IsOngoing())
+
+			_, err = c.Start(time.Minute, compressed)
+			assert.EqualError(t, err, "ongoing capture in progress")
+
+			req := httptest.NewRequest(http.MethodPost, "/v0.4/traces?x=1", nil)
+			req.Header.Set("Content-Type", "application/msgpack")
+			req.Header.Set("Datadog-Meta-Lang", "python")
+			req.Header.Set("X-Datadog-Trace-Count", "1")
+			req.Header.Set("User-Agent", "test")
+			c.Record(req, []byte("payload"))
+			c.Stop()
+			assert.False(t, c.IsOngoing())
+			c.Record(req, []byte("ignored"))
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			recs := readAll(t, f)
+			require.Len(t, recs, 1)
+			assert.Equal(t, "/v0.4/traces?x=1", recs[0].Path)
+			assert.Equal(t, http.Header{
+				"Content-Type":          []string{"application/msgpack"},
+				"Datadog-Meta-Lang":     []string{"python"},
+				"X-Datadog-Trace-Count": []string{"1"},
+			}, recs[0].Header)
+			assert.Equal(t, []byte("payload"), recs[0].Body)
+		})
+	}
+
+	t.Run("duration", func(t *testing.T) {
+		c := NewCapture(t.TempDir())
+		_, err := c.Start(10*time.Millisecond, false)
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return !c.IsOngoing() }, time.Second, 5*time.Millisecond)
+		// a new capture can start once the previous one ended
+		_, err = c.Start(time.Minute, false)
+		assert.NoError(t, err)
+		c.Stop()
+	})
+}
+
+func TestReplay(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		got  []*Record
+		last time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		last = time.Now()
+		got = append(got, &Record{Method: req.Method, Path: req.URL.RequestURI(), Header: req.Header, Body: body})
+		if req.Method == http.MethodPut {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	recs := testRecords()
+	rd, err := NewReader(bytes.NewReader(writeTestFile(t, recs)))
+	require.NoError(t, err)
+	start := time.Now()
+	sent, refused, err := Replay(context.Background(), rd, srv.URL, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, 1, refused)
+	assert.True(t, last.Sub(start) >= 50*time.Millisecond, "the original pace is kept")
+
+	require.Len(t, got, 2)
+	for i, rec := range recs {
+		assert.Equal(t, rec.Method, got[i].Method)
+		assert.Equal(t, rec.Path, got[i].Path)
+		assert.Equal(t, rec.Body, got[i].Body)
+		for k, v := range rec.Header {
+			assert.Equal(t, v, got[i].Header[k])
+		}
+	}
+
+	t.Run("invalid-speed", func(t *testing.T) {
+		_, _, err := Replay(context.Background(), rd, srv.URL, -1)
+		assert.Error(t, err)
+	})
+}
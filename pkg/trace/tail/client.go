@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// Path specifies the path of the debug endpoint streaming processed traces.
+const Path = "/debug/tail"
+
+// Query returns the URL query string encoding the filters.
+func (f Filters) Query() url.Values {
+	q := url.Values{}
+	if f.Service != "" {
+		q.Set("service", f.Service)
+	}
+	if f.Resource != "" {
+		q.Set("resource", f.Resource)
+	}
+	if f.ErrorsOnly {
+		q.Set("errors_only", "true")
+	}
+	return q
+}
+
+// FiltersFromQuery returns the filters encoded in the given URL query string.
+func FiltersFromQuery(q url.Values) (Filters, error) {
+	f := Filters{
+		Service:  q.Get("service"),
+		Resource: q.Get("resource"),
+	}
+	if v := q.Get("errors_only"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("errors_only must be a boolean: %v", err)
+		}
+		f.ErrorsOnly = b
+	}
+	return f, nil
+}
+
+// Stream connects to the trace-agent running with the given configuration and
+// copies the processed traces matching the filters to w, as newline-delimited
+// JSON, until ctx is cancelled or the agent closes the stream.
+func Stream(ctx context.Context, w io.Writer, conf *config.AgentConfig, f Filters) error {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     fmt.Sprintf("%s:%d", conf.ReceiverHost, conf.ReceiverPort),
+		Path:     Path,
+		RawQuery: f.Query().Encode(),
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not reach the trace-agent at %s, make sure it is running: %v", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.New("the trace-agent does not allow streaming traces, set apm_config.tail_enabled to true to enable it")
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package tail provides a way to stream the traces processed by a running trace-agent,
+// for debugging purposes.
+package tail
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// chanSize specifies the number of encoded traces which can be buffered before
+// traces start being dropped.
+const chanSize = 100
+
+// Filters specifies which traces get streamed. Empty filters match all traces.
+type Filters struct {
+	// Service matches traces having at least one span with this exact service.
+	Service string `json:"service"`
+	// Resource matches traces having at least one span whose resource contains this string.
+	Resource string `json:"resource"`
+	// ErrorsOnly matches traces having at least one span marked as an error.
+	ErrorsOnly bool `json:"errors_only"`
+}
+
+// Match reports whether the given trace matches the filters.
+func (f *Filters) Match(t pb.Trace) bool {
+	if f == nil {
+		return true
+	}
+	var service, resource, isError bool
+	for _, span := range t {
+		service = service || f.Service == "" || span.Service == f.Service
+		resource = resource || f.Resource == "" || strings.Contains(span.Resource, f.Resource)
+		isError = isError || !f.ErrorsOnly || span.Error != 0
+		if service && resource && isError {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampling holds the sampling decision taken by the agent for a trace.
+type Sampling struct {
+	// Keep reports whether the trace is kept and sent to Datadog.
+	Keep bool `json:"keep"`
+	// Reason specifies the sampling mechanism which took the decision.
+	Reason string `json:"reason"`
+}
+
+// Trace is a processed trace, as written to the stream.
+type Trace struct {
+	Time     time.Time `json:"time"`
+	Env      string    `json:"env"`
+	Sampling Sampling  `json:"sampling"`
+	Spans    pb.Trace  `json:"spans"`
+}
+
+// Receiver receives the traces processed by the agent and makes them available
+// to a single stream at a time. A nil Receiver is valid and discards all traces.
+type Receiver struct {
+	enabled int32 // atomic; 1 when a stream is active
+	dropped int64 // atomic; number of traces dropped because the stream is too slow
+
+	mu      sync.RWMutex
+	filters Filters
+	out     chan []byte
+}
+
+// NewReceiver returns a new, disabled, Receiver.
+func NewReceiver() *Receiver {
+	return &Receiver{}
+}
+
+// Enabled reports whether a stream is currently active.
+func (r *Receiver) Enabled() bool {
+	return r != nil && atomic.LoadInt32(&r.enabled) == 1
+}
+
+// Subscribe starts a new stream of the traces matching the given filters, returning
+// the channel on which they are written, encoded as JSON. It returns false if
+// another stream is already active. Unsubscribe must be called with the returned
+// channel when done.
+func (r *Receiver) Subscribe(f Filters) (<-chan []byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Enabled() {
+		return nil, false
+	}
+	r.filters = f
+	r.out = make(chan []byte, chanSize)
+	atomic.StoreInt64(&r.dropped, 0)
+	atomic.StoreInt32(&r.enabled, 1)
+	return r.out, true
+}
+
+// Unsubscribe stops the stream reading from out, closing it, and returns the number
+// of traces which were dropped because it did not keep up. It does nothing if out
+// is not the active stream, so that a stale client can't stop a newer one.
+func (r *Receiver) Unsubscribe(out <-chan []byte) (dropped int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if out == nil || out != r.out {
+		return 0
+	}
+	return r.closeLocked()
+}
+
+// Close stops the active stream, if any, closing its channel.
+func (r *Receiver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeLocked()
+}
+
+// closeLocked stops the active stream. r.mu must be held.
+func (r *Receiver) closeLocked() (dropped int64) {
+	atomic.StoreInt32(&r.enabled, 0)
+	if r.out != nil {
+		close(r.out)
+		r.out = nil
+	}
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Handle writes the trace to the active stream if it matches its filters. It
+// never blocks: the trace is dropped if the stream does not keep up. The trace
+// is encoded synchronously, so it may be modified as soon as Handle returns.
+func (r *Receiver) Handle(t pb.Trace, env string, sampling Sampling) {
+	if !r.Enabled() {
+		return
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.out == nil || !r.filters.Match(t) {
+		return
+	}
+	b, err := json.Marshal(Trace{
+		Time:     time.Now().UTC(),
+		Env:      env,
+		Sampling: sampling,
+		Spans:    t,
+	})
+	if err != nil {
+		log.Debugf("Error encoding trace for tail: %v", err)
+		return
+	}
+	select {
+	case r.out <- append(b, '\n'):
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+}
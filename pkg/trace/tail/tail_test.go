@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tail
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiltersMatch(t *testing.T) {
+	trace := pb.Trace{
+		{Service: "web", Resource: "GET /users"},
+		{Service: "db", Resource: "SELECT ? FROM users", Error: 1},
+	}
+	for _, tt := range []struct {
+		filters *Filters
+		match   bool
+	}{
+		{nil, true},
+		{&Filters{}, true},
+		{&Filters{Service: "db"}, true},
+		{&Filters{Service: "cache"}, false},
+		{&Filters{Resource: "/users"}, true},
+		{&Filters{Resource: "/orders"}, false},
+		{&Filters{Service: "web", Resource: "SELECT"}, true},
+		{&Filters{ErrorsOnly: true}, true},
+		{&Filters{Service: "web", ErrorsOnly: true}, true},
+	} {
+		assert.Equal(t, tt.match, tt.filters.Match(trace), "%+v", tt.filters)
+	}
+	assert.False(t, (&Filters{ErrorsOnly: true}).Match(pb.Trace{{Service: "web"}}))
+}
+
+func TestFiltersQuery(t *testing.T) {
+	f := Filters{Service: "web", Resource: "GET /users", ErrorsOnly: true}
+	got, err := FiltersFromQuery(f.Query())
+	assert.NoError(t, err)
+	assert.Equal(t, f, got)
+
+	got, err = FiltersFromQuery(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, Filters{}, got)
+
+	_, err = FiltersFromQuery(url.Values{"errors_only": []string{"maybe"}})
+	assert.Error(t, err)
+}
+
+func TestReceiver(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Receiver
+		assert.False(t, r.Enabled())
+		r.Handle(pb.Trace{{Service: "web"}}, "prod", Sampling{})
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r := NewReceiver()
+		assert.False(t, r.Enabled())
+		r.Handle(pb.Trace{{Service: "web"}}, "prod", Sampling{})
+		r.Close()
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		r := NewReceiver()
+		out, ok := r.Subscribe(Filters{Service: "web"})
+		require.True(t, ok)
+		assert.True(t, r.Enabled())
+
+		_, ok = r.Subscribe(Filters{})
+		assert.False(t, ok, "only one stream is allowed at a time")
+
+		r.Handle(pb.Trace{{Service: "db"}}, "prod", Sampling{})
+		r.Handle(pb.Trace{{Service: "web", Resource: "GET /"}}, "prod", Sampling{Keep: true, Reason: "priority"})
+
+		var got Trace
+		require.NoError(t, json.Unmarshal(<-out, &got))
+		assert.Equal(t, "prod", got.Env)
+		assert.Equal(t, Sampling{Keep: true, Reason: "priority"}, got.Sampling)
+		assert.Equal(t, "GET /", got.Spans[0].Resource)
+		assert.Len(t, out, 0)
+
+		assert.Equal(t, int64(0), r.Unsubscribe(out))
+		assert.False(t, r.Enabled())
+		_, open := <-out
+		assert.False(t, open)
+
+		_, ok = r.Subscribe(Filters{})
+		assert.True(t, ok)
+		r.Close()
+		assert.False(t, r.Enabled())
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		r := NewReceiver()
+		stale, ok := r.Subscribe(Filters{})
+		require.True(t, ok)
+		r.Unsubscribe(stale)
+
+		out, ok := r.Subscribe(Filters{})
+		require.True(t, ok)
+		// a stale client unsubscribing again must not stop the new stream
+		r.Unsubscribe(stale)
+		assert.True(t, r.Enabled())
+		r.Handle(pb.Trace{{Service: "web"}}, "", Sampling{})
+		assert.Len(t, out, 1)
+
+		r.Unsubscribe(out)
+		assert.False(t, r.Enabled())
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		r := NewReceiver()
+		out, ok := r.Subscribe(Filters{})
+		require.True(t, ok)
+		for i := 0; i < chanSize+5; i++ {
+			r.Handle(pb.Trace{{Service: "web"}}, "", Sampling{})
+		}
+		assert.Equal(t, int64(5), r.Unsubscribe(out))
+	})
+}
+
+func TestStream(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, Path, req.URL.Path)
+			assert.Equal(t, "web", req.URL.Query().Get("service"))
+			w.Write([]byte("{}\n{}\n"))
+		}))
+		defer srv.Close()
+
+		var out strings.Builder
+		err := Stream(context.Background(), &out, testConfig(t, srv.URL), Filters{Service: "web"})
+		assert.NoError(t, err)
+		assert.Equal(t, "{}\n{}\n", out.String())
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "Another client is already streaming traces.", http.StatusConflict)
+		}))
+		defer srv.Close()
+
+		err := Stream(context.Background(), &strings.Builder{}, testConfig(t, srv.URL), Filters{})
+		assert.EqualError(t, err, "409 Conflict: Another client is already streaming traces.")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		err := Stream(context.Background(), &strings.Builder{}, testConfig(t, srv.URL), Filters{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "apm_config.tail_enabled")
+	})
+}
+
+func testConfig(t *testing.T, rawurl string) *config.AgentConfig {
+	u, err := url.Parse(rawurl)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	conf := config.New()
+	conf.ReceiverHost = host
+	conf.ReceiverPort, err = strconv.Atoi(port)
+	require.NoError(t, err)
+	return conf
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package compression implements the algorithms used to compress the payloads sent
+// to Datadog. The algorithm and its level are chosen at runtime, see NewCompressor.
+package compression
+
+import (
+	"fmt"
+	"io"
+)
+
+// Kinds of compression supported by NewCompressor.
+const (
+	// NoneKind disables compression.
+	NoneKind = "none"
+	// ZlibKind compresses using zlib (Content-Encoding: deflate).
+	ZlibKind = "zlib"
+	// GzipKind compresses using gzip (Content-Encoding: gzip).
+	GzipKind = "gzip"
+	// ZstdKind compresses using zstd (Content-Encoding: zstd).
+	ZstdKind = "zstd"
+)
+
+// DefaultLevel selects the default compression level of the chosen kind.
+const DefaultLevel = -1
+
+// Compressor compresses payloads with a given algorithm and level. It is safe for
+// concurrent use.
+type Compressor interface {
+	// Kind returns the kind of compression, one of the *Kind constants.
+	Kind() string
+	// ContentEncoding returns the HTTP Content-Encoding header value of compressed
+	// payloads, or an empty string if payloads are sent as is.
+	ContentEncoding() string
+	// Compress returns the compressed src.
+	Compress(src []byte) ([]byte, error)
+	// Decompress returns the decompressed src.
+	Decompress(src []byte) ([]byte, error)
+	// CompressBound returns the worst case size of a compressed payload, given its
+	// size before compression.
+	CompressBound(sourceLen int) int
+	// NewStreamCompressor returns a StreamCompressor writing compressed data to w.
+	NewStreamCompressor(w io.Writer) StreamCompressor
+}
+
+// StreamCompressor compresses the data written to it. Close must be called once all
+// data is written in order to write the compression footer.
+type StreamCompressor interface {
+	io.WriteCloser
+	// Flush writes all pending data to the underlying writer.
+	Flush() error
+}
+
+// NewCompressor returns a Compressor of the given kind using the given level.
+// Accepted levels are 0 (no compression) to 9 for zlib and gzip, and 1 to 20 for
+// zstd; DefaultLevel selects the default level of each kind. The level is ignored
+// by NoneKind.
+func NewCompressor(kind string, level int) (Compressor, error) {
+	switch kind {
+	case NoneKind:
+		return noneCompressor{}, nil
+	case ZlibKind:
+		return newZlibCompressor(level)
+	case GzipKind:
+		return newGzipCompressor(level)
+	case ZstdKind:
+		return newZstdCompressor(level)
+	default:
+		return nil, fmt.Errorf("unknown compression kind %q, must be one of %q, %q, %q or %q", kind, NoneKind, ZlibKind, GzipKind, ZstdKind)
+	}
+}
+
+// FromContentEncoding returns a Compressor of the kind matching an HTTP Content-Encoding
+// header value, using its default level. An empty encoding matches NoneKind.
+func FromContentEncoding(encoding string) (Compressor, error) {
+	switch encoding {
+	case "":
+		return noneCompressor{}, nil
+	case "deflate":
+		return newZlibCompressor(DefaultLevel)
+	case "gzip":
+		return newGzipCompressor(DefaultLevel)
+	case "zstd":
+		return newZstdCompressor(DefaultLevel)
+	default:
+		return nil, fmt.Errorf("unknown content encoding %q", encoding)
+	}
+}
+
+// DefaultCompressor returns the Compressor used when none is configured: zlib at its
+// default level.
+func DefaultCompressor() Compressor {
+	return zlibCompressor{level: DefaultLevel}
+}
+
+func checkLevel(kind string, level, min, max int) error {
+	if level != DefaultLevel && (level < min || level > max) {
+		return fmt.Errorf("invalid %s compression level %d, must be between %d and %d", kind, level, min, max)
+	}
+	return nil
+}
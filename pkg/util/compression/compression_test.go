@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCompressor(t *testing.T) {
+	for _, tt := range []struct {
+		kind     string
+		level    int
+		encoding string
+		err      bool
+	}{
+		{kind: NoneKind, level: DefaultLevel, encoding: ""},
+		{kind: NoneKind, level: 42, encoding: ""},
+		{kind: ZlibKind, level: DefaultLevel, encoding: "deflate"},
+		{kind: ZlibKind, level: 9, encoding: "deflate"},
+		{kind: ZlibKind, level: 0, encoding: "deflate"},
+		{kind: ZlibKind, level: -2, err: true},
+		{kind: ZlibKind, level: 10, err: true},
+		{kind: GzipKind, level: 1, encoding: "gzip"},
+		{kind: GzipKind, level: -2, err: true},
+		{kind: "lz4", level: DefaultLevel, err: true},
+		{kind: "", level: DefaultLevel, err: true},
+	} {
+		c, err := NewCompressor(tt.kind, tt.level)
+		if tt.err {
+			assert.Error(t, err, "%s/%d", tt.kind, tt.level)
+			continue
+		}
+		require.NoError(t, err, "%s/%d", tt.kind, tt.level)
+		assert.Equal(t, tt.kind, c.Kind())
+		assert.Equal(t, tt.encoding, c.ContentEncoding())
+	}
+}
+
+func TestDefaultCompressor(t *testing.T) {
+	c := DefaultCompressor()
+	assert.Equal(t, ZlibKind, c.Kind())
+	assert.Equal(t, "deflate", c.ContentEncoding())
+}
+
+func TestFromContentEncoding(t *testing.T) {
+	for encoding, kind := range map[string]string{"": NoneKind, "deflate": ZlibKind, "gzip": GzipKind} {
+		c, err := FromContentEncoding(encoding)
+		require.NoError(t, err, encoding)
+		assert.Equal(t, kind, c.Kind())
+		assert.Equal(t, encoding, c.ContentEncoding())
+	}
+	_, err := FromContentEncoding("br")
+	assert.Error(t, err)
+}
+
+func TestCompressors(t *testing.T) {
+	for _, kind := range []string{NoneKind, ZlibKind, GzipKind} {
+		t.Run(kind, func(t *testing.T) {
+			testCompressor(t, kind)
+		})
+	}
+}
+
+// testCompressor checks that the default compressor of the given kind round-trips payloads.
+func testCompressor(t *testing.T, kind string) {
+	payload := bytes.Repeat([]byte("a payload which compresses well "), 1000)
+	c, err := NewCompressor(kind, DefaultLevel)
+	require.NoError(t, err)
+
+	t.Run("compress", func(t *testing.T) {
+		compressed, err := c.Compress(payload)
+		require.NoError(t, err)
+		assert.True(t, len(compressed) <= c.CompressBound(len(payload)))
+		if kind != NoneKind {
+			assert.True(t, len(compressed) < len(payload))
+		}
+		decompressed, err := c.Decompress(compressed)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decompressed)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := c.NewStreamCompressor(&buf)
+		_, err := w.Write(payload[:100])
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+		_, err = w.Write(payload[100:])
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		decompressed, err := c.Decompress(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, payload, decompressed)
+	})
+
+	t.Run("bound", func(t *testing.T) {
+		// random-like data does not compress, the bound must still hold
+		incompressible := make([]byte, 4096)
+		for i := range incompressible {
+			incompressible[i] = byte(i*7919 + i>>3)
+		}
+		compressed, err := c.Compress(incompressible)
+		require.NoError(t, err)
+		assert.True(t, len(compressed) <= c.CompressBound(len(incompressible)))
+	})
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+)
+
+// gzipOverhead is the size difference between the gzip header and footer (18 bytes)
+// and the zlib ones (6 bytes) wrapping the same deflate stream.
+const gzipOverhead = 12
+
+// gzipCompressor compresses using gzip.
+type gzipCompressor struct {
+	level int
+}
+
+func newGzipCompressor(level int) (Compressor, error) {
+	if err := checkLevel(GzipKind, level, gzip.NoCompression, gzip.BestCompression); err != nil {
+		return nil, err
+	}
+	return gzipCompressor{level: level}, nil
+}
+
+func (c gzipCompressor) Kind() string { return GzipKind }
+
+func (c gzipCompressor) ContentEncoding() string { return "gzip" }
+
+// Compress will compress the data with gzip
+func (c gzipCompressor) Compress(src []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := c.NewStreamCompressor(&b)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress will decompress the data with gzip
+func (c gzipCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// CompressBound returns the worst case size needed for a destination buffer
+func (c gzipCompressor) CompressBound(sourceLen int) int {
+	return zlibCompressBound(sourceLen) + gzipOverhead
+}
+
+func (c gzipCompressor) NewStreamCompressor(w io.Writer) StreamCompressor {
+	// the level was checked when creating the compressor
+	zw, _ := gzip.NewWriterLevel(w, c.level)
+	return zw
+}
@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import "io"
+
+// noneCompressor does not compress anything.
+type noneCompressor struct{}
+
+func (noneCompressor) Kind() string { return NoneKind }
+
+func (noneCompressor) ContentEncoding() string { return "" }
+
+func (noneCompressor) Compress(src []byte) ([]byte, error) { return src, nil }
+
+func (noneCompressor) Decompress(src []byte) ([]byte, error) { return src, nil }
+
+func (noneCompressor) CompressBound(sourceLen int) int { return sourceLen }
+
+func (noneCompressor) NewStreamCompressor(w io.Writer) StreamCompressor {
+	return noneStreamCompressor{w}
+}
+
+// noneStreamCompressor writes the data as is.
+type noneStreamCompressor struct {
+	io.Writer
+}
+
+func (noneStreamCompressor) Flush() error { return nil }
+
+func (noneStreamCompressor) Close() error { return nil }
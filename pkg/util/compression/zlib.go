@@ -3,53 +3,66 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-// +build zlib
-
 package compression
 
 import (
 	"bytes"
 	"compress/zlib"
+	"io"
 	"io/ioutil"
 )
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "deflate"
+// zlibCompressor compresses using zlib.
+type zlibCompressor struct {
+	level int
+}
+
+func newZlibCompressor(level int) (Compressor, error) {
+	if err := checkLevel(ZlibKind, level, zlib.NoCompression, zlib.BestCompression); err != nil {
+		return nil, err
+	}
+	return zlibCompressor{level: level}, nil
+}
+
+func (c zlibCompressor) Kind() string { return ZlibKind }
+
+func (c zlibCompressor) ContentEncoding() string { return "deflate" }
 
 // Compress will compress the data with zlib
-func Compress(dst []byte, src []byte) ([]byte, error) {
+func (c zlibCompressor) Compress(src []byte) ([]byte, error) {
 	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
-	_, err := w.Write(src)
-	if err != nil {
+	w := c.NewStreamCompressor(&b)
+	if _, err := w.Write(src); err != nil {
 		return nil, err
 	}
-	err = w.Close()
-	if err != nil {
+	if err := w.Close(); err != nil {
 		return nil, err
 	}
-	dst = b.Bytes()
-	return dst, nil
+	return b.Bytes(), nil
 }
 
 // Decompress will decompress the data with zlib
-func Decompress(dst []byte, src []byte) ([]byte, error) {
+func (c zlibCompressor) Decompress(src []byte) ([]byte, error) {
 	r, err := zlib.NewReader(bytes.NewReader(src))
 	if err != nil {
 		return nil, err
 	}
 	defer r.Close()
+	return ioutil.ReadAll(r)
+}
 
-	dst, err = ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	return dst, nil
+// CompressBound returns the worst case size needed for a destination buffer
+func (c zlibCompressor) CompressBound(sourceLen int) int {
+	return zlibCompressBound(sourceLen)
+}
+
+func (c zlibCompressor) NewStreamCompressor(w io.Writer) StreamCompressor {
+	// the level was checked when creating the compressor
+	zw, _ := zlib.NewWriterLevel(w, c.level)
+	return zw
 }
 
-//  CompressBound returns the worst case size needed for a destination buffer
-func CompressBound(sourceLen int) int {
+func zlibCompressBound(sourceLen int) int {
 	// From https://code.woboq.org/gcc/zlib/compress.c.html#compressBound
 	return sourceLen + (sourceLen >> 12) + (sourceLen >> 14) + (sourceLen >> 25) + 13
 }
@@ -3,32 +3,50 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-// +build zstd
+// +build cgo
 
 package compression
 
 import (
-	zstd_0 "github.com/DataDog/zstd_0"
+	"io"
+
+	"github.com/DataDog/zstd"
 )
 
-// TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
-// The agent shouldn't use zstd compression until the intake supports a stable v1 format.
+// zstdCompressor compresses using the stable (v1) zstd format.
+type zstdCompressor struct {
+	level int
+}
+
+func newZstdCompressor(level int) (Compressor, error) {
+	if err := checkLevel(ZstdKind, level, zstd.BestSpeed, zstd.BestCompression); err != nil {
+		return nil, err
+	}
+	if level == DefaultLevel {
+		level = zstd.DefaultCompression
+	}
+	return zstdCompressor{level: level}, nil
+}
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "zstd"
+func (c zstdCompressor) Kind() string { return ZstdKind }
+
+func (c zstdCompressor) ContentEncoding() string { return "zstd" }
 
 // Compress will compress the data with zstd
-func Compress(dst []byte, src []byte) ([]byte, error) {
-	return zstd_0.Compress(dst, src)
+func (c zstdCompressor) Compress(src []byte) ([]byte, error) {
+	return zstd.CompressLevel(nil, src, c.level)
 }
 
 // Decompress will decompress the data with zstd
-func Decompress(dst []byte, src []byte) ([]byte, error) {
-	return zstd_0.Decompress(dst, src)
+func (c zstdCompressor) Decompress(src []byte) ([]byte, error) {
+	return zstd.Decompress(nil, src)
 }
 
 // CompressBound returns the worst case size needed for a destination buffer
-func CompressBound(sourceLen int) int {
-	return zstd_0.CompressBound(sourceLen)
+func (c zstdCompressor) CompressBound(sourceLen int) int {
+	return zstd.CompressBound(sourceLen)
+}
+
+func (c zstdCompressor) NewStreamCompressor(w io.Writer) StreamCompressor {
+	return zstd.NewWriterLevel(w, c.level)
 }
@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !cgo
+
+package compression
+
+import "errors"
+
+// newZstdCompressor reports zstd as unsupported: the zstd library requires cgo.
+func newZstdCompressor(level int) (Compressor, error) {
+	return nil, errors.New("zstd compression is not supported by this build, it requires cgo")
+}
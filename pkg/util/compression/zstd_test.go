@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build cgo
+
+package compression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewZstdCompressor(t *testing.T) {
+	for _, level := range []int{DefaultLevel, 1, 20} {
+		c, err := NewCompressor(ZstdKind, level)
+		require.NoError(t, err, "%d", level)
+		assert.Equal(t, ZstdKind, c.Kind())
+		assert.Equal(t, "zstd", c.ContentEncoding())
+	}
+	for _, level := range []int{0, 21} {
+		_, err := NewCompressor(ZstdKind, level)
+		assert.Error(t, err, "%d", level)
+	}
+}
+
+func TestZstdCompressor(t *testing.T) {
+	testCompressor(t, ZstdKind)
+}
@@ -48,6 +48,7 @@ type ContainerdItf interface {
 	ImageSize(ctn containerd.Container) (int64, error)
 	Spec(ctn containerd.Container) (*oci.Spec, error)
 	SpecWithContext(ctx context.Context, ctn containerd.Container) (*oci.Spec, error)
+	Status(ctn containerd.Container) (containerd.Status, error)
 	Metadata() (containerd.Version, error)
 	Namespace() string
 	TaskMetrics(ctn containerd.Container) (*types.Metric, error)
@@ -248,3 +249,17 @@ func (c *ContainerdUtil) TaskPids(ctn containerd.Container) ([]containerd.Proces
 
 	return t.Pids(ctxNamespace)
 }
+
+// Status interfaces with the containerd api to get the status of the task of a container
+func (c *ContainerdUtil) Status(ctn containerd.Container) (containerd.Status, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+
+	t, errTask := ctn.Task(ctxNamespace, nil)
+	if errTask != nil {
+		return containerd.Status{}, errTask
+	}
+
+	return t.Status(ctxNamespace)
+}
@@ -231,7 +231,8 @@ func getWPAInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, erro
 	return dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
 }
 
-func getDDClient(timeout time.Duration) (dynamic.Interface, error) {
+// GetDDClient returns a dynamic client for the datadoghq/ custom types
+func GetDDClient(timeout time.Duration) (dynamic.Interface, error) {
 	clientConfig, err := getClientConfig(timeout)
 	if err != nil {
 		return nil, err
@@ -240,7 +241,8 @@ func getDDClient(timeout time.Duration) (dynamic.Interface, error) {
 	return dynamic.NewForConfig(clientConfig)
 }
 
-func getDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+// GetDDInformerFactory returns an informer factory for the datadoghq/ custom types
+func GetDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
 	// default to 300s
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
 	client, err := getKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
@@ -338,11 +340,11 @@ func (c *APIClient) connect() error {
 		}
 	}
 	if config.Datadog.GetBool("external_metrics_provider.use_datadogmetric_crd") {
-		if c.DDInformerFactory, err = getDDInformerFactory(); err != nil {
+		if c.DDInformerFactory, err = GetDDInformerFactory(); err != nil {
 			log.Errorf("Error getting datadoghq Client: %s", err.Error())
 			return err
 		}
-		if c.DDClient, err = getDDClient(time.Duration(c.timeoutSeconds) * time.Second); err != nil {
+		if c.DDClient, err = GetDDClient(time.Duration(c.timeoutSeconds) * time.Second); err != nil {
 			log.Errorf("Error getting datadoghq Informer Factory: %s", err.Error())
 			return err
 		}
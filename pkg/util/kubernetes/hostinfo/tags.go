@@ -29,7 +29,7 @@ func GetTags(ctx context.Context) ([]string, error) {
 		return nil, err
 	}
 
-	return extractTags(nodeLabels, labelsToTags), nil
+	return extractTags(nodeLabels, labelsToTags, utils.GetTagExtractionRules()), nil
 }
 
 func getDefaultLabelsToTags() map[string]string {
@@ -48,14 +48,22 @@ func getLabelsToTags() map[string]string {
 	return labelsToTags
 }
 
-func extractTags(nodeLabels, labelsToTags map[string]string) []string {
+func extractTags(nodeLabels, labelsToTags map[string]string, extractionRules utils.TagExtractionRules) []string {
 	tagList := utils.NewTagList()
+	ruleTagList := utils.NewTagList()
 	labelsToTags, glob := utils.InitMetadataAsTags(labelsToTags)
 	for labelName, labelValue := range nodeLabels {
 		labelName, labelValue := LabelPreprocessor(labelName, labelValue)
 		utils.AddMetadataAsTags(labelName, labelValue, labelsToTags, glob, tagList)
+		extractionRules.Apply(utils.NodeLabelsSource, labelName, labelValue, ruleTagList)
 	}
 
 	tags, _, _, _ := tagList.Compute()
-	return tags
+
+	// host tags have no cardinality, so the tags of extraction rules are
+	// kept whatever their cardinality
+	low, orchestrator, high, _ := ruleTagList.Compute()
+	tags = append(tags, low...)
+	tags = append(tags, orchestrator...)
+	return append(tags, high...)
 }
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,9 +40,10 @@ func TestExtractTags(t *testing.T) {
 	}
 
 	for _, tc := range []struct {
-		nodeLabels   map[string]string
-		labelsToTags map[string]string
-		expectedTags []string
+		nodeLabels      map[string]string
+		labelsToTags    map[string]string
+		extractionRules utils.TagExtractionRules
+		expectedTags    []string
 	}{
 		{
 			nodeLabels:   map[string]string{},
@@ -106,9 +108,29 @@ func TestExtractTags(t *testing.T) {
 				"foo_kubernetes.io/role:foo",
 			},
 		},
+		{
+			nodeLabels: gkeLabels,
+			labelsToTags: map[string]string{
+				"beta.kubernetes.io/os": "os",
+			},
+			extractionRules: utils.CompileTagExtractionRules([]utils.TagExtractionRuleConfig{
+				{
+					Source:      utils.NodeLabelsSource,
+					Key:         "kubernetes.io/hostname",
+					Pattern:     `^gke-(?P<cluster>.+)-(?P<pool>default-pool)-[0-9a-f]+-[a-z0-9]+$`,
+					Tags:        map[string]string{"gke_cluster": "${cluster}", "gke_pool": "${pool}"},
+					Cardinality: "high",
+				},
+			}),
+			expectedTags: []string{
+				"os:linux",
+				"gke_cluster:dummy-18",
+				"gke_pool:default-pool",
+			},
+		},
 	} {
 		t.Run("", func(t *testing.T) {
-			tags := extractTags(tc.nodeLabels, tc.labelsToTags)
+			tags := extractTags(tc.nodeLabels, tc.labelsToTags, tc.extractionRules)
 			assert.ElementsMatch(t, tc.expectedTags, tags)
 		})
 	}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+// Package procfs reads the listening sockets of the processes from procfs. It is
+// shared by the components mapping processes to the ports they listen on, like the
+// process listener of Auto Discovery and the process collector of workloadmeta.
+package procfs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// tcpListenState is the state of the listening TCP sockets in /proc/net/tcp
+	tcpListenState = "0A"
+	// udpUnconnectedState is the state of the unconnected UDP sockets in /proc/net/udp
+	udpUnconnectedState = "07"
+)
+
+// socketTables lists the socket tables of procfs, along with their protocol and
+// the state of their listening sockets
+var socketTables = []struct {
+	file     string
+	protocol string
+	state    string
+}{
+	{file: "tcp", protocol: "tcp", state: tcpListenState},
+	{file: "tcp6", protocol: "tcp", state: tcpListenState},
+	{file: "udp", protocol: "udp", state: udpUnconnectedState},
+	{file: "udp6", protocol: "udp", state: udpUnconnectedState},
+}
+
+// Socket is a listening socket: a TCP socket in the listen state, or an
+// unconnected UDP socket.
+type Socket struct {
+	Protocol string // "tcp" or "udp"
+	IP       net.IP
+	Port     int
+}
+
+// ListeningSockets returns the listening sockets of the network namespace of the
+// process whose procfs directory is pidDir, indexed by inode. It fails if the TCP
+// table can't be read; the other tables are skipped when they can't, for instance
+// when IPv6 is disabled.
+func ListeningSockets(pidDir string) (map[string]Socket, error) {
+	sockets := make(map[string]Socket)
+
+	for _, table := range socketTables {
+		content, err := ioutil.ReadFile(filepath.Join(pidDir, "net", table.file))
+		if err != nil {
+			if table.file == "tcp" {
+				return nil, err
+			}
+			continue
+		}
+
+		parseSocketTable(content, table.protocol, table.state, sockets)
+	}
+
+	return sockets, nil
+}
+
+// parseSocketTable adds the sockets of a /proc/net/{tcp,udp}{,6} table in the
+// given state to sockets
+func parseSocketTable(content []byte, protocol, state string, sockets map[string]Socket) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	// Skip header line
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+
+		ip, port, err := parseAddress(fields[1])
+		if err != nil {
+			log.Debugf("error parsing the local address %q: %s", fields[1], err)
+			continue
+		}
+
+		sockets[fields[9]] = Socket{
+			Protocol: protocol,
+			IP:       ip,
+			Port:     port,
+		}
+	}
+}
+
+// parseAddress parses an address of a socket table, made of the hex IP address,
+// in host byte order by group of 4 bytes, and the hex port
+func parseAddress(address string) (net.IP, int, error) {
+	parts := strings.Split(address, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address")
+	}
+
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid IP address")
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port: %s", err)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	return ip, int(port), nil
+}
+
+// SocketInodes returns the deduplicated inodes of the sockets opened by the
+// process whose procfs directory is pidDir, using its file descriptors. The file
+// descriptors of the processes of other users can only be read with the
+// CAP_SYS_PTRACE capability.
+func SocketInodes(pidDir string) ([]string, error) {
+	fdDir := filepath.Join(pidDir, "fd")
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var inodes []string
+
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+
+		inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+		if _, found := seen[inode]; found {
+			continue
+		}
+
+		seen[inode] = struct{}{}
+		inodes = append(inodes, inode)
+	}
+
+	return inodes, nil
+}
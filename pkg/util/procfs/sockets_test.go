@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package procfs
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0500000A:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:18EB 0100007F:D431 01 00000000:00000000 00:00000000 00000000   999        0 1003 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:GGGG 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1006 1 0000000000000000 100 0 0 10 0
+`
+	procNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:4000 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1004 1 0000000000000000 100 0 0 10 0
+`
+	procNetUDP = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  0: 00000000:2BCB 00000000:0000 07 00000000:00000000 00:00000000 00000000   999        0 1005 2 0000000000000000 0
+`
+)
+
+func TestListeningSockets(t *testing.T) {
+	pidDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pidDir, "net"), 0755))
+
+	// the TCP table is required
+	_, err := ListeningSockets(pidDir)
+	assert.Error(t, err)
+
+	for file, content := range map[string]string{"tcp": procNetTCP, "tcp6": procNetTCP6, "udp": procNetUDP} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(pidDir, "net", file), []byte(content), 0644))
+	}
+
+	// the connected socket and the invalid line are left out, and udp6 is missing
+	sockets, err := ListeningSockets(pidDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Socket{
+		"1001": {Protocol: "tcp", IP: net.IPv4(0, 0, 0, 0).To4(), Port: 6379},
+		"1002": {Protocol: "tcp", IP: net.IPv4(10, 0, 0, 5).To4(), Port: 8080},
+		"1004": {Protocol: "tcp", IP: net.IPv6unspecified, Port: 16384},
+		"1005": {Protocol: "udp", IP: net.IPv4(0, 0, 0, 0).To4(), Port: 11211},
+	}, sockets)
+}
+
+func TestParseAddress(t *testing.T) {
+	ip, port, err := parseAddress("0100007F:18EB")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, 6379, port)
+
+	ip, port, err = parseAddress("0000000000000000FFFF00000500000A:1F90")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", ip.String())
+	assert.Equal(t, 8080, port)
+
+	ip, _, err = parseAddress("00000000000000000000000001000000:0016")
+	require.NoError(t, err)
+	assert.Equal(t, "::1", ip.String())
+
+	for _, address := range []string{"", "0100007F", "0100007F:GGGG", "01007F:0016", "zz00007F:0016"} {
+		_, _, err := parseAddress(address)
+		assert.Error(t, err, address)
+	}
+}
+
+func TestSocketInodes(t *testing.T) {
+	pidDir := t.TempDir()
+
+	// the process exited
+	_, err := SocketInodes(pidDir)
+	assert.Error(t, err)
+
+	fdDir := filepath.Join(pidDir, "fd")
+	require.NoError(t, os.MkdirAll(fdDir, 0755))
+	for fd, link := range map[string]string{
+		"0": "/dev/null",
+		"3": "socket:[1001]",
+		"4": "pipe:[3001]",
+		"5": "socket:[1002]",
+		"6": "socket:[1001]",
+	} {
+		require.NoError(t, os.Symlink(link, filepath.Join(fdDir, fd)))
+	}
+
+	inodes, err := SocketInodes(pidDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1001", "1002"}, inodes)
+}
@@ -8,6 +8,7 @@ package tmplvar
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"unicode"
 )
 
@@ -16,6 +17,15 @@ var tmplVarRegex = regexp.MustCompile(`%%.+?%%`)
 // TemplateVar is the info for a parsed template variable.
 type TemplateVar struct {
 	Raw, Name, Key []byte
+	// Filters are applied in order to the value of the variable
+	Filters []Filter
+}
+
+// Filter is a function applied to the value of a template variable, like
+// `default "6379"` in `%%port | default "6379"%%`
+type Filter struct {
+	Name string
+	Args []string
 }
 
 // ParseString returns parsed template variables found in the input string.
@@ -28,8 +38,13 @@ func Parse(b []byte) []TemplateVar {
 	var parsed []TemplateVar
 	vars := tmplVarRegex.FindAll(b, -1)
 	for _, v := range vars {
-		name, key := parseTemplateVar(v)
-		parsed = append(parsed, TemplateVar{v, name, key})
+		pipeline := splitPipeline(string(v[2 : len(v)-2]))
+		name, key := parseTemplateVar([]byte(pipeline[0]))
+		var filters []Filter
+		for _, f := range pipeline[1:] {
+			filters = append(filters, parseFilter(f))
+		}
+		parsed = append(parsed, TemplateVar{v, name, key, filters})
 	}
 	return parsed
 }
@@ -52,3 +67,68 @@ func parseTemplateVar(v []byte) (name, key []byte) {
 	}
 	return name, key
 }
+
+// splitPipeline splits the content of a template variable on the pipes which
+// aren't quoted
+func splitPipeline(s string) []string {
+	var parts []string
+	var current strings.Builder
+	quoted, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == '|':
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+// parseFilter parses a filter made of its name followed by its arguments,
+// separated by spaces. An argument containing spaces must be double-quoted,
+// and can then contain escaped double quotes and backslashes.
+func parseFilter(s string) Filter {
+	var fields []string
+	var current strings.Builder
+	inField, quoted, escaped := false, false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case quoted && r == '"':
+			quoted = false
+		case quoted:
+			current.WriteRune(r)
+		case r == '"':
+			inField, quoted = true, true
+		case unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			inField = true
+			current.WriteRune(r)
+		}
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+
+	if len(fields) == 0 {
+		return Filter{}
+	}
+	return Filter{Name: fields[0], Args: fields[1:]}
+}
@@ -51,3 +51,63 @@ func TestParseTemplateVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFilters(t *testing.T) {
+	testCases := []struct {
+		data    string
+		name    string
+		key     string
+		filters []Filter
+	}{
+		{
+			data: "%%host%%",
+			name: "host",
+		},
+		{
+			data:    "%%port_0 | default \"6379\"%%",
+			name:    "port",
+			key:     "0",
+			filters: []Filter{{Name: "default", Args: []string{"6379"}}},
+		},
+		{
+			data: "%%kube_pod_name|lower|replace \"-\" \"_\"%%",
+			name: "kube",
+			key:  "pod_name",
+			filters: []Filter{
+				{Name: "lower", Args: []string{}},
+				{Name: "replace", Args: []string{"-", "_"}},
+			},
+		},
+		{
+			data: `%%label_com.example/servers | split "|" 1 | default "a \"b\" \\c"%%`,
+			name: "label",
+			key:  "com.example/servers",
+			filters: []Filter{
+				{Name: "split", Args: []string{"|", "1"}},
+				{Name: "default", Args: []string{`a "b" \c`}},
+			},
+		},
+		{
+			data:    `%%env_DB_PASSWORD | default ""%%`,
+			name:    "env",
+			key:     "DB_PASSWORD",
+			filters: []Filter{{Name: "default", Args: []string{""}}},
+		},
+		{
+			data:    "%%host | %%",
+			name:    "host",
+			filters: []Filter{{}},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			vars := ParseString("value: " + testCase.data)
+			assert.Len(t, vars, 1)
+			assert.Equal(t, testCase.data, string(vars[0].Raw))
+			assert.Equal(t, testCase.name, string(vars[0].Name))
+			assert.Equal(t, testCase.key, string(vars[0].Key))
+			assert.Equal(t, testCase.filters, vars[0].Filters)
+		})
+	}
+}
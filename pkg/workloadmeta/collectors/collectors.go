@@ -10,5 +10,8 @@ package collectors
 
 import (
 	// this package only loads the collectors
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/containerd"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/docker"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/kubelet"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/process"
 )
@@ -0,0 +1,333 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build containerd
+
+package containerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/containerd"
+	apievents "github.com/containerd/containerd/api/events"
+	"github.com/containerd/containerd/containers"
+	containerdevents "github.com/containerd/containerd/events"
+	"github.com/containerd/containerd/oci"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	cutil "github.com/DataDog/datadog-agent/pkg/util/containerd"
+	dcontainers "github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID = "containerd"
+
+	containerCreationTopic = "/containers/create"
+	containerUpdateTopic   = "/containers/update"
+	containerDeletionTopic = "/containers/delete"
+	taskStartTopic         = "/tasks/start"
+	taskExitTopic          = "/tasks/exit"
+
+	// kubernetesContainerNameLabel is set by the CRI plugin on the
+	// containers of kubernetes pods
+	kubernetesContainerNameLabel = "io.kubernetes.container.name"
+)
+
+// containerLifecycleFilters subscribes to the lifecycle of containers and of
+// their tasks, which hold the running state.
+var containerLifecycleFilters = []string{
+	fmt.Sprintf(`topic==%q`, containerCreationTopic),
+	fmt.Sprintf(`topic==%q`, containerUpdateTopic),
+	fmt.Sprintf(`topic==%q`, containerDeletionTopic),
+	fmt.Sprintf(`topic==%q`, taskStartTopic),
+	fmt.Sprintf(`topic==%q`, taskExitTopic),
+}
+
+type collector struct {
+	client cutil.ContainerdItf
+	store  *workloadmeta.Store
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(ctx context.Context, store *workloadmeta.Store) error {
+	if !config.IsFeaturePresent(config.Containerd) {
+		return errors.New("the Agent is not running in containerd")
+	}
+
+	// the store holds a single entity per container, so the kubelet
+	// collector remains the only source of containers in Kubernetes
+	if config.IsFeaturePresent(config.Kubernetes) {
+		return errors.New("containers are collected from the kubelet in Kubernetes")
+	}
+
+	var err error
+
+	c.store = store
+	c.client, err = cutil.GetContainerdUtil()
+	if err != nil {
+		return err
+	}
+
+	events, errs := c.client.GetEvents().Subscribe(ctx, containerLifecycleFilters...)
+
+	go c.stream(ctx, events, errs)
+
+	return nil
+}
+
+// Pull is a no-op, as the containerd collector is fed by the containerd
+// event stream.
+func (c *collector) Pull(_ context.Context) error {
+	return nil
+}
+
+// stream sends the existing containers to the store, then keeps it updated as
+// containers and their tasks change.
+func (c *collector) stream(ctx context.Context, events <-chan *containerdevents.Envelope, errs <-chan error) {
+	health := health.RegisterLiveness("workloadmeta-containerd")
+
+	err := c.listContainers()
+	if err != nil {
+		log.Warnf("error listing existing containerd containers: %s", err)
+	}
+
+	for {
+		select {
+		case <-health.C:
+
+		case ev := <-events:
+			c.handleEvent(ev)
+
+		case err := <-errs:
+			if err != nil {
+				log.Errorf("stopping collection: %s", err)
+			}
+
+			c.stop(health)
+
+			return
+
+		case <-ctx.Done():
+			c.stop(health)
+
+			return
+		}
+	}
+}
+
+func (c *collector) stop(healthHandle *health.Handle) {
+	err := healthHandle.Deregister()
+	if err != nil {
+		log.Warnf("error de-registering health check: %s", err)
+	}
+}
+
+func (c *collector) listContainers() error {
+	list, err := c.client.Containers()
+	if err != nil {
+		return err
+	}
+
+	events := make([]workloadmeta.Event, 0, len(list))
+	for _, ctn := range list {
+		ev, ok, err := c.buildCollectorEvent(ctn)
+		if err != nil {
+			log.Debugf("cannot get containerd container %q: %s", ctn.ID(), err)
+			continue
+		}
+
+		if ok {
+			events = append(events, ev)
+		}
+	}
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) handleEvent(envelope *containerdevents.Envelope) {
+	id, err := containerIDFromEvent(envelope)
+	if err != nil {
+		log.Debugf("cannot process containerd event on topic %q: %s", envelope.Topic, err)
+		return
+	}
+
+	if envelope.Topic == containerDeletionTopic {
+		c.store.Notify([]workloadmeta.Event{
+			{
+				Source: collectorID,
+				Type:   workloadmeta.EventTypeUnset,
+				Entity: workloadmeta.EntityID{
+					Kind: workloadmeta.KindContainer,
+					ID:   id,
+				},
+			},
+		})
+
+		return
+	}
+
+	ctn, err := c.client.Container(id)
+	if err != nil {
+		log.Debugf("cannot get containerd container %q: %s", id, err)
+		return
+	}
+
+	ev, ok, err := c.buildCollectorEvent(ctn)
+	if err != nil {
+		log.Debugf("cannot get containerd container %q: %s", id, err)
+		return
+	}
+
+	if ok {
+		c.store.Notify([]workloadmeta.Event{ev})
+	}
+}
+
+// buildCollectorEvent returns the event setting a container in the store, and
+// false if the container is ignored.
+func (c *collector) buildCollectorEvent(ctn containerd.Container) (workloadmeta.Event, bool, error) {
+	info, err := c.client.Info(ctn)
+	if err != nil {
+		return workloadmeta.Event{}, false, err
+	}
+
+	if dcontainers.IsPauseContainer(info.Labels) {
+		return workloadmeta.Event{}, false, nil
+	}
+
+	spec, err := c.client.Spec(ctn)
+	if err != nil {
+		return workloadmeta.Event{}, false, err
+	}
+
+	// containers without a task have been created but never started
+	var status *containerd.Status
+	if st, err := c.client.Status(ctn); err == nil {
+		status = &st
+	} else {
+		log.Tracef("cannot get the task status of containerd container %q: %s", ctn.ID(), err)
+	}
+
+	return workloadmeta.Event{
+		Source: collectorID,
+		Type:   workloadmeta.EventTypeSet,
+		Entity: buildContainer(info, spec, status),
+	}, true, nil
+}
+
+// buildContainer converts a containerd container into a
+// workloadmeta.Container. containerd has no notion of container ports, so
+// none are reported.
+func buildContainer(info containers.Container, spec *oci.Spec, status *containerd.Status) workloadmeta.Container {
+	container := workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   info.ID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   info.Labels[kubernetesContainerNameLabel],
+			Labels: info.Labels,
+		},
+		Image:   buildImage(info.Image),
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+	}
+
+	if spec != nil && spec.Process != nil {
+		container.EnvVars = extractEnvVars(spec.Process.Env)
+	}
+
+	if status != nil {
+		container.State = workloadmeta.ContainerState{
+			Running:    status.Status == containerd.Running,
+			FinishedAt: status.ExitTime,
+		}
+	}
+
+	return container
+}
+
+func buildImage(imageName string) workloadmeta.ContainerImage {
+	image := workloadmeta.ContainerImage{
+		RawName: imageName,
+		Name:    imageName,
+	}
+
+	name, shortName, tag, err := dcontainers.SplitImageName(imageName)
+	if err != nil {
+		log.Debugf("cannot split image name %q: %s", imageName, err)
+		return image
+	}
+
+	if tag == "" {
+		// containerd defaults to latest if tag is omitted
+		tag = "latest"
+	}
+
+	image.Name = name
+	image.ShortName = shortName
+	image.Tag = tag
+
+	return image
+}
+
+func extractEnvVars(env []string) map[string]string {
+	envVars := make(map[string]string, len(env))
+
+	for _, e := range env {
+		envSplit := strings.SplitN(e, "=", 2)
+		if len(envSplit) != 2 {
+			continue
+		}
+
+		envVars[envSplit[0]] = envSplit[1]
+	}
+
+	return envVars
+}
+
+func containerIDFromEvent(envelope *containerdevents.Envelope) (string, error) {
+	if envelope.Event == nil {
+		return "", errors.New("empty event")
+	}
+
+	switch envelope.Topic {
+	case containerCreationTopic:
+		ev := &apievents.ContainerCreate{}
+		err := proto.Unmarshal(envelope.Event.Value, ev)
+		return ev.ID, err
+	case containerUpdateTopic:
+		ev := &apievents.ContainerUpdate{}
+		err := proto.Unmarshal(envelope.Event.Value, ev)
+		return ev.ID, err
+	case containerDeletionTopic:
+		ev := &apievents.ContainerDelete{}
+		err := proto.Unmarshal(envelope.Event.Value, ev)
+		return ev.ID, err
+	case taskStartTopic:
+		ev := &apievents.TaskStart{}
+		err := proto.Unmarshal(envelope.Event.Value, ev)
+		return ev.ContainerID, err
+	case taskExitTopic:
+		ev := &apievents.TaskExit{}
+		err := proto.Unmarshal(envelope.Event.Value, ev)
+		return ev.ContainerID, err
+	default:
+		return "", errors.New("unsupported topic")
+	}
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build containerd
+
+package containerd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd"
+	apievents "github.com/containerd/containerd/api/events"
+	"github.com/containerd/containerd/containers"
+	containerdevents "github.com/containerd/containerd/events"
+	"github.com/containerd/containerd/oci"
+	"github.com/gogo/protobuf/proto"
+	prototypes "github.com/gogo/protobuf/types"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestBuildContainer(t *testing.T) {
+	info := containers.Container{
+		ID:     "3b8efe0c50e8",
+		Image:  "docker.io/library/redis:6.2",
+		Labels: map[string]string{"io.kubernetes.container.name": "redis"},
+	}
+	spec := &oci.Spec{
+		Process: &specs.Process{Env: []string{"REDIS_VERSION=6.2.5", "INVALID"}},
+	}
+	exitTime := time.Date(2021, time.September, 13, 9, 52, 43, 0, time.UTC)
+
+	expected := workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "3b8efe0c50e8",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "redis",
+			Labels: map[string]string{"io.kubernetes.container.name": "redis"},
+		},
+		Image: workloadmeta.ContainerImage{
+			RawName:   "docker.io/library/redis:6.2",
+			Name:      "docker.io/library/redis",
+			ShortName: "redis",
+			Tag:       "6.2",
+		},
+		EnvVars: map[string]string{"REDIS_VERSION": "6.2.5"},
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+	}
+
+	// created, but never started
+	assert.Equal(t, expected, buildContainer(info, spec, nil))
+
+	expected.State = workloadmeta.ContainerState{Running: true}
+	assert.Equal(t, expected, buildContainer(info, spec, &containerd.Status{Status: containerd.Running}))
+
+	expected.State = workloadmeta.ContainerState{FinishedAt: exitTime}
+	assert.Equal(t, expected, buildContainer(info, spec, &containerd.Status{Status: containerd.Stopped, ExitTime: exitTime}))
+}
+
+func TestContainerIDFromEvent(t *testing.T) {
+	for _, tc := range []struct {
+		topic string
+		event proto.Message
+	}{
+		{topic: containerCreationTopic, event: &apievents.ContainerCreate{ID: "3b8efe0c50e8"}},
+		{topic: containerUpdateTopic, event: &apievents.ContainerUpdate{ID: "3b8efe0c50e8"}},
+		{topic: containerDeletionTopic, event: &apievents.ContainerDelete{ID: "3b8efe0c50e8"}},
+		{topic: taskStartTopic, event: &apievents.TaskStart{ContainerID: "3b8efe0c50e8", Pid: 42}},
+		{topic: taskExitTopic, event: &apievents.TaskExit{ContainerID: "3b8efe0c50e8", ID: "exec", Pid: 42}},
+	} {
+		t.Run(tc.topic, func(t *testing.T) {
+			value, err := proto.Marshal(tc.event)
+			require.NoError(t, err)
+
+			id, err := containerIDFromEvent(&containerdevents.Envelope{
+				Topic: tc.topic,
+				Event: &prototypes.Any{Value: value},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "3b8efe0c50e8", id)
+		})
+	}
+
+	_, err := containerIDFromEvent(&containerdevents.Envelope{Topic: "/images/create", Event: &prototypes.Any{}})
+	assert.EqualError(t, err, "unsupported topic")
+}
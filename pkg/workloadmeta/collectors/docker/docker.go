@@ -0,0 +1,312 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build docker
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID = "docker"
+)
+
+type collector struct {
+	dockerUtil *docker.DockerUtil
+	store      *workloadmeta.Store
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(ctx context.Context, store *workloadmeta.Store) error {
+	if !config.IsFeaturePresent(config.Docker) {
+		return errors.New("the Agent is not running in Docker")
+	}
+
+	// the store holds a single entity per container, so the kubelet
+	// collector remains the only source of containers in Kubernetes
+	if config.IsFeaturePresent(config.Kubernetes) {
+		return errors.New("containers are collected from the kubelet in Kubernetes")
+	}
+
+	var err error
+
+	c.store = store
+	c.dockerUtil, err = docker.GetDockerUtil()
+	if err != nil {
+		return err
+	}
+
+	events, errs, err := c.dockerUtil.SubscribeToContainerEvents(collectorID)
+	if err != nil {
+		return err
+	}
+
+	go c.stream(ctx, events, errs)
+
+	return nil
+}
+
+// Pull is a no-op, as the docker collector is fed by the docker event stream.
+func (c *collector) Pull(_ context.Context) error {
+	return nil
+}
+
+// stream sends the containers already running to the store, then keeps it
+// updated as containers start and die.
+func (c *collector) stream(ctx context.Context, events <-chan *docker.ContainerEvent, errs <-chan error) {
+	health := health.RegisterLiveness("workloadmeta-docker")
+
+	err := c.listContainers(ctx)
+	if err != nil {
+		log.Warnf("error listing existing docker containers: %s", err)
+	}
+
+	for {
+		select {
+		case <-health.C:
+
+		case ev := <-events:
+			c.handleEvent(ctx, ev)
+
+		case err := <-errs:
+			if err != nil && err != io.EOF {
+				log.Errorf("stopping collection: %s", err)
+			}
+
+			c.stop(health)
+
+			return
+
+		case <-ctx.Done():
+			c.stop(health)
+
+			return
+		}
+	}
+}
+
+func (c *collector) stop(healthHandle *health.Handle) {
+	err := c.dockerUtil.UnsubscribeFromContainerEvents(collectorID)
+	if err != nil {
+		log.Warnf("error unsubscribing from docker events: %s", err)
+	}
+
+	err = healthHandle.Deregister()
+	if err != nil {
+		log.Warnf("error de-registering health check: %s", err)
+	}
+}
+
+func (c *collector) listContainers(ctx context.Context) error {
+	list, err := c.dockerUtil.RawContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return err
+	}
+
+	events := make([]workloadmeta.Event, 0, len(list))
+	for _, co := range list {
+		ev, err := c.buildCollectorEvent(ctx, co.ID, false)
+		if err != nil {
+			log.Debugf("cannot inspect docker container %q: %s", co.ID, err)
+			continue
+		}
+
+		events = append(events, ev)
+	}
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) handleEvent(ctx context.Context, ev *docker.ContainerEvent) {
+	var event workloadmeta.Event
+
+	switch ev.Action {
+	case docker.ContainerEventActionStart, docker.ContainerEventActionRename:
+		var err error
+
+		// a fresh inspect is needed on start, as a cached one could
+		// date from a previous run of the same container
+		event, err = c.buildCollectorEvent(ctx, ev.ContainerID, ev.Action == docker.ContainerEventActionStart)
+		if err != nil {
+			if !dderrors.IsNotFound(err) {
+				log.Debugf("cannot inspect docker container %q: %s", ev.ContainerID, err)
+			}
+			return
+		}
+
+	case docker.ContainerEventActionDie, docker.ContainerEventActionDied:
+		event = workloadmeta.Event{
+			Source: collectorID,
+			Type:   workloadmeta.EventTypeUnset,
+			Entity: workloadmeta.EntityID{
+				Kind: workloadmeta.KindContainer,
+				ID:   ev.ContainerID,
+			},
+		}
+
+	default:
+		return
+	}
+
+	c.store.Notify([]workloadmeta.Event{event})
+}
+
+func (c *collector) buildCollectorEvent(ctx context.Context, id string, noCache bool) (workloadmeta.Event, error) {
+	var (
+		co  types.ContainerJSON
+		err error
+	)
+
+	if noCache {
+		co, err = c.dockerUtil.InspectNoCache(ctx, id, false)
+	} else {
+		co, err = c.dockerUtil.Inspect(ctx, id, false)
+	}
+	if err != nil {
+		return workloadmeta.Event{}, err
+	}
+
+	if co.Config == nil {
+		return workloadmeta.Event{}, errors.New("missing container config")
+	}
+
+	imageName, err := c.dockerUtil.ResolveImageNameFromContainer(ctx, co)
+	if err != nil {
+		log.Debugf("cannot resolve image name of container %q: %s", id, err)
+		imageName = co.Config.Image
+	}
+
+	return workloadmeta.Event{
+		Source: collectorID,
+		Type:   workloadmeta.EventTypeSet,
+		Entity: buildContainer(co, imageName),
+	}, nil
+}
+
+// buildContainer converts a docker inspect into a workloadmeta.Container.
+// co.Config must not be nil.
+func buildContainer(co types.ContainerJSON, imageName string) workloadmeta.Container {
+	image := buildImage(imageName)
+	image.ID = co.Image
+
+	container := workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   co.ID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   strings.TrimPrefix(co.Name, "/"),
+			Labels: co.Config.Labels,
+		},
+		Image:   image,
+		EnvVars: extractEnvVars(co.Config.Env),
+		Ports:   extractPorts(co),
+		Runtime: workloadmeta.ContainerRuntimeDocker,
+	}
+
+	if st := co.State; st != nil {
+		container.State = workloadmeta.ContainerState{
+			Running:    st.Running,
+			StartedAt:  parseTime(st.StartedAt),
+			FinishedAt: parseTime(st.FinishedAt),
+		}
+	}
+
+	return container
+}
+
+func buildImage(imageName string) workloadmeta.ContainerImage {
+	image := workloadmeta.ContainerImage{
+		RawName: imageName,
+		Name:    imageName,
+	}
+
+	name, shortName, tag, err := containers.SplitImageName(imageName)
+	if err != nil {
+		log.Debugf("cannot split image name %q: %s", imageName, err)
+		return image
+	}
+
+	if tag == "" {
+		// docker defaults to latest if tag is omitted
+		tag = "latest"
+	}
+
+	image.Name = name
+	image.ShortName = shortName
+	image.Tag = tag
+
+	return image
+}
+
+func extractEnvVars(env []string) map[string]string {
+	envVars := make(map[string]string, len(env))
+
+	for _, e := range env {
+		envSplit := strings.SplitN(e, "=", 2)
+		if len(envSplit) != 2 {
+			continue
+		}
+
+		envVars[envSplit[0]] = envSplit[1]
+	}
+
+	return envVars
+}
+
+// extractPorts returns the ports exposed by the container, sorted by port
+// number. Ports are named after their protocol.
+func extractPorts(co types.ContainerJSON) []workloadmeta.ContainerPort {
+	ports := make([]workloadmeta.ContainerPort, 0, len(co.Config.ExposedPorts))
+
+	for port := range co.Config.ExposedPorts {
+		ports = append(ports, workloadmeta.ContainerPort{
+			Name: port.Proto(),
+			Port: port.Int(),
+		})
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Name < ports[j].Name
+	})
+
+	return ports
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
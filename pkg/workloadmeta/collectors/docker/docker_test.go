@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build docker
+
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestBuildContainer(t *testing.T) {
+	co := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    "3b8efe0c50e8",
+			Name:  "/redis",
+			Image: "sha256:7614ae9453d1",
+			State: &types.ContainerState{
+				Running:    true,
+				StartedAt:  "2021-09-13T09:52:43.123456789Z",
+				FinishedAt: "0001-01-01T00:00:00Z",
+			},
+		},
+		Config: &container.Config{
+			Image:  "redis",
+			Labels: map[string]string{"com.datadoghq.tags.env": "prod"},
+			Env:    []string{"REDIS_VERSION=6.2.5", "EMPTY=", "INVALID"},
+			ExposedPorts: nat.PortSet{
+				"6379/tcp":  {},
+				"6379/udp":  {},
+				"26379/tcp": {},
+			},
+		},
+	}
+
+	assert.Equal(t, workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "3b8efe0c50e8",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "redis",
+			Labels: map[string]string{"com.datadoghq.tags.env": "prod"},
+		},
+		Image: workloadmeta.ContainerImage{
+			ID:        "sha256:7614ae9453d1",
+			RawName:   "redis",
+			Name:      "redis",
+			ShortName: "redis",
+			Tag:       "latest",
+		},
+		EnvVars: map[string]string{"REDIS_VERSION": "6.2.5", "EMPTY": ""},
+		Ports: []workloadmeta.ContainerPort{
+			{Name: "tcp", Port: 6379},
+			{Name: "udp", Port: 6379},
+			{Name: "tcp", Port: 26379},
+		},
+		Runtime: workloadmeta.ContainerRuntimeDocker,
+		State: workloadmeta.ContainerState{
+			Running:   true,
+			StartedAt: time.Date(2021, time.September, 13, 9, 52, 43, 123456789, time.UTC),
+		},
+	}, buildContainer(co, "redis"))
+}
+
+func TestBuildImage(t *testing.T) {
+	assert.Equal(t, workloadmeta.ContainerImage{
+		RawName:   "gcr.io/datadoghq/agent:7.31.0",
+		Name:      "gcr.io/datadoghq/agent",
+		ShortName: "agent",
+		Tag:       "7.31.0",
+	}, buildImage("gcr.io/datadoghq/agent:7.31.0"))
+
+	// unparsable names are kept as is
+	assert.Equal(t, workloadmeta.ContainerImage{
+		RawName: "sha256:7614ae9453d1",
+		Name:    "sha256:7614ae9453d1",
+	}, buildImage("sha256:7614ae9453d1"))
+}
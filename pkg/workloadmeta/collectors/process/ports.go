@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/procfs"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// listeningPorts maps the PIDs of the processes to the ports they listen on,
+// using the socket inodes of their file descriptors. The socket tables of each
+// network namespace are read once, through the first of its processes. The
+// file descriptors of the processes of other users can only be read with the
+// CAP_SYS_PTRACE capability.
+func listeningPorts(procRoot string, pids []int32) map[int32][]workloadmeta.ProcessPort {
+	ports := make(map[int32][]workloadmeta.ProcessPort)
+	socketsByNetNS := make(map[string]map[string]procfs.Socket)
+
+	for _, pid := range pids {
+		pidDir := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+
+		netNS, err := os.Readlink(filepath.Join(pidDir, "ns", "net"))
+		if err != nil {
+			// the process exited or its namespaces aren't readable
+			continue
+		}
+
+		sockets, found := socketsByNetNS[netNS]
+		if !found {
+			sockets, err = procfs.ListeningSockets(pidDir)
+			if err != nil {
+				log.Debugf("cannot read the sockets of the network namespace %s: %s", netNS, err)
+			}
+			socketsByNetNS[netNS] = sockets
+		}
+
+		if len(sockets) == 0 {
+			continue
+		}
+
+		if processPorts := processListeningPorts(pidDir, sockets); len(processPorts) > 0 {
+			ports[pid] = processPorts
+		}
+	}
+
+	return ports
+}
+
+// processListeningPorts returns the sorted and deduplicated listening addresses
+// of the sockets of a process
+func processListeningPorts(pidDir string, sockets map[string]procfs.Socket) []workloadmeta.ProcessPort {
+	inodes, err := procfs.SocketInodes(pidDir)
+	if err != nil {
+		// the process exited or its file descriptors aren't readable
+		return nil
+	}
+
+	seen := make(map[workloadmeta.ProcessPort]struct{})
+	var ports []workloadmeta.ProcessPort
+
+	for _, inode := range inodes {
+		socket, found := sockets[inode]
+		if !found {
+			continue
+		}
+
+		port := workloadmeta.ProcessPort{
+			Protocol: socket.Protocol,
+			IP:       socket.IP.String(),
+			Port:     socket.Port,
+		}
+		if _, found := seen[port]; found {
+			continue
+		}
+
+		seen[port] = struct{}{}
+		ports = append(ports, port)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].IP < ports[j].IP
+	})
+
+	return ports
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID = "process"
+)
+
+type collector struct {
+	store             *workloadmeta.Store
+	probe             procutil.Probe
+	procRoot          string
+	containerIDForPID func(pid int) (string, error)
+
+	// m serializes pulls, which the store runs concurrently when one of
+	// them lasts longer than its pull interval
+	m         sync.Mutex
+	interval  time.Duration
+	lastPull  time.Time
+	processes map[int32]workloadmeta.Process
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store *workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.process_collection.enabled") && !processListenerEnabled() {
+		return errors.New("process collection is disabled")
+	}
+
+	c.store = store
+	c.probe = procutil.NewProcessProbe()
+	c.procRoot = util.HostProc()
+	c.containerIDForPID = providers.ContainerImpl().ContainerIDForPID
+	c.interval = time.Duration(config.Datadog.GetInt("workloadmeta.process_collection.interval")) * time.Second
+	c.processes = make(map[int32]workloadmeta.Process)
+
+	return nil
+}
+
+// processListenerEnabled returns whether the process Autodiscovery listener,
+// which discovers services from the processes of the store, is configured.
+func processListenerEnabled() bool {
+	var listeners []config.Listeners
+	if err := config.Datadog.UnmarshalKey("listeners", &listeners); err != nil {
+		log.Debugf("cannot read the Autodiscovery listeners: %s", err)
+	}
+	for _, name := range config.Datadog.GetStringSlice("extra_listeners") {
+		listeners = append(listeners, config.Listeners{Name: name})
+	}
+
+	for _, listener := range listeners {
+		if listener.Name == "process" {
+			return true
+		}
+	}
+	return false
+}
+
+// Pull scans the processes of the host, at most once per collection interval,
+// and notifies the store of the processes that started, changed or exited
+// since the previous scan.
+func (c *collector) Pull(_ context.Context) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastPull) < c.interval {
+		return nil
+	}
+	c.lastPull = now
+
+	procs, err := c.probe.ProcessesByPID(now, false)
+	if err != nil {
+		return err
+	}
+
+	pids := make([]int32, 0, len(procs))
+	for pid := range procs {
+		pids = append(pids, pid)
+	}
+
+	c.store.Notify(c.parseProcesses(procs, listeningPorts(c.procRoot, pids)))
+
+	return nil
+}
+
+// parseProcesses returns the events of the processes that changed since the
+// previous scan, and updates the collector cache.
+func (c *collector) parseProcesses(procs map[int32]*procutil.Process, ports map[int32][]workloadmeta.ProcessPort) []workloadmeta.Event {
+	events := []workloadmeta.Event{}
+	seen := make(map[int32]struct{}, len(procs))
+
+	for pid, proc := range procs {
+		seen[pid] = struct{}{}
+
+		process := buildProcess(proc, ports[pid])
+
+		old, found := c.processes[pid]
+		if found && old.StartTime.Equal(process.StartTime) {
+			// a process can't move to another container, so its
+			// cgroups are only read once
+			process.ContainerID = old.ContainerID
+		} else {
+			containerID, err := c.containerIDForPID(int(pid))
+			if err != nil {
+				log.Debugf("cannot get the container of process %d: %s", pid, err)
+			}
+			process.ContainerID = containerID
+		}
+
+		if found && reflect.DeepEqual(old, process) {
+			continue
+		}
+
+		c.processes[pid] = process
+		events = append(events, workloadmeta.Event{
+			Source: collectorID,
+			Type:   workloadmeta.EventTypeSet,
+			Entity: process,
+		})
+	}
+
+	for pid, process := range c.processes {
+		if _, ok := seen[pid]; ok {
+			continue
+		}
+
+		delete(c.processes, pid)
+		events = append(events, workloadmeta.Event{
+			Source: collectorID,
+			Type:   workloadmeta.EventTypeUnset,
+			Entity: process.EntityID,
+		})
+	}
+
+	return events
+}
+
+func buildProcess(proc *procutil.Process, ports []workloadmeta.ProcessPort) workloadmeta.Process {
+	process := workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(int(proc.Pid)),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: proc.Name,
+		},
+		PID:            int(proc.Pid),
+		PPID:           int(proc.Ppid),
+		Cmdline:        proc.Cmdline,
+		Exe:            proc.Exe,
+		ListeningPorts: ports,
+	}
+
+	if proc.Stats != nil && proc.Stats.CreateTime > 0 {
+		// the creation time is in milliseconds since the epoch
+		process.StartTime = time.Unix(0, proc.Stats.CreateTime*int64(time.Millisecond))
+	}
+
+	return process
+}
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	hostTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:2BCB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:18EB 0100007F:D431 01 00000000:00000000 00:00000000 00000000   999        0 1003 1 0000000000000000 20 4 30 10 -1
+`
+	hostTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:18EB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1004 1 0000000000000000 100 0 0 10 0
+`
+	hostUDP = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  0: 00000000:2BCB 00000000:0000 07 00000000:00000000 00:00000000 00000000   999        0 1005 2 0000000000000000 0
+`
+	containerTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0
+`
+)
+
+// fakeProcess describes a process of a fake procfs
+type fakeProcess struct {
+	netNS     string
+	sockets   map[string]string // maps file names of /proc/<pid>/net to their content
+	fdSockets []string          // socket inodes of the file descriptors
+}
+
+func createFakeProcfs(t *testing.T, processes map[int32]fakeProcess) string {
+	procRoot, err := ioutil.TempDir("", "procfs")
+	require.NoError(t, err)
+
+	for pid, p := range processes {
+		pidDir := filepath.Join(procRoot, fmt.Sprint(pid))
+		for _, dir := range []string{"ns", "net", "fd"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(pidDir, dir), 0755))
+		}
+
+		require.NoError(t, os.Symlink(p.netNS, filepath.Join(pidDir, "ns", "net")))
+		for file, content := range p.sockets {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(pidDir, "net", file), []byte(content), 0644))
+		}
+
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(pidDir, "fd", "0")))
+		for i, inode := range p.fdSockets {
+			require.NoError(t, os.Symlink(fmt.Sprintf("socket:[%s]", inode), filepath.Join(pidDir, "fd", fmt.Sprint(i+3))))
+		}
+	}
+
+	return procRoot
+}
+
+func TestListeningPorts(t *testing.T) {
+	hostSockets := map[string]string{"tcp": hostTCP, "tcp6": hostTCP6, "udp": hostUDP}
+	procRoot := createFakeProcfs(t, map[int32]fakeProcess{
+		// redis listens on 6379 over IPv4 and IPv6, and on 11211/udp
+		10: {netNS: "net:[4026531992]", sockets: hostSockets, fdSockets: []string{"1001", "1004", "1005", "1003"}},
+		// the sockets of the namespace are read through the first process
+		11: {netNS: "net:[4026531992]", fdSockets: []string{"1002"}},
+		// the connected socket isn't listening
+		12: {netNS: "net:[4026531992]", fdSockets: []string{"1003"}},
+		// the namespace of a container has its own sockets
+		20: {netNS: "net:[4026532200]", sockets: map[string]string{"tcp": containerTCP}, fdSockets: []string{"2001", "1001"}},
+	})
+	defer os.RemoveAll(procRoot)
+
+	assert.Equal(t, map[int32][]workloadmeta.ProcessPort{
+		10: {{Protocol: "tcp", IP: "0.0.0.0", Port: 6379}, {Protocol: "tcp", IP: "::", Port: 6379}, {Protocol: "udp", IP: "0.0.0.0", Port: 11211}},
+		11: {{Protocol: "tcp", IP: "127.0.0.1", Port: 11211}},
+		20: {{Protocol: "tcp", IP: "0.0.0.0", Port: 80}},
+	}, listeningPorts(procRoot, []int32{10, 11, 12, 20, 30}))
+}
+
+func TestParseProcesses(t *testing.T) {
+	containerLookups := 0
+	c := &collector{
+		containerIDForPID: func(pid int) (string, error) {
+			containerLookups++
+			if pid == 20 {
+				return "3b8efe0c50e8", nil
+			}
+			return "", nil
+		},
+		processes: make(map[int32]workloadmeta.Process),
+	}
+
+	startTime := time.Unix(1631526763, 0)
+	redis := &procutil.Process{
+		Pid:     10,
+		Ppid:    1,
+		Name:    "redis-server",
+		Exe:     "/usr/bin/redis-server",
+		Cmdline: []string{"redis-server", "*:6379"},
+		Stats:   &procutil.Stats{CreateTime: startTime.UnixNano() / int64(time.Millisecond)},
+	}
+	nginx := &procutil.Process{
+		Pid:     20,
+		Ppid:    19,
+		Name:    "nginx",
+		Cmdline: []string{"nginx", "-g", "daemon off;"},
+		Stats:   &procutil.Stats{CreateTime: startTime.UnixNano() / int64(time.Millisecond)},
+	}
+	ports := map[int32][]workloadmeta.ProcessPort{10: {{Protocol: "tcp", IP: "0.0.0.0", Port: 6379}}}
+
+	expectedRedis := workloadmeta.Process{
+		EntityID:       workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "10"},
+		EntityMeta:     workloadmeta.EntityMeta{Name: "redis-server"},
+		PID:            10,
+		PPID:           1,
+		Cmdline:        []string{"redis-server", "*:6379"},
+		Exe:            "/usr/bin/redis-server",
+		StartTime:      startTime,
+		ListeningPorts: []workloadmeta.ProcessPort{{Protocol: "tcp", IP: "0.0.0.0", Port: 6379}},
+	}
+
+	events := c.parseProcesses(map[int32]*procutil.Process{10: redis, 20: nginx}, ports)
+	require.Len(t, events, 2)
+	for _, ev := range events {
+		assert.Equal(t, workloadmeta.EventTypeSet, ev.Type)
+		assert.Equal(t, collectorID, ev.Source)
+		process := ev.Entity.(workloadmeta.Process)
+		if process.PID == 10 {
+			assert.Equal(t, expectedRedis, process)
+		} else {
+			assert.Equal(t, "3b8efe0c50e8", process.ContainerID)
+		}
+	}
+	assert.Equal(t, 2, containerLookups)
+
+	// unchanged processes aren't sent again, and their container is kept
+	events = c.parseProcesses(map[int32]*procutil.Process{10: redis, 20: nginx}, ports)
+	assert.Empty(t, events)
+	assert.Equal(t, 2, containerLookups)
+
+	// redis stops listening, nginx exits
+	events = c.parseProcesses(map[int32]*procutil.Process{10: redis}, nil)
+	expectedRedis.ListeningPorts = nil
+	assert.ElementsMatch(t, []workloadmeta.Event{
+		{
+			Source: collectorID,
+			Type:   workloadmeta.EventTypeSet,
+			Entity: expectedRedis,
+		},
+		{
+			Source: collectorID,
+			Type:   workloadmeta.EventTypeUnset,
+			Entity: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "20"},
+		},
+	}, events)
+	assert.Equal(t, 2, containerLookups)
+}
+
+func TestProcessListenerEnabled(t *testing.T) {
+	cfg := config.Mock()
+	assert.False(t, processListenerEnabled())
+
+	cfg.Set("listeners", []config.Listeners{{Name: "docker"}})
+	assert.False(t, processListenerEnabled())
+
+	cfg.Set("extra_listeners", []string{"process"})
+	assert.True(t, processListenerEnabled())
+
+	cfg.Set("extra_listeners", []string{})
+	cfg.Set("listeners", []config.Listeners{{Name: "docker"}, {Name: "process"}})
+	assert.True(t, processListenerEnabled())
+}
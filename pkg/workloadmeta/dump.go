@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import "fmt"
+
+// WorkloadDumpResponse is used to dump the store content.
+type WorkloadDumpResponse struct {
+	Entities map[string]WorkloadEntity `json:"entities"`
+}
+
+// WorkloadEntity contains entity data, indexed by source and entity ID.
+type WorkloadEntity struct {
+	Infos map[string]string `json:"infos"`
+}
+
+// Dump returns the content of the store, grouped by kind. The description of
+// each entity is indexed by its source and its ID.
+func (s *Store) Dump(verbose bool) WorkloadDumpResponse {
+	workloadList := WorkloadDumpResponse{
+		Entities: make(map[string]WorkloadEntity),
+	}
+
+	s.storeMut.RLock()
+	defer s.storeMut.RUnlock()
+
+	for kind, entities := range s.store {
+		if len(entities) == 0 {
+			continue
+		}
+
+		entityInfos := WorkloadEntity{
+			Infos: make(map[string]string, len(entities)),
+		}
+
+		for id, cached := range entities {
+			entityInfos.Infos[fmt.Sprintf("source:%s id: %s", cached.source, id)] = cached.entity.String(verbose)
+		}
+
+		workloadList.Entities[string(kind)] = entityInfos
+	}
+
+	return workloadList
+}
+
+// FormatEvent returns a human-readable description of an event of the store,
+// as streamed by the workload-list command.
+func FormatEvent(ev Event, verbose bool) string {
+	eventType := "set"
+	if ev.Type == EventTypeUnset {
+		eventType = "unset"
+	}
+
+	entityID := ev.Entity.GetID()
+	header := fmt.Sprintf("=== %s %s %s (source:%s) ===\n", eventType, entityID.Kind, entityID.ID, ev.Source)
+
+	if ev.Type == EventTypeUnset {
+		return header
+	}
+
+	return header + ev.Entity.String(verbose) + "\n"
+}
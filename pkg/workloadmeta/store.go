@@ -28,6 +28,13 @@ const (
 	eventChBufferSize      = 50
 )
 
+// cachedEntity is an entity of the store, along with the collector that
+// generated it.
+type cachedEntity struct {
+	entity Entity
+	source string
+}
+
 type subscriber struct {
 	name   string
 	ch     chan EventBundle
@@ -39,7 +46,7 @@ type subscriber struct {
 // a kubernetes pod, or a task in any cloud provider.
 type Store struct {
 	storeMut sync.RWMutex
-	store    map[Kind]map[string]Entity
+	store    map[Kind]map[string]*cachedEntity
 
 	subscribersMut sync.RWMutex
 	subscribers    []subscriber
@@ -59,7 +66,7 @@ func NewStore() *Store {
 	}
 
 	return &Store{
-		store:       make(map[Kind]map[string]Entity),
+		store:       make(map[Kind]map[string]*cachedEntity),
 		subscribers: []subscriber{},
 
 		candidates: candidates,
@@ -148,19 +155,16 @@ func (s *Store) Subscribe(name string, filter *Filter) chan EventBundle {
 			continue
 		}
 
-		// TODO(juliogreff): implement filtering by source once
-		// each source has its own separate store. since at the
-		// time of writing there's a single source, this should
-		// not matter.
-
-		for _, entity := range entitiesOfKind {
-			events = append(events, Event{
-				// TODO(juliogreff): insert Source here
-				// after the above TODO has been
-				// addressed.
+		for _, cached := range entitiesOfKind {
+			ev := Event{
 				Type:   EventTypeSet,
-				Entity: entity,
-			})
+				Source: cached.source,
+				Entity: cached.entity,
+			}
+
+			if sub.filter.Match(ev) {
+				events = append(events, ev)
+			}
 		}
 	}
 	s.storeMut.RUnlock()
@@ -229,6 +233,20 @@ func (s *Store) GetECSTask(id string) (ECSTask, error) {
 	return t, nil
 }
 
+// GetProcess returns metadata about a process.
+func (s *Store) GetProcess(id string) (Process, error) {
+	var p Process
+
+	entity, err := s.getEntityByKind(KindProcess, id)
+	if err != nil {
+		return p, err
+	}
+
+	p = entity.(Process)
+
+	return p, nil
+}
+
 // Notify notifies the store with a slice of events.
 func (s *Store) Notify(events []Event) {
 	if len(events) > 0 {
@@ -288,13 +306,16 @@ func (s *Store) handleEvents(evs []Event) {
 
 		entitiesOfKind, ok := s.store[meta.Kind]
 		if !ok {
-			s.store[meta.Kind] = make(map[string]Entity)
+			s.store[meta.Kind] = make(map[string]*cachedEntity)
 			entitiesOfKind = s.store[meta.Kind]
 		}
 
 		switch ev.Type {
 		case EventTypeSet:
-			entitiesOfKind[meta.ID] = ev.Entity
+			entitiesOfKind[meta.ID] = &cachedEntity{
+				entity: ev.Entity,
+				source: ev.Source,
+			}
 		case EventTypeUnset:
 			delete(entitiesOfKind, meta.ID)
 		default:
@@ -336,12 +357,12 @@ func (s *Store) getEntityByKind(kind Kind, id string) (Entity, error) {
 		return nil, errors.NewNotFound(id)
 	}
 
-	entity, ok := entitiesOfKind[id]
+	cached, ok := entitiesOfKind[id]
 	if !ok {
 		return nil, errors.NewNotFound(id)
 	}
 
-	return entity, nil
+	return cached.entity, nil
 }
 
 func notifyChannel(name string, ch chan EventBundle, events []Event, wait bool) {
@@ -7,6 +7,7 @@ package workloadmeta
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
@@ -56,3 +57,93 @@ func TestHandleEvents(t *testing.T) {
 		t.Errorf("expected container %q to be absent. found or had errors. err: %q", container.ID, err)
 	}
 }
+
+func TestSubscribeFiltersSources(t *testing.T) {
+	s := NewStore()
+
+	s.handleEvents([]Event{
+		{
+			Type:   EventTypeSet,
+			Source: fooSource,
+			Entity: Container{EntityID: EntityID{Kind: KindContainer, ID: "foo"}},
+		},
+		{
+			Type:   EventTypeSet,
+			Source: "bar",
+			Entity: Container{EntityID: EntityID{Kind: KindContainer, ID: "bar"}},
+		},
+	})
+
+	ch := s.Subscribe("test", NewFilter(nil, []string{fooSource}))
+	defer s.Unsubscribe(ch)
+
+	bundle := <-ch
+	close(bundle.Ch)
+
+	if len(bundle.Events) != 1 {
+		t.Fatalf("expected a single event, got %d", len(bundle.Events))
+	}
+
+	ev := bundle.Events[0]
+	if ev.Source != fooSource || ev.Entity.GetID().ID != "foo" {
+		t.Errorf("expected the container of source %q, got %+v", fooSource, ev)
+	}
+}
+
+func TestDump(t *testing.T) {
+	s := NewStore()
+
+	container := Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "foo",
+		},
+		EntityMeta: EntityMeta{
+			Name:   "foo",
+			Labels: map[string]string{"app": "redis"},
+		},
+		Runtime: ContainerRuntimeDocker,
+	}
+
+	s.handleEvents([]Event{
+		{
+			Type:   EventTypeSet,
+			Source: fooSource,
+			Entity: container,
+		},
+	})
+
+	for _, verbose := range []bool{false, true} {
+		expected := WorkloadDumpResponse{
+			Entities: map[string]WorkloadEntity{
+				"container": {
+					Infos: map[string]string{
+						"source:foo id: foo": container.String(verbose),
+					},
+				},
+			},
+		}
+
+		if dump := s.Dump(verbose); !reflect.DeepEqual(expected, dump) {
+			t.Errorf("expected dump %+v, got %+v", expected, dump)
+		}
+	}
+
+	if !strings.Contains(container.String(true), "Labels: app:redis") || strings.Contains(container.String(false), "Labels:") {
+		t.Errorf("expected labels in the verbose output only")
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	container := Container{EntityID: EntityID{Kind: KindContainer, ID: "foo"}}
+
+	set := FormatEvent(Event{Type: EventTypeSet, Source: fooSource, Entity: container}, false)
+	if !strings.HasPrefix(set, "=== set container foo (source:foo) ===\n") || !strings.Contains(set, "ID: foo") {
+		t.Errorf("unexpected set event output %q", set)
+	}
+
+	unset := FormatEvent(Event{Type: EventTypeUnset, Source: fooSource, Entity: container.EntityID}, true)
+	if expected := "=== unset container foo (source:foo) ===\n"; unset != expected {
+		t.Errorf("expected unset event output %q, got %q", expected, unset)
+	}
+}
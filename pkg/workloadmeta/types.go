@@ -5,7 +5,12 @@
 
 package workloadmeta
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Kind is the kind of an entity.
 type Kind string
@@ -24,8 +29,10 @@ const (
 	KindContainer     Kind = "container"
 	KindKubernetesPod Kind = "kubernetes_pod"
 	KindECSTask       Kind = "ecs_task"
+	KindProcess       Kind = "process"
 
-	ContainerRuntimeDocker ContainerRuntime = "docker"
+	ContainerRuntimeDocker     ContainerRuntime = "docker"
+	ContainerRuntimeContainerd ContainerRuntime = "containerd"
 
 	ECSLaunchTypeEC2      ECSLaunchType = "ec2"
 	ECSLaunchTypeFargate  ECSLaunchType = "fargate"
@@ -39,6 +46,7 @@ const (
 // usage of interface{}.
 type Entity interface {
 	GetID() EntityID
+	String(verbose bool) string
 }
 
 // EntityID represents the ID of an Entity.
@@ -54,6 +62,11 @@ func (i EntityID) GetID() EntityID {
 	return i
 }
 
+// String returns a string representation of EntityID.
+func (i EntityID) String(_ bool) string {
+	return fmt.Sprintln("Kind:", i.Kind, "ID:", i.ID)
+}
+
 var _ Entity = EntityID{}
 
 // EntityMeta represents generic metadata about an Entity.
@@ -64,6 +77,20 @@ type EntityMeta struct {
 	Labels      map[string]string
 }
 
+// String returns a string representation of EntityMeta.
+func (e EntityMeta) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Name:", e.Name)
+	_, _ = fmt.Fprintln(&sb, "Namespace:", e.Namespace)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Annotations:", mapToString(e.Annotations))
+		_, _ = fmt.Fprintln(&sb, "Labels:", mapToString(e.Labels))
+	}
+
+	return sb.String()
+}
+
 // ContainerImage is the an image used by a container.
 type ContainerImage struct {
 	ID        string
@@ -73,6 +100,21 @@ type ContainerImage struct {
 	Tag       string
 }
 
+// String returns a string representation of ContainerImage.
+func (c ContainerImage) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Name:", c.Name)
+	_, _ = fmt.Fprintln(&sb, "Tag:", c.Tag)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "ID:", c.ID)
+		_, _ = fmt.Fprintln(&sb, "Raw Name:", c.RawName)
+		_, _ = fmt.Fprintln(&sb, "Short Name:", c.ShortName)
+	}
+
+	return sb.String()
+}
+
 // ContainerState is the state of a container.
 type ContainerState struct {
 	Running    bool
@@ -80,12 +122,37 @@ type ContainerState struct {
 	FinishedAt time.Time
 }
 
+// String returns a string representation of ContainerState.
+func (c ContainerState) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Running:", c.Running)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Started At:", c.StartedAt)
+		_, _ = fmt.Fprintln(&sb, "Finished At:", c.FinishedAt)
+	}
+
+	return sb.String()
+}
+
 // ContainerPort is a port open in the container.
 type ContainerPort struct {
 	Name string
 	Port int
 }
 
+// String returns a string representation of ContainerPort.
+func (c ContainerPort) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Port:", c.Port)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Name:", c.Name)
+	}
+
+	return sb.String()
+}
+
 // Container is a containerized workload.
 type Container struct {
 	EntityID
@@ -102,6 +169,36 @@ func (c Container) GetID() EntityID {
 	return c.EntityID
 }
 
+// String returns a string representation of Container.
+func (c Container) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, c.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, c.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Image -----------")
+	_, _ = fmt.Fprint(&sb, c.Image.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Container Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Runtime:", c.Runtime)
+	_, _ = fmt.Fprint(&sb, c.State.String(verbose))
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Env Variables:", mapToString(c.EnvVars))
+
+		if len(c.Ports) > 0 {
+			_, _ = fmt.Fprintln(&sb, "----------- Ports -----------")
+			for _, p := range c.Ports {
+				_, _ = fmt.Fprint(&sb, p.String(verbose))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
 var _ Entity = Container{}
 
 // KubernetesPod is a Kubernetes Pod.
@@ -122,6 +219,40 @@ func (p KubernetesPod) GetID() EntityID {
 	return p.EntityID
 }
 
+// String returns a string representation of KubernetesPod.
+func (p KubernetesPod) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityMeta.String(verbose))
+
+	if len(p.Owners) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Owners -----------")
+		for _, o := range p.Owners {
+			_, _ = fmt.Fprint(&sb, o.String(verbose))
+		}
+	}
+
+	_, _ = fmt.Fprintln(&sb, "----------- Containers -----------")
+	for _, c := range p.Containers {
+		_, _ = fmt.Fprintln(&sb, c)
+	}
+
+	_, _ = fmt.Fprintln(&sb, "----------- Pod Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Ready:", p.Ready)
+	_, _ = fmt.Fprintln(&sb, "Phase:", p.Phase)
+	_, _ = fmt.Fprintln(&sb, "IP:", p.IP)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Priority Class:", p.PriorityClass)
+		_, _ = fmt.Fprintln(&sb, "PVCs:", strings.Join(p.PersistentVolumeClaimNames, " "))
+	}
+
+	return sb.String()
+}
+
 var _ Entity = KubernetesPod{}
 
 // KubernetesPodOwner is extracted from a pod's owner references.
@@ -131,6 +262,18 @@ type KubernetesPodOwner struct {
 	ID   string
 }
 
+// String returns a string representation of KubernetesPodOwner.
+func (o KubernetesPodOwner) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Kind:", o.Kind, "Name:", o.Name)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "ID:", o.ID)
+	}
+
+	return sb.String()
+}
+
 // ECSTask is an ECS Task.
 type ECSTask struct {
 	EntityID
@@ -144,8 +287,88 @@ func (t ECSTask) GetID() EntityID {
 	return t.EntityID
 }
 
+// String returns a string representation of ECSTask.
+func (t ECSTask) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, t.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, t.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Containers -----------")
+	for _, c := range t.Containers {
+		_, _ = fmt.Fprintln(&sb, c.ID)
+	}
+
+	_, _ = fmt.Fprintln(&sb, "----------- Task Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Launch Type:", t.LaunchType)
+
+	return sb.String()
+}
+
 var _ Entity = ECSTask{}
 
+// Process is a process running on the host. Its ID is its PID.
+type Process struct {
+	EntityID
+	EntityMeta
+	PID            int
+	PPID           int
+	Cmdline        []string
+	Exe            string
+	StartTime      time.Time
+	ContainerID    string
+	ListeningPorts []ProcessPort
+}
+
+// GetID returns the Process's EntityID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// String returns a string representation of Process.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Name:", p.Name)
+	_, _ = fmt.Fprintln(&sb, "PID:", p.PID)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "PPID:", p.PPID)
+		_, _ = fmt.Fprintln(&sb, "Executable:", p.Exe)
+		_, _ = fmt.Fprintln(&sb, "Command Line:", strings.Join(p.Cmdline, " "))
+		_, _ = fmt.Fprintln(&sb, "Start Time:", p.StartTime)
+	}
+
+	if len(p.ListeningPorts) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Listening Ports -----------")
+		for _, port := range p.ListeningPorts {
+			_, _ = fmt.Fprint(&sb, port.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = Process{}
+
+// ProcessPort is a port a process listens on.
+type ProcessPort struct {
+	Protocol string
+	IP       string
+	Port     int
+}
+
+// String returns a string representation of ProcessPort.
+func (p ProcessPort) String(_ bool) string {
+	return fmt.Sprintln("Port:", p.Port, "Protocol:", p.Protocol, "IP:", p.IP)
+}
+
 // Event is an event generated by a metadata collector.
 type Event struct {
 	Type   EventType
@@ -159,3 +382,18 @@ type EventBundle struct {
 	Events []Event
 	Ch     chan struct{}
 }
+
+func mapToString(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(&sb, "%s:%s ", k, m[k])
+	}
+
+	return strings.TrimSuffix(sb.String(), " ")
+}
@@ -33,7 +33,6 @@ ALL_TAGS = set(
         "secrets",
         "systemd",
         "zk",
-        "zlib",
     ]
 )
 
@@ -61,24 +60,23 @@ AGENT_TAGS = set(
         "secrets",
         "systemd",
         "zk",
-        "zlib",
     ]
 )
 
 # ANDROID_TAGS lists the tags needed when building the android agent
-ANDROID_TAGS = set(["android", "zlib"])
+ANDROID_TAGS = set(["android"])
 
 # CLUSTER_AGENT_TAGS lists the tags needed when building the cluster-agent
-CLUSTER_AGENT_TAGS = set(["clusterchecks", "kubeapiserver", "orchestrator", "secrets", "zlib", "ec2", "gce"])
+CLUSTER_AGENT_TAGS = set(["clusterchecks", "kubeapiserver", "orchestrator", "secrets", "ec2", "gce"])
 
 # CLUSTER_AGENT_CLOUDFOUNDRY_TAGS lists the tags needed when building the cloudfoundry cluster-agent
 CLUSTER_AGENT_CLOUDFOUNDRY_TAGS = set(["clusterchecks", "secrets"])
 
 # DOGSTATSD_TAGS lists the tags needed when building dogstatsd
-DOGSTATSD_TAGS = set(["docker", "kubelet", "secrets", "zlib"])
+DOGSTATSD_TAGS = set(["docker", "kubelet", "secrets"])
 
 # IOT_AGENT_TAGS lists the tags needed when building the IoT agent
-IOT_AGENT_TAGS = set(["jetson", "systemd", "zlib"])
+IOT_AGENT_TAGS = set(["jetson", "systemd"])
 
 # PROCESS_AGENT_TAGS lists the tags necessary to build the process-agent
 PROCESS_AGENT_TAGS = AGENT_TAGS.union(set(["clusterchecks", "fargateprocess", "orchestrator"]))
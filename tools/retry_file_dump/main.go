@@ -8,6 +8,10 @@ package main
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -16,31 +20,78 @@ import (
 	"io/ioutil"
 	"path"
 	"path/filepath"
+	"strings"
 
 	proto "github.com/golang/protobuf/proto"
 )
 
+// These values must match the ones of pkg/forwarder/internal/retry/file_cipher.go
+const (
+	keyDerivationSalt = "datadog-agent forwarder storage"
+	keyDerivationInfo = "retry files encryption v1"
+)
+
+var retryFileMagic = []byte{'D', 'D', 'R', 'Q', 1}
+
 func main() {
-	folder, err := parseArg()
+	folder, secret, err := parseArg()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	aead, err := newAEAD(secret)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	if err = dumpRetryFiles(folder); err != nil {
+	if err = dumpRetryFiles(folder, aead); err != nil {
 		fmt.Println(err)
 	}
 }
 
-func parseArg() (string, error) {
+func parseArg() (string, []byte, error) {
 	var folder = flag.String("folder", "", "The folder containing `.retry` files.")
+	var key = flag.String("key", "", "The value of `forwarder_storage_encryption_key`.")
+	var keyfile = flag.String("keyfile", "", "The keyfile used to encrypt the `.retry` files, `retry_files.key` in the storage folder by default.")
 	flag.Parse()
 	if *folder == "" {
-		return "", errors.New("Invalid folder: Usage `./retry_file_dump --folder=/opt/datadog-agent/run/transactions_to_retry/c47da40ac935c8fd5ca1441a5ee3d068/`")
+		return "", nil, errors.New("Invalid folder: Usage `./retry_file_dump --folder=/opt/datadog-agent/run/transactions_to_retry/core/c47da40ac935c8fd5ca1441a5ee3d068/ --keyfile=/opt/datadog-agent/run/transactions_to_retry/core/retry_files.key`")
+	}
+	if *key != "" {
+		return *folder, []byte(*key), nil
+	}
+	if *keyfile == "" {
+		return "", nil, errors.New("Either --key or --keyfile must be set")
+	}
+	secret, err := ioutil.ReadFile(*keyfile)
+	if err != nil {
+		return "", nil, err
+	}
+	return *folder, []byte(strings.TrimSpace(string(secret))), nil
+}
+
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	extract := hmac.New(sha256.New, []byte(keyDerivationSalt))
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(keyDerivationInfo))
+	expand.Write([]byte{1})
+	block, err := aes.NewCipher(expand.Sum(nil))
+	if err != nil {
+		return nil, err
 	}
-	return *folder, nil
+	return cipher.NewGCM(block)
 }
 
-func dumpRetryFiles(folder string) error {
+func decrypt(aead cipher.AEAD, data []byte) ([]byte, error) {
+	headerSize := len(retryFileMagic) + aead.NonceSize()
+	if len(data) < headerSize || !bytes.Equal(data[:len(retryFileMagic)], retryFileMagic) {
+		return nil, errors.New("not an encrypted retry file")
+	}
+	return aead.Open(nil, data[len(retryFileMagic):headerSize], data[headerSize:], retryFileMagic)
+}
+
+func dumpRetryFiles(folder string, aead cipher.AEAD) error {
 	entries, err := ioutil.ReadDir(folder)
 	if err != nil {
 		return err
@@ -50,7 +101,7 @@ func dumpRetryFiles(folder string) error {
 		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == ".retry" {
 			fmt.Println(entry.Name())
 			filePath := path.Join(folder, entry.Name())
-			fileContent, err := dumpRetryFile(filePath)
+			fileContent, err := dumpRetryFile(filePath, aead)
 			if err != nil {
 				return err
 			}
@@ -64,11 +115,14 @@ func dumpRetryFiles(folder string) error {
 	return nil
 }
 
-func dumpRetryFile(file string) ([]byte, error) {
+func dumpRetryFile(file string, aead cipher.AEAD) ([]byte, error) {
 	content, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
+	if content, err = decrypt(aead, content); err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s: %v", file, err)
+	}
 	collection := HttpTransactionProtoCollection{}
 
 	if err := proto.Unmarshal(content, &collection); err != nil {